@@ -3,15 +3,25 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"maps"
+	"net/url"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
+	"github.com/shaftoe/savetoink/internal/auth"
 	"github.com/shaftoe/savetoink/internal/config"
 	"github.com/shaftoe/savetoink/internal/consts"
+	"github.com/shaftoe/savetoink/internal/content"
 	"github.com/shaftoe/savetoink/internal/email"
+	"github.com/shaftoe/savetoink/internal/epub"
+	"github.com/shaftoe/savetoink/internal/importer"
+	"github.com/shaftoe/savetoink/internal/model"
 	"github.com/shaftoe/savetoink/internal/service"
+	"github.com/shaftoe/savetoink/internal/transform"
 	"github.com/spf13/cobra"
 )
 
@@ -24,6 +34,26 @@ var (
 
 	sendEmail    bool
 	emailSubject string
+
+	linkMode        string
+	direction       string
+	verticalWriting bool
+	htmlFile        string
+	tags            []string
+	collection      string
+
+	userAgent    string
+	maxSize      int64
+	maxRedirects int
+	headers      []string
+	proxy        string
+	noCache      bool
+	robotsTxt    bool
+
+	account      string
+	importFormat string
+	exportEPUB   bool
+	exportHTML   bool
 )
 
 var rootCmd = &cobra.Command{
@@ -36,28 +66,113 @@ var convertCmd = &cobra.Command{
 	Use:   "convert [url]",
 	Short: "Convert a URL to EPUB",
 	Long: `Fetch a web article from given URL and convert it to EPUB format.
+ Use --html-file to convert a page saved from the browser instead of fetching the URL.
+ Use --tag and --collection to organize the article, listed in the EPUB metadata.
  Use --send to skip local EPUB generation and send converted EPUB to your Kindle.`,
 	Args: cobra.ExactArgs(1),
 	RunE: runConvert,
 }
 
-func runConvert(_ *cobra.Command, args []string) error {
+var validateCmd = &cobra.Command{
+	Use:   "validate [file.epub]",
+	Short: "Validate the structure of an EPUB file",
+	Long: `Check an EPUB file for structural problems that make e-readers reject it:
+ mimetype ordering, container.xml, OPF manifest and spine, XHTML well-formedness and missing resources.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runValidate,
+}
+
+var rulesCmd = &cobra.Command{
+	Use:   "rules",
+	Short: "Inspect site-specific extraction rules",
+}
+
+var rulesTestCmd = &cobra.Command{
+	Use:   "test [url]",
+	Short: "Preview the effect of extraction rules on a URL",
+	Long: `Extract the article at given URL with and without the matching site-specific rule
+ (bundled or from SAVETOINK_RULES_FILE) and compare the results.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runRulesTest,
+}
+
+var importCmd = &cobra.Command{
+	Use:   "import [file]",
+	Short: "Import articles exported from Pocket, Instapaper, Omnivore or a browser",
+	Long: `Save the links of a Pocket HTML or CSV export, an Instapaper CSV export, an Omnivore JSON export
+ or a browser bookmark file, keeping the time they were saved and their tags. Links already saved are skipped
+ and imported articles are not sent to Kindle. Requires the DynamoDB table of the server.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runImport,
+}
+
+var exportCmd = &cobra.Command{
+	Use:   "export [file.zip]",
+	Short: "Export the saved articles to a zip archive",
+	Long: `Write every saved article with its content to a zip archive as JSON Lines, with a browser bookmark file
+ and optionally the EPUB and HTML file of each article. Requires the DynamoDB table of the server.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runExport,
+}
+
+func runConvert(cmd *cobra.Command, args []string) error {
 	url := args[0]
 
+	links, err := transform.ParseLinkMode(linkMode)
+	if err != nil {
+		return err
+	}
+
+	textDirection, err := epub.ParseDirection(direction)
+	if err != nil {
+		return err
+	}
+
 	cfg, err := config.Load(consts.ModeCLI)
 	if err != nil {
 		return fmt.Errorf("failed to load config: %w", err)
 	}
 
+	if err = applyFetchFlags(cmd, cfg); err != nil {
+		return err
+	}
+
+	articleTags, err := service.ParseTags(tags)
+	if err != nil {
+		return err
+	}
+
+	articleCollection, err := service.ParseCollection(collection)
+	if err != nil {
+		return err
+	}
+
+	opts := service.ProcessOptions{
+		LinkMode:        links,
+		Direction:       textDirection,
+		VerticalWriting: verticalWriting,
+		NoCache:         noCache,
+		Tags:            articleTags,
+		Collection:      articleCollection,
+	}
+
+	if htmlFile != "" {
+		opts.HTML, err = os.ReadFile(htmlFile)
+		if err != nil {
+			return fmt.Errorf("failed to read HTML file: %w", err)
+		}
+		fmt.Printf("Reading article from: %s (%s)\n", htmlFile, url)
+	} else {
+		fmt.Printf("Fetching article from: %s\n", url)
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), timeout)
 	defer cancel()
 
-	fmt.Printf("Fetching article from: %s\n", url)
-
 	svc := service.New(cfg)
 
 	start := time.Now()
-	result, err := svc.Process(ctx, url)
+	result, err := svc.Process(ctx, url, opts)
 	if err != nil {
 		return fmt.Errorf("failed to process article: %w", err)
 	}
@@ -82,6 +197,223 @@ func runConvert(_ *cobra.Command, args []string) error {
 	return nil
 }
 
+func runValidate(_ *cobra.Command, args []string) error {
+	data, err := os.ReadFile(args[0])
+	if err != nil {
+		return fmt.Errorf("failed to read EPUB: %w", err)
+	}
+
+	report := epub.Validate(data)
+	for _, issue := range report.Issues {
+		fmt.Printf("[%s] %s\n", issue.Severity, issue)
+	}
+
+	if validationErr := report.Err(); validationErr != nil {
+		return validationErr
+	}
+
+	fmt.Printf("\n✓ EPUB is valid (%d warnings)\n", len(report.Warnings()))
+
+	return nil
+}
+
+func runImport(cmd *cobra.Command, args []string) error {
+	format, err := importer.ParseFormat(importFormat)
+	if err != nil {
+		return err
+	}
+
+	data, err := os.ReadFile(args[0])
+	if err != nil {
+		return fmt.Errorf("failed to read import file: %w", err)
+	}
+
+	cfg, err := config.Load(consts.ModeCLI)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	if err = applyFetchFlags(cmd, cfg); err != nil {
+		return err
+	}
+
+	if cfg.DynamoDBTable == "" {
+		return errors.New("SAVETOINK_DYNAMODB_TABLE_NAME is required to import articles")
+	}
+	if err = cfg.LoadAWSConfig(context.Background()); err != nil {
+		return err
+	}
+
+	start := time.Now()
+	job, err := service.New(cfg).Import(context.Background(), account, data, service.ImportOptions{
+		Format: format,
+		Wait:   true,
+		Progress: func(job *model.ImportJob) {
+			fmt.Printf("\r%d/%d processed", job.Imported+job.Skipped+job.Failed, job.Total)
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to import: %w", err)
+	}
+
+	fmt.Printf("\n\n✓ Imported %d articles from %s in %v (%d already saved, %d failed)\n",
+		job.Imported, job.Format, time.Since(start).Round(time.Second), job.Skipped, job.Failed)
+	for _, importErr := range job.Errors {
+		fmt.Printf("  %s\n", importErr)
+	}
+
+	return nil
+}
+
+func runExport(_ *cobra.Command, args []string) error {
+	path := "savetoink-export.zip"
+	if len(args) > 0 {
+		path = args[0]
+	}
+
+	cfg, err := config.Load(consts.ModeCLI)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	if cfg.DynamoDBTable == "" {
+		return errors.New("SAVETOINK_DYNAMODB_TABLE_NAME is required to export articles")
+	}
+	if err = cfg.LoadAWSConfig(context.Background()); err != nil {
+		return err
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create export file: %w", err)
+	}
+
+	start := time.Now()
+	count, err := service.New(cfg).Export(context.Background(), account, file, service.ExportOptions{
+		EPUB: exportEPUB,
+		HTML: exportHTML,
+	})
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(path)
+		return fmt.Errorf("failed to export: %w", err)
+	}
+
+	fmt.Printf("✓ Exported %d articles to %s in %v\n", count, path, time.Since(start).Round(time.Second))
+
+	return nil
+}
+
+func runRulesTest(cmd *cobra.Command, args []string) error {
+	rawURL := args[0]
+
+	cfg, err := config.Load(consts.ModeCLI)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	if err = applyFetchFlags(cmd, cfg); err != nil {
+		return err
+	}
+
+	parsedURL, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("failed to parse URL: %w", err)
+	}
+
+	rule := cfg.Rules.Match(parsedURL)
+	if rule == nil {
+		fmt.Printf("No rule matches %s (%d rules loaded)\n", rawURL, cfg.Rules.Len())
+		return nil
+	}
+	fmt.Printf("Matched rule: %s\n", rule.Name)
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	without, err := content.NewExtractor(content.WithFetchConfig(cfg.Fetch)).ExtractFromURL(ctx, rawURL)
+	if err != nil {
+		fmt.Printf("Extraction without rule failed: %v\n", err)
+		without = &model.Article{}
+	}
+
+	with, err := content.NewExtractor(content.WithRules(cfg.Rules), content.WithFetchConfig(cfg.Fetch)).
+		ExtractFromURL(ctx, rawURL)
+	if err != nil {
+		return fmt.Errorf("failed to extract article with rule %s: %w", rule.Name, err)
+	}
+
+	fmt.Printf("\n%-10s %-40s %s\n", "", "without rule", "with rule")
+	fmt.Printf("%-10s %-40.40s %s\n", "Title", without.Title, with.Title)
+	fmt.Printf("%-10s %-40.40s %s\n", "Author", without.Author, with.Author)
+	fmt.Printf("%-10s %-40s %s\n", "Extractor", without.Extractor, with.Extractor)
+	fmt.Printf("%-10s %-40d %d\n", "Words", without.WordCount, with.WordCount)
+
+	printVerboseOutput(service.NewProcessResult(with, nil, rawURL))
+
+	return nil
+}
+
+// applyFetchFlags overrides the fetch configuration read from the environment with the flags set by the user.
+func applyFetchFlags(cmd *cobra.Command, cfg *config.Config) error {
+	flags := cmd.Flags()
+
+	if flags.Changed("user-agent") {
+		cfg.Fetch.UserAgent = userAgent
+	}
+	if flags.Changed("timeout") {
+		cfg.Fetch.Timeout = timeout
+	}
+	if flags.Changed("max-size") {
+		cfg.Fetch.MaxBodySize = maxSize
+	}
+	if flags.Changed("max-redirects") {
+		cfg.Fetch.MaxRedirects = maxRedirects
+	}
+
+	if flags.Changed("robots") {
+		cfg.Fetch.RobotsTxt = robotsTxt
+	}
+
+	if len(headers) > 0 {
+		merged := make(map[string]string, len(cfg.Fetch.Headers)+len(headers))
+		maps.Copy(merged, cfg.Fetch.Headers)
+		for _, header := range headers {
+			name, value, ok := strings.Cut(header, ":")
+			if !ok || strings.TrimSpace(name) == "" {
+				return fmt.Errorf("invalid header %q, expected \"Name: value\"", header)
+			}
+			merged[strings.TrimSpace(name)] = strings.TrimSpace(value)
+		}
+		cfg.Fetch.Headers = merged
+	}
+
+	if proxy != "" {
+		proxyURL, err := content.ParseProxyURL(proxy)
+		if err != nil {
+			return err
+		}
+		cfg.Fetch.Proxy = proxyURL
+	}
+
+	return nil
+}
+
+// addFetchFlags adds the flags configuring how URLs are fetched.
+func addFetchFlags(cmd *cobra.Command) {
+	cmd.Flags().DurationVarP(&timeout, "timeout", "t",
+		defaultTimeoutSeconds*time.Second, "Timeout for HTTP requests")
+	cmd.Flags().StringVar(&userAgent, "user-agent", consts.FetchUserAgent, "User-Agent header sent when fetching")
+	cmd.Flags().Int64Var(&maxSize, "max-size", consts.FetchMaxBodySize, "Maximum size in bytes of fetched pages")
+	cmd.Flags().IntVar(&maxRedirects, "max-redirects", consts.FetchMaxRedirects, "Maximum number of redirects followed")
+	cmd.Flags().StringArrayVarP(&headers, "header", "H", nil,
+		`Extra request header as "Name: value", can be repeated`)
+	cmd.Flags().StringVar(&proxy, "proxy", "", "Proxy URL (http, https or socks5) to fetch through")
+	cmd.Flags().BoolVar(&robotsTxt, "robots", false, "Refuse to fetch pages disallowed by the site robots.txt")
+}
+
 func printVerboseOutput(result *service.ProcessResult) {
 	if verbose {
 		fmt.Println("\n--- Extracted Content (HTML) ---")
@@ -105,14 +437,41 @@ func printResult(resp *email.SendEmailResponse) {
 
 func main() {
 	convertCmd.Flags().StringVarP(&outputPath, "output", "o", "article.epub", "Output file path")
-	convertCmd.Flags().DurationVarP(&timeout, "timeout", "t",
-		defaultTimeoutSeconds*time.Second, "Timeout for HTTP requests")
+	addFetchFlags(convertCmd)
 	convertCmd.Flags().BoolVarP(&verbose, "verbose", "v", false, "Show extracted HTML content")
 
 	convertCmd.Flags().BoolVar(&sendEmail, "send", false, "Send EPUB to Kindle via email instead of saving locally")
 	convertCmd.Flags().StringVar(&emailSubject, "email-subject", "", "Email subject (defaults to article title)")
+	convertCmd.Flags().StringVar(&linkMode, "links", string(consts.LinkModeKeep),
+		"How to render hyperlinks: keep, strip or endnotes")
+	convertCmd.Flags().StringVar(&direction, "direction", "auto",
+		"Text direction: auto (from article language), ltr or rtl")
+	convertCmd.Flags().BoolVar(&verticalWriting, "vertical", false,
+		"Use vertical writing mode for languages supporting it (Japanese)")
+	convertCmd.Flags().StringVar(&htmlFile, "html-file", "",
+		"Convert the HTML page saved in this file instead of fetching the URL")
+	convertCmd.Flags().BoolVar(&noCache, "no-cache", false, "Fetch the URL even when a cached copy is fresh")
+	convertCmd.Flags().StringArrayVar(&tags, "tag", nil, "Tag of the article listed in the EPUB metadata (repeatable)")
+	convertCmd.Flags().StringVar(&collection, "collection", "", "Collection of the article listed in the EPUB metadata")
 
 	rootCmd.AddCommand(convertCmd)
+	rootCmd.AddCommand(validateCmd)
+
+	addFetchFlags(importCmd)
+	importCmd.Flags().StringVar(&account, "account", auth.AdminAccountID, "Account to import the articles to")
+	importCmd.Flags().StringVar(&importFormat, "format", "",
+		"Format of the file: pocket-html, pocket-csv, instapaper-csv, omnivore-json or bookmarks (detected by default)")
+	rootCmd.AddCommand(importCmd)
+
+	exportCmd.Flags().StringVar(&account, "account", auth.AdminAccountID, "Account to export the articles of")
+	exportCmd.Flags().BoolVar(&exportEPUB, "epub", false, "Add the EPUB file of each article")
+	exportCmd.Flags().BoolVar(&exportHTML, "html", false, "Add the HTML file of each article")
+	rootCmd.AddCommand(exportCmd)
+
+	addFetchFlags(rulesTestCmd)
+	rulesTestCmd.Flags().BoolVarP(&verbose, "verbose", "v", false, "Show HTML content extracted with the rule")
+	rulesCmd.AddCommand(rulesTestCmd)
+	rootCmd.AddCommand(rulesCmd)
 
 	if err := rootCmd.Execute(); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
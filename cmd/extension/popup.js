@@ -25,7 +25,7 @@ document.addEventListener("DOMContentLoaded", () => {
 
       const response = await makeApiRequest("/v1/articles", {
         method: "POST",
-        body: JSON.stringify({ url: tab.url }),
+        body: JSON.stringify({ url: tab.url, html: await captureHTML(tab) }),
       });
 
       if (response.ok) {
@@ -41,6 +41,20 @@ document.addEventListener("DOMContentLoaded", () => {
     }
   });
 
+  // Send the DOM rendered in the tab so that paywalled and logged-in pages
+  // can be saved, the server fetches the URL when the page can't be captured.
+  async function captureHTML(tab) {
+    try {
+      const [injection] = await chrome.scripting.executeScript({
+        target: { tabId: tab.id },
+        func: () => document.documentElement.outerHTML,
+      });
+      return injection.result;
+    } catch {
+      return undefined;
+    }
+  }
+
   optionsLink.addEventListener("click", (e) => {
     e.preventDefault();
     chrome.runtime.openOptionsPage();
@@ -2,6 +2,7 @@
 package main
 
 import (
+	"context"
 	"log/slog"
 	"net/http"
 	"os"
@@ -9,6 +10,7 @@ import (
 	"github.com/shaftoe/savetoink/internal/config"
 	"github.com/shaftoe/savetoink/internal/consts"
 	"github.com/shaftoe/savetoink/internal/server"
+	"github.com/shaftoe/savetoink/internal/service"
 )
 
 func main() {
@@ -20,6 +22,11 @@ func main() {
 
 	router := server.NewRouter(cfg)
 
+	if cfg.FeedPollInterval > 0 {
+		slog.Info("starting feed poller", "interval", cfg.FeedPollInterval)
+		go service.New(cfg).RunFeedPoller(context.Background(), cfg.FeedPollInterval)
+	}
+
 	port := "8080"
 	slog.Info("starting HTTP server", "port", port)
 	srv := &http.Server{
@@ -2,23 +2,68 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
 	"log/slog"
+	"net/http"
 	"os"
 
 	"github.com/akrylysov/algnhsa"
+	"github.com/aws/aws-lambda-go/lambda"
 	"github.com/shaftoe/savetoink/internal/config"
 	"github.com/shaftoe/savetoink/internal/consts"
 	"github.com/shaftoe/savetoink/internal/server"
+	"github.com/shaftoe/savetoink/internal/service"
 )
 
+// binaryContentTypes are the content types of the responses returned base64 encoded, the function URL decodes
+// them, other bodies are returned as text.
+var binaryContentTypes = []string{"application/zip", "application/epub+zip"}
+
+// newHTTPHandler adapts router to the function URL requests and responses.
+func newHTTPHandler(router http.Handler) lambda.Handler {
+	return algnhsa.New(router, &algnhsa.Options{BinaryContentTypes: binaryContentTypes})
+}
+
+// scheduledEvent holds the fields identifying the events sent by EventBridge schedules.
+type scheduledEvent struct {
+	Source     string `json:"source"`
+	DetailType string `json:"detail-type"`
+}
+
+// handler polls the feeds of all accounts when invoked by an EventBridge schedule, since the HTTP server poller
+// doesn't run on Lambda, and serves HTTP requests otherwise.
+type handler struct {
+	http    lambda.Handler
+	service *service.Service
+}
+
+func (h *handler) Invoke(ctx context.Context, payload []byte) ([]byte, error) {
+	var event scheduledEvent
+	if err := json.Unmarshal(payload, &event); err != nil || event.Source != "aws.events" ||
+		event.DetailType != "Scheduled Event" {
+		return h.http.Invoke(ctx, payload)
+	}
+
+	result, err := h.service.PollFeeds(ctx, "")
+	if err != nil {
+		slog.Error("failed to poll feeds", "error", err)
+		return nil, err
+	}
+	slog.Info("polled feeds", "feeds", result.Feeds, "articles", result.Articles, "digests", result.Digests)
+
+	return json.Marshal(result)
+}
+
 func main() {
 	cfg, err := config.Load(consts.ModeServer)
 	if err != nil {
 		slog.Error("failed to load configuration", "error", err)
 		os.Exit(1)
 	}
+	cfg.Lambda = true
 
 	router := server.NewRouter(cfg)
 
-	algnhsa.ListenAndServe(router, nil)
+	lambda.Start(&handler{http: newHTTPHandler(router), service: service.New(cfg)})
 }
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+// functionURLRequest is a function URL request payload, in the API Gateway HTTP API 2.0 format.
+const functionURLRequest = `{
+  "version": "2.0",
+  "routeKey": "$default",
+  "rawPath": "/v1/export",
+  "rawQueryString": "",
+  "headers": {"host": "example.lambda-url.eu-west-1.on.aws"},
+  "requestContext": {
+    "domainName": "example.lambda-url.eu-west-1.on.aws",
+    "http": {"method": "GET", "path": "/v1/export", "protocol": "HTTP/1.1", "sourceIp": "127.0.0.1"},
+    "requestId": "id",
+    "routeKey": "$default",
+    "stage": "$default"
+  },
+  "isBase64Encoded": false
+}`
+
+func TestHTTPHandlerBinaryResponse(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		expectZip   bool
+	}{
+		{name: "export archive", contentType: "application/zip", expectZip: true},
+		{name: "epub", contentType: "application/epub+zip", expectZip: true},
+		{name: "json", contentType: "application/json"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var archive bytes.Buffer
+			zw := zip.NewWriter(&archive)
+			f, err := zw.Create("articles.json")
+			if err != nil {
+				t.Fatalf("failed to create archive entry: %v", err)
+			}
+			if _, err = f.Write([]byte(`[]`)); err != nil {
+				t.Fatalf("failed to write archive entry: %v", err)
+			}
+			if err = zw.Close(); err != nil {
+				t.Fatalf("failed to close archive: %v", err)
+			}
+
+			router := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.Header().Set("Content-Type", tt.contentType)
+				_, _ = w.Write(archive.Bytes())
+			})
+
+			payload, err := newHTTPHandler(router).Invoke(context.Background(), []byte(functionURLRequest))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			var resp struct {
+				Body            string `json:"body"`
+				IsBase64Encoded bool   `json:"isBase64Encoded"`
+			}
+			if err = json.Unmarshal(payload, &resp); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if resp.IsBase64Encoded != tt.expectZip {
+				t.Fatalf("expected isBase64Encoded %v, got %v", tt.expectZip, resp.IsBase64Encoded)
+			}
+			if !tt.expectZip {
+				return
+			}
+
+			body, err := base64.StdEncoding.DecodeString(resp.Body)
+			if err != nil {
+				t.Fatalf("failed to decode body: %v", err)
+			}
+			zr, err := zip.NewReader(bytes.NewReader(body), int64(len(body)))
+			if err != nil {
+				t.Fatalf("failed to read archive: %v", err)
+			}
+			if len(zr.File) != 1 || zr.File[0].Name != "articles.json" {
+				t.Errorf("expected the archive with articles.json, got %d files", len(zr.File))
+			}
+		})
+	}
+}
@@ -61,4 +61,24 @@ describe('ArticleControls.svelte', () => {
 
 		confirmSpy.mockRestore();
 	});
+
+	it('should render unarchive and unfavorite buttons for archived favorites', async () => {
+		const article: ArticleType = {
+			account: 'test-account',
+			id: '1',
+			url: 'https://example.com/article',
+			createdAt: '2024-01-01T00:00:00Z',
+			title: 'Test Article',
+			readState: 'archived',
+			favorite: true
+		};
+
+		const { container } = render(ArticleControls, { article });
+
+		await expect.element(page.getByRole('button', { name: 'Unarchive' })).toBeInTheDocument();
+		await expect.element(page.getByRole('button', { name: 'Unfavorite' })).toBeInTheDocument();
+
+		const readState = container.querySelector('form[action="?/archive"] input[name="readState"]');
+		expect(readState?.getAttribute('value')).toBe('unread');
+	});
 });
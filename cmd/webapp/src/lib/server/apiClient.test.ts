@@ -127,6 +127,37 @@ describe('ApiClient', () => {
 			expect(result).toEqual(mockResponse);
 		});
 
+		it('should fetch articles filtered by read state', async () => {
+			const mockResponse: ListArticlesResponse = {
+				articles: [],
+				page: 1,
+				page_size: 10,
+				total: 0,
+				has_more: false
+			};
+
+			mockFetch.mockResolvedValue({
+				ok: true,
+				json: async () => mockResponse
+			});
+
+			const client = createApiClient('test-key', 'http://localhost:8080');
+			await client.getArticles(mockFetch as unknown as typeof globalThis.fetch, 1, 10, [
+				'unread',
+				'read'
+			]);
+
+			expect(mockFetch).toHaveBeenCalledWith(
+				'http://localhost:8080/v1/articles?page=1&page_size=10&state=unread%2Cread',
+				{
+					headers: {
+						'Content-Type': 'application/json',
+						Authorization: 'Bearer test-key'
+					}
+				}
+			);
+		});
+
 		it('should fetch articles without pagination params', async () => {
 			const mockResponse: ListArticlesResponse = {
 				articles: [],
@@ -185,6 +216,41 @@ describe('ApiClient', () => {
 		});
 	});
 
+	describe('updateArticle', () => {
+		it('should patch article read state and favorite', async () => {
+			const mockArticle: Article = {
+				account: 'test-account',
+				id: '123',
+				url: 'https://example.com',
+				createdAt: '2024-01-01T00:00:00Z',
+				readState: 'archived',
+				favorite: true
+			};
+
+			mockFetch.mockResolvedValue({
+				ok: true,
+				json: async () => mockArticle
+			});
+
+			const client = createApiClient('test-key', 'http://localhost:8080');
+			const result = await client.updateArticle(
+				'123',
+				{ readState: 'archived', favorite: true },
+				mockFetch as unknown as typeof globalThis.fetch
+			);
+
+			expect(mockFetch).toHaveBeenCalledWith('http://localhost:8080/v1/articles/123', {
+				method: 'PATCH',
+				body: JSON.stringify({ readState: 'archived', favorite: true }),
+				headers: {
+					'Content-Type': 'application/json',
+					Authorization: 'Bearer test-key'
+				}
+			});
+			expect(result).toEqual(mockArticle);
+		});
+	});
+
 	describe('deleteArticle', () => {
 		it('should delete article by id', async () => {
 			mockFetch.mockResolvedValue({
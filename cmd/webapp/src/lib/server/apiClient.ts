@@ -6,6 +6,8 @@ import type {
 	ListArticlesResponse,
 	DeleteArticleResponse,
 	HealthResponse,
+	ReadState,
+	UpdateArticleRequest,
 	ErrorResponse
 } from './types';
 
@@ -69,11 +71,13 @@ export class ApiClient {
 	async getArticles(
 		fetch: typeof globalThis.fetch,
 		page?: number,
-		pageSize?: number
+		pageSize?: number,
+		states?: ReadState[]
 	): Promise<ListArticlesResponse> {
 		const params = new URLSearchParams();
 		if (page) params.set('page', page.toString());
 		if (pageSize) params.set('page_size', pageSize.toString());
+		if (states?.length) params.set('state', states.join(','));
 		const query = params.toString();
 		return this.request<ListArticlesResponse>(`/v1/articles${query ? `?${query}` : ''}`, {}, fetch);
 	}
@@ -82,6 +86,21 @@ export class ApiClient {
 		return this.request<Article>(`/v1/articles/${id}`, {}, fetch);
 	}
 
+	async updateArticle(
+		id: string,
+		data: UpdateArticleRequest,
+		fetch: typeof globalThis.fetch
+	): Promise<Article> {
+		return this.request<Article>(
+			`/v1/articles/${id}`,
+			{
+				method: 'PATCH',
+				body: JSON.stringify(data)
+			},
+			fetch
+		);
+	}
+
 	async deleteArticle(id: string, fetch: typeof globalThis.fetch): Promise<DeleteArticleResponse> {
 		return this.request<DeleteArticleResponse>(
 			`/v1/articles/${id}`,
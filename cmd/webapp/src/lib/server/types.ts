@@ -1,9 +1,12 @@
+export type ReadState = 'unread' | 'read' | 'archived';
+
 // TODO: https://github.com/savetoink/savetoink/issues/2
 export interface Article {
 	account: string;
 	id: string;
 	url: string;
 	createdAt: string;
+	canonicalUrl?: string;
 	title?: string;
 	content?: string;
 	author?: string;
@@ -17,6 +20,20 @@ export interface Article {
 	wordCount?: number;
 	readingTimeMinutes?: number;
 	publishedAt?: string;
+	warnings?: string[];
+	extractor?: string;
+	clientCaptured?: boolean;
+	charset?: string;
+	tags?: string[];
+	collection?: string;
+	retryAt?: string;
+	readState?: ReadState;
+	readAt?: string;
+	archivedAt?: string;
+	favorite?: boolean;
+	favoritedAt?: string;
+	direction?: 'ltr' | 'rtl';
+	verticalWriting?: boolean;
 	deliveryStatus?: 'pending' | 'delivered' | 'failed';
 	deliveredFrom?: string;
 	deliveredTo?: string;
@@ -26,6 +43,12 @@ export interface Article {
 
 export interface CreateArticleRequest {
 	url: string;
+	html?: string;
+}
+
+export interface UpdateArticleRequest {
+	readState?: ReadState;
+	favorite?: boolean;
 }
 
 export interface CreateArticleResponse {
@@ -48,6 +71,40 @@ export interface DeleteArticleResponse {
 	deleted: number;
 }
 
+export interface Feed {
+	account: string;
+	id: string;
+	url: string;
+	title?: string;
+	delivery: 'immediate' | 'digest' | 'none';
+	subscribedAt: string;
+	lastItemId?: string;
+	lastItemAt?: string;
+	lastPolledAt?: string;
+	error?: string;
+	digestArticleIds?: string[];
+	lastDigestAt?: string;
+}
+
+export interface ListFeedsResponse {
+	feeds: Feed[];
+}
+
+export interface ImportJob {
+	account: string;
+	id: string;
+	format: 'pocket-html' | 'pocket-csv' | 'instapaper-csv' | 'omnivore-json' | 'bookmarks';
+	status: 'running' | 'completed' | 'failed';
+	startedAt: string;
+	updatedAt: string;
+	total: number;
+	imported: number;
+	skipped: number;
+	failed: number;
+	errors?: string[];
+	completedAt?: string;
+}
+
 export interface HealthResponse {
 	status: string;
 }
@@ -1,24 +1,35 @@
 import { fail } from '@sveltejs/kit';
 import type { Actions, PageServerLoad } from './$types';
 import { requireApiKey } from '$lib/server/auth';
+import type { ReadState } from '$lib/server/types';
+
+const inboxStates: ReadState[] = ['unread', 'read'];
+const archiveStates: ReadState[] = ['archived'];
 
 export const load: PageServerLoad = async ({ locals, fetch, url }) => {
 	const apiClient = requireApiKey(locals);
 
 	const pageParam = url.searchParams.get('page');
 	const pageSizeParam = url.searchParams.get('page_size');
+	const view = url.searchParams.get('view') === 'archive' ? 'archive' : 'inbox';
 
 	const page = pageParam ? parseInt(pageParam, 10) : 1;
 	const pageSize = pageSizeParam ? parseInt(pageSizeParam, 10) : 10;
 
 	try {
-		const response = await apiClient.getArticles(fetch, page, pageSize);
+		const response = await apiClient.getArticles(
+			fetch,
+			page,
+			pageSize,
+			view === 'archive' ? archiveStates : inboxStates
+		);
 		return {
 			articles: response.articles,
 			total: response.total,
 			page: response.page,
 			page_size: response.page_size,
 			has_more: response.has_more,
+			view,
 			error: undefined
 		};
 	} catch (err) {
@@ -28,6 +39,7 @@ export const load: PageServerLoad = async ({ locals, fetch, url }) => {
 			page: 1,
 			page_size: 10,
 			has_more: false,
+			view,
 			error: err instanceof Error ? err.message : 'failed to load articles'
 		};
 	}
@@ -50,5 +62,43 @@ export const actions: Actions = {
 		} catch (err) {
 			return fail(500, { error: err instanceof Error ? err.message : 'failed to delete article' });
 		}
+	},
+
+	archive: async ({ locals, fetch, request }) => {
+		const apiClient = requireApiKey(locals);
+
+		const formData = await request.formData();
+		const id = formData.get('id');
+		const readState = formData.get('readState') === 'unread' ? 'unread' : 'archived';
+
+		if (!id || typeof id !== 'string') {
+			return fail(400, { error: 'article id is required' });
+		}
+
+		try {
+			await apiClient.updateArticle(id, { readState }, fetch);
+			return { success: true };
+		} catch (err) {
+			return fail(500, { error: err instanceof Error ? err.message : 'failed to update article' });
+		}
+	},
+
+	favorite: async ({ locals, fetch, request }) => {
+		const apiClient = requireApiKey(locals);
+
+		const formData = await request.formData();
+		const id = formData.get('id');
+		const favorite = formData.get('favorite') === 'true';
+
+		if (!id || typeof id !== 'string') {
+			return fail(400, { error: 'article id is required' });
+		}
+
+		try {
+			await apiClient.updateArticle(id, { favorite }, fetch);
+			return { success: true };
+		} catch (err) {
+			return fail(500, { error: err instanceof Error ? err.message : 'failed to update article' });
+		}
 	}
 };
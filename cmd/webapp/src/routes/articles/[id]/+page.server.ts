@@ -21,5 +21,25 @@ export const actions: Actions = {
 
 		await apiClient.deleteArticle(id, fetch);
 		throw redirect(303, '/');
+	},
+
+	archive: async ({ locals, fetch, params, request }) => {
+		const apiClient = requireApiKey(locals);
+
+		const formData = await request.formData();
+		const readState = formData.get('readState') === 'unread' ? 'unread' : 'archived';
+
+		const article = await apiClient.updateArticle(params.id, { readState }, fetch);
+		return { article };
+	},
+
+	favorite: async ({ locals, fetch, params, request }) => {
+		const apiClient = requireApiKey(locals);
+
+		const formData = await request.formData();
+		const favorite = formData.get('favorite') === 'true';
+
+		const article = await apiClient.updateArticle(params.id, { favorite }, fetch);
+		return { article };
 	}
 };
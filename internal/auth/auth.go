@@ -20,10 +20,12 @@ import (
 const (
 	authHeader       = "Authorization"
 	authHeaderPrefix = "Bearer "
-	adminAccountID   = "admin"
 	allowedClockSkew = 30 * time.Second
 )
 
+// AdminAccountID is the account of requests authenticated with the shared API key.
+const AdminAccountID = "admin"
+
 type contextKey string
 
 const (
@@ -94,7 +96,7 @@ func sharedAPIKeyMiddleware(apiKeySecret string) func(http.Handler) http.Handler
 				handleAuthError(r.Context(), next, w, r, "invalid API key")
 				return
 			}
-			ctx := addAccountIDToContext(r.Context(), adminAccountID)
+			ctx := addAccountIDToContext(r.Context(), AdminAccountID)
 			next.ServeHTTP(w, r.WithContext(ctx))
 		})
 	}
@@ -3,11 +3,16 @@ package config
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/shaftoe/savetoink/internal/consts"
+	"github.com/shaftoe/savetoink/internal/content"
+	"github.com/shaftoe/savetoink/internal/content/rules"
 	"github.com/spf13/viper"
 )
 
@@ -23,10 +28,25 @@ type Config struct {
 	Debug            bool
 	SendEnabled      bool
 	DynamoDBTable    string
+	// ArticleIndexes is the number of article list index changes deployed to DynamoDBTable, see
+	// consts.ArticleIndexesNone.
+	ArticleIndexes   int
 	Mode             consts.RunMode
 	AWSConfig        *aws.Config
 	EmailProvider    consts.EmailProvider
 	AuthBackend      consts.AuthBackend
+	RulesFile        string
+	Rules            *rules.Set
+	FetchAllowHosts  []string
+	FetchDenyHosts   []string
+	Fetch            content.FetchConfig
+	FetchCache       consts.CacheBackend
+	FetchCacheDir    string
+	FetchCacheSize   int64
+	FeedPollInterval time.Duration
+	// Lambda is set when running as an AWS Lambda function, where responses are buffered and capped
+	// and nothing runs once the response is returned.
+	Lambda bool
 }
 
 // Load reads configuration from environment variables and returns a Config instance.
@@ -44,10 +64,28 @@ func Load(mode consts.RunMode) (*Config, error) {
 		cfg.AuthBackend = consts.AuthBackendSharedAPIKey
 	}
 
+	if cfg.FetchCache == "" {
+		cfg.FetchCache = consts.CacheMemory
+	}
+
+	if cfg.FeedPollInterval == 0 {
+		cfg.FeedPollInterval = consts.FeedPollInterval
+	}
+
 	if err := cfg.validate(); err != nil {
 		return nil, err
 	}
 
+	extractionRules, err := rules.Load(cfg.RulesFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load extraction rules: %w", err)
+	}
+	cfg.Rules = extractionRules
+
+	if cfg.Fetch, err = loadFetchConfig(); err != nil {
+		return nil, err
+	}
+
 	return cfg, nil
 }
 
@@ -59,12 +97,29 @@ func bindEnvVars() error {
 		{"api-key", "SAVETOINK_MAILJET_API_KEY"},
 		{"api-key-secret", "SAVETOINK_API_KEY"},
 		{"api-secret", "SAVETOINK_MAILJET_API_SECRET"},
+		{"article-indexes", "SAVETOINK_ARTICLE_INDEXES"},
 		{"auth-backend", "SAVETOINK_AUTH_BACKEND"},
 		{"auth0-audience", "SAVETOINK_AUTH0_AUDIENCE"},
 		{"auth0-domain", "SAVETOINK_AUTH0_DOMAIN"},
 		{"debug", "SAVETOINK_DEBUG"},
 		{"destination-email", "SAVETOINK_DEST_EMAIL"},
 		{"dynamodb-table", "SAVETOINK_DYNAMODB_TABLE_NAME"},
+		{"feed-poll-interval", "SAVETOINK_FEED_POLL_INTERVAL"},
+		{"fetch-allow-hosts", "SAVETOINK_FETCH_ALLOW_HOSTS"},
+		{"fetch-cache", "SAVETOINK_FETCH_CACHE"},
+		{"fetch-cache-dir", "SAVETOINK_FETCH_CACHE_DIR"},
+		{"fetch-cache-size", "SAVETOINK_FETCH_CACHE_SIZE"},
+		{"fetch-deny-hosts", "SAVETOINK_FETCH_DENY_HOSTS"},
+		{"fetch-headers", "SAVETOINK_FETCH_HEADERS"},
+		{"fetch-host-concurrency", "SAVETOINK_FETCH_HOST_CONCURRENCY"},
+		{"fetch-host-interval", "SAVETOINK_FETCH_HOST_INTERVAL"},
+		{"fetch-max-redirects", "SAVETOINK_FETCH_MAX_REDIRECTS"},
+		{"fetch-max-size", "SAVETOINK_FETCH_MAX_SIZE"},
+		{"fetch-proxy", "SAVETOINK_FETCH_PROXY"},
+		{"fetch-robots", "SAVETOINK_FETCH_ROBOTS"},
+		{"fetch-timeout", "SAVETOINK_FETCH_TIMEOUT"},
+		{"fetch-user-agent", "SAVETOINK_FETCH_USER_AGENT"},
+		{"rules-file", "SAVETOINK_RULES_FILE"},
 		{"send-enabled", "SAVETOINK_SEND_ENABLED"},
 		{"sender-email", "SAVETOINK_SENDER_EMAIL"},
 	}
@@ -80,15 +135,23 @@ func bindEnvVars() error {
 func loadConfig(mode consts.RunMode) *Config {
 	cfg := &Config{
 		APIKeySecret:     viper.GetString("api-key-secret"),
+		ArticleIndexes:   viper.GetInt("article-indexes"),
 		Auth0Audience:    viper.GetString("auth0-audience"),
 		Auth0Domain:      viper.GetString("auth0-domain"),
 		AuthBackend:      consts.AuthBackend(viper.GetString("auth-backend")),
 		Debug:            viper.GetBool("debug"),
 		DestEmail:        viper.GetString("destination-email"),
 		DynamoDBTable:    viper.GetString("dynamodb-table"),
+		FeedPollInterval: viper.GetDuration("feed-poll-interval"),
+		FetchAllowHosts:  splitList(viper.GetString("fetch-allow-hosts")),
+		FetchCache:       consts.CacheBackend(viper.GetString("fetch-cache")),
+		FetchCacheDir:    viper.GetString("fetch-cache-dir"),
+		FetchCacheSize:   viper.GetInt64("fetch-cache-size"),
+		FetchDenyHosts:   splitList(viper.GetString("fetch-deny-hosts")),
 		MailjetAPIKey:    viper.GetString("api-key"),
 		MailjetAPISecret: viper.GetString("api-secret"),
 		Mode:             mode,
+		RulesFile:        viper.GetString("rules-file"),
 		SendEnabled:      viper.GetBool("send-enabled"),
 		SenderEmail:      viper.GetString("sender-email"),
 	}
@@ -96,6 +159,48 @@ func loadConfig(mode consts.RunMode) *Config {
 	return cfg
 }
 
+// loadFetchConfig reads the HTTP fetching settings, zero values are replaced by defaults in the extractor.
+// Headers are set as a JSON object, e.g. {"Accept-Language": "en"}.
+func loadFetchConfig() (content.FetchConfig, error) {
+	fetch := content.FetchConfig{
+		UserAgent:    viper.GetString("fetch-user-agent"),
+		Timeout:      viper.GetDuration("fetch-timeout"),
+		MaxBodySize:  viper.GetInt64("fetch-max-size"),
+		MaxRedirects: viper.GetInt("fetch-max-redirects"),
+
+		HostConcurrency: viper.GetInt("fetch-host-concurrency"),
+		HostInterval:    viper.GetDuration("fetch-host-interval"),
+		RobotsTxt:       viper.GetBool("fetch-robots"),
+	}
+
+	if headers := viper.GetString("fetch-headers"); headers != "" {
+		if err := json.Unmarshal([]byte(headers), &fetch.Headers); err != nil {
+			return fetch, fmt.Errorf("invalid SAVETOINK_FETCH_HEADERS: %w", err)
+		}
+	}
+
+	if proxy := viper.GetString("fetch-proxy"); proxy != "" {
+		proxyURL, err := content.ParseProxyURL(proxy)
+		if err != nil {
+			return fetch, fmt.Errorf("invalid SAVETOINK_FETCH_PROXY: %w", err)
+		}
+		fetch.Proxy = proxyURL
+	}
+
+	return fetch, nil
+}
+
+// splitList splits a comma separated list, ignoring blank items.
+func splitList(value string) []string {
+	var items []string
+	for item := range strings.SplitSeq(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
 func (c *Config) validate() error {
 	var missing []string
 
@@ -105,6 +210,16 @@ func (c *Config) validate() error {
 		}
 	}
 
+	if c.ArticleIndexes < consts.ArticleIndexesNone || c.ArticleIndexes > consts.ArticleIndexesAll {
+		return fmt.Errorf("unsupported article indexes: %d", c.ArticleIndexes)
+	}
+
+	switch c.FetchCache {
+	case "", consts.CacheMemory, consts.CacheDisk, consts.CacheNone:
+	default:
+		return fmt.Errorf("unsupported fetch cache: %s", c.FetchCache)
+	}
+
 	if c.SendEnabled {
 		c.EmailProvider = consts.EmailBackendMailjet
 		c.validateSendEnabledConfig(&missing)
@@ -137,7 +252,13 @@ func (c *Config) validateServerConfig(missing *[]string) error {
 		*missing = append(*missing, "SAVETOINK_DYNAMODB_TABLE_NAME")
 	}
 
-	cfg, err := config.LoadDefaultConfig(context.Background())
+	return c.LoadAWSConfig(context.Background())
+}
+
+// LoadAWSConfig loads the AWS configuration used to access DynamoDB. It is loaded by Load in server mode,
+// CLI commands using the server storage load it themselves.
+func (c *Config) LoadAWSConfig(ctx context.Context) error {
+	cfg, err := config.LoadDefaultConfig(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to load AWS config: %w", err)
 	}
@@ -1,8 +1,11 @@
 package config
 
 import (
+	"net/url"
 	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/shaftoe/savetoink/internal/consts"
 	"github.com/stretchr/testify/assert"
@@ -103,6 +106,14 @@ func TestConfigValidate(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "config with unsupported article indexes",
+			config: &Config{
+				Mode:           consts.ModeCLI,
+				ArticleIndexes: consts.ArticleIndexesAll + 1,
+			},
+			wantErr: true,
+		},
 		{
 			name: "CLI config missing kindle email with send enabled",
 			config: &Config{
@@ -147,6 +158,14 @@ func TestConfigValidate(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "CLI config with unsupported fetch cache",
+			config: &Config{
+				Mode:       consts.ModeCLI,
+				FetchCache: "redis",
+			},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -257,3 +276,115 @@ func TestLoadServerModeAuth0(t *testing.T) {
 	assert.Equal(t, "example.auth0.com", cfg.Auth0Domain)
 	assert.Equal(t, "test-audience", cfg.Auth0Audience)
 }
+
+func TestLoadRulesFile(t *testing.T) {
+	rulesFile := filepath.Join(t.TempDir(), "rules.yaml")
+	err := os.WriteFile(rulesFile, []byte("rules:\n  - name: custom\n    hosts: [example.com]\n"), 0o600)
+	assert.NoError(t, err)
+
+	_ = os.Setenv("SAVETOINK_RULES_FILE", rulesFile)
+	defer func() {
+		_ = os.Unsetenv("SAVETOINK_RULES_FILE")
+	}()
+
+	cfg, err := Load(consts.ModeCLI)
+	assert.NoError(t, err)
+	assert.Equal(t, rulesFile, cfg.RulesFile)
+	assert.Equal(t, "custom", cfg.Rules.Match(&url.URL{Host: "example.com"}).Name)
+
+	_ = os.Setenv("SAVETOINK_RULES_FILE", filepath.Join(t.TempDir(), "missing.yaml"))
+	_, err = Load(consts.ModeCLI)
+	assert.Error(t, err)
+}
+
+func TestLoadFetchHosts(t *testing.T) {
+	_ = os.Setenv("SAVETOINK_FETCH_ALLOW_HOSTS", "example.com, *.example.org,")
+	_ = os.Setenv("SAVETOINK_FETCH_DENY_HOSTS", "internal.example.com")
+	defer func() {
+		_ = os.Unsetenv("SAVETOINK_FETCH_ALLOW_HOSTS")
+		_ = os.Unsetenv("SAVETOINK_FETCH_DENY_HOSTS")
+	}()
+
+	cfg, err := Load(consts.ModeCLI)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"example.com", "*.example.org"}, cfg.FetchAllowHosts)
+	assert.Equal(t, []string{"internal.example.com"}, cfg.FetchDenyHosts)
+}
+
+func TestLoadFetchConfig(t *testing.T) {
+	env := map[string]string{
+		"SAVETOINK_FETCH_USER_AGENT":    "test-agent",
+		"SAVETOINK_FETCH_TIMEOUT":       "5s",
+		"SAVETOINK_FETCH_MAX_SIZE":      "1024",
+		"SAVETOINK_FETCH_MAX_REDIRECTS": "3",
+		"SAVETOINK_FETCH_HEADERS":       `{"Accept-Language": "it"}`,
+		"SAVETOINK_FETCH_PROXY":         "http://proxy.example.com:3128",
+
+		"SAVETOINK_FETCH_HOST_CONCURRENCY": "4",
+		"SAVETOINK_FETCH_HOST_INTERVAL":    "2s",
+		"SAVETOINK_FETCH_ROBOTS":           "true",
+	}
+	for key, value := range env {
+		_ = os.Setenv(key, value)
+	}
+	defer func() {
+		for key := range env {
+			_ = os.Unsetenv(key)
+		}
+	}()
+
+	cfg, err := Load(consts.ModeCLI)
+	assert.NoError(t, err)
+	assert.Equal(t, "test-agent", cfg.Fetch.UserAgent)
+	assert.Equal(t, 5*time.Second, cfg.Fetch.Timeout)
+	assert.Equal(t, int64(1024), cfg.Fetch.MaxBodySize)
+	assert.Equal(t, 3, cfg.Fetch.MaxRedirects)
+	assert.Equal(t, map[string]string{"Accept-Language": "it"}, cfg.Fetch.Headers)
+	assert.Equal(t, "proxy.example.com:3128", cfg.Fetch.Proxy.Host)
+	assert.Equal(t, 4, cfg.Fetch.HostConcurrency)
+	assert.Equal(t, 2*time.Second, cfg.Fetch.HostInterval)
+	assert.True(t, cfg.Fetch.RobotsTxt)
+
+	_ = os.Setenv("SAVETOINK_FETCH_PROXY", "ftp://proxy.example.com")
+	_, err = Load(consts.ModeCLI)
+	assert.Error(t, err)
+
+	_ = os.Setenv("SAVETOINK_FETCH_PROXY", "")
+	_ = os.Setenv("SAVETOINK_FETCH_HEADERS", "Accept-Language: it")
+	_, err = Load(consts.ModeCLI)
+	assert.Error(t, err)
+}
+
+func TestLoadFetchCache(t *testing.T) {
+	cfg, err := Load(consts.ModeCLI)
+	assert.NoError(t, err)
+	assert.Equal(t, consts.CacheMemory, cfg.FetchCache)
+
+	_ = os.Setenv("SAVETOINK_FETCH_CACHE", "disk")
+	_ = os.Setenv("SAVETOINK_FETCH_CACHE_DIR", "/tmp/savetoink")
+	_ = os.Setenv("SAVETOINK_FETCH_CACHE_SIZE", "2048")
+	defer func() {
+		_ = os.Unsetenv("SAVETOINK_FETCH_CACHE")
+		_ = os.Unsetenv("SAVETOINK_FETCH_CACHE_DIR")
+		_ = os.Unsetenv("SAVETOINK_FETCH_CACHE_SIZE")
+	}()
+
+	cfg, err = Load(consts.ModeCLI)
+	assert.NoError(t, err)
+	assert.Equal(t, consts.CacheDisk, cfg.FetchCache)
+	assert.Equal(t, "/tmp/savetoink", cfg.FetchCacheDir)
+	assert.Equal(t, int64(2048), cfg.FetchCacheSize)
+}
+
+func TestLoadFeedPollInterval(t *testing.T) {
+	cfg, err := Load(consts.ModeCLI)
+	assert.NoError(t, err)
+	assert.Equal(t, consts.FeedPollInterval, cfg.FeedPollInterval)
+
+	_ = os.Setenv("SAVETOINK_FEED_POLL_INTERVAL", "5m")
+	defer func() { _ = os.Unsetenv("SAVETOINK_FEED_POLL_INTERVAL") }()
+
+	cfg, err = Load(consts.ModeCLI)
+	assert.NoError(t, err)
+	assert.Equal(t, 5*time.Minute, cfg.FeedPollInterval)
+}
@@ -35,6 +35,114 @@ const (
 	StatusFailed Status = "failed"
 )
 
+// ReadState represents the reading workflow state of an article.
+type ReadState string
+
+const (
+	// ReadStateUnread indicates that the article was delivered, or saved without sending, and not read yet.
+	ReadStateUnread ReadState = "unread"
+	// ReadStateRead indicates that the article has been read.
+	ReadStateRead ReadState = "read"
+	// ReadStateArchived indicates that the article has been archived, read or not.
+	ReadStateArchived ReadState = "archived"
+)
+
+// LinkMode defines how hyperlinks in article content are rendered in the EPUB.
+type LinkMode string
+
+const (
+	// LinkModeKeep keeps hyperlinks as they are.
+	LinkModeKeep LinkMode = "keep"
+	// LinkModeStrip replaces hyperlinks with their text.
+	LinkModeStrip LinkMode = "strip"
+	// LinkModeEndnotes replaces hyperlinks with numbered references to a list of URLs at the end.
+	LinkModeEndnotes LinkMode = "endnotes"
+)
+
+// TextDirection defines the direction in which article text is laid out.
+type TextDirection string
+
+const (
+	// DirectionLTR lays out text left-to-right.
+	DirectionLTR TextDirection = "ltr"
+	// DirectionRTL lays out text right-to-left, e.g. for Arabic, Hebrew and Persian.
+	DirectionRTL TextDirection = "rtl"
+)
+
+// FeedDelivery defines how articles created from new feed entries are delivered.
+type FeedDelivery string
+
+const (
+	// FeedDeliveryImmediate sends each new article to Kindle as soon as it is saved.
+	FeedDeliveryImmediate FeedDelivery = "immediate"
+	// FeedDeliveryDigest collects new articles and sends them together once per FeedDigestInterval.
+	FeedDeliveryDigest FeedDelivery = "digest"
+	// FeedDeliveryNone only saves new articles.
+	FeedDeliveryNone FeedDelivery = "none"
+)
+
+// ImportFormat identifies the format of a file exported by another service or a browser.
+type ImportFormat string
+
+const (
+	// ImportFormatPocketHTML is the HTML export of Pocket, a list of links with their save time and tags.
+	ImportFormatPocketHTML ImportFormat = "pocket-html"
+	// ImportFormatPocketCSV is the CSV export of Pocket.
+	ImportFormatPocketCSV ImportFormat = "pocket-csv"
+	// ImportFormatInstapaperCSV is the CSV export of Instapaper.
+	ImportFormatInstapaperCSV ImportFormat = "instapaper-csv"
+	// ImportFormatOmnivoreJSON is the JSON metadata export of Omnivore.
+	ImportFormatOmnivoreJSON ImportFormat = "omnivore-json"
+	// ImportFormatBookmarks is the Netscape bookmark file exported by browsers.
+	ImportFormatBookmarks ImportFormat = "bookmarks"
+)
+
+// ImportStatus represents the progress of an import job.
+type ImportStatus string
+
+const (
+	// ImportStatusRunning indicates that the items of the import are being saved.
+	ImportStatusRunning ImportStatus = "running"
+	// ImportStatusCompleted indicates that all the items of the import were processed.
+	ImportStatusCompleted ImportStatus = "completed"
+	// ImportStatusFailed indicates that the import stopped before processing all its items.
+	ImportStatusFailed ImportStatus = "failed"
+)
+
+// ArticleSort defines the date or duration articles are listed by.
+type ArticleSort string
+
+const (
+	// SortCreated lists articles by the time they were saved.
+	SortCreated ArticleSort = "created"
+	// SortPublished lists articles by their publication date, articles without one are not listed.
+	SortPublished ArticleSort = "published"
+	// SortReadingTime lists articles by their estimated reading time, articles without one are not listed.
+	SortReadingTime ArticleSort = "reading_time"
+)
+
+// SortOrder defines the direction articles are listed in.
+type SortOrder string
+
+const (
+	// SortOrderAsc lists articles from the oldest or shortest.
+	SortOrderAsc SortOrder = "asc"
+	// SortOrderDesc lists articles from the newest or longest.
+	SortOrderDesc SortOrder = "desc"
+)
+
+// CacheBackend defines where fetched documents are cached.
+type CacheBackend string
+
+const (
+	// CacheMemory caches fetched documents in memory, for the lifetime of the process.
+	CacheMemory CacheBackend = "memory"
+	// CacheDisk caches fetched documents in a directory, surviving restarts.
+	CacheDisk CacheBackend = "disk"
+	// CacheNone disables caching of fetched documents.
+	CacheNone CacheBackend = "none"
+)
+
 // HTTP server timeout constants.
 const (
 	// ReadTimeout is the maximum duration for reading the entire request, including the body.
@@ -80,19 +188,253 @@ const (
 	MaxSubjectLength = 100
 )
 
+// Article list index changes deployed, counted by the ArticleIndexes parameter of the stack and by the
+// SAVETOINK_ARTICLE_INDEXES setting of the code querying them. DynamoDB makes one index change per table update.
+const (
+	// ArticleIndexesNone is the table with DynamoDBGSIName only.
+	ArticleIndexesNone = 0
+	// ArticleIndexesPublishedAt adds DynamoDBPublishedAtGSIName.
+	ArticleIndexesPublishedAt = 1
+	// ArticleIndexesReadingTime adds DynamoDBReadingTimeGSIName.
+	ArticleIndexesReadingTime = 2
+	// ArticleIndexesCreatedAtV2 adds DynamoDBGSINameV2, queried instead of DynamoDBGSIName.
+	ArticleIndexesCreatedAtV2 = 3
+	// ArticleIndexesAll deletes DynamoDBGSIName.
+	ArticleIndexesAll = 4
+)
+
 // DynamoDB constants.
 const (
 	// DynamoDBBatchSize is the maximum number of items in a BatchWriteItem operation.
 	DynamoDBBatchSize = 25
 
-	// DynamoDBGSIName is the name of the Global Secondary Index for sorting articles by creation date.
+	// DynamoDBBatchAttempts is the maximum number of attempts to write the items of a batch that DynamoDB left
+	// unprocessed, waiting DynamoDBBatchBackoff, doubled after each attempt, in between.
+	DynamoDBBatchAttempts = 5
+	// DynamoDBBatchBackoff is the wait before the first retry of unprocessed batch items.
+	DynamoDBBatchBackoff = 50 * time.Millisecond
+
+	// DynamoDBGSIName is the name of the Global Secondary Index for sorting articles by creation date, queried until
+	// DynamoDBGSINameV2 is deployed. It doesn't project tags, collection and reading state.
 	DynamoDBGSIName = "AccountCreatedAtIndex"
+
+	// DynamoDBGSINameV2 is the name of the Global Secondary Index replacing DynamoDBGSIName from
+	// ArticleIndexesCreatedAtV2.
+	DynamoDBGSINameV2 = "AccountCreatedAtIndexV2"
+
+	// DynamoDBPublishedAtGSIName is the name of the Global Secondary Index for sorting articles by publication
+	// date. Articles without publishedAt attribute are not in the index.
+	DynamoDBPublishedAtGSIName = "AccountPublishedAtIndex"
+
+	// DynamoDBReadingTimeGSIName is the name of the Global Secondary Index for sorting articles by reading time.
+	// Articles without readingTimeMinutes attribute, e.g. failed ones, are not in the index.
+	DynamoDBReadingTimeGSIName = "AccountReadingTimeIndex"
+
+	// DynamoDBFeedsAccount is the partition holding the feed subscriptions of all accounts, polled together.
+	// Like settings, they have no createdAt attribute and are never returned by the GSI used to list articles.
+	DynamoDBFeedsAccount = "feeds#"
+
+	// DynamoDBAliasIDPrefix prefixes the id of the items recording the id an article is stored with when it differs
+	// from the id of the URL it was saved from, e.g. because of a redirect or a canonical URL.
+	DynamoDBAliasIDPrefix = "alias#"
+
+	// DynamoDBImportIDPrefix prefixes the id of the items holding import jobs, never listed as articles either.
+	DynamoDBImportIDPrefix = "import#"
+
+	// DynamoDBTagIDPrefix prefixes the id of the items counting the articles of a tag. The articles of the tag are
+	// copied, without content, to the partition of the account followed by "#" and the tag item id.
+	DynamoDBTagIDPrefix = "tag#"
+
+	// DynamoDBCollectionIDPrefix prefixes the id of the items counting the articles of a collection, copied like
+	// the articles of a tag.
+	DynamoDBCollectionIDPrefix = "collection#"
+
+	// DynamoDBSettingsID is the id of the item holding account settings. The item has no createdAt
+	// attribute so it is never returned by the GSI used to list articles.
+	DynamoDBSettingsID = "settings"
 )
 
 // Content extraction constants.
 const (
-	// WordsPerMinute is the average reading speed used to calculate estimated reading time.
+	// WordsPerMinute is the average reading speed used to calculate estimated reading time
+	// of languages without a specific reading speed.
 	WordsPerMinute = 250
+
+	// CharactersPerMinute is the average reading speed of Chinese characters and Japanese kana used to
+	// calculate estimated reading time of languages without a specific reading speed.
+	CharactersPerMinute = 300
+
+	// ExtractorTitleBonus is the score added to extraction results that found a title.
+	ExtractorTitleBonus = 50
+
+	// ExtractorScoreMargin is the factor by which a fallback extractor score must exceed
+	// the score of preferred extractors to win.
+	ExtractorScoreMargin = 1.25
+
+	// PaginationMaxPages is the maximum number of pages of an article split across pages that are stitched together.
+	PaginationMaxPages = 10
+
+	// DiscussionMaxComments is the maximum number of comments of a discussion (Hacker News, Reddit, Lobsters)
+	// rendered with the linked article, the top ones in thread order.
+	DiscussionMaxComments = 100
+
+	// DiscussionMaxDepth is the maximum nesting of rendered comments, deeper replies are not indented further.
+	DiscussionMaxDepth = 6
+
+	// CharsetPrescanSize is the number of bytes at the beginning of an HTML document searched for meta charset tags.
+	CharsetPrescanSize = 1024
+
+	// CharsetMinConfidence is the minimum confidence, between 0 and 100, of the statistical detection of
+	// the charset of a document, below which it is assumed to be windows-1252.
+	CharsetMinConfidence = 30
+
+	// TextTitleMaxLength is the maximum length of the first line of a plain text document used as title.
+	TextTitleMaxLength = 80
+)
+
+// Content fetching constants.
+const (
+	// FetchDialTimeout is the maximum duration for establishing connections and TLS handshakes.
+	FetchDialTimeout = 10 * time.Second
+
+	// FetchIdleConnTimeout is the maximum amount of time an idle connection is kept open.
+	FetchIdleConnTimeout = 90 * time.Second
+
+	// FetchMaxIdleConns is the maximum number of idle connections kept open.
+	FetchMaxIdleConns = 10
+
+	// FetchMaxRedirects is the default maximum number of redirects followed when fetching a URL.
+	FetchMaxRedirects = 10
+
+	// FetchTimeout is the default maximum duration of a request, including reading the response body.
+	FetchTimeout = 30 * time.Second
+
+	// FetchMaxBodySize is the default maximum size in bytes of a fetched response body.
+	FetchMaxBodySize = 10 << 20
+
+	// ClientHTMLMaxSize is the maximum size in bytes of an HTML document submitted by the client.
+	ClientHTMLMaxSize = 5 << 20
+
+	// ArticleRequestMaxSize is the maximum size in bytes of an article creation request body,
+	// leaving room for the JSON escaping of an HTML document of ClientHTMLMaxSize.
+	ArticleRequestMaxSize = 8 << 20
+
+	// FetchHostConcurrency is the default maximum number of concurrent requests to the same host.
+	FetchHostConcurrency = 2
+
+	// FetchHostInterval is the default minimum interval between the start of requests to the same host.
+	FetchHostInterval = 250 * time.Millisecond
+
+	// FetchRetryAfter is how long requests to a host are deferred after a 429 or 503 response
+	// without a valid Retry-After header.
+	FetchRetryAfter = time.Minute
+
+	// FetchMaxRetryAfter is the maximum duration requests to a host are deferred for, whatever its Retry-After.
+	FetchMaxRetryAfter = time.Hour
+
+	// RobotsCacheTTL is how long the robots.txt rules of a site are cached.
+	RobotsCacheTTL = 24 * time.Hour
+
+	// RobotsMaxSize is the maximum size in bytes of a robots.txt file parsed, as required by RFC 9309.
+	RobotsMaxSize = 500 << 10
+
+	// RobotsMaxCrawlDelay is the maximum robots.txt Crawl-delay applied between requests to the same host.
+	RobotsMaxCrawlDelay = 10 * time.Second
+
+	// FetchCacheMaxSize is the default maximum size in bytes of the documents kept by the in-memory fetch cache.
+	FetchCacheMaxSize = 64 << 20
+
+	// FetchCacheDirName is the directory, under the user cache directory, of the default on-disk fetch cache.
+	FetchCacheDirName = "savetoink"
+
+	// FetchUserAgent is the default User-Agent header sent when fetching URLs.
+	FetchUserAgent = "Mozilla/5.0 (compatible; savetoink/1.0; +https://github.com/shaftoe/savetoink)"
+)
+
+// Feed constants.
+const (
+	// FeedPollInterval is the default interval between polls of all feeds by the HTTP server.
+	FeedPollInterval = 30 * time.Minute
+
+	// FeedMaxNewEntries is the maximum number of new entries of a feed saved per poll, the most recent ones.
+	FeedMaxNewEntries = 10
+
+	// FeedMaxSeenEntries is the maximum number of entry IDs of a feed remembered as seen, the most recent ones.
+	FeedMaxSeenEntries = 200
+
+	// FeedDigestInterval is the minimum interval between digests of the same feed.
+	FeedDigestInterval = 24 * time.Hour
+
+	// FeedDigestMaxArticles is the maximum number of articles bundled in a digest.
+	FeedDigestMaxArticles = 50
+)
+
+// Import constants.
+const (
+	// ImportMaxSize is the maximum size in bytes of an imported file.
+	ImportMaxSize = 16 << 20
+
+	// ImportMaxItems is the maximum number of items of an import.
+	ImportMaxItems = 10000
+
+	// ImportMaxErrors is the maximum number of item errors recorded on an import job.
+	ImportMaxErrors = 20
+
+	// ImportProgressInterval is the number of items processed between updates of the stored import job.
+	ImportProgressInterval = 10
+
+	// ImportStaleAfter is the duration after which a running import job whose progress is not updated is reported
+	// as failed, e.g. when the server was stopped while saving its items. It is much longer than saving
+	// ImportProgressInterval items takes.
+	ImportStaleAfter = time.Hour
+)
+
+// Export constants.
+const (
+	// ExportArticlesFile is the name of the JSON Lines file listing the articles in an export archive.
+	ExportArticlesFile = "articles.jsonl"
+
+	// ExportBookmarksFile is the name of the Netscape bookmark file in an export archive.
+	ExportBookmarksFile = "bookmarks.html"
+
+	// ExportArticlesDir is the directory of the EPUB and HTML files of the articles in an export archive.
+	ExportArticlesDir = "articles/"
+
+	// ExportSlugMaxLength is the maximum length of the title slug in the name of an exported article file.
+	ExportSlugMaxLength = 60
+
+	// ExportIDPrefixLength is the length of the article ID prefix keeping the names of exported files unique.
+	ExportIDPrefixLength = 8
+
+	// LambdaMaxExportSize is the maximum size of an export archive returned by the Lambda function, its response
+	// payload is limited to 6 MB and binary bodies are base64 encoded.
+	LambdaMaxExportSize = 4 << 20
+
+	// LambdaMaxImportItems is the maximum number of items of an import on Lambda, where they are saved within the
+	// request: fetching each one within FetchTimeout keeps the import below the 540 s function timeout.
+	LambdaMaxImportItems = 15
+)
+
+// Search constants.
+const (
+	// SearchMaxQueryLength is the maximum length in bytes of a search query.
+	SearchMaxQueryLength = 500
+
+	// SearchMaxQueryTerms is the maximum number of terms of a search query, phrase terms included.
+	SearchMaxQueryTerms = 32
+
+	// SearchIndexTTL is the duration the articles of an account indexed in memory are searched before they are
+	// read and indexed again, to find the articles stored by other processes.
+	SearchIndexTTL = 5 * time.Minute
+)
+
+// Tag constants.
+const (
+	// TagMaxLength is the maximum length in characters of a tag or collection name.
+	TagMaxLength = 64
+
+	// TagMaxCount is the maximum number of tags of an article.
+	TagMaxCount = 32
 )
 
 // EPUB constants.
@@ -102,6 +444,42 @@ const (
 
 	// DefaultChapterFilename is the default filename for a chapter in single-chapter EPUBs.
 	DefaultChapterFilename = "chapter1.xhtml"
+
+	// ChapterFilenameFormat is the filename format for chapters in multi-chapter EPUBs.
+	ChapterFilenameFormat = "chapter%d.xhtml"
+
+	// TOCMinWords is the minimum word count for an article to be split into chapters.
+	TOCMinWords = 1500
+
+	// DefaultLanguage is the EPUB language used when the article language is unknown.
+	DefaultLanguage = "en"
+
+	// VerticalCSSFilename is the filename of the stylesheet enabling vertical writing mode.
+	VerticalCSSFilename = "vertical.css"
+
+	// TOCMinHeadings is the minimum number of h1/h2/h3 headings for an article to be split into chapters.
+	TOCMinHeadings = 2
+
+	// EPUBMaxImages is the maximum number of images embedded in an EPUB, the others are left as remote references.
+	EPUBMaxImages = 100
+
+	// ImageFilenameFormat is the filename format of the images embedded in an EPUB, numbered and with extension.
+	ImageFilenameFormat = "image%03d%s"
+)
+
+// Content transformation constants.
+const (
+	// EndnotesTitle is the heading of the endnotes section collecting footnote definitions.
+	EndnotesTitle = "Notes"
+
+	// FootnoteIDFormat is the id format for footnote definitions moved to the endnotes section.
+	FootnoteIDFormat = "note-%d"
+
+	// LinksTitle is the heading of the section listing URLs of links converted to endnotes.
+	LinksTitle = "Links"
+
+	// LinkIDFormat is the id format for entries of the links section.
+	LinkIDFormat = "link-%d"
 )
 
 // Error messages.
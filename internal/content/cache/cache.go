@@ -0,0 +1,129 @@
+// Package cache provides caches of fetched documents, revalidated with conditional requests.
+package cache
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Cache stores fetched documents by request URL. Implementations are safe for concurrent use.
+type Cache interface {
+	// Get returns the entry stored for key, if any.
+	Get(key string) (*Entry, bool)
+	// Set stores entry for key, replacing any previous entry.
+	Set(key string, entry *Entry)
+}
+
+// Entry is a fetched document with the validators and freshness information of its response.
+type Entry struct {
+	// URL is the URL of the final response, after redirects.
+	URL          string    `json:"url"`
+	ContentType  string    `json:"contentType"`
+	Body         []byte    `json:"body"`
+	ETag         string    `json:"etag,omitempty"`
+	LastModified string    `json:"lastModified,omitempty"`
+	StoredAt     time.Time `json:"storedAt"`
+	// Expires is the time until which the entry is fresh and served without revalidation.
+	Expires time.Time `json:"expires"`
+}
+
+// NewEntry creates an entry for a response with header, reports false if the response must not be stored in a
+// cache shared by all accounts, because of Cache-Control: no-store or private or Vary: *, or if it could never be
+// reused, being neither fresh nor revalidatable.
+func NewEntry(header http.Header, finalURL string, body []byte, now time.Time) (*Entry, bool) {
+	directives := parseCacheControl(header.Get("Cache-Control"))
+	_, noStore := directives["no-store"]
+	_, private := directives["private"]
+	if noStore || private || strings.TrimSpace(header.Get("Vary")) == "*" {
+		return nil, false
+	}
+
+	entry := &Entry{
+		URL:          finalURL,
+		ContentType:  header.Get("Content-Type"),
+		Body:         body,
+		ETag:         header.Get("ETag"),
+		LastModified: header.Get("Last-Modified"),
+	}
+	entry.Revalidated(header, now)
+
+	if !entry.Fresh(now) && !entry.Revalidatable() {
+		return nil, false
+	}
+
+	return entry, true
+}
+
+// Fresh reports whether the entry can be served at now without revalidation. Stale entries are never served
+// without a successful revalidation, as required by Cache-Control: must-revalidate.
+func (e *Entry) Fresh(now time.Time) bool {
+	return now.Before(e.Expires)
+}
+
+// Revalidatable reports whether the entry has validators for a conditional request.
+func (e *Entry) Revalidatable() bool {
+	return e.ETag != "" || e.LastModified != ""
+}
+
+// SetConditionalHeaders adds the validators of the entry to header.
+func (e *Entry) SetConditionalHeaders(header http.Header) {
+	if e.ETag != "" {
+		header.Set("If-None-Match", e.ETag)
+	}
+	if e.LastModified != "" {
+		header.Set("If-Modified-Since", e.LastModified)
+	}
+}
+
+// Revalidated updates freshness and validators of the entry from the header of a response
+// received at now, either the one the entry was created from or a 304 Not Modified.
+func (e *Entry) Revalidated(header http.Header, now time.Time) {
+	e.StoredAt = now
+	e.Expires = now.Add(freshness(header, now))
+
+	if etag := header.Get("ETag"); etag != "" {
+		e.ETag = etag
+	}
+	if lastModified := header.Get("Last-Modified"); lastModified != "" {
+		e.LastModified = lastModified
+	}
+}
+
+// freshness returns for how long a response with header is fresh in a shared cache: s-maxage, max-age if there
+// is none, or Expires, zero with no-cache or without freshness information.
+func freshness(header http.Header, now time.Time) time.Duration {
+	directives := parseCacheControl(header.Get("Cache-Control"))
+	if _, noCache := directives["no-cache"]; noCache {
+		return 0
+	}
+
+	for _, directive := range []string{"s-maxage", "max-age"} {
+		if maxAge, ok := directives[directive]; ok {
+			seconds, err := strconv.Atoi(maxAge)
+			if err != nil || seconds < 0 {
+				return 0
+			}
+			return time.Duration(seconds) * time.Second
+		}
+	}
+
+	if expires, err := http.ParseTime(header.Get("Expires")); err == nil && expires.After(now) {
+		return expires.Sub(now)
+	}
+
+	return 0
+}
+
+// parseCacheControl returns the lowercased directives of a Cache-Control header with their values.
+func parseCacheControl(value string) map[string]string {
+	directives := make(map[string]string)
+	for directive := range strings.SplitSeq(value, ",") {
+		name, arg, _ := strings.Cut(strings.TrimSpace(directive), "=")
+		if name = strings.ToLower(strings.TrimSpace(name)); name != "" {
+			directives[name] = strings.Trim(strings.TrimSpace(arg), `"`)
+		}
+	}
+	return directives
+}
@@ -0,0 +1,123 @@
+package cache
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewEntry(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		header    http.Header
+		wantStore bool
+		wantFresh time.Duration
+	}{
+		{
+			name:      "max-age",
+			header:    http.Header{"Cache-Control": {"public, max-age=600"}},
+			wantStore: true,
+			wantFresh: 10 * time.Minute,
+		},
+		{
+			name:      "max-age takes precedence over expires",
+			header:    http.Header{"Cache-Control": {"max-age=60"}, "Expires": {"Wed, 01 May 2024 13:00:00 GMT"}},
+			wantStore: true,
+			wantFresh: time.Minute,
+		},
+		{
+			name:      "expires",
+			header:    http.Header{"Expires": {"Wed, 01 May 2024 13:00:00 GMT"}},
+			wantStore: true,
+			wantFresh: time.Hour,
+		},
+		{
+			name:      "s-maxage takes precedence over max-age",
+			header:    http.Header{"Cache-Control": {"max-age=600, s-maxage=60"}},
+			wantStore: true,
+			wantFresh: time.Minute,
+		},
+		{
+			name:      "must-revalidate",
+			header:    http.Header{"Cache-Control": {"max-age=60, must-revalidate"}},
+			wantStore: true,
+			wantFresh: time.Minute,
+		},
+		{
+			name:      "no-cache is stored but stale",
+			header:    http.Header{"Cache-Control": {"no-cache, max-age=600"}, "Etag": {`"v1"`}},
+			wantStore: true,
+		},
+		{
+			name:      "invalid max-age",
+			header:    http.Header{"Cache-Control": {"max-age=soon"}, "Etag": {`"v1"`}},
+			wantStore: true,
+		},
+		{
+			name:      "no freshness information",
+			header:    http.Header{"Last-Modified": {"Wed, 01 May 2024 11:00:00 GMT"}},
+			wantStore: true,
+		},
+		{
+			name:   "neither fresh nor revalidatable",
+			header: http.Header{"Cache-Control": {"no-cache"}},
+		},
+		{
+			name:   "no-store",
+			header: http.Header{"Cache-Control": {"No-Store"}},
+		},
+		{
+			name:   "private",
+			header: http.Header{"Cache-Control": {"private, max-age=600"}, "Etag": {`"v1"`}},
+		},
+		{
+			name:   "vary on everything",
+			header: http.Header{"Vary": {"*"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry, ok := NewEntry(tt.header, "https://example.com/a", []byte("body"), now)
+			assert.Equal(t, tt.wantStore, ok)
+			if !ok {
+				return
+			}
+			assert.Equal(t, now.Add(tt.wantFresh), entry.Expires)
+			assert.Equal(t, tt.wantFresh > 0, entry.Fresh(now))
+			assert.False(t, entry.Fresh(now.Add(tt.wantFresh)))
+		})
+	}
+}
+
+func TestEntryRevalidation(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	header := http.Header{
+		"Content-Type":  {"text/html"},
+		"Etag":          {`"v1"`},
+		"Last-Modified": {"Tue, 30 Apr 2024 10:00:00 GMT"},
+	}
+
+	entry, ok := NewEntry(header, "https://example.com/a", []byte("body"), now)
+	assert.True(t, ok)
+	assert.Equal(t, "text/html", entry.ContentType)
+	assert.True(t, entry.Revalidatable())
+
+	conditional := make(http.Header)
+	entry.SetConditionalHeaders(conditional)
+	assert.Equal(t, `"v1"`, conditional.Get("If-None-Match"))
+	assert.Equal(t, "Tue, 30 Apr 2024 10:00:00 GMT", conditional.Get("If-Modified-Since"))
+
+	later := now.Add(time.Hour)
+	entry.Revalidated(http.Header{"Etag": {`"v2"`}, "Cache-Control": {"max-age=60"}}, later)
+	assert.Equal(t, `"v2"`, entry.ETag)
+	assert.Equal(t, "Tue, 30 Apr 2024 10:00:00 GMT", entry.LastModified)
+	assert.Equal(t, later, entry.StoredAt)
+	assert.True(t, entry.Fresh(later))
+
+	assert.False(t, (&Entry{}).Revalidatable())
+}
@@ -0,0 +1,88 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"log"
+	"os"
+	"path/filepath"
+)
+
+const (
+	// dirMode is the permission of the cache directory.
+	dirMode = 0o700
+	// fileMode is the permission of cache files.
+	fileMode = 0o600
+)
+
+// Disk is a cache storing entries as JSON files in a directory, one per key, surviving restarts.
+// Entries are never evicted, only replaced when the same URL is fetched again.
+type Disk struct {
+	dir string
+}
+
+// NewDisk creates a Disk cache in dir, created on first write.
+func NewDisk(dir string) *Disk {
+	return &Disk{dir: dir}
+}
+
+// Get implements Cache.Get.
+func (d *Disk) Get(key string) (*Entry, bool) {
+	data, err := os.ReadFile(d.path(key))
+	if err != nil {
+		return nil, false
+	}
+
+	var entry Entry
+	if err = json.Unmarshal(data, &entry); err != nil {
+		log.Printf("warning: failed to decode cache entry for %s: %v", key, err)
+		return nil, false
+	}
+
+	return &entry, true
+}
+
+// Set implements Cache.Set. Write errors are logged, the entry is then fetched again next time.
+func (d *Disk) Set(key string, entry *Entry) {
+	if err := d.write(key, entry); err != nil {
+		log.Printf("warning: failed to write cache entry for %s: %v", key, err)
+	}
+}
+
+// write stores entry atomically, through a temporary file renamed into place.
+func (d *Disk) write(key string, entry *Entry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	if err = os.MkdirAll(d.dir, dirMode); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(d.dir, "*.tmp")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	if _, err = tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err = tmp.Chmod(fileMode); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), d.path(key))
+}
+
+func (d *Disk) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(d.dir, hex.EncodeToString(sum[:])+".json")
+}
@@ -0,0 +1,45 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDisk(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "cache")
+	d := NewDisk(dir)
+
+	_, ok := d.Get("https://example.com/a")
+	assert.False(t, ok)
+
+	stored := &Entry{
+		URL:         "https://example.com/a",
+		ContentType: "text/html",
+		Body:        []byte("<p>body</p>"),
+		ETag:        `"v1"`,
+		StoredAt:    time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC),
+	}
+	d.Set("https://example.com/a", stored)
+
+	entry, ok := NewDisk(dir).Get("https://example.com/a")
+	assert.True(t, ok)
+	assert.Equal(t, stored, entry)
+
+	files, err := os.ReadDir(dir)
+	assert.NoError(t, err)
+	assert.Len(t, files, 1)
+}
+
+func TestDiskCorruptEntry(t *testing.T) {
+	dir := t.TempDir()
+	d := NewDisk(dir)
+
+	assert.NoError(t, os.WriteFile(d.path("https://example.com/a"), []byte("{"), fileMode))
+
+	_, ok := d.Get("https://example.com/a")
+	assert.False(t, ok)
+}
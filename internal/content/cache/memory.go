@@ -0,0 +1,80 @@
+package cache
+
+import (
+	"container/list"
+	"sync"
+)
+
+// Memory is an in-memory least recently used cache bounded by the total size of the stored bodies.
+type Memory struct {
+	mu       sync.Mutex
+	maxBytes int64
+	size     int64
+	order    *list.List
+	items    map[string]*list.Element
+}
+
+type memoryItem struct {
+	key   string
+	entry *Entry
+}
+
+// NewMemory creates a Memory cache evicting least recently used entries above maxBytes of bodies.
+func NewMemory(maxBytes int64) *Memory {
+	return &Memory{
+		maxBytes: maxBytes,
+		order:    list.New(),
+		items:    make(map[string]*list.Element),
+	}
+}
+
+// Get implements Cache.Get.
+func (m *Memory) Get(key string) (*Entry, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	element, ok := m.items[key]
+	if !ok {
+		return nil, false
+	}
+	m.order.MoveToFront(element)
+
+	entry := *element.Value.(*memoryItem).entry
+	return &entry, true
+}
+
+// Set implements Cache.Set. Entries larger than the cache are not stored.
+func (m *Memory) Set(key string, entry *Entry) {
+	size := int64(len(entry.Body))
+	if size > m.maxBytes {
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if element, ok := m.items[key]; ok {
+		m.remove(element)
+	}
+
+	stored := *entry
+	m.items[key] = m.order.PushFront(&memoryItem{key: key, entry: &stored})
+	m.size += size
+
+	for m.size > m.maxBytes {
+		m.remove(m.order.Back())
+	}
+}
+
+// Len returns the number of stored entries.
+func (m *Memory) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.order.Len()
+}
+
+func (m *Memory) remove(element *list.Element) {
+	item := m.order.Remove(element).(*memoryItem)
+	delete(m.items, item.key)
+	m.size -= int64(len(item.entry.Body))
+}
@@ -0,0 +1,48 @@
+package cache
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemory(t *testing.T) {
+	m := NewMemory(10)
+
+	m.Set("a", &Entry{Body: []byte("aaaa")})
+	m.Set("b", &Entry{Body: []byte("bbbb")})
+
+	// a becomes the most recently used entry, b is evicted to make room for c
+	_, ok := m.Get("a")
+	assert.True(t, ok)
+	m.Set("c", &Entry{Body: []byte("cccc")})
+
+	_, ok = m.Get("b")
+	assert.False(t, ok)
+	entry, ok := m.Get("a")
+	assert.True(t, ok)
+	assert.Equal(t, "aaaa", string(entry.Body))
+	assert.Equal(t, 2, m.Len())
+
+	// replacing an entry updates the size
+	m.Set("a", &Entry{Body: []byte("a")})
+	m.Set("d", &Entry{Body: []byte("ddddd")})
+	assert.Equal(t, 3, m.Len())
+
+	// entries larger than the cache are not stored
+	m.Set("e", &Entry{Body: []byte("eeeeeeeeeee")})
+	_, ok = m.Get("e")
+	assert.False(t, ok)
+	assert.Equal(t, 3, m.Len())
+}
+
+func TestMemoryGetReturnsCopy(t *testing.T) {
+	m := NewMemory(10)
+	m.Set("a", &Entry{ETag: `"v1"`})
+
+	entry, _ := m.Get("a")
+	entry.ETag = `"v2"`
+
+	stored, _ := m.Get("a")
+	assert.Equal(t, `"v1"`, stored.ETag)
+}
@@ -0,0 +1,51 @@
+package content
+
+import (
+	"bytes"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// canonicalLink returns the URL declared by the <link rel="canonical"> element of doc,
+// resolved against pageURL, or an empty string if there is none or it is not an http(s) URL.
+func canonicalLink(doc []byte, pageURL *url.URL) string {
+	tokenizer := html.NewTokenizer(bytes.NewReader(doc))
+	for {
+		switch tokenizer.Next() {
+		case html.ErrorToken:
+			return ""
+		case html.StartTagToken, html.SelfClosingTagToken:
+			token := tokenizer.Token()
+			if token.DataAtom == atom.Body {
+				return ""
+			}
+			if token.DataAtom != atom.Link {
+				continue
+			}
+
+			var href string
+			canonical := false
+			for _, attr := range token.Attr {
+				switch attr.Key {
+				case "href":
+					href = strings.TrimSpace(attr.Val)
+				case "rel":
+					canonical = strings.EqualFold(strings.TrimSpace(attr.Val), "canonical")
+				}
+			}
+			if !canonical || href == "" {
+				continue
+			}
+
+			canonicalURL, err := pageURL.Parse(href)
+			if err != nil || validateURL(canonicalURL.String()) != nil {
+				return ""
+			}
+			return canonicalURL.String()
+		default:
+		}
+	}
+}
@@ -0,0 +1,30 @@
+package content
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestCanonicalLink(t *testing.T) {
+	pageURL, _ := url.Parse("https://m.example.com/amp/story?utm_source=feed")
+
+	tests := []struct {
+		name string
+		doc  string
+		want string
+	}{
+		{"absolute", `<head><link rel="canonical" href="https://example.com/story"></head>`, "https://example.com/story"},
+		{"relative", `<head><link href="/story" rel="Canonical"/></head>`, "https://m.example.com/story"},
+		{"missing", `<head><link rel="alternate" href="/feed.xml"></head>`, ""},
+		{"unsupported scheme", `<head><link rel="canonical" href="javascript:alert(1)"></head>`, ""},
+		{"in body", `<head></head><body><link rel="canonical" href="/other"></body>`, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := canonicalLink([]byte(tt.doc), pageURL); got != tt.want {
+				t.Errorf("canonicalLink() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
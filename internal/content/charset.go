@@ -0,0 +1,161 @@
+package content
+
+import (
+	"bytes"
+	"mime"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gogs/chardet"
+	"github.com/shaftoe/savetoink/internal/consts"
+	"golang.org/x/net/html"
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/htmlindex"
+	"golang.org/x/text/encoding/unicode"
+)
+
+// Names of the charsets of documents already encoded in UTF-8 and of the fallback charset.
+const (
+	charsetUTF8        = "utf-8"
+	charsetWindows1252 = "windows-1252"
+)
+
+// byteOrderMarks maps byte order marks to the charset they declare.
+var byteOrderMarks = []struct {
+	bom     []byte
+	charset string
+}{
+	{[]byte{0xEF, 0xBB, 0xBF}, charsetUTF8},
+	{[]byte{0xFE, 0xFF}, "utf-16be"},
+	{[]byte{0xFF, 0xFE}, "utf-16le"},
+}
+
+// decodeCharset transcodes a text document to UTF-8 and returns it with the name of its detected charset.
+// The charset is detected, in order of precedence, from the byte order mark, the charset parameter of
+// contentType, for HTML documents the <meta charset> or <meta http-equiv="Content-Type"> tags, and
+// statistically when the document is not valid UTF-8. Documents that can't be decoded are returned as is.
+func decodeCharset(body []byte, contentType string, isHTML bool) ([]byte, string) {
+	for _, mark := range byteOrderMarks {
+		if bytes.HasPrefix(body, mark.bom) {
+			return transcode(body[len(mark.bom):], mark.charset)
+		}
+	}
+
+	if _, params, err := mime.ParseMediaType(contentType); err == nil {
+		if decoded, name, ok := transcodeDeclared(body, params["charset"]); ok {
+			return decoded, name
+		}
+	}
+
+	if isHTML {
+		if decoded, name, ok := transcodeDeclared(body, metaCharset(body)); ok {
+			return decoded, name
+		}
+	}
+
+	if utf8.Valid(body) {
+		return body, charsetUTF8
+	}
+
+	return transcode(body, detectCharset(body, isHTML))
+}
+
+// transcodeDeclared transcodes body from a declared charset, reports false if it is empty or unknown.
+func transcodeDeclared(body []byte, charset string) ([]byte, string, bool) {
+	if charset == "" {
+		return nil, "", false
+	}
+	if _, err := htmlindex.Get(charset); err != nil {
+		return nil, "", false
+	}
+	decoded, name := transcode(body, charset)
+	return decoded, name, true
+}
+
+// transcode decodes body from charset to UTF-8, it is returned as is if charset is unknown.
+func transcode(body []byte, charset string) ([]byte, string) {
+	enc, err := htmlindex.Get(charset)
+	if err != nil {
+		return body, charset
+	}
+
+	name, err := htmlindex.Name(enc)
+	if err != nil {
+		name = strings.ToLower(charset)
+	}
+	if enc == encoding.Nop || enc == unicode.UTF8 {
+		return body, name
+	}
+
+	decoded, err := enc.NewDecoder().Bytes(body)
+	if err != nil {
+		return body, name
+	}
+
+	return decoded, name
+}
+
+// metaCharset returns the charset declared by the meta tags at the beginning of an HTML document.
+func metaCharset(body []byte) string {
+	tokenizer := html.NewTokenizer(bytes.NewReader(body[:min(len(body), consts.CharsetPrescanSize)]))
+	for {
+		switch tokenizer.Next() {
+		case html.ErrorToken:
+			return ""
+		case html.StartTagToken, html.SelfClosingTagToken:
+			token := tokenizer.Token()
+			if token.Data == "body" {
+				return ""
+			}
+			if token.Data != "meta" {
+				continue
+			}
+			if charset := metaTagCharset(token); charset != "" {
+				return charset
+			}
+		}
+	}
+}
+
+// metaTagCharset returns the charset of a <meta charset> tag, or of a <meta http-equiv="Content-Type">
+// tag with a charset parameter in its content.
+func metaTagCharset(token html.Token) string {
+	var httpEquiv, content string
+	for _, attr := range token.Attr {
+		switch attr.Key {
+		case "charset":
+			return strings.TrimSpace(attr.Val)
+		case "http-equiv":
+			httpEquiv = attr.Val
+		case "content":
+			content = attr.Val
+		}
+	}
+
+	if !strings.EqualFold(httpEquiv, "content-type") {
+		return ""
+	}
+	if _, params, err := mime.ParseMediaType(content); err == nil {
+		return params["charset"]
+	}
+	return ""
+}
+
+// detectCharset guesses the charset of a document that is not valid UTF-8 from its byte patterns,
+// falling back to windows-1252, the default of browsers, when the guess is not reliable.
+func detectCharset(body []byte, isHTML bool) string {
+	detector := chardet.NewTextDetector()
+	if isHTML {
+		detector = chardet.NewHtmlDetector()
+	}
+
+	result, err := detector.DetectBest(body)
+	if err != nil || result.Confidence < consts.CharsetMinConfidence {
+		return charsetWindows1252
+	}
+	if _, err = htmlindex.Get(result.Charset); err != nil {
+		return charsetWindows1252
+	}
+
+	return result.Charset
+}
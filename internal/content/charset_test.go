@@ -0,0 +1,144 @@
+package content
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/encoding/simplifiedchinese"
+	"golang.org/x/text/encoding/unicode"
+)
+
+func encode(t *testing.T, enc encoding.Encoding, s string) []byte {
+	t.Helper()
+	encoded, err := enc.NewEncoder().Bytes([]byte(s))
+	if err != nil {
+		t.Fatalf("failed to encode test document: %v", err)
+	}
+	return encoded
+}
+
+func TestDecodeCharset(t *testing.T) {
+	russian := "Москва является столицей Российской Федерации и крупнейшим городом страны. " +
+		"В городе находится множество музеев, театров и исторических памятников. Каждый год миллионы " +
+		"туристов приезжают сюда, чтобы увидеть Красную площадь и Кремль. Жители города работают " +
+		"в различных отраслях экономики, от науки до торговли."
+	japaneseText := "吾輩は猫である。名前はまだ無い。どこで生れたかとんと見当がつかぬ。" +
+		"何でも薄暗いじめじめした所でニャーニャー泣いていた事だけは記憶している。"
+
+	tests := []struct {
+		name        string
+		body        []byte
+		contentType string
+		isHTML      bool
+		want        string
+		wantCharset string
+	}{
+		{
+			name:        "shift_jis from header",
+			body:        encode(t, japanese.ShiftJIS, "<p>日本語の記事</p>"),
+			contentType: "text/html; charset=Shift_JIS",
+			isHTML:      true,
+			want:        "<p>日本語の記事</p>",
+			wantCharset: "shift_jis",
+		},
+		{
+			name:        "gbk from meta charset",
+			body:        encode(t, simplifiedchinese.GBK, `<html><head><meta charset="gbk"></head><body>中文文章</body>`),
+			contentType: "text/html",
+			isHTML:      true,
+			want:        `<html><head><meta charset="gbk"></head><body>中文文章</body>`,
+			wantCharset: "gbk",
+		},
+		{
+			name: "iso-8859-1 from meta http-equiv",
+			body: encode(t, charmap.Windows1252,
+				`<meta http-equiv="Content-Type" content="text/html; charset=iso-8859-1"><p>Café crème</p>`),
+			isHTML:      true,
+			want:        `<meta http-equiv="Content-Type" content="text/html; charset=iso-8859-1"><p>Café crème</p>`,
+			wantCharset: "windows-1252",
+		},
+		{
+			name:        "header takes precedence over meta",
+			body:        encode(t, charmap.Windows1251, `<meta charset="utf-8"><p>Привет</p>`),
+			contentType: "text/html; charset=windows-1251",
+			isHTML:      true,
+			want:        `<meta charset="utf-8"><p>Привет</p>`,
+			wantCharset: "windows-1251",
+		},
+		{
+			name:        "statistical detection",
+			body:        encode(t, charmap.Windows1251, russian),
+			contentType: "text/plain",
+			want:        russian,
+			wantCharset: "windows-1251",
+		},
+		{
+			name:        "statistical detection of multi-byte charset",
+			body:        encode(t, japanese.ShiftJIS, "<p>"+japaneseText+"</p>"),
+			isHTML:      true,
+			want:        "<p>" + japaneseText + "</p>",
+			wantCharset: "shift_jis",
+		},
+		{
+			name:        "unreliable detection falls back to windows-1252",
+			body:        []byte{'a', 0xE9, 'b'},
+			contentType: "text/plain",
+			want:        "aéb",
+			wantCharset: "windows-1252",
+		},
+		{
+			name:        "utf-8 byte order mark",
+			body:        append([]byte{0xEF, 0xBB, 0xBF}, "<p>Ciao</p>"...),
+			contentType: "text/html; charset=iso-8859-1",
+			isHTML:      true,
+			want:        "<p>Ciao</p>",
+			wantCharset: "utf-8",
+		},
+		{
+			name:        "utf-16 byte order mark",
+			body:        encode(t, unicode.UTF16(unicode.LittleEndian, unicode.UseBOM), "<p>Ciao</p>"),
+			isHTML:      true,
+			want:        "<p>Ciao</p>",
+			wantCharset: "utf-16le",
+		},
+		{
+			name:        "undeclared utf-8",
+			body:        []byte("<p>Grüße</p>"),
+			contentType: "text/html; charset=unknown",
+			isHTML:      true,
+			want:        "<p>Grüße</p>",
+			wantCharset: "utf-8",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, charset := decodeCharset(tt.body, tt.contentType, tt.isHTML)
+			assert.Equal(t, tt.want, string(got))
+			assert.Equal(t, tt.wantCharset, charset)
+		})
+	}
+}
+
+func TestExtractFromURLCharset(t *testing.T) {
+	page := `<html><head><meta charset="Shift_JIS"><title>記事</title></head><body><article>` +
+		`<p>これはテスト用の記事です。文字化けせずに抽出されるべき日本語の本文がここにあります。</p>` +
+		`</article></body></html>`
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write(encode(t, japanese.ShiftJIS, page))
+	}))
+	defer server.Close()
+
+	article, err := NewExtractor().ExtractFromURL(context.Background(), server.URL)
+	assert.NoError(t, err)
+	assert.Equal(t, "shift_jis", article.Charset)
+	assert.Contains(t, article.Content, "文字化けせずに抽出されるべき日本語の本文")
+}
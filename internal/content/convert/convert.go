@@ -0,0 +1,112 @@
+// Package convert turns non-HTML documents (PDF, plain text and Markdown) into HTML extraction results.
+package convert
+
+import (
+	"mime"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/shaftoe/savetoink/internal/content/extract"
+)
+
+// Media types of the supported documents.
+const (
+	MediaTypeHTML     = "text/html"
+	MediaTypeXHTML    = "application/xhtml+xml"
+	MediaTypePDF      = "application/pdf"
+	MediaTypeText     = "text/plain"
+	MediaTypeMarkdown = "text/markdown"
+)
+
+// Func converts a document fetched from pageURL to an extraction result with HTML content.
+type Func func(doc []byte, pageURL *url.URL) (*extract.Result, error)
+
+// converters maps media types to the functions converting them.
+var converters = map[string]Func{
+	MediaTypePDF:      PDF,
+	MediaTypeText:     Text,
+	MediaTypeMarkdown: Markdown,
+	"text/x-markdown": Markdown,
+}
+
+// extensionTypes maps file extensions to media types, used when servers send a generic content type.
+var extensionTypes = map[string]string{
+	".pdf":      MediaTypePDF,
+	".txt":      MediaTypeText,
+	".md":       MediaTypeMarkdown,
+	".markdown": MediaTypeMarkdown,
+}
+
+// For returns the function converting documents of mediaType, nil if the type is not supported.
+func For(mediaType string) Func {
+	return converters[mediaType]
+}
+
+// IsHTML reports whether mediaType is handled by the HTML extractors.
+func IsHTML(mediaType string) bool {
+	return mediaType == MediaTypeHTML || mediaType == MediaTypeXHTML
+}
+
+// MediaType returns the media type of a document from its Content-Type header, without parameters.
+// Generic types (plain text, binary or missing) are refined from the extension of the URL path,
+// e.g. Markdown files are often served as text/plain.
+func MediaType(contentType string, pageURL *url.URL) string {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType = strings.ToLower(strings.TrimSpace(contentType))
+	}
+
+	switch mediaType {
+	case "", MediaTypeText, "application/octet-stream", "binary/octet-stream":
+		if pageURL != nil {
+			if byExtension, ok := extensionTypes[strings.ToLower(path.Ext(pageURL.Path))]; ok {
+				return byExtension
+			}
+		}
+	}
+
+	return mediaType
+}
+
+// titleFromURL returns the file name of the URL path without extension, the host name if the path is empty.
+func titleFromURL(pageURL *url.URL) string {
+	if pageURL == nil {
+		return ""
+	}
+
+	name := path.Base(pageURL.Path)
+	name = strings.TrimSuffix(name, path.Ext(name))
+	if name == "" || name == "." || name == "/" {
+		return pageURL.Hostname()
+	}
+
+	if unescaped, err := url.PathUnescape(name); err == nil {
+		name = unescaped
+	}
+	return name
+}
+
+// newResult creates a result for content converted by the named converter.
+func newResult(name, title, content string, pageURL *url.URL) (*extract.Result, error) {
+	wordCount := extract.CountWords(content)
+	if wordCount == 0 {
+		return nil, extract.ErrNoContent
+	}
+
+	if title == "" {
+		title = titleFromURL(pageURL)
+	}
+
+	result := &extract.Result{
+		Extractor: name,
+		Title:     title,
+		Content:   content,
+		WordCount: wordCount,
+	}
+	if pageURL != nil {
+		result.Hostname = pageURL.Hostname()
+	}
+
+	return result, nil
+}
@@ -0,0 +1,163 @@
+package convert
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/shaftoe/savetoink/internal/content/extract"
+)
+
+func mustParseURL(t *testing.T, raw string) *url.URL {
+	t.Helper()
+	u, err := url.Parse(raw)
+	if err != nil {
+		t.Fatalf("failed to parse URL %q: %v", raw, err)
+	}
+	return u
+}
+
+func TestMediaType(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		url         string
+		want        string
+	}{
+		{"html with charset", "text/html; charset=utf-8", "https://example.com/", MediaTypeHTML},
+		{"uppercase", "Text/HTML", "https://example.com/", MediaTypeHTML},
+		{"pdf", "application/pdf", "https://example.com/paper", MediaTypePDF},
+		{"markdown", "text/markdown; charset=utf-8", "https://example.com/README", MediaTypeMarkdown},
+		{"markdown served as text", "text/plain; charset=utf-8", "https://example.com/README.md", MediaTypeMarkdown},
+		{"pdf served as binary", "application/octet-stream", "https://example.com/paper.PDF", MediaTypePDF},
+		{"missing content type", "", "https://example.com/rfc9110.txt", MediaTypeText},
+		{"plain text", "text/plain", "https://example.com/rfc9110", MediaTypeText},
+		{"html extension does not override", "text/html", "https://example.com/notes.md", MediaTypeHTML},
+		{"unsupported", "image/png", "https://example.com/image.png", "image/png"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MediaType(tt.contentType, mustParseURL(t, tt.url)); got != tt.want {
+				t.Errorf("MediaType(%q, %q) = %q, want %q", tt.contentType, tt.url, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFor(t *testing.T) {
+	for _, mediaType := range []string{MediaTypePDF, MediaTypeText, MediaTypeMarkdown, "text/x-markdown"} {
+		if For(mediaType) == nil {
+			t.Errorf("expected a converter for %s", mediaType)
+		}
+	}
+	for _, mediaType := range []string{MediaTypeHTML, "image/png", ""} {
+		if For(mediaType) != nil {
+			t.Errorf("expected no converter for %q", mediaType)
+		}
+	}
+}
+
+func TestText(t *testing.T) {
+	tests := []struct {
+		name      string
+		doc       string
+		url       string
+		wantTitle string
+	}{
+		{
+			name:      "first line as title",
+			doc:       "A Short Story\r\n\r\nOnce upon a time <there> was a text & a title.\r\n",
+			url:       "https://example.com/story.txt",
+			wantTitle: "A Short Story",
+		},
+		{
+			name: "columnar header falls back to file name",
+			doc: "Internet Engineering Task Force (IETF)                  R. Fielding, Ed.\n\n" +
+				"HTTP Semantics\n\nAbstract text.\n",
+			url:       "https://www.rfc-editor.org/rfc/rfc9110.txt",
+			wantTitle: "rfc9110",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Text([]byte(tt.doc), mustParseURL(t, tt.url))
+			if err != nil {
+				t.Fatalf("Text() unexpected error = %v", err)
+			}
+
+			if result.Title != tt.wantTitle {
+				t.Errorf("expected title %q, got %q", tt.wantTitle, result.Title)
+			}
+			if !strings.HasPrefix(result.Content, "<pre>") || strings.Contains(result.Content, "\r") {
+				t.Errorf("expected preformatted content, got %q", result.Content)
+			}
+			if result.Extractor != "text" || result.WordCount == 0 || result.Hostname == "" {
+				t.Errorf("unexpected result %+v", result)
+			}
+		})
+	}
+
+	result, _ := Text([]byte("a <b> & c"), nil)
+	if !strings.Contains(result.Content, "a &lt;b&gt; &amp; c") {
+		t.Errorf("expected escaped content, got %q", result.Content)
+	}
+
+	if _, err := Text([]byte("\n \n"), nil); !errors.Is(err, extract.ErrNoContent) {
+		t.Errorf("expected ErrNoContent for blank document, got %v", err)
+	}
+}
+
+func TestMarkdown(t *testing.T) {
+	doc := "Intro paragraph.\n\n# Project Title\n\nSome *emphasis* and a [link](https://example.com).\n\n" +
+		"| a | b |\n|---|---|\n| 1 | 2 |\n\n<script>alert(1)</script>\n"
+
+	result, err := Markdown([]byte(doc), mustParseURL(t, "https://example.com/README.md"))
+	if err != nil {
+		t.Fatalf("Markdown() unexpected error = %v", err)
+	}
+
+	if result.Title != "Project Title" {
+		t.Errorf("expected title %q, got %q", "Project Title", result.Title)
+	}
+	wants := []string{"<h1>Project Title</h1>", "<em>emphasis</em>", `<a href="https://example.com">`, "<table>"}
+	for _, want := range wants {
+		if !strings.Contains(result.Content, want) {
+			t.Errorf("expected content to contain %q, got %q", want, result.Content)
+		}
+	}
+	if strings.Contains(result.Content, "<script>") {
+		t.Errorf("expected raw HTML to be omitted, got %q", result.Content)
+	}
+	if result.Extractor != "markdown" || result.WordCount == 0 {
+		t.Errorf("unexpected result %+v", result)
+	}
+
+	result, err = Markdown([]byte("No heading here."), mustParseURL(t, "https://example.com/docs/getting%20started.md"))
+	if err != nil {
+		t.Fatalf("Markdown() unexpected error = %v", err)
+	}
+	if result.Title != "getting started" {
+		t.Errorf("expected title from file name, got %q", result.Title)
+	}
+}
+
+func TestTitleFromURL(t *testing.T) {
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{"https://example.com/papers/attention.pdf", "attention"},
+		{"https://example.com/", "example.com"},
+		{"https://example.com", "example.com"},
+		{"https://example.com/notes/", "notes"},
+	}
+
+	for _, tt := range tests {
+		if got := titleFromURL(mustParseURL(t, tt.url)); got != tt.want {
+			t.Errorf("titleFromURL(%q) = %q, want %q", tt.url, got, tt.want)
+		}
+	}
+}
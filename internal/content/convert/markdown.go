@@ -0,0 +1,46 @@
+package convert
+
+import (
+	"bytes"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/shaftoe/savetoink/internal/content/extract"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/text"
+)
+
+// markdown renders GitHub Flavored Markdown, raw HTML in the source is omitted.
+var markdown = goldmark.New(goldmark.WithExtensions(extension.GFM))
+
+// Markdown renders a Markdown document to HTML, using its first level 1 heading as title.
+func Markdown(doc []byte, pageURL *url.URL) (*extract.Result, error) {
+	root := markdown.Parser().Parse(text.NewReader(doc))
+
+	var buf bytes.Buffer
+	if err := markdown.Renderer().Render(&buf, doc, root); err != nil {
+		return nil, fmt.Errorf("failed to render Markdown: %w", err)
+	}
+
+	return newResult("markdown", markdownTitle(root, doc), buf.String(), pageURL)
+}
+
+func markdownTitle(root ast.Node, source []byte) string {
+	var title string
+
+	_ = ast.Walk(root, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		if heading, ok := n.(*ast.Heading); ok && heading.Level == 1 {
+			title = strings.TrimSpace(string(heading.Lines().Value(source)))
+			return ast.WalkStop, nil
+		}
+		return ast.WalkContinue, nil
+	})
+
+	return title
+}
@@ -0,0 +1,81 @@
+package convert
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"io"
+	"net/url"
+	"strings"
+
+	"github.com/ledongthuc/pdf"
+	"github.com/shaftoe/savetoink/internal/content/extract"
+)
+
+// PDF extracts the text of a PDF document into HTML paragraphs.
+// The title is read from the document information dictionary, falling back to the file name.
+// Layout, images and scanned pages without a text layer are lost.
+func PDF(doc []byte, pageURL *url.URL) (result *extract.Result, err error) {
+	// the PDF parser panics on some malformed documents
+	defer func() {
+		if r := recover(); r != nil {
+			result, err = nil, fmt.Errorf("failed to read PDF: %v", r)
+		}
+	}()
+
+	reader, err := pdf.NewReader(bytes.NewReader(doc), int64(len(doc)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read PDF: %w", err)
+	}
+
+	plain, err := reader.GetPlainText()
+	if err != nil {
+		return nil, fmt.Errorf("failed to extract PDF text: %w", err)
+	}
+
+	text, err := io.ReadAll(plain)
+	if err != nil {
+		return nil, fmt.Errorf("failed to extract PDF text: %w", err)
+	}
+
+	var content strings.Builder
+	for _, paragraph := range paragraphs(string(text)) {
+		content.WriteString("<p>" + html.EscapeString(paragraph) + "</p>\n")
+	}
+
+	title := strings.TrimSpace(reader.Trailer().Key("Info").Key("Title").Text())
+
+	return newResult("pdf", title, content.String(), pageURL)
+}
+
+// paragraphs joins lines of text into paragraphs, ending them at blank lines
+// and at lines ending with a sentence terminator.
+func paragraphs(text string) []string {
+	var (
+		result  []string
+		current []string
+	)
+
+	flush := func() {
+		if len(current) > 0 {
+			result = append(result, strings.Join(current, " "))
+			current = nil
+		}
+	}
+
+	for line := range strings.Lines(text) {
+		line = strings.Join(strings.Fields(line), " ")
+		if line == "" {
+			flush()
+			continue
+		}
+
+		current = append(current, line)
+		if strings.ContainsAny(line[len(line)-1:], ".!?:") {
+			flush()
+		}
+	}
+	flush()
+
+	return result
+}
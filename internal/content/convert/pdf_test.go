@@ -0,0 +1,84 @@
+package convert
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// buildPDF returns a single page PDF document showing lines of text, with title in the document information.
+func buildPDF(title string, lines ...string) []byte {
+	var stream strings.Builder
+	stream.WriteString("BT /F1 12 Tf 72 720 Td\n")
+	for _, line := range lines {
+		fmt.Fprintf(&stream, "(%s) Tj T*\n", line)
+	}
+	stream.WriteString("ET")
+
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Contents 4 0 R " +
+			"/Resources << /Font << /F1 5 0 R >> >> >>",
+		fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", stream.Len(), stream.String()),
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>",
+		fmt.Sprintf("<< /Title (%s) >>", title),
+	}
+
+	var doc strings.Builder
+	doc.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for i, object := range objects {
+		offsets[i] = doc.Len()
+		fmt.Fprintf(&doc, "%d 0 obj\n%s\nendobj\n", i+1, object)
+	}
+
+	xref := doc.Len()
+	fmt.Fprintf(&doc, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&doc, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&doc, "trailer\n<< /Size %d /Root 1 0 R /Info 6 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+
+	return []byte(doc.String())
+}
+
+func TestPDF(t *testing.T) {
+	doc := buildPDF("Attention Is All You Need",
+		"The dominant sequence transduction models are based",
+		"on complex recurrent networks.",
+		"We propose a new simple network architecture.")
+
+	result, err := PDF(doc, mustParseURL(t, "https://example.com/paper.pdf"))
+	if err != nil {
+		t.Fatalf("PDF() unexpected error = %v", err)
+	}
+
+	if result.Title != "Attention Is All You Need" {
+		t.Errorf("expected title from document information, got %q", result.Title)
+	}
+	want := "<p>The dominant sequence transduction models are based on complex recurrent networks.</p>\n" +
+		"<p>We propose a new simple network architecture.</p>\n"
+	if result.Content != want {
+		t.Errorf("expected content %q, got %q", want, result.Content)
+	}
+	if result.Extractor != "pdf" || result.WordCount != 18 {
+		t.Errorf("unexpected result %+v", result)
+	}
+}
+
+func TestPDFInvalid(t *testing.T) {
+	if _, err := PDF([]byte("not a PDF"), nil); err == nil {
+		t.Error("expected error for invalid PDF")
+	}
+}
+
+func TestParagraphs(t *testing.T) {
+	text := "First line\nwraps here.\n\nHeading\n\n  Spaced   out  \ntext ends without stop"
+	want := []string{"First line wraps here.", "Heading", "Spaced out text ends without stop"}
+
+	if got := paragraphs(text); !reflect.DeepEqual(got, want) {
+		t.Errorf("paragraphs() = %q, want %q", got, want)
+	}
+}
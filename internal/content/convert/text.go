@@ -0,0 +1,33 @@
+package convert
+
+import (
+	"html"
+	"net/url"
+	"strings"
+
+	"github.com/shaftoe/savetoink/internal/consts"
+	"github.com/shaftoe/savetoink/internal/content/extract"
+)
+
+// Text wraps a plain text document into preformatted HTML, preserving its layout.
+// The first line is used as title if it is short and not part of a multi-column header, as in RFCs.
+func Text(doc []byte, pageURL *url.URL) (*extract.Result, error) {
+	text := strings.ReplaceAll(string(doc), "\r\n", "\n")
+	content := "<pre>" + html.EscapeString(strings.Trim(text, "\n")) + "</pre>"
+
+	return newResult("text", textTitle(text), content, pageURL)
+}
+
+func textTitle(text string) string {
+	for line := range strings.Lines(text) {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if len(line) > consts.TextTitleMaxLength || strings.Contains(line, "   ") {
+			return ""
+		}
+		return line
+	}
+	return ""
+}
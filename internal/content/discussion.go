@@ -0,0 +1,89 @@
+package content
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+
+	"github.com/shaftoe/savetoink/internal/consts"
+	"github.com/shaftoe/savetoink/internal/content/discussion"
+	"github.com/shaftoe/savetoink/internal/content/extract"
+	"github.com/shaftoe/savetoink/internal/model"
+)
+
+// extractDiscussion extracts the discussion at pageURL, parsing the document at docURL with source.
+// The linked article is extracted and rendered before the top comments, see discussion.Render, a failure
+// to extract it only leaves a link to it.
+func (e *Extractor) extractDiscussion(
+	ctx context.Context,
+	pageURL *url.URL,
+	source discussion.Source,
+	docURL *url.URL,
+) (*model.Article, error) {
+	body, finalURL, err := e.fetchDiscussion(ctx, docURL)
+	if err != nil {
+		return nil, err
+	}
+
+	thread, err := source.Parse(body, finalURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to extract article content: %w", err)
+	}
+
+	result := &extract.Result{
+		Extractor:   source.Name(),
+		Title:       thread.Title,
+		Author:      thread.Author,
+		SiteName:    thread.Site,
+		Hostname:    pageURL.Hostname(),
+		PublishedAt: thread.CreatedAt,
+	}
+
+	var linked *discussion.Article
+	if thread.Link != "" {
+		article, linkErr := e.extractURL(ctx, thread.Link)
+		if linkErr != nil {
+			log.Printf("warning: failed to extract article %s linked by %s: %v", thread.Link, pageURL, linkErr)
+		} else {
+			linked = &discussion.Article{Title: article.Title, Content: article.Content}
+			result.Excerpt = article.Excerpt
+			result.ImageURL = article.ImageURL
+			result.Language = article.Language
+		}
+	}
+
+	result.Content = discussion.Render(thread, pageURL.String(), linked, consts.DiscussionMaxComments)
+
+	return e.buildArticle(result, pageURL.String()), nil
+}
+
+// fetchDiscussion fetches the document describing a discussion, HTML or JSON, and returns its body and
+// the URL of the final response.
+func (e *Extractor) fetchDiscussion(ctx context.Context, docURL *url.URL) ([]byte, *url.URL, error) {
+	parsedURL, err := e.checkURL(docURL.String())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	header := make(http.Header)
+	header.Set("Accept", "application/json, text/html;q=0.9")
+
+	resp, done, err := e.send(ctx, parsedURL, header)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer done()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	body, err := e.readBody(resp)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return body, resp.Request.URL, nil
+}
@@ -0,0 +1,242 @@
+// Package discussion parses discussion threads from Hacker News, Reddit and Lobsters and renders them,
+// with the article they link to and their comments, into readable HTML.
+package discussion
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"html"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/shaftoe/savetoink/internal/consts"
+	nethtml "golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// ErrInvalidThread is returned when a fetched document is not a discussion thread of the source.
+var ErrInvalidThread = errors.New("invalid discussion thread")
+
+// Thread is a discussion thread.
+type Thread struct {
+	// Site is the name of the site hosting the discussion.
+	Site   string
+	Title  string
+	Author string
+	// Link is the URL of the article discussed, empty for text posts.
+	Link string
+	// Text is the HTML text of the post, if any.
+	Text      string
+	CreatedAt time.Time
+	// Comments are the top-level comments, in the order the site ranks them.
+	Comments []*Comment
+}
+
+// Comment is a comment of a thread with its replies.
+type Comment struct {
+	Author string
+	// Text is the HTML text of the comment.
+	Text      string
+	CreatedAt time.Time
+	Replies   []*Comment
+}
+
+// Article is the article linked by a thread, extracted by the caller.
+type Article struct {
+	Title   string
+	Content string
+}
+
+// Source fetches and parses the discussions of a site.
+type Source interface {
+	// Name returns a short identifier of the source, recorded as the extractor of the article.
+	Name() string
+	// Match reports whether u is a discussion of the site and returns the URL of the document describing it,
+	// e.g. its JSON representation.
+	Match(u *url.URL) (*url.URL, bool)
+	// Parse parses the document fetched from the URL returned by Match.
+	Parse(doc []byte, docURL *url.URL) (*Thread, error)
+}
+
+// Sources returns the supported discussion sites.
+func Sources() []Source {
+	return []Source{HackerNews{}, Reddit{}, Lobsters{}}
+}
+
+// Match returns the source of the discussion at u and the URL of the document describing it, nil if u
+// is not a supported discussion.
+func Match(u *url.URL) (Source, *url.URL) {
+	for _, source := range Sources() {
+		if docURL, ok := source.Match(u); ok {
+			return source, docURL
+		}
+	}
+	return nil, nil
+}
+
+// Render renders a thread fetched from threadURL into HTML: the post, the linked article, if extracted, or a
+// link to it, then at most maxComments comments in thread order, replies nested in blockquotes up to
+// consts.DiscussionMaxDepth levels.
+func Render(thread *Thread, threadURL string, linked *Article, maxComments int) string {
+	var b strings.Builder
+
+	b.WriteString(`<p>Discussion on <a href="` + html.EscapeString(threadURL) + `">` + html.EscapeString(thread.Site) +
+		"</a>")
+	if thread.Author != "" {
+		b.WriteString(" by " + html.EscapeString(thread.Author))
+	}
+	b.WriteString("</p>\n")
+
+	if thread.Text != "" {
+		b.WriteString(sanitize(thread.Text) + "\n")
+	}
+
+	if thread.Link != "" {
+		link := html.EscapeString(thread.Link)
+		if linked != nil && linked.Content != "" {
+			title := linked.Title
+			if title == "" {
+				title = thread.Title
+			}
+			b.WriteString("<h2>" + html.EscapeString(title) + "</h2>\n")
+			b.WriteString(`<p><a href="` + link + `">` + link + "</a></p>\n")
+			b.WriteString(linked.Content + "\n")
+		} else {
+			b.WriteString(`<p>Linked article: <a href="` + link + `">` + link + "</a></p>\n")
+		}
+	}
+
+	comments := limit(thread.Comments, maxComments)
+	if len(comments) > 0 {
+		b.WriteString("<h2>Comments</h2>\n")
+		for _, comment := range comments {
+			renderComment(&b, comment, 0)
+		}
+	}
+
+	return b.String()
+}
+
+func renderComment(b *strings.Builder, comment *Comment, depth int) {
+	tag := "blockquote"
+	if depth == 0 {
+		tag = "div"
+	}
+
+	b.WriteString("<" + tag + ` class="comment">`)
+	b.WriteString("<p><strong>" + html.EscapeString(comment.Author) + "</strong>")
+	if !comment.CreatedAt.IsZero() {
+		b.WriteString(" · " + comment.CreatedAt.UTC().Format(time.DateOnly))
+	}
+	b.WriteString("</p>\n" + sanitize(comment.Text) + "\n")
+
+	if depth+1 < consts.DiscussionMaxDepth {
+		for _, reply := range comment.Replies {
+			renderComment(b, reply, depth+1)
+		}
+	}
+	b.WriteString("</" + tag + ">\n")
+
+	// replies beyond the maximum depth are rendered at the same level as their parent
+	if depth+1 >= consts.DiscussionMaxDepth {
+		for _, reply := range comment.Replies {
+			renderComment(b, reply, depth)
+		}
+	}
+}
+
+// limit returns a copy of comments truncated to the first n comments in thread order, replies included.
+func limit(comments []*Comment, n int) []*Comment {
+	var truncate func([]*Comment) []*Comment
+	truncate = func(comments []*Comment) []*Comment {
+		var kept []*Comment
+		for _, comment := range comments {
+			if n <= 0 {
+				break
+			}
+			n--
+			c := *comment
+			c.Replies = truncate(comment.Replies)
+			kept = append(kept, &c)
+		}
+		return kept
+	}
+	return truncate(comments)
+}
+
+// flatComment is a comment of a thread listed in order with its depth, the top-level being 0.
+type flatComment struct {
+	Comment
+	depth int
+}
+
+// nest builds the comment tree of comments listed in thread order with their depth.
+func nest(flat []flatComment) []*Comment {
+	var roots []*Comment
+	var parents []*Comment
+
+	for i := range flat {
+		comment := &flat[i].Comment
+		depth := min(max(flat[i].depth, 0), len(parents))
+		parents = parents[:depth]
+
+		if depth == 0 {
+			roots = append(roots, comment)
+		} else {
+			parent := parents[depth-1]
+			parent.Replies = append(parent.Replies, comment)
+		}
+		parents = append(parents, comment)
+	}
+
+	return roots
+}
+
+// droppedElements are removed from the HTML text of posts and comments.
+var droppedElements = map[atom.Atom]bool{
+	atom.Script: true, atom.Style: true, atom.Iframe: true, atom.Object: true, atom.Embed: true, atom.Form: true,
+}
+
+// sanitize parses an HTML fragment, dropping scripts and embedded content, and renders it back balanced
+// in a div, as the text of posts and comments may start without a paragraph.
+func sanitize(fragment string) string {
+	parent := &nethtml.Node{Type: nethtml.ElementNode, Data: "div", DataAtom: atom.Div}
+	nodes, err := nethtml.ParseFragment(strings.NewReader(fragment), parent)
+	if err != nil {
+		return "<div>" + html.EscapeString(fragment) + "</div>"
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("<div>")
+	for _, node := range nodes {
+		if node.Type == nethtml.ElementNode && droppedElements[node.DataAtom] {
+			continue
+		}
+		drop(node)
+		if renderErr := nethtml.Render(&buf, node); renderErr != nil {
+			return "<div>" + html.EscapeString(fragment) + "</div>"
+		}
+	}
+	buf.WriteString("</div>")
+
+	return buf.String()
+}
+
+func drop(node *nethtml.Node) {
+	for child := node.FirstChild; child != nil; {
+		next := child.NextSibling
+		if child.Type == nethtml.ElementNode && droppedElements[child.DataAtom] {
+			node.RemoveChild(child)
+		} else {
+			drop(child)
+		}
+		child = next
+	}
+}
+
+// errInvalid wraps ErrInvalidThread with the reason a document could not be parsed.
+func errInvalid(source Source, reason string) error {
+	return fmt.Errorf("%w: %s: %s", ErrInvalidThread, source.Name(), reason)
+}
@@ -0,0 +1,152 @@
+package discussion
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/shaftoe/savetoink/internal/consts"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func mustParseURL(t *testing.T, raw string) *url.URL {
+	t.Helper()
+	u, err := url.Parse(raw)
+	require.NoError(t, err)
+	return u
+}
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{"https://news.ycombinator.com/item?id=1", "hackernews"},
+		{"https://www.reddit.com/r/golang/comments/1abcde/title/", "reddit"},
+		{"https://lobste.rs/s/abc123/title", "lobsters"},
+		{"https://example.com/article", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			source, docURL := Match(mustParseURL(t, tt.url))
+			if tt.want == "" {
+				assert.Nil(t, source)
+				assert.Nil(t, docURL)
+				return
+			}
+			require.NotNil(t, source)
+			assert.Equal(t, tt.want, source.Name())
+			assert.NotNil(t, docURL)
+		})
+	}
+}
+
+func TestRender(t *testing.T) {
+	thread := &Thread{
+		Site:   "Hacker News",
+		Title:  "Title & more",
+		Author: "shaftoe",
+		Link:   "https://example.com/post",
+		Comments: []*Comment{
+			{Author: "alice", Text: "First<p>second paragraph</p>", Replies: []*Comment{
+				{Author: "bob", Text: "<p>reply</p>"},
+			}},
+			{Author: "carol", Text: "<p>another</p>"},
+		},
+	}
+
+	got := Render(thread, "https://news.ycombinator.com/item?id=1", &Article{Content: "<p>Article body</p>"}, 10)
+
+	assert.Contains(t, got,
+		`<p>Discussion on <a href="https://news.ycombinator.com/item?id=1">Hacker News</a> by shaftoe</p>`)
+	assert.Contains(t, got, "<h2>Title &amp; more</h2>", "the thread title is used when the article has none")
+	assert.Contains(t, got, "<p>Article body</p>")
+	assert.Contains(t, got, "<h2>Comments</h2>")
+	assert.Contains(t, got, `<div class="comment"><p><strong>alice</strong></p>`+"\n"+
+		`<div>First<p>second paragraph</p></div>`+"\n"+
+		`<blockquote class="comment"><p><strong>bob</strong></p>`+"\n"+`<div><p>reply</p></div>`+"\n"+
+		"</blockquote>\n</div>")
+	assert.Less(t, strings.Index(got, "Article body"), strings.Index(got, "alice"))
+
+	withoutArticle := Render(thread, "https://news.ycombinator.com/item?id=1", nil, 1)
+	assert.Contains(t, withoutArticle,
+		`<p>Linked article: <a href="https://example.com/post">https://example.com/post</a></p>`)
+	assert.Contains(t, withoutArticle, "alice")
+	assert.NotContains(t, withoutArticle, "bob", "comments are limited")
+	assert.NotContains(t, withoutArticle, "carol", "comments are limited")
+
+	empty := Render(&Thread{Site: "Lobsters", Text: "<p>text post</p>"}, "https://lobste.rs/s/abc", nil, 10)
+	assert.Contains(t, empty, "<p>text post</p>")
+	assert.NotContains(t, empty, "Comments")
+	assert.NotContains(t, empty, "Linked article")
+}
+
+func TestRenderMaxDepth(t *testing.T) {
+	root := &Comment{Author: "level0"}
+	parent := root
+	for level := 1; level <= consts.DiscussionMaxDepth+1; level++ {
+		reply := &Comment{Author: fmt.Sprintf("level%d", level)}
+		parent.Replies = []*Comment{reply}
+		parent = reply
+	}
+
+	got := Render(&Thread{Site: "Reddit", Comments: []*Comment{root}}, "https://reddit.com/", nil, 100)
+
+	nesting, deepest := 0, 0
+	tags := strings.NewReplacer("<blockquote", " open ", "</blockquote>", " close ").Replace(got)
+	for _, tag := range strings.Fields(tags) {
+		switch tag {
+		case "open":
+			nesting++
+			deepest = max(deepest, nesting)
+		case "close":
+			nesting--
+		}
+	}
+	assert.Equal(t, consts.DiscussionMaxDepth-1, deepest, "nesting is capped")
+	for level := 0; level <= consts.DiscussionMaxDepth+1; level++ {
+		assert.Contains(t, got, fmt.Sprintf("<strong>level%d</strong>", level), "deeper replies are kept")
+	}
+}
+
+func TestNest(t *testing.T) {
+	flat := []flatComment{
+		{Comment: Comment{Author: "a"}, depth: 0},
+		{Comment: Comment{Author: "b"}, depth: 1},
+		{Comment: Comment{Author: "c"}, depth: 3},
+		{Comment: Comment{Author: "d"}, depth: 1},
+		{Comment: Comment{Author: "e"}, depth: 0},
+	}
+
+	roots := nest(flat)
+
+	require.Len(t, roots, 2)
+	assert.Equal(t, "a", roots[0].Author)
+	require.Len(t, roots[0].Replies, 2)
+	assert.Equal(t, "b", roots[0].Replies[0].Author)
+	require.Len(t, roots[0].Replies[0].Replies, 1, "skipped levels are attached to the deepest parent")
+	assert.Equal(t, "c", roots[0].Replies[0].Replies[0].Author)
+	assert.Equal(t, "d", roots[0].Replies[1].Author)
+	assert.Equal(t, "e", roots[1].Author)
+}
+
+func TestSanitize(t *testing.T) {
+	tests := []struct {
+		name     string
+		fragment string
+		want     string
+	}{
+		{"text", "plain text", "<div>plain text</div>"},
+		{"unbalanced", "<p>open <b>bold", "<div><p>open <b>bold</b></p></div>"},
+		{"script", "<p>text<script>alert(1)</script></p><iframe src=x></iframe>", "<div><p>text</p></div>"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, sanitize(tt.fragment))
+		})
+	}
+}
@@ -0,0 +1,129 @@
+package discussion
+
+import (
+	"bytes"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-shiori/dom"
+	"golang.org/x/net/html"
+)
+
+const hackerNewsHost = "news.ycombinator.com"
+
+// HackerNews parses Hacker News item pages. Comments are listed in rank order with their indentation level.
+type HackerNews struct{}
+
+// Name implements Source.
+func (HackerNews) Name() string {
+	return "hackernews"
+}
+
+// Match implements Source, matching item pages. The document describing the discussion is the page itself.
+func (HackerNews) Match(u *url.URL) (*url.URL, bool) {
+	if !strings.EqualFold(u.Hostname(), hackerNewsHost) || u.Path != "/item" {
+		return nil, false
+	}
+
+	id := u.Query().Get("id")
+	if _, err := strconv.ParseUint(id, 10, 64); err != nil {
+		return nil, false
+	}
+
+	return &url.URL{Scheme: "https", Host: hackerNewsHost, Path: "/item", RawQuery: "id=" + id}, true
+}
+
+// Parse implements Source.
+func (h HackerNews) Parse(doc []byte, docURL *url.URL) (*Thread, error) {
+	root, err := dom.Parse(bytes.NewReader(doc))
+	if err != nil {
+		return nil, errInvalid(h, err.Error())
+	}
+
+	item := dom.QuerySelector(root, ".fatitem")
+	if item == nil {
+		return nil, errInvalid(h, "missing item")
+	}
+
+	thread := &Thread{Site: "Hacker News"}
+	if title := dom.QuerySelector(item, ".titleline > a"); title != nil {
+		thread.Title = strings.TrimSpace(dom.TextContent(title))
+		if link, linkErr := docURL.Parse(dom.GetAttribute(title, "href")); linkErr == nil && !h.isItem(link) {
+			thread.Link = link.String()
+		}
+	}
+	if title := dom.QuerySelector(root, "title"); thread.Title == "" && title != nil {
+		thread.Title = strings.TrimSpace(dom.TextContent(title))
+	}
+
+	if author := dom.QuerySelector(item, ".hnuser"); author != nil {
+		thread.Author = dom.TextContent(author)
+	}
+	thread.CreatedAt = hackerNewsTime(dom.QuerySelector(item, ".age"))
+	if text := dom.QuerySelector(item, ".toptext"); text != nil {
+		thread.Text = strings.TrimSpace(dom.InnerHTML(text))
+	}
+
+	var flat []flatComment
+	for _, row := range dom.QuerySelectorAll(root, "tr.comtr") {
+		comment := flatComment{depth: hackerNewsIndent(row)}
+		comment.CreatedAt = hackerNewsTime(dom.QuerySelector(row, ".age"))
+		if author := dom.QuerySelector(row, ".hnuser"); author != nil {
+			comment.Author = dom.TextContent(author)
+		}
+		if text := dom.QuerySelector(row, ".commtext"); text != nil {
+			comment.Text = strings.TrimSpace(dom.InnerHTML(text))
+		} else {
+			// deleted and flagged comments are kept for the context of their replies
+			comment.Author, comment.Text = "[deleted]", "[deleted]"
+		}
+		flat = append(flat, comment)
+	}
+	thread.Comments = nest(flat)
+
+	return thread, nil
+}
+
+// isItem reports whether u is a Hacker News item page, the link of text posts such as Ask HN.
+func (HackerNews) isItem(u *url.URL) bool {
+	return strings.EqualFold(u.Hostname(), hackerNewsHost) && u.Path == "/item"
+}
+
+// hackerNewsIndent returns the indentation level of a comment row, from the indent attribute or, in older
+// pages, the width of the spacer image, 40 pixels per level.
+func hackerNewsIndent(row *html.Node) int {
+	ind := dom.QuerySelector(row, "td.ind")
+	if ind == nil {
+		return 0
+	}
+
+	if indent, err := strconv.Atoi(dom.GetAttribute(ind, "indent")); err == nil {
+		return indent
+	}
+
+	if img := dom.QuerySelector(ind, "img"); img != nil {
+		if width, err := strconv.Atoi(dom.GetAttribute(img, "width")); err == nil {
+			return width / 40
+		}
+	}
+
+	return 0
+}
+
+// hackerNewsTime parses the time of an age element, its title holding the ISO time optionally followed by
+// the Unix time.
+func hackerNewsTime(age *html.Node) time.Time {
+	if age == nil {
+		return time.Time{}
+	}
+
+	value, _, _ := strings.Cut(dom.GetAttribute(age, "title"), " ")
+	t, err := time.Parse("2006-01-02T15:04:05", value)
+	if err != nil {
+		return time.Time{}
+	}
+
+	return t.UTC()
+}
@@ -0,0 +1,86 @@
+package discussion
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHackerNewsMatch(t *testing.T) {
+	tests := []struct {
+		url     string
+		wantDoc string
+	}{
+		{"https://news.ycombinator.com/item?id=40000001", "https://news.ycombinator.com/item?id=40000001"},
+		{"http://News.YCombinator.com/item?id=1&p=2", "https://news.ycombinator.com/item?id=1"},
+		{"https://news.ycombinator.com/item?id=abc", ""},
+		{"https://news.ycombinator.com/news", ""},
+		{"https://example.com/item?id=1", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			docURL, ok := HackerNews{}.Match(mustParseURL(t, tt.url))
+			if tt.wantDoc == "" {
+				assert.False(t, ok)
+				return
+			}
+			require.True(t, ok)
+			assert.Equal(t, tt.wantDoc, docURL.String())
+		})
+	}
+}
+
+func TestHackerNewsParse(t *testing.T) {
+	doc, err := os.ReadFile("testdata/hackernews_item.html")
+	require.NoError(t, err)
+
+	thread, err := HackerNews{}.Parse(doc, mustParseURL(t, "https://news.ycombinator.com/item?id=40000001"))
+	require.NoError(t, err)
+
+	assert.Equal(t, "Hacker News", thread.Site)
+	assert.Equal(t, "Show HN: A tool to send articles to your Kindle", thread.Title)
+	assert.Equal(t, "https://example.com/posts/kindle-tool", thread.Link)
+	assert.Equal(t, "shaftoe", thread.Author)
+	assert.Equal(t, time.Date(2024, time.May, 1, 9, 30, 0, 0, time.UTC), thread.CreatedAt)
+	assert.Empty(t, thread.Text)
+
+	require.Len(t, thread.Comments, 2)
+	first := thread.Comments[0]
+	assert.Equal(t, "alice", first.Author)
+	assert.Contains(t, first.Text, "This is great")
+	assert.Contains(t, first.Text, "<i>paywalled</i>")
+	assert.NotContains(t, first.Text, "reply?id=", "the reply link is not part of the comment")
+	assert.Equal(t, time.Date(2024, time.May, 1, 10, 0, 0, 0, time.UTC), first.CreatedAt)
+
+	require.Len(t, first.Replies, 1)
+	assert.Equal(t, "shaftoe", first.Replies[0].Author)
+	require.Len(t, first.Replies[0].Replies, 1)
+	assert.Equal(t, "[deleted]", first.Replies[0].Replies[0].Author, "flagged comments are kept as placeholders")
+
+	assert.Equal(t, "bob", thread.Comments[1].Author)
+	assert.Contains(t, thread.Comments[1].Text, "<code>calibre</code>")
+}
+
+func TestHackerNewsParseTextPost(t *testing.T) {
+	doc, err := os.ReadFile("testdata/hackernews_ask.html")
+	require.NoError(t, err)
+
+	thread, err := HackerNews{}.Parse(doc, mustParseURL(t, "https://news.ycombinator.com/item?id=40000100"))
+	require.NoError(t, err)
+
+	assert.Equal(t, "Ask HN: How do you read long articles?", thread.Title)
+	assert.Empty(t, thread.Link, "text posts link to themselves")
+	assert.Equal(t, "carol", thread.Author)
+	assert.Contains(t, thread.Text, "<p>What works for you?</p>")
+	assert.Empty(t, thread.Comments)
+}
+
+func TestHackerNewsParseInvalid(t *testing.T) {
+	_, err := HackerNews{}.Parse([]byte("<html><body>Not found</body></html>"),
+		mustParseURL(t, "https://news.ycombinator.com/item?id=1"))
+	assert.ErrorIs(t, err, ErrInvalidThread)
+}
@@ -0,0 +1,105 @@
+package discussion
+
+import (
+	"encoding/json"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const lobstersHost = "lobste.rs"
+
+// Lobsters parses the JSON representation of Lobsters stories, comments listed in thread order with their depth.
+type Lobsters struct{}
+
+// Name implements Source.
+func (Lobsters) Name() string {
+	return "lobsters"
+}
+
+// Match implements Source, matching /s/{id} stories.
+func (Lobsters) Match(u *url.URL) (*url.URL, bool) {
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+	if !strings.EqualFold(u.Hostname(), lobstersHost) || len(parts) < 2 || parts[0] != "s" || parts[1] == "" {
+		return nil, false
+	}
+
+	id := strings.TrimSuffix(parts[1], ".json")
+
+	return &url.URL{Scheme: "https", Host: lobstersHost, Path: "/s/" + id + ".json"}, true
+}
+
+// lobstersUser is a username, an object with a username in older versions of the API.
+type lobstersUser string
+
+func (u *lobstersUser) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err == nil {
+		*u = lobstersUser(name)
+		return nil
+	}
+
+	var user struct {
+		Username string `json:"username"`
+	}
+	if err := json.Unmarshal(data, &user); err != nil {
+		return err
+	}
+	*u = lobstersUser(user.Username)
+	return nil
+}
+
+type lobstersStory struct {
+	Title         string       `json:"title"`
+	URL           string       `json:"url"`
+	Description   string       `json:"description"`
+	SubmitterUser lobstersUser `json:"submitter_user"`
+	CreatedAt     time.Time    `json:"created_at"`
+	Comments      []struct {
+		Comment        string       `json:"comment"`
+		CommentingUser lobstersUser `json:"commenting_user"`
+		CreatedAt      time.Time    `json:"created_at"`
+		// Depth is 0 for top-level comments, older versions of the API have IndentLevel, 1 for top-level comments.
+		Depth       *int `json:"depth"`
+		IndentLevel int  `json:"indent_level"`
+	} `json:"comments"`
+}
+
+// Parse implements Source.
+func (l Lobsters) Parse(doc []byte, docURL *url.URL) (*Thread, error) {
+	var story lobstersStory
+	if err := json.Unmarshal(doc, &story); err != nil {
+		return nil, errInvalid(l, err.Error())
+	}
+
+	if story.Title == "" {
+		return nil, errInvalid(l, "missing story")
+	}
+
+	thread := &Thread{
+		Site:      "Lobsters",
+		Title:     story.Title,
+		Author:    string(story.SubmitterUser),
+		Text:      story.Description,
+		CreatedAt: story.CreatedAt.UTC(),
+	}
+	if link, err := docURL.Parse(story.URL); err == nil && story.URL != "" {
+		thread.Link = link.String()
+	}
+
+	flat := make([]flatComment, 0, len(story.Comments))
+	for _, c := range story.Comments {
+		depth := c.IndentLevel - 1
+		if c.Depth != nil {
+			depth = *c.Depth
+		}
+		comment := flatComment{depth: depth}
+		comment.Author = string(c.CommentingUser)
+		comment.Text = c.Comment
+		comment.CreatedAt = c.CreatedAt.UTC()
+		flat = append(flat, comment)
+	}
+	thread.Comments = nest(flat)
+
+	return thread, nil
+}
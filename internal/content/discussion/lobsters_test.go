@@ -0,0 +1,74 @@
+package discussion
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLobstersMatch(t *testing.T) {
+	tests := []struct {
+		url     string
+		wantDoc string
+	}{
+		{"https://lobste.rs/s/abc123/reading_on_e_ink_devices", "https://lobste.rs/s/abc123.json"},
+		{"https://lobste.rs/s/abc123", "https://lobste.rs/s/abc123.json"},
+		{"https://lobste.rs/s/abc123.json", "https://lobste.rs/s/abc123.json"},
+		{"https://lobste.rs/t/reading", ""},
+		{"https://example.com/s/abc123", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			docURL, ok := Lobsters{}.Match(mustParseURL(t, tt.url))
+			if tt.wantDoc == "" {
+				assert.False(t, ok)
+				return
+			}
+			require.True(t, ok)
+			assert.Equal(t, tt.wantDoc, docURL.String())
+		})
+	}
+}
+
+func TestLobstersParse(t *testing.T) {
+	doc, err := os.ReadFile("testdata/lobsters_story.json")
+	require.NoError(t, err)
+
+	thread, err := Lobsters{}.Parse(doc, mustParseURL(t, "https://lobste.rs/s/abc123.json"))
+	require.NoError(t, err)
+
+	assert.Equal(t, "Lobsters", thread.Site)
+	assert.Equal(t, "Reading on e-ink devices", thread.Title)
+	assert.Equal(t, "https://example.com/posts/e-ink", thread.Link)
+	assert.Equal(t, "dave", thread.Author)
+	assert.Equal(t, time.Date(2024, time.May, 3, 17, 0, 0, 0, time.UTC), thread.CreatedAt)
+
+	require.Len(t, thread.Comments, 2)
+	assert.Equal(t, "erin", thread.Comments[0].Author)
+	require.Len(t, thread.Comments[0].Replies, 1)
+	assert.Equal(t, "frank", thread.Comments[0].Replies[0].Author)
+	assert.Equal(t, "grace", thread.Comments[1].Author)
+}
+
+func TestLobstersParseLegacyFormat(t *testing.T) {
+	doc := `{"title": "Story", "url": "", "description": "<p>Text post</p>",
+		"submitter_user": {"username": "dave"},
+		"comments": [
+			{"comment": "<p>top</p>", "indent_level": 1, "commenting_user": {"username": "erin"}},
+			{"comment": "<p>reply</p>", "indent_level": 2, "commenting_user": {"username": "frank"}}
+		]}`
+
+	thread, err := Lobsters{}.Parse([]byte(doc), mustParseURL(t, "https://lobste.rs/s/abc123.json"))
+	require.NoError(t, err)
+
+	assert.Equal(t, "dave", thread.Author)
+	assert.Empty(t, thread.Link)
+	assert.Equal(t, "<p>Text post</p>", thread.Text)
+	require.Len(t, thread.Comments, 1)
+	require.Len(t, thread.Comments[0].Replies, 1)
+	assert.Equal(t, "frank", thread.Comments[0].Replies[0].Author)
+}
@@ -0,0 +1,122 @@
+package discussion
+
+import (
+	"encoding/json"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// redditHosts are the hosts serving Reddit threads.
+var redditHosts = []string{"reddit.com", "www.reddit.com", "old.reddit.com", "new.reddit.com", "np.reddit.com"}
+
+// Reddit parses the JSON representation of Reddit threads, a post listing followed by a comment listing.
+type Reddit struct{}
+
+// Name implements Source.
+func (Reddit) Name() string {
+	return "reddit"
+}
+
+// Match implements Source, matching /r/{subreddit}/comments/{id} threads, with unescaped HTML in the JSON document.
+func (Reddit) Match(u *url.URL) (*url.URL, bool) {
+	host := strings.ToLower(u.Hostname())
+	known := false
+	for _, redditHost := range redditHosts {
+		known = known || host == redditHost
+	}
+
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+	if !known || len(parts) < 4 || parts[0] != "r" || parts[2] != "comments" || parts[3] == "" {
+		return nil, false
+	}
+
+	return &url.URL{
+		Scheme:   "https",
+		Host:     "www.reddit.com",
+		Path:     "/r/" + parts[1] + "/comments/" + parts[3] + "/.json",
+		RawQuery: "raw_json=1",
+	}, true
+}
+
+type redditListing struct {
+	Data struct {
+		Children []redditThing `json:"children"`
+	} `json:"data"`
+}
+
+type redditThing struct {
+	Kind string `json:"kind"`
+	Data struct {
+		Title      string  `json:"title"`
+		Author     string  `json:"author"`
+		URL        string  `json:"url"`
+		IsSelf     bool    `json:"is_self"`
+		SelfText   string  `json:"selftext_html"`
+		Body       string  `json:"body_html"`
+		CreatedUTC float64 `json:"created_utc"`
+		// Replies is an empty string for comments without replies, a listing otherwise.
+		Replies json.RawMessage `json:"replies"`
+	} `json:"data"`
+}
+
+// Parse implements Source.
+func (r Reddit) Parse(doc []byte, docURL *url.URL) (*Thread, error) {
+	var listings []redditListing
+	if err := json.Unmarshal(doc, &listings); err != nil {
+		return nil, errInvalid(r, err.Error())
+	}
+
+	if len(listings) == 0 || len(listings[0].Data.Children) == 0 {
+		return nil, errInvalid(r, "missing post")
+	}
+
+	post := listings[0].Data.Children[0].Data
+	thread := &Thread{
+		Site:      "Reddit",
+		Title:     post.Title,
+		Author:    post.Author,
+		Text:      post.SelfText,
+		CreatedAt: redditTime(post.CreatedUTC),
+	}
+	if link, err := docURL.Parse(post.URL); err == nil && !post.IsSelf && post.URL != "" {
+		thread.Link = link.String()
+	}
+
+	if len(listings) > 1 {
+		thread.Comments = redditComments(listings[1])
+	}
+
+	return thread, nil
+}
+
+// redditComments returns the comments of a listing, skipping the "more comments" placeholders.
+func redditComments(listing redditListing) []*Comment {
+	var comments []*Comment
+	for _, thing := range listing.Data.Children {
+		if thing.Kind != "t1" {
+			continue
+		}
+
+		comment := &Comment{
+			Author:    thing.Data.Author,
+			Text:      thing.Data.Body,
+			CreatedAt: redditTime(thing.Data.CreatedUTC),
+		}
+
+		var replies redditListing
+		if err := json.Unmarshal(thing.Data.Replies, &replies); err == nil {
+			comment.Replies = redditComments(replies)
+		}
+
+		comments = append(comments, comment)
+	}
+	return comments
+}
+
+func redditTime(seconds float64) time.Time {
+	if seconds <= 0 {
+		return time.Time{}
+	}
+	return time.Unix(int64(seconds), 0).UTC()
+}
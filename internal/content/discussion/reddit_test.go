@@ -0,0 +1,71 @@
+package discussion
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedditMatch(t *testing.T) {
+	tests := []struct {
+		url     string
+		wantDoc string
+	}{
+		{
+			"https://www.reddit.com/r/golang/comments/1abcde/writing_an_epub_generator_in_go/",
+			"https://www.reddit.com/r/golang/comments/1abcde/.json?raw_json=1",
+		},
+		{
+			"https://old.reddit.com/r/golang/comments/1abcde/writing_an_epub_generator_in_go/c1/?context=3",
+			"https://www.reddit.com/r/golang/comments/1abcde/.json?raw_json=1",
+		},
+		{"https://reddit.com/r/golang/comments/1abcde", "https://www.reddit.com/r/golang/comments/1abcde/.json?raw_json=1"},
+		{"https://www.reddit.com/r/golang/", ""},
+		{"https://www.reddit.com/user/gopher42/comments/1abcde", ""},
+		{"https://notreddit.com/r/golang/comments/1abcde", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			docURL, ok := Reddit{}.Match(mustParseURL(t, tt.url))
+			if tt.wantDoc == "" {
+				assert.False(t, ok)
+				return
+			}
+			require.True(t, ok)
+			assert.Equal(t, tt.wantDoc, docURL.String())
+		})
+	}
+}
+
+func TestRedditParse(t *testing.T) {
+	doc, err := os.ReadFile("testdata/reddit_thread.json")
+	require.NoError(t, err)
+
+	thread, err := Reddit{}.Parse(doc, mustParseURL(t, "https://www.reddit.com/r/golang/comments/1abcde/.json"))
+	require.NoError(t, err)
+
+	assert.Equal(t, "Reddit", thread.Site)
+	assert.Equal(t, "Writing an EPUB generator in Go", thread.Title)
+	assert.Equal(t, "https://example.com/posts/epub-in-go", thread.Link)
+	assert.Equal(t, "gopher42", thread.Author)
+	assert.Equal(t, time.Unix(1714550400, 0).UTC(), thread.CreatedAt)
+
+	require.Len(t, thread.Comments, 2, "more comments placeholders are skipped")
+	assert.Equal(t, "epubfan", thread.Comments[0].Author)
+	assert.Contains(t, thread.Comments[0].Text, "<code>nav.xhtml</code>")
+	require.Len(t, thread.Comments[0].Replies, 1)
+	assert.Equal(t, "gopher42", thread.Comments[0].Replies[0].Author)
+	assert.Empty(t, thread.Comments[0].Replies[0].Replies)
+	assert.Equal(t, "[deleted]", thread.Comments[1].Author)
+}
+
+func TestRedditParseInvalid(t *testing.T) {
+	for _, doc := range []string{`{"error": 404}`, `[]`, `<html></html>`} {
+		_, err := Reddit{}.Parse([]byte(doc), mustParseURL(t, "https://www.reddit.com/r/golang/comments/1/.json"))
+		assert.ErrorIs(t, err, ErrInvalidThread, doc)
+	}
+}
@@ -0,0 +1,62 @@
+package content
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// routeTo sends the requests of the extractor to the TLS server, whatever their host.
+func routeTo(e *Extractor, server *httptest.Server) {
+	transport := server.Client().Transport.(*http.Transport).Clone()
+	transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true} //nolint:gosec // test server
+	transport.DialContext = func(ctx context.Context, network, _ string) (net.Conn, error) {
+		return (&net.Dialer{}).DialContext(ctx, network, server.Listener.Addr().String())
+	}
+	e.client.Transport = transport
+}
+
+func TestExtractDiscussion(t *testing.T) {
+	thread, err := os.ReadFile("discussion/testdata/hackernews_item.html")
+	require.NoError(t, err)
+
+	var linkedFetched bool
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		switch r.Host {
+		case "news.ycombinator.com":
+			_, _ = w.Write(thread)
+		case "example.com":
+			linkedFetched = true
+			_, _ = w.Write([]byte(`<html><head><title>Kindle tool</title></head><body><article>` +
+				`<h1>Kindle tool</h1><p>The article discussed on Hacker News, long enough to be extracted ` +
+				`as the main content of the page.</p></article></body></html>`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	extractor := NewExtractor(WithFetchConfig(FetchConfig{HostInterval: -1}))
+	routeTo(extractor, server)
+
+	article, err := extractor.ExtractFromURL(context.Background(), "https://news.ycombinator.com/item?id=40000001")
+	require.NoError(t, err)
+
+	assert.True(t, linkedFetched, "the linked article is extracted")
+	assert.Equal(t, "Show HN: A tool to send articles to your Kindle", article.Title)
+	assert.Equal(t, "shaftoe", article.Author)
+	assert.Equal(t, "Hacker News", article.SiteName)
+	assert.Equal(t, "hackernews", article.Extractor)
+	assert.Contains(t, article.Content, "The article discussed on Hacker News")
+	assert.Contains(t, article.Content, "<strong>alice</strong>")
+	assert.NotContains(t, article.Content, "<script")
+	assert.Positive(t, article.WordCount)
+}
@@ -0,0 +1,142 @@
+// Package extract provides pluggable strategies extracting the main readable content from HTML documents.
+package extract
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/shaftoe/savetoink/internal/consts"
+	"github.com/shaftoe/savetoink/internal/content/wordcount"
+)
+
+// ErrNoContent is returned when no readable content could be extracted from a document.
+var ErrNoContent = errors.New("no content extracted")
+
+// Result holds the content and metadata extracted from an HTML document.
+type Result struct {
+	// Extractor is the name of the extractor that produced the result.
+	Extractor   string
+	Title       string
+	Author      string
+	Content     string
+	Excerpt     string
+	ImageURL    string
+	SiteName    string
+	Hostname    string
+	PageType    string
+	Language    string
+	PublishedAt time.Time
+	WordCount   int
+}
+
+// Extractor extracts the main readable content from an HTML document.
+type Extractor interface {
+	// Name returns a short identifier of the extractor, recorded on the extracted article.
+	Name() string
+	// Extract returns the content of doc, fetched from pageURL, or ErrNoContent.
+	Extract(doc []byte, pageURL *url.URL) (*Result, error)
+}
+
+// Registry holds extractors in order of preference and picks the best result among them.
+type Registry struct {
+	extractors []Extractor
+}
+
+// NewRegistry creates a Registry running the given extractors in order of preference.
+func NewRegistry(extractors ...Extractor) *Registry {
+	return &Registry{extractors: extractors}
+}
+
+// DefaultRegistry creates a Registry chaining trafilatura, go-readability and the largest text block fallback.
+func DefaultRegistry() *Registry {
+	return NewRegistry(NewTrafilatura(), NewReadability(), NewLargestBlock())
+}
+
+// Register appends an extractor to the registry, with lower preference than those already registered.
+func (r *Registry) Register(e Extractor) {
+	r.extractors = append(r.extractors, e)
+}
+
+// Extract runs all registered extractors on doc and returns the result with the best score.
+// A result replaces the one of a preferred extractor only if its score is higher by
+// consts.ExtractorScoreMargin, and metadata missing from the winning result is filled in
+// from the other results.
+func (r *Registry) Extract(doc []byte, pageURL *url.URL) (*Result, error) {
+	var (
+		results   []*Result
+		errs      []error
+		best      *Result
+		bestScore float64
+	)
+
+	for _, e := range r.extractors {
+		result, err := e.Extract(doc, pageURL)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", e.Name(), err))
+			continue
+		}
+
+		result.Extractor = e.Name()
+		if result.WordCount == 0 {
+			result.WordCount = CountWords(result.Content)
+		}
+		if result.WordCount == 0 {
+			errs = append(errs, fmt.Errorf("%s: %w", e.Name(), ErrNoContent))
+			continue
+		}
+		results = append(results, result)
+
+		if current := score(result); best == nil || current > bestScore*consts.ExtractorScoreMargin {
+			best, bestScore = result, current
+		}
+	}
+
+	if best == nil {
+		return nil, fmt.Errorf("%w: %w", ErrNoContent, errors.Join(errs...))
+	}
+
+	for _, result := range results {
+		if result != best {
+			fillMetadata(best, result)
+		}
+	}
+
+	return best, nil
+}
+
+func score(result *Result) float64 {
+	s := float64(result.WordCount)
+	if result.Title != "" {
+		s += consts.ExtractorTitleBonus
+	}
+	return s
+}
+
+// fillMetadata copies metadata fields missing from dst from src.
+func fillMetadata(dst, src *Result) {
+	fill := func(dst *string, src string) {
+		if *dst == "" {
+			*dst = src
+		}
+	}
+
+	fill(&dst.Title, src.Title)
+	fill(&dst.Author, src.Author)
+	fill(&dst.Excerpt, src.Excerpt)
+	fill(&dst.ImageURL, src.ImageURL)
+	fill(&dst.SiteName, src.SiteName)
+	fill(&dst.Hostname, src.Hostname)
+	fill(&dst.PageType, src.PageType)
+	fill(&dst.Language, src.Language)
+
+	if dst.PublishedAt.IsZero() {
+		dst.PublishedAt = src.PublishedAt
+	}
+}
+
+// CountWords returns the number of words in the text of an HTML fragment, see wordcount.CountHTML.
+func CountWords(content string) int {
+	return wordcount.CountHTML(content).Total()
+}
@@ -0,0 +1,172 @@
+package extract
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+type fakeExtractor struct {
+	name   string
+	result *Result
+	err    error
+}
+
+func (f *fakeExtractor) Name() string {
+	return f.name
+}
+
+func (f *fakeExtractor) Extract(_ []byte, _ *url.URL) (*Result, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	result := *f.result
+	return &result, nil
+}
+
+func words(n int) string {
+	return "<p>" + strings.Repeat("word ", n) + "</p>"
+}
+
+func TestRegistryExtract(t *testing.T) {
+	published := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		extractors []Extractor
+		winner     string
+		wantErr    bool
+	}{
+		{
+			name: "preferred extractor wins on similar scores",
+			extractors: []Extractor{
+				&fakeExtractor{name: "first", result: &Result{Title: "T", Content: words(100)}},
+				&fakeExtractor{name: "second", result: &Result{Title: "T", Content: words(110)}},
+			},
+			winner: "first",
+		},
+		{
+			name: "fallback wins on much higher score",
+			extractors: []Extractor{
+				&fakeExtractor{name: "first", result: &Result{Content: words(10)}},
+				&fakeExtractor{name: "second", result: &Result{Title: "T", Content: words(500)}},
+			},
+			winner: "second",
+		},
+		{
+			name: "fallback used when preferred extractor fails",
+			extractors: []Extractor{
+				&fakeExtractor{name: "first", err: ErrNoContent},
+				&fakeExtractor{name: "second", result: &Result{Content: words(5)}},
+			},
+			winner: "second",
+		},
+		{
+			name: "empty content is discarded",
+			extractors: []Extractor{
+				&fakeExtractor{name: "first", result: &Result{Title: "T", Content: "<div></div>"}},
+				&fakeExtractor{name: "second", result: &Result{Content: words(5)}},
+			},
+			winner: "second",
+		},
+		{
+			name: "all extractors fail",
+			extractors: []Extractor{
+				&fakeExtractor{name: "first", err: errors.New("boom")},
+				&fakeExtractor{name: "second", result: &Result{Content: ""}},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registry := NewRegistry()
+			for _, e := range tt.extractors {
+				registry.Register(e)
+			}
+
+			result, err := registry.Extract(nil, nil)
+			if tt.wantErr {
+				if !errors.Is(err, ErrNoContent) {
+					t.Errorf("expected ErrNoContent, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Extract() unexpected error = %v", err)
+			}
+
+			if result.Extractor != tt.winner {
+				t.Errorf("expected %q to win, got %q", tt.winner, result.Extractor)
+			}
+		})
+	}
+
+	t.Run("metadata filled from other results", func(t *testing.T) {
+		registry := NewRegistry(
+			&fakeExtractor{name: "first", result: &Result{Content: words(10), SiteName: "Site", PublishedAt: published}},
+			&fakeExtractor{name: "second", result: &Result{Title: "Title", Author: "Author", Content: words(500)}},
+		)
+
+		result, err := registry.Extract(nil, nil)
+		if err != nil {
+			t.Fatalf("Extract() unexpected error = %v", err)
+		}
+
+		if result.Title != "Title" || result.Author != "Author" || result.SiteName != "Site" ||
+			!result.PublishedAt.Equal(published) {
+			t.Errorf("expected merged metadata, got %+v", result)
+		}
+	})
+}
+
+func TestDefaultRegistry(t *testing.T) {
+	doc := []byte(`<html lang="en"><head><title>Fallback Article</title></head><body>` +
+		`<nav><a href="/">Home</a></nav><main><h1>Fallback Article</h1>` +
+		strings.Repeat(words(40), 5) + `</main><footer>Copyright</footer></body></html>`)
+	pageURL, _ := url.Parse("https://example.com/post")
+
+	result, err := DefaultRegistry().Extract(doc, pageURL)
+	if err != nil {
+		t.Fatalf("Extract() unexpected error = %v", err)
+	}
+
+	if result.Extractor == "" {
+		t.Error("expected extractor name to be recorded")
+	}
+	if result.Title != "Fallback Article" {
+		t.Errorf("expected title %q, got %q", "Fallback Article", result.Title)
+	}
+	if result.WordCount < 200 {
+		t.Errorf("expected at least 200 words, got %d", result.WordCount)
+	}
+}
+
+func TestLargestBlock(t *testing.T) {
+	doc := []byte(`<html lang="de"><head><title>Title</title><script>var x = 1;</script></head><body>` +
+		`<div class="sidebar"><p>Short teaser.</p></div>` +
+		`<div class="post"><p>First long paragraph of the post body.</p><p>Second long paragraph.</p></div>` +
+		`<footer><p>A very long footer paragraph that should be ignored entirely by the extractor.</p></footer>` +
+		`</body></html>`)
+	pageURL, _ := url.Parse("https://example.com/post")
+
+	result, err := NewLargestBlock().Extract(doc, pageURL)
+	if err != nil {
+		t.Fatalf("Extract() unexpected error = %v", err)
+	}
+
+	if !strings.Contains(result.Content, "Second long paragraph") || strings.Contains(result.Content, "teaser") {
+		t.Errorf("expected post block, got %q", result.Content)
+	}
+	if result.Title != "Title" || result.Language != "de" || result.Hostname != "example.com" {
+		t.Errorf("unexpected metadata %+v", result)
+	}
+
+	_, err = NewLargestBlock().Extract([]byte(`<html><body><div></div></body></html>`), pageURL)
+	if !errors.Is(err, ErrNoContent) {
+		t.Errorf("expected ErrNoContent, got %v", err)
+	}
+}
@@ -0,0 +1,80 @@
+package extract
+
+import (
+	"bytes"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/go-shiori/dom"
+	"golang.org/x/net/html"
+)
+
+// noiseTags are removed before looking for the largest text block.
+var noiseTags = []string{"script", "style", "noscript", "nav", "header", "footer", "aside", "form", "iframe"}
+
+// textTags are the elements whose text is attributed to their parent block.
+var textTags = []string{"p", "pre", "blockquote", "li"}
+
+// LargestBlock is a last resort extractor returning the element holding the most paragraph text.
+type LargestBlock struct{}
+
+// NewLargestBlock creates a new LargestBlock extractor.
+func NewLargestBlock() *LargestBlock {
+	return &LargestBlock{}
+}
+
+// Name implements Extractor.Name.
+func (l *LargestBlock) Name() string {
+	return "largest-block"
+}
+
+// Extract implements Extractor.Extract.
+func (l *LargestBlock) Extract(doc []byte, pageURL *url.URL) (*Result, error) {
+	root, err := dom.Parse(bytes.NewReader(doc))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse document: %w", err)
+	}
+
+	result := &Result{
+		Title:    documentTitle(root),
+		Language: dom.GetAttribute(dom.DocumentElement(root), "lang"),
+	}
+	if pageURL != nil {
+		result.Hostname = pageURL.Hostname()
+	}
+
+	dom.RemoveNodes(dom.GetAllNodesWithTag(root, noiseTags...), nil)
+
+	lengths := make(map[*html.Node]int)
+	var block *html.Node
+	for _, node := range dom.GetAllNodesWithTag(root, textTags...) {
+		parent := node.Parent
+		if parent == nil {
+			continue
+		}
+		lengths[parent] += len(strings.TrimSpace(dom.TextContent(node)))
+		if block == nil || lengths[parent] > lengths[block] {
+			block = parent
+		}
+	}
+
+	if block == nil || lengths[block] == 0 {
+		return nil, ErrNoContent
+	}
+
+	result.Content = dom.InnerHTML(block)
+
+	return result, nil
+}
+
+func documentTitle(root *html.Node) string {
+	for _, selector := range []string{"title", "h1"} {
+		if node := dom.QuerySelector(root, selector); node != nil {
+			if title := strings.Join(strings.Fields(dom.TextContent(node)), " "); title != "" {
+				return title
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,57 @@
+package extract
+
+import (
+	"bytes"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/go-shiori/go-readability"
+)
+
+// Readability extracts content with go-readability, a port of Mozilla's Readability.js.
+type Readability struct{}
+
+// NewReadability creates a new Readability extractor.
+func NewReadability() *Readability {
+	return &Readability{}
+}
+
+// Name implements Extractor.Name.
+func (r *Readability) Name() string {
+	return "readability"
+}
+
+// Extract implements Extractor.Extract.
+func (r *Readability) Extract(doc []byte, pageURL *url.URL) (*Result, error) {
+	parser := readability.NewParser()
+	article, err := parser.Parse(bytes.NewReader(doc), pageURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to extract article content: %w", err)
+	}
+
+	if article.Content == "" {
+		return nil, ErrNoContent
+	}
+
+	var publishedAt time.Time
+	if article.PublishedTime != nil {
+		publishedAt = *article.PublishedTime
+	}
+
+	result := &Result{
+		Title:       article.Title,
+		Author:      article.Byline,
+		Content:     article.Content,
+		Excerpt:     article.Excerpt,
+		ImageURL:    article.Image,
+		SiteName:    article.SiteName,
+		Language:    article.Language,
+		PublishedAt: publishedAt,
+	}
+	if pageURL != nil {
+		result.Hostname = pageURL.Hostname()
+	}
+
+	return result, nil
+}
@@ -0,0 +1,50 @@
+package extract
+
+import (
+	"bytes"
+	"fmt"
+	"net/url"
+
+	"github.com/go-shiori/dom"
+	"github.com/markusmobius/go-trafilatura"
+)
+
+// Trafilatura extracts content with go-trafilatura, which also provides the richest metadata.
+type Trafilatura struct{}
+
+// NewTrafilatura creates a new Trafilatura extractor.
+func NewTrafilatura() *Trafilatura {
+	return &Trafilatura{}
+}
+
+// Name implements Extractor.Name.
+func (t *Trafilatura) Name() string {
+	return "trafilatura"
+}
+
+// Extract implements Extractor.Extract.
+func (t *Trafilatura) Extract(doc []byte, pageURL *url.URL) (*Result, error) {
+	result, err := trafilatura.Extract(bytes.NewReader(doc), trafilatura.Options{
+		OriginalURL: pageURL,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to extract article content: %w", err)
+	}
+
+	if result.ContentNode == nil {
+		return nil, ErrNoContent
+	}
+
+	return &Result{
+		Title:       result.Metadata.Title,
+		Author:      result.Metadata.Author,
+		Content:     dom.InnerHTML(result.ContentNode),
+		Excerpt:     result.Metadata.Description,
+		ImageURL:    result.Metadata.Image,
+		SiteName:    result.Metadata.Sitename,
+		Hostname:    result.Metadata.Hostname,
+		PageType:    result.Metadata.PageType,
+		Language:    result.Metadata.Language,
+		PublishedAt: result.Metadata.Date,
+	}, nil
+}
@@ -5,117 +5,191 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"io"
-	"log"
 	"net/http"
 	"net/url"
-	"strings"
 	"time"
 
-	"github.com/go-shiori/dom"
-	"github.com/markusmobius/go-trafilatura"
 	"github.com/shaftoe/savetoink/internal/consts"
+	"github.com/shaftoe/savetoink/internal/content/cache"
+	"github.com/shaftoe/savetoink/internal/content/convert"
+	"github.com/shaftoe/savetoink/internal/content/discussion"
+	"github.com/shaftoe/savetoink/internal/content/extract"
+	"github.com/shaftoe/savetoink/internal/content/rules"
+	"github.com/shaftoe/savetoink/internal/content/wordcount"
 	"github.com/shaftoe/savetoink/internal/model"
 )
 
 // Extractor handles the extraction of article content from URLs and HTML.
+// Fetched documents are handed over to a chain of extraction strategies, see extract.Registry,
+// wrapped by the site-specific rule matching the URL, if any.
 type Extractor struct {
-	client *http.Client
+	client     *http.Client
+	fetch      FetchConfig
+	policy     *HostPolicy
+	cache      cache.Cache
+	scheduler  *scheduler
+	robots     *robotsCache
+	extractors *extract.Registry
+	rules      *rules.Set
 }
 
-// NewExtractor creates a new Extractor instance.
-func NewExtractor() *Extractor {
-	return &Extractor{
-		client: &http.Client{},
+// ErrHTMLTooLarge is returned when an HTML document submitted by the client exceeds consts.ClientHTMLMaxSize.
+var ErrHTMLTooLarge = errors.New("HTML document too large")
+
+// Option configures an Extractor.
+type Option func(*Extractor)
+
+// WithRules applies site-specific extraction rules.
+func WithRules(set *rules.Set) Option {
+	return func(e *Extractor) {
+		e.rules = set
 	}
 }
 
+// NewExtractor creates a new Extractor instance using the default extraction strategies.
+func NewExtractor(opts ...Option) *Extractor {
+	e := &Extractor{
+		extractors: extract.DefaultRegistry(),
+	}
+
+	for _, opt := range opts {
+		opt(e)
+	}
+
+	e.fetch = e.fetch.withDefaults()
+	e.client = newHTTPClient(e.fetch, e.policy)
+	e.scheduler = newScheduler(e.fetch.HostConcurrency, e.fetch.HostInterval)
+	e.robots = newRobotsCache()
+
+	return e
+}
+
 // ExtractFromURL fetches and extracts article content from given URL.
+// Hacker News, Reddit and Lobsters discussions are rendered with the article they link to and their
+// top comments, see package discussion.
+// HTML documents go through the extraction strategies, following pagination links of articles
+// split across pages, PDF, plain text and Markdown documents are converted to HTML, see package convert.
+// The URL of the returned article is the one of the final response, after redirects,
+// its CanonicalURL the one declared by the page with <link rel="canonical">, if any.
 func (e *Extractor) ExtractFromURL(ctx context.Context, urlStr string) (*model.Article, error) {
-	parsedURL, body, err := e.fetchURL(ctx, urlStr)
+	if parsed, parseErr := url.Parse(urlStr); parseErr == nil {
+		if source, docURL := discussion.Match(parsed); source != nil {
+			return e.extractDiscussion(ctx, parsed, source, docURL)
+		}
+	}
+
+	return e.extractURL(ctx, urlStr)
+}
+
+// extractURL fetches and extracts article content from urlStr, see ExtractFromURL.
+func (e *Extractor) extractURL(ctx context.Context, urlStr string) (*model.Article, error) {
+	var rule *rules.Rule
+	if parsed, parseErr := url.Parse(urlStr); parseErr == nil {
+		rule = e.rules.Match(parsed)
+	}
+
+	var headers map[string]string
+	if rule != nil {
+		headers = rule.Headers
+	}
+
+	doc, err := e.fetchURL(ctx, urlStr, headers)
 	if err != nil {
 		return nil, err
 	}
-	defer func() {
-		if closeErr := body.Close(); closeErr != nil {
-			log.Printf("warning: failed to close response body: %v", closeErr)
-		}
-	}()
 
-	opts := trafilatura.Options{
-		OriginalURL: parsedURL,
+	if !convert.IsHTML(doc.mediaType) {
+		result, convertErr := convert.For(doc.mediaType)(doc.body, doc.url)
+		if convertErr != nil {
+			return nil, fmt.Errorf("failed to extract article content: %w", convertErr)
+		}
+		article := e.buildArticle(result, doc.url.String())
+		article.Charset = doc.charset
+		return article, nil
 	}
 
-	result, err := trafilatura.Extract(body, opts)
+	result, err := e.extractResult(doc.body, doc.url, rule)
 	if err != nil {
-		return nil, fmt.Errorf("failed to extract article content: %w", err)
+		return nil, err
 	}
+	e.stitchPages(ctx, result, doc, headers, rule)
 
-	if result.ContentNode == nil {
-		return nil, errors.New("no content extracted")
-	}
+	article := e.buildArticle(result, doc.url.String())
+	article.CanonicalURL = canonicalLink(doc.body, doc.url)
+	article.Charset = doc.charset
 
-	return e.buildArticle(result, urlStr), nil
+	return article, nil
 }
 
-func (e *Extractor) fetchURL(ctx context.Context, urlStr string) (*url.URL, io.ReadCloser, error) {
+// ExtractFromHTML extracts article content from an HTML document already rendered by the client,
+// e.g. a paywalled page captured by the browser extension, instead of fetching urlStr.
+// The returned article is marked as client-captured.
+func (e *Extractor) ExtractFromHTML(doc []byte, urlStr string) (*model.Article, error) {
 	if err := validateURL(urlStr); err != nil {
-		return nil, nil, fmt.Errorf("invalid URL: %w", err)
+		return nil, fmt.Errorf("invalid URL: %w", err)
 	}
 
-	parsedURL, err := url.Parse(urlStr)
-	if err != nil {
-		return nil, nil, fmt.Errorf("failed to parse URL: %w", err)
+	if len(doc) > consts.ClientHTMLMaxSize {
+		return nil, fmt.Errorf("%w: %d bytes exceeds %d", ErrHTMLTooLarge, len(doc), consts.ClientHTMLMaxSize)
 	}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, urlStr, http.NoBody)
+	pageURL, err := url.Parse(urlStr)
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to create request: %w", err)
+		return nil, fmt.Errorf("failed to parse URL: %w", err)
 	}
 
-	resp, err := e.client.Do(req)
+	doc, charset := decodeCharset(doc, "", true)
+
+	result, err := e.extractResult(doc, pageURL, e.rules.Match(pageURL))
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to fetch URL: %w", err)
+		return nil, err
 	}
 
-	if resp.StatusCode != http.StatusOK {
-		if closeErr := resp.Body.Close(); closeErr != nil {
-			log.Printf("warning: failed to close response body: %v", closeErr)
-		}
-		return nil, nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
-	}
+	article := e.buildArticle(result, pageURL.String())
+	article.CanonicalURL = canonicalLink(doc, pageURL)
+	article.ClientCaptured = true
+	article.Charset = charset
 
-	contentType := resp.Header.Get("Content-Type")
-	if !strings.HasPrefix(contentType, "text/html") {
-		if closeErr := resp.Body.Close(); closeErr != nil {
-			log.Printf("warning: failed to close response body: %v", closeErr)
-		}
-		return nil, nil, fmt.Errorf("expected HTML content, got: %s", contentType)
+	return article, nil
+}
+
+// extractResult extracts article content from an HTML document with rule, if not nil, or the extraction strategies.
+func (e *Extractor) extractResult(doc []byte, pageURL *url.URL, rule *rules.Rule) (*extract.Result, error) {
+	var (
+		result *extract.Result
+		err    error
+	)
+	if rule != nil {
+		result, err = rule.Extract(doc, pageURL, e.extractors)
+	} else {
+		result, err = e.extractors.Extract(doc, pageURL)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to extract article content: %w", err)
 	}
 
-	return parsedURL, resp.Body, nil
+	return result, nil
 }
 
-func (e *Extractor) buildArticle(result *trafilatura.ExtractResult, urlStr string) *model.Article {
-	contentHTML := dom.InnerHTML(result.ContentNode)
-	plainText := stripHTML(contentHTML)
-	wordCount := countWords(plainText)
+func (e *Extractor) buildArticle(result *extract.Result, urlStr string) *model.Article {
+	stats := wordcount.CountHTML(result.Content)
 
 	return &model.Article{
-		Title:              result.Metadata.Title,
-		Author:             result.Metadata.Author,
-		Content:            contentHTML,
-		Excerpt:            result.Metadata.Description,
-		ImageURL:           result.Metadata.Image,
-		PublishedAt:        toTimePtr(result.Metadata.Date),
+		Title:              result.Title,
+		Author:             result.Author,
+		Content:            result.Content,
+		Excerpt:            result.Excerpt,
+		ImageURL:           result.ImageURL,
+		PublishedAt:        toTimePtr(result.PublishedAt),
 		URL:                urlStr,
 		CreatedAt:          time.Now().UTC(),
-		WordCount:          wordCount,
-		ReadingTimeMinutes: (wordCount + consts.WordsPerMinute - 1) / consts.WordsPerMinute,
-		SourceDomain:       result.Metadata.Hostname,
-		SiteName:           result.Metadata.Sitename,
-		ContentType:        result.Metadata.PageType,
-		Language:           result.Metadata.Language,
+		WordCount:          stats.Total(),
+		ReadingTimeMinutes: wordcount.ReadingTime(stats, result.Language),
+		SourceDomain:       result.Hostname,
+		SiteName:           result.SiteName,
+		ContentType:        result.PageType,
+		Language:           result.Language,
+		Extractor:          result.Extractor,
 	}
 }
 
@@ -126,42 +200,6 @@ func toTimePtr(t time.Time) *time.Time {
 	return &t
 }
 
-func stripHTML(html string) string {
-	re := strings.NewReplacer(
-		"<p>", " ",
-		"</p>", " ",
-		"<div>", " ",
-		"</div>", " ",
-		"<br>", " ",
-		"<br/>", " ",
-		"<br />", " ",
-	)
-
-	result := re.Replace(html)
-
-	var stripped strings.Builder
-	inTag := false
-	for _, r := range result {
-		switch r {
-		case '<':
-			inTag = true
-		case '>':
-			inTag = false
-		default:
-			if !inTag {
-				stripped.WriteRune(r)
-			}
-		}
-	}
-
-	return stripped.String()
-}
-
-func countWords(text string) int {
-	fields := strings.Fields(text)
-	return len(fields)
-}
-
 func validateURL(urlStr string) error {
 	if urlStr == "" {
 		return errors.New("url cannot be empty")
@@ -2,10 +2,15 @@ package content
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
+
+	"github.com/shaftoe/savetoink/internal/consts"
+	"github.com/shaftoe/savetoink/internal/content/rules"
 )
 
 func TestNewExtractor(t *testing.T) {
@@ -267,3 +272,76 @@ func TestArticleFields(t *testing.T) {
 		t.Error("Expected ID to be set")
 	}
 }
+
+func TestExtractFromURLWithRules(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Test") != "rule" {
+			t.Errorf("expected rule header, got %q", r.Header.Get("X-Test"))
+		}
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write([]byte(`<html><head><title>Page</title></head><body><div class="paywall">Pay up</div>` +
+			`<article><h1>Story</h1><p>Free content of the story.</p></article></body></html>`))
+	}))
+	defer server.Close()
+
+	set, err := rules.Parse([]byte(`rules:
+  - name: local
+    hosts: ["127.0.0.1"]
+    content_root: article
+    headers:
+      X-Test: rule
+`))
+	if err != nil {
+		t.Fatalf("Parse() unexpected error = %v", err)
+	}
+
+	article, err := NewExtractor(WithRules(set)).ExtractFromURL(context.Background(), server.URL)
+	if err != nil {
+		t.Fatalf("ExtractFromURL() unexpected error = %v", err)
+	}
+
+	if article.Extractor != "rule:local" {
+		t.Errorf("expected extractor %q, got %q", "rule:local", article.Extractor)
+	}
+	if strings.Contains(article.Content, "Pay up") || !strings.Contains(article.Content, "Free content") {
+		t.Errorf("unexpected content %q", article.Content)
+	}
+}
+
+func TestExtractFromHTML(t *testing.T) {
+	doc := []byte(`<html><head><title>Paywalled</title></head><body><div class="paywall">Subscribe</div>` +
+		`<article><h1>Paywalled</h1><p>Content only visible to logged-in readers of the site.</p></article></body></html>`)
+
+	set, err := rules.Parse([]byte(`rules:
+  - name: paywalled
+    hosts: ["news.example.com"]
+    content_root: article
+`))
+	if err != nil {
+		t.Fatalf("Parse() unexpected error = %v", err)
+	}
+
+	article, err := NewExtractor(WithRules(set)).ExtractFromHTML(doc, "https://news.example.com/story")
+	if err != nil {
+		t.Fatalf("ExtractFromHTML() unexpected error = %v", err)
+	}
+
+	if !article.ClientCaptured {
+		t.Error("expected article to be marked as client-captured")
+	}
+	if article.URL != "https://news.example.com/story" {
+		t.Errorf("expected URL %s, got %s", "https://news.example.com/story", article.URL)
+	}
+	if article.Extractor != "rule:paywalled" || !strings.Contains(article.Content, "logged-in readers") {
+		t.Errorf("unexpected article %+v", article)
+	}
+
+	if _, err = NewExtractor().ExtractFromHTML(doc, "ftp://example.com/"); err == nil {
+		t.Error("expected error for invalid URL")
+	}
+
+	large := []byte(strings.Repeat("a", consts.ClientHTMLMaxSize+1))
+	if _, err = NewExtractor().ExtractFromHTML(large, "https://example.com/"); !errors.Is(err, ErrHTMLTooLarge) {
+		t.Errorf("expected ErrHTMLTooLarge, got %v", err)
+	}
+}
@@ -0,0 +1,64 @@
+package content
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// FeedResponse is the response to a conditional request for a feed.
+type FeedResponse struct {
+	// URL is the URL of the final response, after redirects.
+	URL          *url.URL
+	Body         []byte
+	ETag         string
+	LastModified string
+	// NotModified is true when the feed did not change since the response with the validators sent.
+	NotModified bool
+}
+
+// FetchFeed fetches the feed at urlStr, a conditional request when etag or lastModified, the validators
+// of the previous response, are set. Feeds are not cached, their validators are part of the feed state.
+func (e *Extractor) FetchFeed(ctx context.Context, urlStr, etag, lastModified string) (*FeedResponse, error) {
+	parsedURL, err := e.checkURL(urlStr)
+	if err != nil {
+		return nil, err
+	}
+
+	header := make(http.Header)
+	header.Set("Accept", "application/rss+xml, application/atom+xml, application/feed+json, application/xml;q=0.9")
+	if etag != "" {
+		header.Set("If-None-Match", etag)
+	}
+	if lastModified != "" {
+		header.Set("If-Modified-Since", lastModified)
+	}
+
+	resp, done, err := e.send(ctx, parsedURL, header)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+
+	feed := &FeedResponse{
+		URL:          resp.Request.URL,
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+	}
+
+	switch resp.StatusCode {
+	case http.StatusNotModified:
+		feed.NotModified = true
+		return feed, nil
+	case http.StatusOK:
+	default:
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	if feed.Body, err = e.readBody(resp); err != nil {
+		return nil, err
+	}
+
+	return feed, nil
+}
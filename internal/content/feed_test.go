@@ -0,0 +1,56 @@
+package content
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFetchFeed(t *testing.T) {
+	const body = `<rss version="2.0"><channel><title>Feed</title></channel></rss>`
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/feed":
+			assert.Contains(t, r.Header.Get("Accept"), "application/rss+xml")
+			if r.Header.Get("If-None-Match") == `"v1"` {
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+			w.Header().Set("ETag", `"v1"`)
+			w.Header().Set("Last-Modified", "Mon, 01 Jan 2024 10:00:00 GMT")
+			_, _ = w.Write([]byte(body))
+		case "/moved":
+			http.Redirect(w, r, "/feed", http.StatusMovedPermanently)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	e := NewExtractor(WithFetchConfig(FetchConfig{HostInterval: -1}))
+	ctx := context.Background()
+
+	resp, err := e.FetchFeed(ctx, server.URL+"/moved", "", "")
+	require.NoError(t, err)
+	assert.False(t, resp.NotModified)
+	assert.Equal(t, body, string(resp.Body))
+	assert.Equal(t, `"v1"`, resp.ETag)
+	assert.Equal(t, "Mon, 01 Jan 2024 10:00:00 GMT", resp.LastModified)
+	assert.Equal(t, server.URL+"/feed", resp.URL.String())
+
+	resp, err = e.FetchFeed(ctx, server.URL+"/feed", `"v1"`, "")
+	require.NoError(t, err)
+	assert.True(t, resp.NotModified)
+	assert.Empty(t, resp.Body)
+
+	_, err = e.FetchFeed(ctx, server.URL+"/missing", "", "")
+	if err == nil || !strings.Contains(err.Error(), "404") {
+		t.Errorf("expected a status code error, got %v", err)
+	}
+}
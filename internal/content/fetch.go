@@ -0,0 +1,339 @@
+package content
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/shaftoe/savetoink/internal/consts"
+	"github.com/shaftoe/savetoink/internal/content/convert"
+)
+
+var (
+	// ErrResponseTooLarge is returned when a fetched response body exceeds the configured maximum size.
+	ErrResponseTooLarge = errors.New("response too large")
+	// ErrUnsupportedContentType is returned when a fetched document is neither HTML nor supported by convert.
+	ErrUnsupportedContentType = errors.New("unsupported content type")
+)
+
+// FetchConfig configures how an Extractor fetches URLs. Zero values fall back to the defaults in consts.
+type FetchConfig struct {
+	// UserAgent is sent with every request.
+	UserAgent string
+	// Timeout limits each request, including redirects and reading the response body.
+	Timeout time.Duration
+	// MaxBodySize is the maximum size in bytes of a response body.
+	MaxBodySize int64
+	// MaxRedirects is the maximum number of redirects followed.
+	MaxRedirects int
+	// Headers are sent with every request, site-specific rule headers take precedence.
+	Headers map[string]string
+	// HostConcurrency is the maximum number of concurrent requests to the same host.
+	HostConcurrency int
+	// HostInterval is the minimum interval between the start of requests to the same host, negative to disable.
+	HostInterval time.Duration
+	// RobotsTxt enables compliance with the robots.txt rules of sites for UserAgent.
+	RobotsTxt bool
+	// Proxy is the URL of the proxy requests are sent through. When nil the proxy is read
+	// from the environment (HTTP_PROXY, HTTPS_PROXY, NO_PROXY) unless SSRF protection is enabled,
+	// with SSRF protection the hosts of requests are resolved and checked before using the proxy.
+	Proxy *url.URL
+}
+
+// WithFetchConfig configures user agent, timeout, size cap, redirects, headers and proxy used to fetch URLs.
+func WithFetchConfig(cfg FetchConfig) Option {
+	return func(e *Extractor) {
+		e.fetch = cfg
+	}
+}
+
+// ParseProxyURL parses and validates a proxy URL, supported schemes are http, https and socks5.
+func ParseProxyURL(rawURL string) (*url.URL, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse proxy URL: %w", err)
+	}
+
+	switch u.Scheme {
+	case "http", "https", "socks5":
+	default:
+		return nil, fmt.Errorf("unsupported proxy scheme %q, must be http, https or socks5", u.Scheme)
+	}
+
+	if u.Host == "" {
+		return nil, errors.New("proxy URL must have a host")
+	}
+
+	return u, nil
+}
+
+func (c FetchConfig) withDefaults() FetchConfig {
+	if c.UserAgent == "" {
+		c.UserAgent = consts.FetchUserAgent
+	}
+	if c.Timeout <= 0 {
+		c.Timeout = consts.FetchTimeout
+	}
+	if c.MaxBodySize <= 0 {
+		c.MaxBodySize = consts.FetchMaxBodySize
+	}
+	if c.MaxRedirects <= 0 {
+		c.MaxRedirects = consts.FetchMaxRedirects
+	}
+	if c.HostConcurrency <= 0 {
+		c.HostConcurrency = consts.FetchHostConcurrency
+	}
+	if c.HostInterval == 0 {
+		c.HostInterval = consts.FetchHostInterval
+	}
+	return c
+}
+
+// newHTTPClient creates the client used to fetch URLs. When policy is not nil hosts are checked against it
+// and connections to internal IP addresses are refused, see WithSSRFProtection.
+func newHTTPClient(cfg FetchConfig, policy *HostPolicy) *http.Client {
+	dialer := &net.Dialer{
+		Timeout:   consts.FetchDialTimeout,
+		KeepAlive: consts.FetchDialTimeout,
+	}
+
+	transport := &http.Transport{
+		Proxy:                 http.ProxyFromEnvironment,
+		DialContext:           dialer.DialContext,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          consts.FetchMaxIdleConns,
+		IdleConnTimeout:       consts.FetchIdleConnTimeout,
+		TLSHandshakeTimeout:   consts.FetchDialTimeout,
+		ExpectContinueTimeout: time.Second,
+	}
+
+	if policy != nil {
+		// the dialer must see the address of the target host, environment proxies are ignored
+		transport.Proxy = nil
+		transport.DialContext = guardedDialContext(dialer, cfg.Proxy)
+	}
+
+	if cfg.Proxy != nil {
+		transport.Proxy = http.ProxyURL(cfg.Proxy)
+		if policy != nil {
+			// the proxy resolves host names, they are checked before sending requests through it
+			transport.Proxy = guardedProxy(cfg.Proxy, net.DefaultResolver.LookupNetIP)
+		}
+	}
+
+	return &http.Client{
+		Transport: transport,
+		Timeout:   cfg.Timeout,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= cfg.MaxRedirects {
+				return fmt.Errorf("stopped after %d redirects", cfg.MaxRedirects)
+			}
+			if policy == nil {
+				return nil
+			}
+			if err := validateURL(req.URL.String()); err != nil {
+				return fmt.Errorf("%w: invalid redirect: %w", ErrForbiddenHost, err)
+			}
+			return policy.check(req.URL.Hostname())
+		},
+	}
+}
+
+// document is a fetched response.
+type document struct {
+	// url is the URL of the final response, after redirects.
+	url *url.URL
+	// mediaType is the media type of the response, see convert.MediaType.
+	mediaType string
+	// charset is the detected charset of text documents, their body is transcoded to UTF-8.
+	charset string
+	body    []byte
+}
+
+// newDocument creates the document of a response, transcoding text documents to UTF-8.
+func newDocument(finalURL *url.URL, contentType string, body []byte) *document {
+	doc := &document{url: finalURL, mediaType: convert.MediaType(contentType, finalURL), body: body}
+	if doc.mediaType != convert.MediaTypePDF {
+		doc.body, doc.charset = decodeCharset(body, contentType, convert.IsHTML(doc.mediaType))
+	}
+	return doc
+}
+
+// fetchURL fetches urlStr and returns the final response after redirects. With a cache, fresh
+// entries are returned without a request and stale ones are revalidated with a conditional GET.
+// Requests are scheduled per host, see FetchConfig, and deferred while the host is backing off.
+func (e *Extractor) fetchURL(ctx context.Context, urlStr string, headers map[string]string) (*document, error) {
+	parsedURL, err := e.checkURL(urlStr)
+	if err != nil {
+		return nil, err
+	}
+
+	cached := e.cachedEntry(ctx, urlStr)
+	if cached != nil && cached.Fresh(time.Now()) {
+		return entryDocument(cached)
+	}
+
+	header := make(http.Header)
+	for key, value := range headers {
+		header.Set(key, value)
+	}
+	if cached != nil {
+		cached.SetConditionalHeaders(header)
+	}
+
+	resp, done, err := e.send(ctx, parsedURL, header)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+
+	if cached != nil && resp.StatusCode == http.StatusNotModified {
+		return e.revalidated(urlStr, cached, resp.Header)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	contentType := resp.Header.Get("Content-Type")
+	mediaType := convert.MediaType(contentType, resp.Request.URL)
+	if !convert.IsHTML(mediaType) && convert.For(mediaType) == nil {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedContentType, contentType)
+	}
+
+	body, err := e.readBody(resp)
+	if err != nil {
+		return nil, err
+	}
+
+	e.storeEntry(urlStr, resp.Header, resp.Request.URL, body)
+
+	return newDocument(resp.Request.URL, contentType, body), nil
+}
+
+// FetchImage fetches the image at urlStr to embed it in an EPUB, returning its content and media type.
+// Images are fetched like documents, with the host checks of the SSRF policy, if any, the schedule of the
+// host and the configured maximum size, but are never cached.
+func (e *Extractor) FetchImage(ctx context.Context, urlStr string) ([]byte, string, error) {
+	parsedURL, err := e.checkURL(urlStr)
+	if err != nil {
+		return nil, "", err
+	}
+
+	resp, done, err := e.send(ctx, parsedURL, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	defer done()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	contentType := resp.Header.Get("Content-Type")
+	if mediaType, _, _ := mime.ParseMediaType(contentType); !strings.HasPrefix(mediaType, "image/") {
+		return nil, "", fmt.Errorf("%w: %s", ErrUnsupportedContentType, contentType)
+	}
+
+	body, err := e.readBody(resp)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return body, contentType, nil
+}
+
+// checkURL validates and parses urlStr, checking its host against the SSRF policy, if any.
+func (e *Extractor) checkURL(urlStr string) (*url.URL, error) {
+	if err := validateURL(urlStr); err != nil {
+		return nil, fmt.Errorf("invalid URL: %w", err)
+	}
+
+	parsedURL, err := url.Parse(urlStr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse URL: %w", err)
+	}
+
+	if e.policy != nil {
+		if err = e.policy.check(parsedURL.Hostname()); err != nil {
+			return nil, err
+		}
+	}
+
+	return parsedURL, nil
+}
+
+// send sends a GET request for u with our User-Agent, the configured headers and header, once allowed by
+// robots.txt, if enabled, and by the schedule of the host. 429 and 503 responses are returned as a
+// DeferredError. The returned function closes the response body and releases the host slot.
+func (e *Extractor) send(ctx context.Context, u *url.URL, header http.Header) (*http.Response, func(), error) {
+	if e.fetch.RobotsTxt {
+		if err := e.checkRobots(ctx, u); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	release, err := e.scheduler.acquire(ctx, u.Host)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), http.NoBody)
+	if err != nil {
+		release()
+		return nil, nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("User-Agent", e.fetch.UserAgent)
+	for key, value := range e.fetch.Headers {
+		req.Header.Set(key, value)
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		release()
+		return nil, nil, fmt.Errorf("failed to fetch URL: %w", err)
+	}
+
+	done := func() {
+		if closeErr := resp.Body.Close(); closeErr != nil {
+			log.Printf("warning: failed to close response body: %v", closeErr)
+		}
+		release()
+	}
+
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable {
+		done()
+		return nil, nil, e.scheduler.backoff(resp.Request.URL.Host, resp.Header)
+	}
+
+	return resp, done, nil
+}
+
+// readBody reads the body of resp, up to the configured maximum size.
+func (e *Extractor) readBody(resp *http.Response) ([]byte, error) {
+	if resp.ContentLength > e.fetch.MaxBodySize {
+		return nil, fmt.Errorf("%w: %d bytes exceeds %d", ErrResponseTooLarge, resp.ContentLength, e.fetch.MaxBodySize)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, e.fetch.MaxBodySize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+	if int64(len(body)) > e.fetch.MaxBodySize {
+		return nil, fmt.Errorf("%w: exceeds %d bytes", ErrResponseTooLarge, e.fetch.MaxBodySize)
+	}
+
+	return body, nil
+}
@@ -0,0 +1,200 @@
+package content
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/shaftoe/savetoink/internal/consts"
+)
+
+const fetchTestPage = `<html><head><title>Fetched</title></head><body><article>` +
+	`<p>This is the content of the fetched test article, long enough to be extracted.</p></article></body></html>`
+
+func TestExtractFromURLFetchConfig(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("User-Agent") != "test-agent" {
+			t.Errorf("expected User-Agent %q, got %q", "test-agent", r.Header.Get("User-Agent"))
+		}
+		if r.Header.Get("Accept-Language") != "it" {
+			t.Errorf("expected Accept-Language %q, got %q", "it", r.Header.Get("Accept-Language"))
+		}
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write([]byte(fetchTestPage))
+	}))
+	defer server.Close()
+
+	extractor := NewExtractor(WithFetchConfig(FetchConfig{
+		UserAgent: "test-agent",
+		Headers:   map[string]string{"Accept-Language": "it"},
+	}))
+	if _, err := extractor.ExtractFromURL(context.Background(), server.URL); err != nil {
+		t.Fatalf("ExtractFromURL() unexpected error = %v", err)
+	}
+}
+
+func TestExtractFromURLDefaultUserAgent(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("User-Agent") != consts.FetchUserAgent {
+			t.Errorf("expected default User-Agent, got %q", r.Header.Get("User-Agent"))
+		}
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write([]byte(fetchTestPage))
+	}))
+	defer server.Close()
+
+	if _, err := NewExtractor().ExtractFromURL(context.Background(), server.URL); err != nil {
+		t.Fatalf("ExtractFromURL() unexpected error = %v", err)
+	}
+}
+
+func TestExtractFromURLFinalURL(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/short", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/articles/fetched", http.StatusMovedPermanently)
+	})
+	mux.HandleFunc("/articles/fetched", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write([]byte(fetchTestPage))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	article, err := NewExtractor().ExtractFromURL(context.Background(), server.URL+"/short")
+	if err != nil {
+		t.Fatalf("ExtractFromURL() unexpected error = %v", err)
+	}
+
+	if article.URL != server.URL+"/articles/fetched" {
+		t.Errorf("expected final URL %s, got %s", server.URL+"/articles/fetched", article.URL)
+	}
+}
+
+func TestExtractFromURLMaxRedirects(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, r.URL.Path+"x", http.StatusFound)
+	}))
+	defer server.Close()
+
+	_, err := NewExtractor(WithFetchConfig(FetchConfig{MaxRedirects: 2})).
+		ExtractFromURL(context.Background(), server.URL+"/")
+	if err == nil || !strings.Contains(err.Error(), "stopped after 2 redirects") {
+		t.Errorf("expected redirect limit error, got %v", err)
+	}
+}
+
+func TestExtractFromURLMaxBodySize(t *testing.T) {
+	tests := []struct {
+		name    string
+		chunked bool
+	}{
+		{"content length", false},
+		{"chunked", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.Header().Set("Content-Type", "text/html")
+				if tt.chunked {
+					w.(http.Flusher).Flush()
+				}
+				_, _ = w.Write([]byte(fetchTestPage))
+			}))
+			defer server.Close()
+
+			_, err := NewExtractor(WithFetchConfig(FetchConfig{MaxBodySize: 64})).
+				ExtractFromURL(context.Background(), server.URL)
+			if !errors.Is(err, ErrResponseTooLarge) {
+				t.Errorf("expected ErrResponseTooLarge, got %v", err)
+			}
+		})
+	}
+}
+
+func TestParseProxyURL(t *testing.T) {
+	tests := []struct {
+		raw     string
+		wantErr bool
+	}{
+		{"http://proxy.example.com:3128", false},
+		{"https://proxy.example.com", false},
+		{"socks5://127.0.0.1:1080", false},
+		{"ftp://proxy.example.com", true},
+		{"proxy.example.com:3128", true},
+		{"http://", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			_, err := ParseProxyURL(tt.raw)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseProxyURL(%q) error = %v, wantErr %v", tt.raw, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestExtractFromURLDocuments(t *testing.T) {
+	tests := []struct {
+		name          string
+		path          string
+		contentType   string
+		body          string
+		wantErr       error
+		wantExtractor string
+		wantTitle     string
+	}{
+		{
+			name:          "plain text",
+			path:          "/rfc.txt",
+			contentType:   "text/plain; charset=utf-8",
+			body:          "Plain Title\n\nSome plain text content.",
+			wantExtractor: "text",
+			wantTitle:     "Plain Title",
+		},
+		{
+			name:          "markdown served as text",
+			path:          "/README.md",
+			contentType:   "text/plain",
+			body:          "# Readme\n\nSome *Markdown* content.",
+			wantExtractor: "markdown",
+			wantTitle:     "Readme",
+		},
+		{
+			name:        "unsupported",
+			path:        "/image.png",
+			contentType: "image/png",
+			body:        "\x89PNG",
+			wantErr:     ErrUnsupportedContentType,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.Header().Set("Content-Type", tt.contentType)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			article, err := NewExtractor().ExtractFromURL(context.Background(), server.URL+tt.path)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("expected error %v, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ExtractFromURL() unexpected error = %v", err)
+			}
+
+			if article.Extractor != tt.wantExtractor || article.Title != tt.wantTitle || article.WordCount == 0 {
+				t.Errorf("unexpected article %+v", article)
+			}
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package content
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/shaftoe/savetoink/internal/content/cache"
+)
+
+// noCacheKey is the context key disabling the fetch cache for a request.
+type noCacheKey struct{}
+
+// WithCache caches fetched documents in c, revalidating stale entries with conditional requests.
+func WithCache(c cache.Cache) Option {
+	return func(e *Extractor) {
+		e.cache = c
+	}
+}
+
+// WithoutCache returns a context in which documents are always fetched from the origin server,
+// responses still refresh the cache.
+func WithoutCache(ctx context.Context) context.Context {
+	return context.WithValue(ctx, noCacheKey{}, true)
+}
+
+func cacheDisabled(ctx context.Context) bool {
+	disabled, _ := ctx.Value(noCacheKey{}).(bool)
+	return disabled
+}
+
+// cachedEntry returns the cache entry for urlStr unless the cache is disabled for the request.
+func (e *Extractor) cachedEntry(ctx context.Context, urlStr string) *cache.Entry {
+	if e.cache == nil || cacheDisabled(ctx) {
+		return nil
+	}
+	entry, ok := e.cache.Get(urlStr)
+	if !ok {
+		return nil
+	}
+	return entry
+}
+
+// storeEntry caches the response with header and body fetched for urlStr, if allowed by its Cache-Control.
+func (e *Extractor) storeEntry(urlStr string, header http.Header, finalURL *url.URL, body []byte) {
+	if e.cache == nil {
+		return
+	}
+	if entry, ok := cache.NewEntry(header, finalURL.String(), body, time.Now()); ok {
+		e.cache.Set(urlStr, entry)
+	}
+}
+
+// revalidated refreshes the cache entry of urlStr from the header of a 304 Not Modified response.
+func (e *Extractor) revalidated(urlStr string, entry *cache.Entry, header http.Header) (*document, error) {
+	entry.Revalidated(header, time.Now())
+	e.cache.Set(urlStr, entry)
+	return entryDocument(entry)
+}
+
+// entryDocument returns the document stored in a cache entry.
+func entryDocument(entry *cache.Entry) (*document, error) {
+	finalURL, err := url.Parse(entry.URL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cached URL: %w", err)
+	}
+	return newDocument(finalURL, entry.ContentType, entry.Body), nil
+}
@@ -0,0 +1,121 @@
+package content
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/shaftoe/savetoink/internal/content/cache"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExtractFromURLCache(t *testing.T) {
+	tests := []struct {
+		name              string
+		cacheControl      string
+		etag              string
+		ctx               context.Context
+		wantRequests      int32
+		wantRevalidations int32
+	}{
+		{
+			name:         "fresh entry is served without requests",
+			cacheControl: "max-age=600",
+			ctx:          context.Background(),
+			wantRequests: 1,
+		},
+		{
+			name:              "stale entry is revalidated",
+			cacheControl:      "no-cache",
+			etag:              `"v1"`,
+			ctx:               context.Background(),
+			wantRequests:      2,
+			wantRevalidations: 1,
+		},
+		{
+			name:         "stale entry without validators is fetched again",
+			cacheControl: "max-age=0",
+			ctx:          context.Background(),
+			wantRequests: 2,
+		},
+		{
+			name:         "private is never cached",
+			cacheControl: "private, max-age=600",
+			etag:         `"v1"`,
+			ctx:          context.Background(),
+			wantRequests: 2,
+		},
+		{
+			name:         "no-store is never cached",
+			cacheControl: "no-store",
+			etag:         `"v1"`,
+			ctx:          context.Background(),
+			wantRequests: 2,
+		},
+		{
+			name:         "cache disabled for the request",
+			cacheControl: "max-age=600",
+			etag:         `"v1"`,
+			ctx:          WithoutCache(context.Background()),
+			wantRequests: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests, revalidations atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests.Add(1)
+				if tt.etag != "" {
+					w.Header().Set("ETag", tt.etag)
+				}
+				w.Header().Set("Cache-Control", tt.cacheControl)
+				if tt.etag != "" && r.Header.Get("If-None-Match") == tt.etag {
+					revalidations.Add(1)
+					w.WriteHeader(http.StatusNotModified)
+					return
+				}
+				w.Header().Set("Content-Type", "text/html")
+				_, _ = w.Write([]byte(fetchTestPage))
+			}))
+			defer server.Close()
+
+			extractor := NewExtractor(WithCache(cache.NewMemory(1 << 20)))
+
+			first, err := extractor.ExtractFromURL(context.Background(), server.URL)
+			assert.NoError(t, err)
+			second, err := extractor.ExtractFromURL(tt.ctx, server.URL)
+			assert.NoError(t, err)
+
+			assert.Equal(t, first.Content, second.Content)
+			assert.Equal(t, tt.wantRequests, requests.Load())
+			assert.Equal(t, tt.wantRevalidations, revalidations.Load())
+		})
+	}
+}
+
+func TestExtractFromURLCacheFinalURL(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/short", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/articles/fetched", http.StatusMovedPermanently)
+	})
+	mux.HandleFunc("/articles/fetched", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Header().Set("Cache-Control", "max-age=600")
+		_, _ = w.Write([]byte(fetchTestPage))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	extractor := NewExtractor(WithCache(cache.NewMemory(1 << 20)))
+	_, err := extractor.ExtractFromURL(context.Background(), server.URL+"/short")
+	assert.NoError(t, err)
+
+	server.Close()
+
+	article, err := extractor.ExtractFromURL(context.Background(), server.URL+"/short")
+	assert.NoError(t, err)
+	assert.Equal(t, server.URL+"/articles/fetched", article.URL)
+}
@@ -9,9 +9,23 @@ import (
 	"github.com/google/uuid"
 )
 
-// CleanURL strips query parameters and fragments from a URL to ensure
-// the same base URL always produces a consistent result.
-// Returns the cleaned URL with scheme, host, and path only.
+// trackingParams are query parameters added by analytics, ads and share buttons,
+// which do not change the content of the page.
+var trackingParams = map[string]bool{
+	"fbclid": true, "gclid": true, "dclid": true, "gbraid": true, "wbraid": true, "msclkid": true,
+	"yclid": true, "twclid": true, "igshid": true, "mc_cid": true, "mc_eid": true, "_ga": true, "_gl": true,
+	"_hsenc": true, "_hsmi": true, "mkt_tok": true, "vero_id": true, "oly_anon_id": true, "oly_enc_id": true,
+	"ref": true, "ref_src": true, "ref_url": true, "referrer": true, "source": true, "share": true,
+	"si": true, "cmpid": true, "icid": true, "spm": true, "trk": true, "sr_share": true,
+}
+
+// trackingPrefixes are prefixes of tracking query parameter families, e.g. utm_source and utm_medium.
+var trackingPrefixes = []string{"utm_", "pk_", "mtm_", "hsa_", "__twitter"}
+
+// CleanURL canonicalizes a URL to ensure the same page always produces a consistent result:
+// scheme and host are lowercased, default ports, fragments, trailing slashes and tracking
+// query parameters (utm_*, fbclid, gclid, ref...) are removed, and the remaining query
+// parameters, which may identify the page as in ?id=123, are sorted.
 func CleanURL(rawURL string) (string, error) {
 	parsedURL, err := url.Parse(rawURL)
 	if err != nil {
@@ -22,17 +36,54 @@ func CleanURL(rawURL string) (string, error) {
 		return "", errors.New("url must have scheme and host")
 	}
 
-	path := strings.TrimSuffix(parsedURL.Path, "/")
+	scheme := strings.ToLower(parsedURL.Scheme)
+	host := strings.ToLower(parsedURL.Hostname())
+	if port := parsedURL.Port(); port != "" && !isDefaultPort(scheme, port) {
+		host += ":" + port
+	} else if strings.Contains(host, ":") {
+		host = "[" + host + "]"
+	}
+
+	path := strings.TrimSuffix(parsedURL.EscapedPath(), "/")
 	if path == "" {
 		path = "/"
 	}
 
-	return fmt.Sprintf("%s://%s%s", parsedURL.Scheme, parsedURL.Host, path), nil
+	query := parsedURL.Query()
+	for param := range query {
+		if isTrackingParam(param) {
+			query.Del(param)
+		}
+	}
+
+	cleanURL := scheme + "://" + host + path
+	if encoded := query.Encode(); encoded != "" {
+		cleanURL += "?" + encoded
+	}
+
+	return cleanURL, nil
+}
+
+func isDefaultPort(scheme, port string) bool {
+	return (scheme == "http" && port == "80") || (scheme == "https" && port == "443")
+}
+
+func isTrackingParam(param string) bool {
+	param = strings.ToLower(param)
+	if trackingParams[param] {
+		return true
+	}
+	for _, prefix := range trackingPrefixes {
+		if strings.HasPrefix(param, prefix) {
+			return true
+		}
+	}
+	return false
 }
 
 // ArticleIDFromURL generates a deterministic UUID v5 for an article from its URL.
-// Strips query parameters and fragments before hashing to ensure
-// the same base URL always produces the same ID.
+// The URL is canonicalized with CleanURL before hashing to ensure
+// the same page always produces the same ID.
 //
 // Uses UUID v5 with the URL namespace as defined in RFC 4122.
 func ArticleIDFromURL(rawURL string) (string, error) {
@@ -41,7 +92,28 @@ func ArticleIDFromURL(rawURL string) (string, error) {
 		return "", err
 	}
 
-	id := uuid.NewSHA1(uuid.NameSpaceURL, []byte(cleanURL))
+	return uuid.NewSHA1(uuid.NameSpaceURL, []byte(cleanURL)).String(), nil
+}
+
+// LegacyArticleIDFromURL generates the ID articles were stored with before query parameters
+// were preserved by CleanURL: all query parameters are stripped and the host is not lowercased.
+// It is used to find and replace articles saved with a legacy ID when they are saved again.
+func LegacyArticleIDFromURL(rawURL string) (string, error) {
+	parsedURL, err := url.Parse(rawURL)
+	if err != nil {
+		return "", fmt.Errorf("url must be valid: %w", err)
+	}
+
+	if parsedURL.Scheme == "" || parsedURL.Host == "" {
+		return "", errors.New("url must have scheme and host")
+	}
+
+	path := strings.TrimSuffix(parsedURL.Path, "/")
+	if path == "" {
+		path = "/"
+	}
+
+	legacyURL := fmt.Sprintf("%s://%s%s", parsedURL.Scheme, parsedURL.Host, path)
 
-	return id.String(), nil
+	return uuid.NewSHA1(uuid.NameSpaceURL, []byte(legacyURL)).String(), nil
 }
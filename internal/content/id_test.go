@@ -137,11 +137,53 @@ func TestCleanURL(t *testing.T) {
 		errContains string
 	}{
 		{
-			name:        "strips query parameters",
-			inputURL:    "https://example.com/article/123?source=twitter&utm=test",
+			name:        "strips tracking query parameters",
+			inputURL:    "https://example.com/article/123?source=twitter&utm_medium=social&fbclid=abc",
 			expectedURL: "https://example.com/article/123",
 			wantErr:     false,
 		},
+		{
+			name:        "keeps significant query parameters",
+			inputURL:    "https://news.ycombinator.com/item?id=1&utm_source=hn",
+			expectedURL: "https://news.ycombinator.com/item?id=1",
+			wantErr:     false,
+		},
+		{
+			name:        "tracking parameters are case insensitive",
+			inputURL:    "https://example.com/?p=123&UTM_Source=x&GCLID=y",
+			expectedURL: "https://example.com/?p=123",
+			wantErr:     false,
+		},
+		{
+			name:        "lowercases scheme and host",
+			inputURL:    "HTTPS://Example.COM/Article",
+			expectedURL: "https://example.com/Article",
+			wantErr:     false,
+		},
+		{
+			name:        "drops default port",
+			inputURL:    "https://example.com:443/article",
+			expectedURL: "https://example.com/article",
+			wantErr:     false,
+		},
+		{
+			name:        "keeps other ports",
+			inputURL:    "http://example.com:8080/article",
+			expectedURL: "http://example.com:8080/article",
+			wantErr:     false,
+		},
+		{
+			name:        "IPv6 host",
+			inputURL:    "http://[::1]:80/article",
+			expectedURL: "http://[::1]/article",
+			wantErr:     false,
+		},
+		{
+			name:        "keeps escaped path",
+			inputURL:    "https://en.wikipedia.org/wiki/Go_(programming_language)%3F",
+			expectedURL: "https://en.wikipedia.org/wiki/Go_(programming_language)%3F",
+			wantErr:     false,
+		},
 		{
 			name:        "strips fragment",
 			inputURL:    "https://example.com/article/123#section-1",
@@ -185,9 +227,9 @@ func TestCleanURL(t *testing.T) {
 			wantErr:     false,
 		},
 		{
-			name:        "complex path with query",
-			inputURL:    "https://example.com/blog/2023/12/post?id=456&category=tech",
-			expectedURL: "https://example.com/blog/2023/12/post",
+			name:        "complex path with query sorted",
+			inputURL:    "https://example.com/blog/2023/12/post?id=456&category=tech&ref=news",
+			expectedURL: "https://example.com/blog/2023/12/post?category=tech&id=456",
 			wantErr:     false,
 		},
 		{
@@ -248,3 +290,29 @@ func TestCleanURL_Deterministic(t *testing.T) {
 		assert.Equal(t, expectedCleanURL, cleanURL)
 	}
 }
+
+func TestArticleIDFromURL_QueryParameters(t *testing.T) {
+	id1, err1 := ArticleIDFromURL("https://news.ycombinator.com/item?id=1")
+	id2, err2 := ArticleIDFromURL("https://news.ycombinator.com/item?id=2")
+	id3, err3 := ArticleIDFromURL("https://News.ycombinator.com:443/item?utm_source=x&id=1")
+
+	assert.NoError(t, err1)
+	assert.NoError(t, err2)
+	assert.NoError(t, err3)
+	assert.NotEqual(t, id1, id2, "significant query parameters should produce different IDs")
+	assert.Equal(t, id1, id3, "tracking parameters, host case and default port should not change the ID")
+}
+
+func TestLegacyArticleIDFromURL(t *testing.T) {
+	// IDs stored before query parameters were preserved, must not change
+	legacyID, err := LegacyArticleIDFromURL("https://example.com/article/123/?id=1#intro")
+	assert.NoError(t, err)
+	assert.Equal(t, "6eafc477-0c8c-58c5-b013-cfe13434c086", legacyID)
+
+	id, err := ArticleIDFromURL("https://example.com/article/123")
+	assert.NoError(t, err)
+	assert.Equal(t, id, legacyID, "URLs without query parameters keep their ID")
+
+	_, err = LegacyArticleIDFromURL("example.com/article")
+	assert.Error(t, err)
+}
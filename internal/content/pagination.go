@@ -0,0 +1,157 @@
+package content
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/shaftoe/savetoink/internal/consts"
+	"github.com/shaftoe/savetoink/internal/content/convert"
+	"github.com/shaftoe/savetoink/internal/content/extract"
+	"github.com/shaftoe/savetoink/internal/content/rules"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// pageParams are the query parameters holding the page number of paginated articles.
+// "p" is left out as blogs commonly use it for post ids.
+var pageParams = []string{"page", "pg", "pagina", "seite"}
+
+// pagination tracks the pages of an article split across pages.
+// Only links to the next page number of the first page URL are followed, either as a query
+// parameter (?page=2) or as a path suffix (/2, /page/2), so that links to other articles,
+// even with rel="next", are not mistaken for pages.
+type pagination struct {
+	first *url.URL
+	// query is the encoded query of the first page without page number parameters
+	query string
+	page  int
+}
+
+func newPagination(first *url.URL) *pagination {
+	query := first.Query()
+	for _, param := range pageParams {
+		query.Del(param)
+	}
+	return &pagination{first: first, query: query.Encode(), page: 1}
+}
+
+// next returns the URL of the page following the current one, linked from doc, nil if there is none.
+func (p *pagination) next(doc []byte, current *url.URL) *url.URL {
+	for _, href := range links(doc) {
+		candidate, err := current.Parse(href)
+		if err != nil {
+			continue
+		}
+		if p.isPage(candidate, p.page+1) {
+			candidate.Fragment = ""
+			return candidate
+		}
+	}
+	return nil
+}
+
+// isPage reports whether u is page number n of the article.
+func (p *pagination) isPage(u *url.URL, n int) bool {
+	if !strings.EqualFold(u.Host, p.first.Host) || u.Scheme != p.first.Scheme {
+		return false
+	}
+
+	number := strconv.Itoa(n)
+	basePath := strings.TrimSuffix(p.first.Path, "/")
+	path := strings.TrimSuffix(u.Path, "/")
+	query := u.Query()
+
+	if path == basePath {
+		for _, param := range pageParams {
+			if query.Get(param) != number {
+				continue
+			}
+			query.Del(param)
+			return query.Encode() == p.query
+		}
+		return false
+	}
+
+	return query.Encode() == p.query &&
+		(path == basePath+"/"+number || path == basePath+"/page/"+number)
+}
+
+// links returns the href of links in doc, rel="next" links first.
+func links(doc []byte) []string {
+	var next, others []string
+
+	tokenizer := html.NewTokenizer(bytes.NewReader(doc))
+	for {
+		switch tokenizer.Next() {
+		case html.ErrorToken:
+			return append(next, others...)
+		case html.StartTagToken, html.SelfClosingTagToken:
+			token := tokenizer.Token()
+			if token.DataAtom != atom.A && token.DataAtom != atom.Link {
+				continue
+			}
+
+			var href, rel string
+			for _, attr := range token.Attr {
+				switch attr.Key {
+				case "href":
+					href = attr.Val
+				case "rel":
+					rel = attr.Val
+				}
+			}
+
+			switch {
+			case href == "":
+			case strings.Contains(strings.ToLower(rel), "next"):
+				next = append(next, href)
+			case token.DataAtom == atom.A:
+				others = append(others, href)
+			}
+		default:
+		}
+	}
+}
+
+// stitchPages appends to result the content of the following pages of an article split across pages,
+// up to consts.PaginationMaxPages in total. A page failing to be fetched or extracted ends stitching,
+// keeping the content of the previous pages.
+func (e *Extractor) stitchPages(
+	ctx context.Context,
+	result *extract.Result,
+	first *document,
+	headers map[string]string,
+	rule *rules.Rule,
+) {
+	pages := newPagination(first.url)
+
+	for current := first; pages.page < consts.PaginationMaxPages; pages.page++ {
+		nextURL := pages.next(current.body, current.url)
+		if nextURL == nil {
+			return
+		}
+
+		doc, err := e.fetchURL(ctx, nextURL.String(), headers)
+		if err != nil {
+			log.Printf("warning: failed to fetch page %d of %s: %v", pages.page+1, first.url, err)
+			return
+		}
+		if !convert.IsHTML(doc.mediaType) || !strings.EqualFold(doc.url.Host, first.url.Host) {
+			return
+		}
+
+		pageResult, err := e.extractResult(doc.body, doc.url, rule)
+		if err != nil {
+			log.Printf("warning: failed to extract page %d of %s: %v", pages.page+1, first.url, err)
+			return
+		}
+
+		result.Content += "\n" + pageResult.Content
+		result.WordCount += pageResult.WordCount
+		current = doc
+	}
+}
@@ -0,0 +1,119 @@
+package content
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/shaftoe/savetoink/internal/consts"
+)
+
+func TestPaginationIsPage(t *testing.T) {
+	tests := []struct {
+		name  string
+		first string
+		url   string
+		want  bool
+	}{
+		{"query parameter", "https://example.com/story?id=7", "https://example.com/story?page=2&id=7", true},
+		{"explicit first page", "https://example.com/story?page=1", "https://example.com/story?page=2", true},
+		{"path suffix", "https://example.com/story/", "https://example.com/story/2", true},
+		{"path page suffix", "https://example.com/story", "https://example.com/story/page/2/", true},
+		{"host case", "https://example.com/story", "https://EXAMPLE.com/story?pg=2", true},
+		{"wrong page number", "https://example.com/story", "https://example.com/story?page=3", false},
+		{"other host", "https://example.com/story", "https://other.example.com/story?page=2", false},
+		{"other scheme", "https://example.com/story", "http://example.com/story?page=2", false},
+		{"other query", "https://example.com/story?id=7", "https://example.com/story?page=2&id=8", false},
+		{"post id parameter", "https://example.com/?p=1", "https://example.com/?p=2", false},
+		{"next post", "https://example.com/posts/41", "https://example.com/posts/42", false},
+		{"other article", "https://example.com/story", "https://example.com/other?page=2", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			first, _ := url.Parse(tt.first)
+			u, _ := url.Parse(tt.url)
+			if got := newPagination(first).isPage(u, 2); got != tt.want {
+				t.Errorf("isPage(%s) = %v, want %v", tt.url, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPaginationNext(t *testing.T) {
+	first, _ := url.Parse("https://example.com/story")
+	doc := []byte(`<html><head><link rel="prev" href="/story?page=0"></head><body>` +
+		`<a rel="next" href="/another-story">Next story</a>` +
+		`<a href="/story?page=3">3</a><a href="/story?page=2#top">2</a></body></html>`)
+
+	next := newPagination(first).next(doc, first)
+	if next == nil || next.String() != "https://example.com/story?page=2" {
+		t.Errorf("expected page 2 URL, got %v", next)
+	}
+
+	if next = newPagination(first).next([]byte(`<a href="/another-story">x</a>`), first); next != nil {
+		t.Errorf("expected no next page, got %s", next)
+	}
+}
+
+func TestExtractFromURLPagination(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		page := r.URL.Query().Get("page")
+		if page == "" {
+			page = "1"
+		}
+
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = fmt.Fprintf(w, `<html><head><title>Long Story</title>
+<link rel="next" href="/story?page=%s"></head><body><article><h1>Long Story</h1>
+<p>This is the text of page number %s of the long story, split across many pages by the site.</p>
+</article><nav><a href="/story?page=2">2</a></nav></body></html>`, page+"0", page)
+	}))
+	defer server.Close()
+
+	// pages link with rel="next" to a wrong page number (page 1 to 10, page 2 to 20) and all link to page 2,
+	// so only the first two pages are stitched
+	article, err := NewExtractor().ExtractFromURL(context.Background(), server.URL+"/story")
+	if err != nil {
+		t.Fatalf("ExtractFromURL() unexpected error = %v", err)
+	}
+
+	if !strings.Contains(article.Content, "page number 1 ") || !strings.Contains(article.Content, "page number 2 ") {
+		t.Errorf("expected content of pages 1 and 2, got %q", article.Content)
+	}
+	if requests != 2 {
+		t.Errorf("expected 2 requests, got %d", requests)
+	}
+	if article.WordCount < 30 {
+		t.Errorf("expected word count of both pages, got %d", article.WordCount)
+	}
+}
+
+func TestExtractFromURLPaginationLimit(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		page := 1
+		_, _ = fmt.Sscanf(r.URL.Query().Get("page"), "%d", &page)
+
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = fmt.Fprintf(w, `<html><head><title>Endless Story</title></head><body><article>
+<p>This is the text of page number %d of the endless story, split across many pages.</p>
+<a href="/story?page=%d">Next page</a></article></body></html>`, page, page+1)
+	}))
+	defer server.Close()
+
+	if _, err := NewExtractor().ExtractFromURL(context.Background(), server.URL+"/story"); err != nil {
+		t.Fatalf("ExtractFromURL() unexpected error = %v", err)
+	}
+
+	if requests != consts.PaginationMaxPages {
+		t.Errorf("expected %d requests, got %d", consts.PaginationMaxPages, requests)
+	}
+}
@@ -0,0 +1,227 @@
+package content
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shaftoe/savetoink/internal/consts"
+)
+
+// ErrRobotsDisallowed is returned when robots.txt compliance is enabled and the site disallows fetching a URL.
+var ErrRobotsDisallowed = errors.New("disallowed by robots.txt")
+
+// robotsRules are the robots.txt rules applying to our User-Agent.
+type robotsRules struct {
+	rules      []robotsRule
+	crawlDelay time.Duration
+}
+
+type robotsRule struct {
+	allow   bool
+	pattern *regexp.Regexp
+	// length is the length of the rule path, the longest matching rule wins
+	length int
+}
+
+// robotsGroup is a group of rules for one or more user agents.
+type robotsGroup struct {
+	agents []string
+	robotsRules
+}
+
+// parseRobots parses a robots.txt file as specified by RFC 9309 and returns the rules of the groups matching
+// userAgent, i.e. naming a product token contained in it, or of the * groups when none does.
+func parseRobots(body []byte, userAgent string) *robotsRules {
+	var groups []*robotsGroup
+	var current *robotsGroup
+	inRules := false
+
+	for line := range strings.Lines(string(body)) {
+		line, _, _ = strings.Cut(line, "#")
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		key, value = strings.ToLower(strings.TrimSpace(key)), strings.TrimSpace(value)
+
+		switch key {
+		case "user-agent":
+			if current == nil || inRules {
+				current = &robotsGroup{}
+				groups = append(groups, current)
+				inRules = false
+			}
+			current.agents = append(current.agents, strings.ToLower(value))
+		case "allow", "disallow":
+			if current == nil {
+				continue
+			}
+			inRules = true
+			if value != "" {
+				current.rules = append(current.rules, newRobotsRule(key == "allow", value))
+			}
+		case "crawl-delay":
+			if current == nil {
+				continue
+			}
+			inRules = true
+			if seconds, err := strconv.ParseFloat(value, 64); err == nil && seconds > 0 {
+				current.crawlDelay = time.Duration(seconds * float64(time.Second))
+			}
+		}
+	}
+
+	userAgent = strings.ToLower(userAgent)
+	specific, wildcard := &robotsRules{}, &robotsRules{}
+	for _, group := range groups {
+		for _, agent := range group.agents {
+			target := wildcard
+			if agent != "*" {
+				if agent == "" || !strings.Contains(userAgent, agent) {
+					continue
+				}
+				target = specific
+			}
+			target.rules = append(target.rules, group.rules...)
+			target.crawlDelay = max(target.crawlDelay, group.crawlDelay)
+			break
+		}
+	}
+
+	if len(specific.rules) > 0 || specific.crawlDelay > 0 {
+		return specific
+	}
+	return wildcard
+}
+
+// newRobotsRule compiles a rule path, where * matches any sequence of characters and a trailing $
+// anchors the end of the URL path.
+func newRobotsRule(allow bool, path string) robotsRule {
+	anchored := strings.HasSuffix(path, "$")
+	parts := strings.Split(strings.TrimSuffix(path, "$"), "*")
+	for i, part := range parts {
+		parts[i] = regexp.QuoteMeta(part)
+	}
+
+	expr := "^" + strings.Join(parts, ".*")
+	if anchored {
+		expr += "$"
+	}
+
+	return robotsRule{allow: allow, pattern: regexp.MustCompile(expr), length: len(path)}
+}
+
+// allowed reports whether path, including the query, can be fetched: the longest matching rule
+// applies, allow rules win ties, and paths not matching any rule are allowed.
+func (r *robotsRules) allowed(path string) bool {
+	allowed, longest := true, -1
+	for _, rule := range r.rules {
+		if !rule.pattern.MatchString(path) {
+			continue
+		}
+		if rule.length > longest || (rule.length == longest && rule.allow) {
+			allowed, longest = rule.allow, rule.length
+		}
+	}
+	return allowed
+}
+
+// robotsCache caches the robots.txt rules of each site for consts.RobotsCacheTTL.
+type robotsCache struct {
+	mu      sync.Mutex
+	entries map[string]robotsEntry
+}
+
+type robotsEntry struct {
+	rules   *robotsRules
+	expires time.Time
+}
+
+func newRobotsCache() *robotsCache {
+	return &robotsCache{entries: make(map[string]robotsEntry)}
+}
+
+func (c *robotsCache) get(site string) (*robotsRules, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[site]
+	if !ok || time.Now().After(entry.expires) {
+		return nil, false
+	}
+	return entry.rules, true
+}
+
+func (c *robotsCache) set(site string, rules *robotsRules) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[site] = robotsEntry{rules: rules, expires: time.Now().Add(consts.RobotsCacheTTL)}
+}
+
+// checkRobots returns ErrRobotsDisallowed when the robots.txt of the site of u disallows fetching it
+// with our User-Agent, and applies its Crawl-delay to the host.
+func (e *Extractor) checkRobots(ctx context.Context, u *url.URL) error {
+	site := u.Scheme + "://" + u.Host
+
+	rules, ok := e.robots.get(site)
+	if !ok {
+		var err error
+		if rules, err = e.fetchRobots(ctx, site); err != nil {
+			return err
+		}
+		e.robots.set(site, rules)
+	}
+
+	if rules.crawlDelay > 0 {
+		e.scheduler.setInterval(u.Host, min(rules.crawlDelay, consts.RobotsMaxCrawlDelay))
+	}
+
+	if !rules.allowed(u.RequestURI()) {
+		return fmt.Errorf("%w: %s", ErrRobotsDisallowed, u.String())
+	}
+
+	return nil
+}
+
+// fetchRobots fetches and parses the robots.txt of site. A missing file (4xx) allows everything,
+// a server error defers fetching from the site.
+func (e *Extractor) fetchRobots(ctx context.Context, site string) (*robotsRules, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, site+"/robots.txt", http.NoBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create robots.txt request: %w", err)
+	}
+	req.Header.Set("User-Agent", e.fetch.UserAgent)
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch robots.txt: %w", err)
+	}
+	defer func() {
+		if closeErr := resp.Body.Close(); closeErr != nil {
+			log.Printf("warning: failed to close robots.txt response body: %v", closeErr)
+		}
+	}()
+
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= http.StatusInternalServerError:
+		return nil, e.scheduler.backoff(req.URL.Host, resp.Header)
+	case resp.StatusCode >= http.StatusBadRequest:
+		return &robotsRules{}, nil
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, consts.RobotsMaxSize))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read robots.txt: %w", err)
+	}
+
+	return parseRobots(body, e.fetch.UserAgent), nil
+}
@@ -0,0 +1,111 @@
+package content
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const robotsTestFile = `# robots.txt
+User-agent: *
+Disallow: /private/
+Allow: /private/public
+Disallow: /*.pdf$
+
+User-agent: savetoink
+User-agent: otherbot
+Disallow: /drafts
+Allow: /drafts/published
+Crawl-delay: 2
+
+User-agent: badbot
+Disallow: /
+`
+
+func TestParseRobots(t *testing.T) {
+	tests := []struct {
+		name      string
+		userAgent string
+		path      string
+		want      bool
+	}{
+		{name: "wildcard group disallows", userAgent: "test-agent", path: "/private/page", want: false},
+		{name: "longest rule allows", userAgent: "test-agent", path: "/private/public/page", want: true},
+		{name: "wildcard and end anchor", userAgent: "test-agent", path: "/files/doc.pdf", want: false},
+		{name: "end anchor not matching", userAgent: "test-agent", path: "/files/doc.pdf?download=1", want: true},
+		{name: "no matching rule", userAgent: "test-agent", path: "/articles/1", want: true},
+		{name: "specific group", userAgent: "Mozilla/5.0 (compatible; savetoink/1.0)", path: "/drafts/1", want: false},
+		{
+			name:      "specific group replaces wildcard group",
+			userAgent: "Mozilla/5.0 (compatible; savetoink/1.0)",
+			path:      "/private/page",
+			want:      true,
+		},
+		{
+			name:      "specific group allow",
+			userAgent: "Mozilla/5.0 (compatible; SaveToInk/1.0)",
+			path:      "/drafts/published/1",
+			want:      true,
+		},
+		{name: "disallow all", userAgent: "badbot/2.0", path: "/", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rules := parseRobots([]byte(robotsTestFile), tt.userAgent)
+			assert.Equal(t, tt.want, rules.allowed(tt.path))
+		})
+	}
+
+	assert.Equal(t, 2*time.Second, parseRobots([]byte(robotsTestFile), "savetoink/1.0").crawlDelay)
+	assert.Zero(t, parseRobots([]byte(robotsTestFile), "test-agent").crawlDelay)
+	assert.True(t, parseRobots(nil, "test-agent").allowed("/anything"))
+}
+
+func TestExtractFromURLRobots(t *testing.T) {
+	var robotsRequests atomic.Int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("/robots.txt", func(w http.ResponseWriter, _ *http.Request) {
+		robotsRequests.Add(1)
+		_, _ = w.Write([]byte("User-agent: *\nDisallow: /private\n"))
+	})
+	mux.HandleFunc("/", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write([]byte(fetchTestPage))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	extractor := NewExtractor(WithFetchConfig(FetchConfig{RobotsTxt: true, HostInterval: -1}))
+
+	_, err := extractor.ExtractFromURL(context.Background(), server.URL+"/private/article")
+	assert.ErrorIs(t, err, ErrRobotsDisallowed)
+
+	_, err = extractor.ExtractFromURL(context.Background(), server.URL+"/article")
+	assert.NoError(t, err)
+	assert.Equal(t, int32(1), robotsRequests.Load(), "robots.txt should be cached")
+
+	// robots.txt is ignored unless enabled
+	_, err = NewExtractor().ExtractFromURL(context.Background(), server.URL+"/private/article")
+	assert.NoError(t, err)
+}
+
+func TestExtractFromURLRobotsMissing(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/robots.txt", http.NotFound)
+	mux.HandleFunc("/", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write([]byte(fetchTestPage))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	extractor := NewExtractor(WithFetchConfig(FetchConfig{RobotsTxt: true}))
+	_, err := extractor.ExtractFromURL(context.Background(), server.URL+"/private/article")
+	assert.NoError(t, err)
+}
@@ -0,0 +1,214 @@
+package rules
+
+import (
+	"bytes"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/go-shiori/dom"
+	"github.com/shaftoe/savetoink/internal/content/extract"
+	"golang.org/x/net/html"
+)
+
+// Extract applies the rule around the extractors: elements matching Remove (but not Keep) are
+// removed from doc before extraction, then the extracted content is replaced by the content root
+// if ContentRoot is set, kept elements dropped by the extraction are restored otherwise, and title
+// and author are overridden.
+func (r *Rule) Extract(doc []byte, pageURL *url.URL, extractors *extract.Registry) (*extract.Result, error) {
+	root, err := dom.Parse(bytes.NewReader(doc))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse document: %w", err)
+	}
+
+	r.removeElements(root)
+
+	var buf bytes.Buffer
+	if err = html.Render(&buf, root); err != nil {
+		return nil, fmt.Errorf("failed to render document: %w", err)
+	}
+
+	// extraction failures are tolerated as long as the content root provides the content
+	result, extractErr := extractors.Extract(buf.Bytes(), pageURL)
+	if result == nil {
+		result = &extract.Result{}
+	}
+
+	if r.ContentRoot != "" {
+		r.useContentRoot(root, result)
+	} else {
+		r.restoreKeptElements(root, result)
+	}
+
+	if extract.CountWords(result.Content) == 0 {
+		if extractErr == nil {
+			extractErr = extract.ErrNoContent
+		}
+		return nil, fmt.Errorf("failed to extract content: %w", extractErr)
+	}
+
+	if title := selectorText(root, r.Title); title != "" {
+		result.Title = title
+	}
+
+	if author := selectorText(root, r.Author); author != "" {
+		result.Author = author
+	}
+
+	return result, nil
+}
+
+func (r *Rule) removeElements(root *html.Node) {
+	for _, selector := range r.Remove {
+		dom.RemoveNodes(dom.QuerySelectorAll(root, selector), func(node *html.Node) bool {
+			return !r.isKept(node)
+		})
+	}
+}
+
+// isKept reports whether node matches or contains an element matching a Keep selector.
+func (r *Rule) isKept(node *html.Node) bool {
+	for _, selector := range r.Keep {
+		for _, kept := range dom.QuerySelectorAll(node.Parent, selector) {
+			for n := kept; n != nil; n = n.Parent {
+				if n == node {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// restoreKeptElements reinserts the kept elements of the page whose text is missing from the extracted content,
+// each after the closest element preceding it in the page that the extraction kept, e.g. a figure caption after
+// its image. Kept elements without such an element are left out.
+func (r *Rule) restoreKeptElements(root *html.Node, result *extract.Result) {
+	body := dom.QuerySelector(root, "body")
+	if body == nil || len(r.Keep) == 0 || result.Content == "" {
+		return
+	}
+
+	contentDoc, err := dom.FastParse(strings.NewReader(result.Content))
+	if err != nil {
+		return
+	}
+	contentBody := dom.QuerySelector(contentDoc, "body")
+	if contentBody == nil {
+		return
+	}
+
+	pageElements := dom.GetElementsByTagName(body, "*")
+	text := normalize(dom.TextContent(contentBody))
+	restored := false
+	for _, selector := range r.Keep {
+		for _, kept := range dom.QuerySelectorAll(body, selector) {
+			keptText := normalize(dom.TextContent(kept))
+			if keptText == "" || strings.Contains(text, keptText) {
+				continue
+			}
+
+			anchor := findAnchor(contentBody, pageElements, kept)
+			if anchor == nil {
+				continue
+			}
+			anchor.Parent.InsertBefore(dom.Clone(kept, true), anchor.NextSibling)
+			text = normalize(dom.TextContent(contentBody))
+			restored = true
+		}
+	}
+
+	if restored {
+		result.Content = dom.InnerHTML(contentBody)
+		result.WordCount = extract.CountWords(result.Content)
+	}
+}
+
+// anchorTags lists the elements of the page a kept element can be restored after.
+var anchorTags = map[string]bool{
+	"img": true, "p": true, "h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true,
+	"li": true, "blockquote": true, "pre": true,
+}
+
+// findAnchor returns the element of content matching the closest element preceding kept in pageElements, the
+// elements of the page in document order: an image with the same source or a block with the same text.
+func findAnchor(content *html.Node, pageElements []*html.Node, kept *html.Node) *html.Node {
+	index := slices.Index(pageElements, kept)
+	for i := index - 1; i >= 0; i-- {
+		previous := pageElements[i]
+		tag := dom.TagName(previous)
+		if !anchorTags[tag] || isAncestor(previous, kept) {
+			continue
+		}
+
+		for _, candidate := range dom.GetElementsByTagName(content, tag) {
+			if sameElement(previous, candidate) {
+				return candidate
+			}
+		}
+	}
+	return nil
+}
+
+// sameElement reports whether the extracted element candidate stands for the page element: images with the same
+// source path, extractors may have resolved relative sources, or elements with the same text.
+func sameElement(element, candidate *html.Node) bool {
+	if dom.TagName(element) == "img" {
+		src, candidateSrc := sourcePath(element), sourcePath(candidate)
+		return src != "" && (candidateSrc == src || strings.HasSuffix(candidateSrc, "/"+src))
+	}
+
+	text := normalize(dom.TextContent(element))
+	return text != "" && text == normalize(dom.TextContent(candidate))
+}
+
+func sourcePath(img *html.Node) string {
+	src, err := url.Parse(strings.TrimSpace(dom.GetAttribute(img, "src")))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimPrefix(src.Path, "/")
+}
+
+func isAncestor(node, descendant *html.Node) bool {
+	for parent := descendant.Parent; parent != nil; parent = parent.Parent {
+		if parent == node {
+			return true
+		}
+	}
+	return false
+}
+
+// useContentRoot replaces the result content with the content root, if found.
+func (r *Rule) useContentRoot(root *html.Node, result *extract.Result) {
+	contentRoot := r.contentRoot(root)
+	if contentRoot == nil {
+		return
+	}
+
+	result.Content = dom.InnerHTML(contentRoot)
+	result.WordCount = extract.CountWords(result.Content)
+	result.Extractor = "rule:" + r.Name
+}
+
+func (r *Rule) contentRoot(root *html.Node) *html.Node {
+	return dom.QuerySelector(root, r.ContentRoot)
+}
+
+func selectorText(root *html.Node, selector string) string {
+	if selector == "" {
+		return ""
+	}
+
+	node := dom.QuerySelector(root, selector)
+	if node == nil {
+		return ""
+	}
+
+	return normalize(dom.TextContent(node))
+}
+
+func normalize(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
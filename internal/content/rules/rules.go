@@ -0,0 +1,170 @@
+// Package rules provides site-specific extraction rules, keyed by host and path pattern,
+// fixing up sites the generic extractors handle badly.
+package rules
+
+import (
+	_ "embed" // bundled rules
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/andybalholm/cascadia"
+	"go.yaml.in/yaml/v3"
+)
+
+//go:embed rules.yaml
+var bundledRules []byte
+
+// Rule describes how to fetch and extract articles from a site.
+type Rule struct {
+	Name        string            `yaml:"name"`
+	Hosts       []string          `yaml:"hosts"`
+	Path        string            `yaml:"path"`
+	Remove      []string          `yaml:"remove"`
+	Keep        []string          `yaml:"keep"`
+	ContentRoot string            `yaml:"content_root"`
+	Title       string            `yaml:"title"`
+	Author      string            `yaml:"author"`
+	Headers     map[string]string `yaml:"headers"`
+
+	pathPattern *regexp.Regexp
+}
+
+// Set holds rules in order of precedence.
+type Set struct {
+	rules []*Rule
+}
+
+type rulesFile struct {
+	Rules []*Rule `yaml:"rules"`
+}
+
+// Parse parses and validates rules from YAML data.
+func Parse(data []byte) (*Set, error) {
+	var file rulesFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse rules: %w", err)
+	}
+
+	for i, rule := range file.Rules {
+		if err := rule.compile(); err != nil {
+			return nil, fmt.Errorf("invalid rule #%d %q: %w", i+1, rule.Name, err)
+		}
+	}
+
+	return &Set{rules: file.Rules}, nil
+}
+
+// Bundled returns the rules shipped with the application.
+func Bundled() (*Set, error) {
+	return Parse(bundledRules)
+}
+
+// Load returns the rules read from the YAML file at path, followed by the bundled ones.
+// Only the bundled rules are returned when path is empty.
+func Load(path string) (*Set, error) {
+	bundled, err := Bundled()
+	if err != nil {
+		return nil, err
+	}
+
+	if path == "" {
+		return bundled, nil
+	}
+
+	data, err := os.ReadFile(path) // #nosec G304 - path comes from trusted configuration
+	if err != nil {
+		return nil, fmt.Errorf("failed to read rules file: %w", err)
+	}
+
+	user, err := Parse(data)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Set{rules: append(user.rules, bundled.rules...)}, nil
+}
+
+// Match returns the first rule matching u, nil if none does.
+func (s *Set) Match(u *url.URL) *Rule {
+	if s == nil || u == nil {
+		return nil
+	}
+
+	for _, rule := range s.rules {
+		if rule.Matches(u) {
+			return rule
+		}
+	}
+	return nil
+}
+
+// Len returns the number of rules in the set.
+func (s *Set) Len() int {
+	if s == nil {
+		return 0
+	}
+	return len(s.rules)
+}
+
+// Matches reports whether the rule applies to u.
+func (r *Rule) Matches(u *url.URL) bool {
+	host := strings.ToLower(u.Hostname())
+
+	matched := false
+	for _, pattern := range r.Hosts {
+		if MatchHost(pattern, host) {
+			matched = true
+			break
+		}
+	}
+
+	if !matched {
+		return false
+	}
+
+	return r.pathPattern == nil || r.pathPattern.MatchString(u.EscapedPath())
+}
+
+// MatchHost matches host against pattern, "*.example.com" matching example.com and its subdomains.
+// Matching is case-insensitive.
+func MatchHost(pattern, host string) bool {
+	pattern, host = strings.ToLower(pattern), strings.ToLower(host)
+	if domain, ok := strings.CutPrefix(pattern, "*."); ok {
+		return host == domain || strings.HasSuffix(host, "."+domain)
+	}
+	return host == pattern
+}
+
+func (r *Rule) compile() error {
+	if r.Name == "" {
+		return errors.New("missing name")
+	}
+
+	if len(r.Hosts) == 0 {
+		return errors.New("missing hosts")
+	}
+
+	if r.Path != "" {
+		pattern, err := regexp.Compile(r.Path)
+		if err != nil {
+			return fmt.Errorf("invalid path pattern: %w", err)
+		}
+		r.pathPattern = pattern
+	}
+
+	selectors := append(append([]string{r.ContentRoot, r.Title, r.Author}, r.Remove...), r.Keep...)
+	for _, selector := range selectors {
+		if selector == "" {
+			continue
+		}
+		if _, err := cascadia.ParseGroup(selector); err != nil {
+			return fmt.Errorf("invalid selector %q: %w", selector, err)
+		}
+	}
+
+	return nil
+}
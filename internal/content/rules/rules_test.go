@@ -0,0 +1,285 @@
+package rules
+
+import (
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/shaftoe/savetoink/internal/content/extract"
+)
+
+func mustParseURL(t *testing.T, raw string) *url.URL {
+	t.Helper()
+	u, err := url.Parse(raw)
+	if err != nil {
+		t.Fatalf("failed to parse URL %q: %v", raw, err)
+	}
+	return u
+}
+
+func TestBundled(t *testing.T) {
+	set, err := Bundled()
+	if err != nil {
+		t.Fatalf("Bundled() unexpected error = %v", err)
+	}
+
+	if set.Len() == 0 {
+		t.Error("expected bundled rules")
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		yaml string
+	}{
+		{name: "not yaml", yaml: "rules: [:"},
+		{name: "missing name", yaml: "rules:\n  - hosts: [example.com]\n"},
+		{name: "missing hosts", yaml: "rules:\n  - name: example\n"},
+		{name: "invalid path", yaml: "rules:\n  - name: example\n    hosts: [example.com]\n    path: \"[\"\n"},
+		{name: "invalid selector", yaml: "rules:\n  - name: example\n    hosts: [example.com]\n    remove: [\"div[\"]\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Parse([]byte(tt.yaml)); err == nil {
+				t.Error("expected error, got nil")
+			}
+		})
+	}
+}
+
+func TestMatch(t *testing.T) {
+	set, err := Parse([]byte(`rules:
+  - name: blog
+    hosts: ["*.example.com"]
+    path: ^/blog/
+  - name: exact
+    hosts: ["example.org"]
+`))
+	if err != nil {
+		t.Fatalf("Parse() unexpected error = %v", err)
+	}
+
+	tests := []struct {
+		url      string
+		expected string
+	}{
+		{url: "https://example.com/blog/post", expected: "blog"},
+		{url: "https://www.example.com/blog/post", expected: "blog"},
+		{url: "https://www.example.com/about", expected: ""},
+		{url: "https://notexample.com/blog/post", expected: ""},
+		{url: "https://EXAMPLE.org/anything", expected: "exact"},
+		{url: "https://sub.example.org/anything", expected: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			name := ""
+			if rule := set.Match(mustParseURL(t, tt.url)); rule != nil {
+				name = rule.Name
+			}
+			if name != tt.expected {
+				t.Errorf("expected rule %q, got %q", tt.expected, name)
+			}
+		})
+	}
+}
+
+func TestLoadUserRulesTakePrecedence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rules.yaml")
+	if err := os.WriteFile(path, []byte("rules:\n  - name: mine\n    hosts: [\"*.wikipedia.org\"]\n"), 0o600); err != nil {
+		t.Fatalf("failed to write rules: %v", err)
+	}
+
+	set, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() unexpected error = %v", err)
+	}
+
+	if rule := set.Match(mustParseURL(t, "https://en.wikipedia.org/wiki/Go")); rule == nil || rule.Name != "mine" {
+		t.Errorf("expected user rule to match first, got %+v", rule)
+	}
+
+	bundled, _ := Bundled()
+	if set.Len() != bundled.Len()+1 {
+		t.Errorf("expected %d rules, got %d", bundled.Len()+1, set.Len())
+	}
+}
+
+const testDocument = `<html><head><title>Page title</title></head><body>` +
+	`<div class="banner">Subscribe to read more!</div>` +
+	`<article><h1 class="headline">Real headline</h1><span class="byline">Jane Doe</span>` +
+	`<p>First paragraph of the story with enough words to be extracted.</p>` +
+	`<figure><img src="a.jpg"/><figcaption>A caption worth keeping</figcaption></figure>` +
+	`<p>Second paragraph of the story with enough words to be extracted.</p>` +
+	`<div class="comments"><p>First comment!</p></div></article></body></html>`
+
+func TestRuleExtract(t *testing.T) {
+	tests := []struct {
+		name        string
+		rule        string
+		contains    []string
+		notContains []string
+		title       string
+		author      string
+		extractor   string
+	}{
+		{
+			name: "remove and overrides",
+			rule: `rules:
+  - name: site
+    hosts: [example.com]
+    remove: [.banner, .comments]
+    title: .headline
+    author: .byline
+`,
+			contains:    []string{"First paragraph"},
+			notContains: []string{"Subscribe", "First comment"},
+			title:       "Real headline",
+			author:      "Jane Doe",
+			extractor:   "root",
+		},
+		{
+			name: "content root",
+			rule: `rules:
+  - name: site
+    hosts: [example.com]
+    content_root: article
+    remove: [.comments]
+`,
+			contains:    []string{"A caption worth keeping", "Jane Doe"},
+			notContains: []string{"Subscribe", "First comment"},
+			extractor:   "rule:site",
+		},
+		{
+			name: "keep protects from removal",
+			rule: `rules:
+  - name: site
+    hosts: [example.com]
+    content_root: article
+    remove: [figure]
+    keep: [figcaption]
+`,
+			contains:  []string{"A caption worth keeping"},
+			extractor: "rule:site",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			set, err := Parse([]byte(tt.rule))
+			if err != nil {
+				t.Fatalf("Parse() unexpected error = %v", err)
+			}
+			pageURL := mustParseURL(t, "https://example.com/story")
+
+			result, err := set.Match(pageURL).Extract([]byte(testDocument), pageURL, extract.NewRegistry(&rootExtractor{}))
+			if err != nil {
+				t.Fatalf("Extract() unexpected error = %v", err)
+			}
+
+			for _, expected := range tt.contains {
+				if !strings.Contains(result.Content, expected) {
+					t.Errorf("expected content to contain %q, got %q", expected, result.Content)
+				}
+			}
+			for _, unexpected := range tt.notContains {
+				if strings.Contains(result.Content, unexpected) {
+					t.Errorf("expected content not to contain %q, got %q", unexpected, result.Content)
+				}
+			}
+			if tt.title != "" && result.Title != tt.title {
+				t.Errorf("expected title %q, got %q", tt.title, result.Title)
+			}
+			if tt.author != "" && result.Author != tt.author {
+				t.Errorf("expected author %q, got %q", tt.author, result.Author)
+			}
+			if result.Extractor != tt.extractor {
+				t.Errorf("expected extractor %q, got %q", tt.extractor, result.Extractor)
+			}
+		})
+	}
+}
+
+func TestRuleExtract_KeptElementDropped(t *testing.T) {
+	set, err := Parse([]byte("rules:\n  - name: site\n    hosts: [example.com]\n    keep: [figcaption]\n"))
+	if err != nil {
+		t.Fatalf("Parse() unexpected error = %v", err)
+	}
+	pageURL := mustParseURL(t, "https://example.com/story")
+
+	registry := extract.NewRegistry(&rootExtractor{dropFigures: true})
+	result, err := set.Match(pageURL).Extract([]byte(testDocument), pageURL, registry)
+	if err != nil {
+		t.Fatalf("Extract() unexpected error = %v", err)
+	}
+
+	if !strings.Contains(result.Content, "extracted.</p><figcaption>A caption worth keeping</figcaption>") {
+		t.Errorf("expected the caption restored after the paragraph preceding it, got %q", result.Content)
+	}
+	if strings.Contains(result.Content, "Subscribe") || result.Extractor != "root" {
+		t.Errorf("expected the extracted content without the rest of the page, got %q from %q",
+			result.Content, result.Extractor)
+	}
+}
+
+func TestRuleExtract_KeptElementRestoredAfterImage(t *testing.T) {
+	set, err := Parse([]byte("rules:\n  - name: site\n    hosts: [example.com]\n    keep: [figcaption]\n"))
+	if err != nil {
+		t.Fatalf("Parse() unexpected error = %v", err)
+	}
+	pageURL := mustParseURL(t, "https://example.com/story")
+
+	registry := extract.NewRegistry(&captionlessExtractor{})
+	result, err := set.Match(pageURL).Extract([]byte(testDocument), pageURL, registry)
+	if err != nil {
+		t.Fatalf("Extract() unexpected error = %v", err)
+	}
+
+	if !strings.Contains(result.Content, `<img src="https://example.com/a.jpg"/><figcaption>A caption worth keeping`) {
+		t.Errorf("expected the caption restored after its image, got %q", result.Content)
+	}
+	if strings.Count(result.Content, "A caption worth keeping") != 1 {
+		t.Errorf("expected the caption restored once, got %q", result.Content)
+	}
+}
+
+// captionlessExtractor returns the paragraphs and images of the <article> element with resolved sources,
+// dropping captions like trafilatura does.
+type captionlessExtractor struct{}
+
+func (c *captionlessExtractor) Name() string {
+	return "captionless"
+}
+
+func (c *captionlessExtractor) Extract(_ []byte, _ *url.URL) (*extract.Result, error) {
+	return &extract.Result{Content: `<p>First paragraph of the story with enough words to be extracted.</p>` +
+		`<figure><img src="https://example.com/a.jpg"/></figure>` +
+		`<p>Second paragraph of the story with enough words to be extracted.</p>`}, nil
+}
+
+// rootExtractor returns the whole <article> element, standing in for a real extractor.
+type rootExtractor struct {
+	dropFigures bool
+}
+
+func (r *rootExtractor) Name() string {
+	return "root"
+}
+
+func (r *rootExtractor) Extract(doc []byte, _ *url.URL) (*extract.Result, error) {
+	content := string(doc)
+	start := strings.Index(content, "<article>")
+	end := strings.Index(content, "</article>")
+	if start < 0 || end < 0 {
+		return nil, extract.ErrNoContent
+	}
+	content = content[start : end+len("</article>")]
+	if start, end = strings.Index(content, "<figure>"), strings.Index(content, "</figure>"); r.dropFigures && start >= 0 {
+		content = content[:start] + content[end+len("</figure>"):]
+	}
+	return &extract.Result{Title: "Page title", Content: content}, nil
+}
@@ -0,0 +1,150 @@
+package content
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shaftoe/savetoink/internal/consts"
+)
+
+// ErrDeferred is returned when a host asked to slow down (429 Too Many Requests or 503 Service Unavailable)
+// or is still backing off after doing so. Fetching can be retried later, see DeferredError.
+var ErrDeferred = errors.New("fetch deferred")
+
+// DeferredError is the error returned when fetching from a host is deferred, it wraps ErrDeferred.
+type DeferredError struct {
+	Host string
+	// RetryAt is the time after which fetching from the host can be retried.
+	RetryAt time.Time
+}
+
+func (e *DeferredError) Error() string {
+	return fmt.Sprintf("%v: %s asked to retry after %s", ErrDeferred, e.Host, e.RetryAt.Format(time.RFC3339))
+}
+
+func (e *DeferredError) Unwrap() error {
+	return ErrDeferred
+}
+
+// scheduler limits the number of concurrent requests and the request rate per host,
+// and defers requests to hosts backing off.
+type scheduler struct {
+	concurrency int
+	interval    time.Duration
+
+	mu    sync.Mutex
+	hosts map[string]*hostState
+}
+
+// hostState is the scheduling state of a host.
+type hostState struct {
+	slots chan struct{}
+	// next is the earliest time the next request to the host can start.
+	next time.Time
+	// interval overrides the scheduler interval, e.g. with the robots.txt Crawl-delay.
+	interval time.Duration
+	// retryAt is the time until which requests to the host are deferred.
+	retryAt time.Time
+}
+
+func newScheduler(concurrency int, interval time.Duration) *scheduler {
+	return &scheduler{
+		concurrency: concurrency,
+		interval:    interval,
+		hosts:       make(map[string]*hostState),
+	}
+}
+
+func (s *scheduler) host(host string) *hostState {
+	state, ok := s.hosts[host]
+	if !ok {
+		state = &hostState{slots: make(chan struct{}, s.concurrency), interval: s.interval}
+		s.hosts[host] = state
+	}
+	return state
+}
+
+// acquire waits for a free slot of host and for the request interval to elapse, the returned function
+// releases the slot. Returns a DeferredError while the host is backing off.
+func (s *scheduler) acquire(ctx context.Context, host string) (func(), error) {
+	s.mu.Lock()
+	state := s.host(host)
+	retryAt := state.retryAt
+	s.mu.Unlock()
+
+	if time.Now().Before(retryAt) {
+		return nil, &DeferredError{Host: host, RetryAt: retryAt}
+	}
+
+	select {
+	case state.slots <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	release := func() { <-state.slots }
+
+	s.mu.Lock()
+	now := time.Now()
+	start := now
+	if state.next.After(now) {
+		start = state.next
+	}
+	state.next = start.Add(state.interval)
+	s.mu.Unlock()
+
+	if wait := start.Sub(now); wait > 0 {
+		timer := time.NewTimer(wait)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			release()
+			return nil, ctx.Err()
+		}
+	}
+
+	return release, nil
+}
+
+// setInterval sets the minimum interval between requests to host, if longer than the configured one.
+func (s *scheduler) setInterval(host string, interval time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if state := s.host(host); interval > state.interval {
+		state.interval = interval
+	}
+}
+
+// backoff defers requests to host for the duration in the Retry-After header of a 429 or 503 response.
+func (s *scheduler) backoff(host string, header http.Header) *DeferredError {
+	retryAt := time.Now().Add(retryAfter(header.Get("Retry-After"), time.Now()))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if state := s.host(host); retryAt.After(state.retryAt) {
+		state.retryAt = retryAt
+	}
+
+	return &DeferredError{Host: host, RetryAt: retryAt}
+}
+
+// retryAfter parses a Retry-After header value, either seconds or an HTTP date, clamped between
+// consts.FetchRetryAfter when missing or invalid and consts.FetchMaxRetryAfter.
+func retryAfter(value string, now time.Time) time.Duration {
+	delay := consts.FetchRetryAfter
+
+	value = strings.TrimSpace(value)
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		delay = time.Duration(seconds) * time.Second
+	} else if date, dateErr := http.ParseTime(value); dateErr == nil {
+		delay = max(date.Sub(now), 0)
+	}
+
+	return min(delay, consts.FetchMaxRetryAfter)
+}
@@ -0,0 +1,137 @@
+package content
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/shaftoe/savetoink/internal/consts"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name  string
+		value string
+		want  time.Duration
+	}{
+		{name: "seconds", value: "120", want: 2 * time.Minute},
+		{name: "http date", value: "Wed, 01 May 2024 12:05:00 GMT", want: 5 * time.Minute},
+		{name: "past http date", value: "Wed, 01 May 2024 11:00:00 GMT", want: 0},
+		{name: "missing", value: "", want: consts.FetchRetryAfter},
+		{name: "invalid", value: "soon", want: consts.FetchRetryAfter},
+		{name: "capped", value: "86400", want: consts.FetchMaxRetryAfter},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, retryAfter(tt.value, now))
+		})
+	}
+}
+
+func TestSchedulerConcurrency(t *testing.T) {
+	s := newScheduler(2, -1)
+
+	var running, peak atomic.Int32
+	var wg sync.WaitGroup
+	for range 6 {
+		wg.Go(func() {
+			release, err := s.acquire(context.Background(), "example.com")
+			if err != nil {
+				t.Errorf("acquire() unexpected error = %v", err)
+				return
+			}
+			defer release()
+
+			current := running.Add(1)
+			for {
+				old := peak.Load()
+				if current <= old || peak.CompareAndSwap(old, current) {
+					break
+				}
+			}
+			time.Sleep(10 * time.Millisecond)
+			running.Add(-1)
+		})
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(2), peak.Load())
+}
+
+func TestSchedulerInterval(t *testing.T) {
+	s := newScheduler(1, 50*time.Millisecond)
+
+	start := time.Now()
+	for range 3 {
+		release, err := s.acquire(context.Background(), "example.com")
+		assert.NoError(t, err)
+		release()
+	}
+	assert.GreaterOrEqual(t, time.Since(start), 100*time.Millisecond)
+
+	// other hosts are not delayed
+	start = time.Now()
+	release, err := s.acquire(context.Background(), "example.org")
+	assert.NoError(t, err)
+	release()
+	assert.Less(t, time.Since(start), 50*time.Millisecond)
+}
+
+func TestSchedulerCanceled(t *testing.T) {
+	s := newScheduler(1, time.Hour)
+
+	release, err := s.acquire(context.Background(), "example.com")
+	assert.NoError(t, err)
+	release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = s.acquire(ctx, "example.com")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestExtractFromURLDeferred(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+	}{
+		{name: "too many requests", status: http.StatusTooManyRequests},
+		{name: "service unavailable", status: http.StatusServiceUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				requests.Add(1)
+				w.Header().Set("Retry-After", "120")
+				w.WriteHeader(tt.status)
+			}))
+			defer server.Close()
+
+			extractor := NewExtractor()
+
+			_, err := extractor.ExtractFromURL(context.Background(), server.URL)
+			assert.ErrorIs(t, err, ErrDeferred)
+
+			var deferred *DeferredError
+			if assert.True(t, errors.As(err, &deferred)) {
+				assert.WithinDuration(t, time.Now().Add(2*time.Minute), deferred.RetryAt, 5*time.Second)
+			}
+
+			// the host is backing off, no request is sent
+			_, err = extractor.ExtractFromURL(context.Background(), server.URL+"/other")
+			assert.ErrorIs(t, err, ErrDeferred)
+			assert.Equal(t, int32(1), requests.Load())
+		})
+	}
+}
@@ -0,0 +1,170 @@
+package content
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"syscall"
+
+	"github.com/shaftoe/savetoink/internal/content/rules"
+)
+
+// ErrForbiddenHost is returned when fetching a URL whose host is denied by configuration
+// or resolves to a loopback, link-local, private or otherwise internal IP address.
+var ErrForbiddenHost = errors.New("forbidden host")
+
+// forbiddenPrefixes lists address ranges not covered by the netip.Addr helpers used in isForbiddenIP.
+var forbiddenPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),         // "this" network
+	netip.MustParsePrefix("100.64.0.0/10"),     // carrier-grade NAT
+	netip.MustParsePrefix("192.0.0.0/24"),      // IETF protocol assignments
+	netip.MustParsePrefix("198.18.0.0/15"),     // benchmarking
+	netip.MustParsePrefix("240.0.0.0/4"),       // reserved, including broadcast
+	netip.MustParsePrefix("64:ff9b::/96"),      // NAT64, may embed internal IPv4 addresses
+	netip.MustParsePrefix("64:ff9b:1::/48"),    // local-use NAT64
+	netip.MustParsePrefix("2001:db8::/32"),     // documentation
+	netip.MustParsePrefix("fd00:ec2::254/128"), // EC2 instance metadata over IPv6, also covered by IsPrivate
+}
+
+// HostPolicy restricts the hosts an Extractor may fetch from.
+type HostPolicy struct {
+	// Allow lists the only hosts that may be fetched, all hosts are allowed when empty.
+	Allow []string
+	// Deny lists hosts that must never be fetched.
+	Deny []string
+}
+
+// check returns ErrForbiddenHost if host is an internal IP address, or is denied or not allowed by the policy.
+func (p HostPolicy) check(host string) error {
+	if addr, err := netip.ParseAddr(host); err == nil && isForbiddenIP(addr) {
+		return fmt.Errorf("%w: %s is an internal address", ErrForbiddenHost, host)
+	}
+
+	for _, pattern := range p.Deny {
+		if rules.MatchHost(pattern, host) {
+			return fmt.Errorf("%w: %s is denied", ErrForbiddenHost, host)
+		}
+	}
+
+	if len(p.Allow) == 0 {
+		return nil
+	}
+
+	for _, pattern := range p.Allow {
+		if rules.MatchHost(pattern, host) {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%w: %s is not allowed", ErrForbiddenHost, host)
+}
+
+// WithSSRFProtection makes the Extractor check hosts against policy and refuse to connect to internal
+// IP addresses. The check happens on the address actually dialed, after DNS resolution, so it also covers
+// redirects and DNS rebinding. When a proxy is configured, see WithFetchConfig, host names are resolved and
+// checked before each request is sent to the proxy, which resolves them again: the proxy must also refuse
+// internal addresses to cover DNS rebinding.
+func WithSSRFProtection(policy HostPolicy) Option {
+	return func(e *Extractor) {
+		e.policy = &policy
+	}
+}
+
+// guardedDialContext returns a DialContext function refusing connections to internal IP addresses,
+// except for the configured proxy which is trusted.
+func guardedDialContext(dialer *net.Dialer, proxy *url.URL) func(context.Context, string, string) (net.Conn, error) {
+	guarded := *dialer
+	guarded.Control = denyInternalAddress
+
+	proxyAddr := ""
+	if proxy != nil {
+		proxyAddr = net.JoinHostPort(proxy.Hostname(), proxyPort(proxy))
+	}
+
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		if addr == proxyAddr {
+			return dialer.DialContext(ctx, network, addr)
+		}
+		return guarded.DialContext(ctx, network, addr)
+	}
+}
+
+// lookupFunc resolves a host name to its IP addresses, see net.Resolver.LookupNetIP.
+type lookupFunc func(ctx context.Context, network, host string) ([]netip.Addr, error)
+
+// guardedProxy returns a Proxy function sending requests through proxy once the host of their URL is checked not
+// to be, or resolve to, an internal IP address.
+func guardedProxy(proxy *url.URL, lookup lookupFunc) func(*http.Request) (*url.URL, error) {
+	return func(req *http.Request) (*url.URL, error) {
+		host := req.URL.Hostname()
+		if addr, err := netip.ParseAddr(host); err == nil {
+			if isForbiddenIP(addr) {
+				return nil, fmt.Errorf("%w: %s is an internal address", ErrForbiddenHost, host)
+			}
+			return proxy, nil
+		}
+
+		addrs, err := lookup(req.Context(), "ip", host)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve %s: %w", host, err)
+		}
+		for _, addr := range addrs {
+			if isForbiddenIP(addr) {
+				return nil, fmt.Errorf("%w: %s resolves to internal address %s", ErrForbiddenHost, host, addr)
+			}
+		}
+
+		return proxy, nil
+	}
+}
+
+func proxyPort(proxy *url.URL) string {
+	if port := proxy.Port(); port != "" {
+		return port
+	}
+
+	switch proxy.Scheme {
+	case "https":
+		return "443"
+	case "socks5":
+		return "1080"
+	default:
+		return "80"
+	}
+}
+
+// denyInternalAddress is a net.Dialer Control function rejecting connections to internal IP addresses.
+func denyInternalAddress(_, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return fmt.Errorf("%w: unparsable address %q", ErrForbiddenHost, address)
+	}
+
+	if isForbiddenIP(addrPort.Addr()) {
+		return fmt.Errorf("%w: %s is an internal address", ErrForbiddenHost, addrPort.Addr())
+	}
+
+	return nil
+}
+
+func isForbiddenIP(addr netip.Addr) bool {
+	addr = addr.Unmap()
+
+	if addr.IsLoopback() || addr.IsPrivate() || addr.IsUnspecified() ||
+		addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() ||
+		addr.IsInterfaceLocalMulticast() || addr.IsMulticast() {
+		return true
+	}
+
+	for _, prefix := range forbiddenPrefixes {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,206 @@
+package content
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"net/url"
+	"testing"
+)
+
+func TestIsForbiddenIP(t *testing.T) {
+	tests := []struct {
+		addr string
+		want bool
+	}{
+		{"127.0.0.1", true},
+		{"::1", true},
+		{"0.0.0.0", true},
+		{"10.1.2.3", true},
+		{"172.16.0.1", true},
+		{"192.168.1.1", true},
+		{"100.64.0.1", true},
+		{"169.254.169.254", true},
+		{"fe80::1", true},
+		{"fd00:ec2::254", true},
+		{"::ffff:127.0.0.1", true},
+		{"::ffff:169.254.169.254", true},
+		{"64:ff9b::a00:1", true},
+		{"224.0.0.1", true},
+		{"255.255.255.255", true},
+		{"93.184.216.34", false},
+		{"2606:2800:220:1:248:1893:25c8:1946", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.addr, func(t *testing.T) {
+			if got := isForbiddenIP(netip.MustParseAddr(tt.addr)); got != tt.want {
+				t.Errorf("isForbiddenIP(%s) = %v, want %v", tt.addr, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHostPolicyCheck(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  HostPolicy
+		host    string
+		wantErr bool
+	}{
+		{"empty policy", HostPolicy{}, "example.com", false},
+		{"internal IP literal", HostPolicy{}, "169.254.169.254", true},
+		{"allowed host", HostPolicy{Allow: []string{"example.com"}}, "example.com", false},
+		{"allowed wildcard", HostPolicy{Allow: []string{"*.example.com"}}, "blog.example.com", false},
+		{"not allowed", HostPolicy{Allow: []string{"example.com"}}, "example.org", true},
+		{"denied host", HostPolicy{Deny: []string{"example.com"}}, "EXAMPLE.com", true},
+		{"deny wins over allow", HostPolicy{Allow: []string{"*.example.com"}, Deny: []string{"admin.example.com"}},
+			"admin.example.com", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.policy.check(tt.host)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("check(%q) error = %v, wantErr %v", tt.host, err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrForbiddenHost) {
+				t.Errorf("expected ErrForbiddenHost, got %v", err)
+			}
+		})
+	}
+}
+
+func TestExtractFromURLWithSSRFProtection(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write([]byte(`<html><body><p>Internal content.</p></body></html>`))
+	}))
+	defer server.Close()
+
+	extractor := NewExtractor(WithSSRFProtection(HostPolicy{}))
+	_, err := extractor.ExtractFromURL(context.Background(), server.URL)
+	if !errors.Is(err, ErrForbiddenHost) {
+		t.Errorf("expected ErrForbiddenHost fetching a loopback address, got %v", err)
+	}
+}
+
+func TestFetchImageWithSSRFProtection(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "image/png")
+		_, _ = w.Write([]byte("internal image"))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name      string
+		url       string
+		forbidden bool
+	}{
+		{"loopback server", server.URL + "/image.png", true},
+		{"instance metadata", "http://169.254.169.254/latest/meta-data/image.png", true},
+		{"private network", "http://10.0.0.1/image.png", true},
+		{"local file", "file:///etc/hostname", false},
+	}
+
+	extractor := NewExtractor(WithSSRFProtection(HostPolicy{}))
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, _, err := extractor.FetchImage(context.Background(), tt.url)
+			if err == nil {
+				t.Fatalf("expected an error fetching %s, got %q", tt.url, data)
+			}
+			if tt.forbidden && !errors.Is(err, ErrForbiddenHost) {
+				t.Errorf("expected ErrForbiddenHost, got %v", err)
+			}
+		})
+	}
+}
+
+func TestSafeClientRedirect(t *testing.T) {
+	client := newHTTPClient(FetchConfig{}.withDefaults(), &HostPolicy{Deny: []string{"denied.example.com"}})
+
+	tests := []struct {
+		name   string
+		target string
+	}{
+		{"denied host", "https://denied.example.com/"},
+		{"unsupported scheme", "file:///etc/passwd"},
+		{"internal IP literal", "http://[::1]/"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.target, http.NoBody)
+			err := client.CheckRedirect(req, []*http.Request{req})
+			if !errors.Is(err, ErrForbiddenHost) {
+				t.Errorf("expected ErrForbiddenHost, got %v", err)
+			}
+		})
+	}
+}
+
+func TestGuardedProxy(t *testing.T) {
+	proxy := &url.URL{Scheme: "http", Host: "proxy.example.com:3128"}
+	lookup := func(_ context.Context, _, host string) ([]netip.Addr, error) {
+		switch host {
+		case "internal.example.com":
+			return []netip.Addr{netip.MustParseAddr("93.184.216.34"), netip.MustParseAddr("10.0.0.1")}, nil
+		case "public.example.com":
+			return []netip.Addr{netip.MustParseAddr("93.184.216.34")}, nil
+		default:
+			return nil, errors.New("no such host")
+		}
+	}
+
+	tests := []struct {
+		name      string
+		target    string
+		wantErr   bool
+		forbidden bool
+	}{
+		{"public host", "https://public.example.com/article", false, false},
+		{"public IP literal", "http://93.184.216.34/article", false, false},
+		{"host resolving to an internal address", "https://internal.example.com/", true, true},
+		{"instance metadata", "http://169.254.169.254/latest/meta-data/", true, true},
+		{"unresolvable host", "https://unknown.example.com/", true, false},
+	}
+
+	proxyFunc := guardedProxy(proxy, lookup)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := proxyFunc(httptest.NewRequest(http.MethodGet, tt.target, http.NoBody))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+			if tt.forbidden && !errors.Is(err, ErrForbiddenHost) {
+				t.Errorf("expected ErrForbiddenHost, got %v", err)
+			}
+			if err == nil && got != proxy {
+				t.Errorf("expected the proxy %s, got %v", proxy, got)
+			}
+		})
+	}
+}
+
+func TestExtractFromURLWithSSRFProtectionAndProxy(t *testing.T) {
+	proxyCalled := false
+	proxyServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		proxyCalled = true
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write([]byte(`<html><body><p>Internal content.</p></body></html>`))
+	}))
+	defer proxyServer.Close()
+
+	proxyURL, _ := url.Parse(proxyServer.URL)
+	extractor := NewExtractor(WithSSRFProtection(HostPolicy{}), WithFetchConfig(FetchConfig{Proxy: proxyURL}))
+	_, err := extractor.ExtractFromURL(context.Background(), "http://localhost/admin")
+	if !errors.Is(err, ErrForbiddenHost) {
+		t.Errorf("expected ErrForbiddenHost fetching localhost through a proxy, got %v", err)
+	}
+	if proxyCalled {
+		t.Error("expected the request not to reach the proxy")
+	}
+}
@@ -0,0 +1,98 @@
+package wordcount
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCountHTML(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    Stats
+	}{
+		{
+			name:    "paragraphs",
+			content: "<p>The quick brown fox.</p><p>Jumps over the lazy dog!</p>",
+			want:    Stats{Words: 9},
+		},
+		{
+			name:    "adjacent blocks separate words",
+			content: "<h2>Title</h2><p>text</p><ul><li>one</li><li>two</li></ul>line<br>break",
+			want:    Stats{Words: 6},
+		},
+		{
+			name:    "inline elements do not separate words",
+			content: "<p>un<em>believ</em>able, it's <a href=\"#\">self-evident</a></p>",
+			want:    Stats{Words: 4},
+		},
+		{
+			name:    "scripts and styles are skipped",
+			content: "<p>Visible text</p><script>var hidden = 'not counted';</script><style>p { color: red; }</style>",
+			want:    Stats{Words: 2},
+		},
+		{
+			name:    "punctuation and numbers",
+			content: "<p>It costs 3.50 euros — or $4 — in 2024 … really?</p>",
+			want:    Stats{Words: 9},
+		},
+		{
+			name:    "chinese",
+			content: "<p>我们今天去公园散步。</p>",
+			want:    Stats{Characters: 9},
+		},
+		{
+			name:    "japanese with furigana",
+			content: "<p><ruby>漢字<rp>(</rp><rt>かんじ</rt><rp>)</rp></ruby>とカタカナ</p>",
+			want:    Stats{Characters: 7},
+		},
+		{
+			name:    "korean words are separated by spaces",
+			content: "<p>오늘 날씨가 좋습니다</p>",
+			want:    Stats{Words: 3},
+		},
+		{
+			name:    "mixed scripts",
+			content: "<p>Go言語は Google が開発した</p>",
+			want:    Stats{Words: 2, Characters: 8},
+		},
+		{
+			name:    "empty",
+			content: "",
+			want:    Stats{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, CountHTML(tt.content))
+		})
+	}
+}
+
+func TestReadingTime(t *testing.T) {
+	tests := []struct {
+		name     string
+		stats    Stats
+		language string
+		want     int
+	}{
+		{name: "english", stats: Stats{Words: 228}, language: "en", want: 1},
+		{name: "english rounded up", stats: Stats{Words: 229}, language: "en-US", want: 2},
+		{name: "german is read slower", stats: Stats{Words: 1000}, language: "de", want: 6},
+		{name: "unknown language", stats: Stats{Words: 500}, language: "xx", want: 2},
+		{name: "no language", stats: Stats{Words: 750}, language: "", want: 3},
+		{name: "japanese characters", stats: Stats{Characters: 3570}, language: "ja", want: 10},
+		{name: "chinese characters", stats: Stats{Characters: 2550}, language: "zh_TW", want: 10},
+		{name: "characters of unknown language", stats: Stats{Characters: 3000}, want: 10},
+		{name: "mixed", stats: Stats{Words: 228, Characters: 357}, language: "ja", want: 2},
+		{name: "empty", stats: Stats{}, language: "en", want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, ReadingTime(tt.stats, tt.language))
+		})
+	}
+}
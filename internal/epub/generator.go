@@ -3,7 +3,9 @@ package epub
 
 import (
 	"bytes"
+	"context"
 	"fmt"
+	"html"
 	"os"
 	"strings"
 
@@ -20,11 +22,28 @@ const (
 )
 
 // Generator handles EPUB file generation from article content.
-type Generator struct{}
+type Generator struct {
+	images ImageFetcher
+}
+
+// Option configures a Generator.
+type Option func(*Generator)
+
+// WithImageFetcher embeds the images of articles downloaded with fetcher, they are left as remote
+// references otherwise.
+func WithImageFetcher(fetcher ImageFetcher) Option {
+	return func(g *Generator) {
+		g.images = fetcher
+	}
+}
 
 // NewGenerator creates a new EPUB generator instance.
-func NewGenerator() *Generator {
-	return &Generator{}
+func NewGenerator(opts ...Option) *Generator {
+	g := &Generator{}
+	for _, opt := range opts {
+		opt(g)
+	}
+	return g
 }
 
 func buildMetadataHeader(article *model.Article) string {
@@ -64,6 +83,16 @@ func buildMetadataHeader(article *model.Article) string {
 		metaLines = append(metaLines, fmt.Sprintf("<p><strong>Type:</strong> %s</p>", contentType))
 	}
 
+	if article.Collection != "" {
+		metaLines = append(metaLines,
+			fmt.Sprintf("<p><strong>Collection:</strong> %s</p>", html.EscapeString(article.Collection)))
+	}
+
+	if len(article.Tags) > 0 {
+		metaLines = append(metaLines,
+			fmt.Sprintf("<p><strong>Tags:</strong> %s</p>", html.EscapeString(strings.Join(article.Tags, ", "))))
+	}
+
 	if len(metaLines) == 0 {
 		return ""
 	}
@@ -75,7 +104,7 @@ func buildMetadataHeader(article *model.Article) string {
 }
 
 // Generate creates an EPUB file from the given article and returns its bytes.
-func (g *Generator) Generate(article *model.Article) ([]byte, error) {
+func (g *Generator) Generate(ctx context.Context, article *model.Article) ([]byte, error) {
 	e, err := epub.NewEpub(article.Title)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create EPUB: %w", err)
@@ -95,17 +124,24 @@ func (g *Generator) Generate(article *model.Article) ([]byte, error) {
 
 	e.SetLang(article.Language)
 	if article.Language == "" {
-		e.SetLang("en")
+		e.SetLang(consts.DefaultLanguage)
 	}
 
-	articleContent := buildMetadataHeader(article) + article.Content
+	l := layoutFor(article)
+	if err = l.apply(e); err != nil {
+		return nil, err
+	}
 
-	_, err = e.AddSection(articleContent, consts.DefaultChapterTitle, consts.DefaultChapterFilename, "")
+	body, err := g.embedImages(ctx, e, article.Content, article.URL)
 	if err != nil {
-		return nil, fmt.Errorf("failed to add chapter: %w", err)
+		return nil, err
 	}
+	withImages := *article
+	withImages.Content = body
 
-	e.EmbedImages()
+	if err = addContent(e, &withImages, &l); err != nil {
+		return nil, err
+	}
 
 	var buffer bytes.Buffer
 	_, err = e.WriteTo(&buffer)
@@ -116,9 +152,33 @@ func (g *Generator) Generate(article *model.Article) ([]byte, error) {
 	return buffer.Bytes(), nil
 }
 
+// addContent adds the article content to the EPUB, split into nested chapters
+// with a table of contents for long articles with headings, as a single section otherwise.
+func addContent(e *epub.Epub, article *model.Article, l *layout) error {
+	header := buildMetadataHeader(article)
+
+	chapters, err := splitChapters(article)
+	if err != nil {
+		return err
+	}
+
+	if len(chapters) > 0 {
+		return addChapters(e, "", chapters, header, l)
+	}
+
+	_, err = e.AddSection(
+		l.wrap(header+article.Content), consts.DefaultChapterTitle, consts.DefaultChapterFilename, l.cssPath,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to add chapter: %w", err)
+	}
+
+	return nil
+}
+
 // GenerateAndWrite generates an EPUB file and writes it to the specified path.
-func (g *Generator) GenerateAndWrite(article *model.Article, outputPath string) error {
-	data, err := g.Generate(article)
+func (g *Generator) GenerateAndWrite(ctx context.Context, article *model.Article, outputPath string) error {
+	data, err := g.Generate(ctx, article)
 	if err != nil {
 		return err
 	}
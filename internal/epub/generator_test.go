@@ -1,6 +1,8 @@
 package epub
 
 import (
+	"context"
+	"strings"
 	"testing"
 	"time"
 
@@ -22,7 +24,7 @@ func TestGenerate_Success(t *testing.T) {
 		Author:  "Test Author",
 	}
 
-	data, err := gen.Generate(article)
+	data, err := gen.Generate(context.Background(), article)
 
 	if err != nil {
 		t.Fatalf("Generate() unexpected error = %v", err)
@@ -52,7 +54,7 @@ func TestGenerate_WithMetadata(t *testing.T) {
 		CreatedAt:          time.Now().UTC(),
 	}
 
-	data, err := gen.Generate(article)
+	data, err := gen.Generate(context.Background(), article)
 
 	if err != nil {
 		t.Fatalf("Generate() unexpected error = %v", err)
@@ -67,6 +69,20 @@ func TestGenerate_WithMetadata(t *testing.T) {
 	}
 }
 
+func TestBuildMetadataHeader_Tags(t *testing.T) {
+	article := &model.Article{Tags: []string{"go", "c&c"}, Collection: "Reading <list>"}
+
+	header := buildMetadataHeader(article)
+
+	if !strings.Contains(header, "<p><strong>Tags:</strong> go, c&amp;c</p>") {
+		t.Errorf("buildMetadataHeader() = %q, expected the escaped tags", header)
+	}
+
+	if !strings.Contains(header, "<p><strong>Collection:</strong> Reading &lt;list&gt;</p>") {
+		t.Errorf("buildMetadataHeader() = %q, expected the escaped collection", header)
+	}
+}
+
 func TestGenerate_EmptyTitle(t *testing.T) {
 	gen := NewGenerator()
 	article := &model.Article{
@@ -74,7 +90,7 @@ func TestGenerate_EmptyTitle(t *testing.T) {
 		Content: "<p>This is test content</p>",
 	}
 
-	data, err := gen.Generate(article)
+	data, err := gen.Generate(context.Background(), article)
 
 	if err != nil {
 		t.Fatalf("Generate() unexpected error = %v", err)
@@ -92,7 +108,7 @@ func TestGenerate_EmptyContent(t *testing.T) {
 		Content: "",
 	}
 
-	data, err := gen.Generate(article)
+	data, err := gen.Generate(context.Background(), article)
 
 	if err != nil {
 		t.Fatalf("Generate() unexpected error = %v", err)
@@ -111,7 +127,7 @@ func TestGenerate_WithLanguage(t *testing.T) {
 		Language: "en",
 	}
 
-	data, err := gen.Generate(article)
+	data, err := gen.Generate(context.Background(), article)
 
 	if err != nil {
 		t.Fatalf("Generate() unexpected error = %v", err)
@@ -130,7 +146,7 @@ func TestGenerate_WithExcerpt(t *testing.T) {
 		Excerpt: "This is a test excerpt",
 	}
 
-	data, err := gen.Generate(article)
+	data, err := gen.Generate(context.Background(), article)
 
 	if err != nil {
 		t.Fatalf("Generate() unexpected error = %v", err)
@@ -149,7 +165,7 @@ func TestGenerate_WithImage(t *testing.T) {
 		ImageURL: "https://example.com/image.jpg",
 	}
 
-	data, err := gen.Generate(article)
+	data, err := gen.Generate(context.Background(), article)
 
 	if err != nil {
 		t.Fatalf("Generate() unexpected error = %v", err)
@@ -168,7 +184,7 @@ func TestGenerate_ZeroReadingTime(t *testing.T) {
 		ReadingTimeMinutes: 0,
 	}
 
-	data, err := gen.Generate(article)
+	data, err := gen.Generate(context.Background(), article)
 
 	if err != nil {
 		t.Fatalf("Generate() unexpected error = %v", err)
@@ -187,7 +203,7 @@ func TestGenerate_NilPublishedAt(t *testing.T) {
 		PublishedAt: nil,
 	}
 
-	data, err := gen.Generate(article)
+	data, err := gen.Generate(context.Background(), article)
 
 	if err != nil {
 		t.Fatalf("Generate() unexpected error = %v", err)
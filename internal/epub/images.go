@@ -0,0 +1,175 @@
+package epub
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"log"
+	"mime"
+	"net/url"
+	"strings"
+
+	"github.com/go-shiori/go-epub"
+	"github.com/shaftoe/savetoink/internal/consts"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// ImageFetcher downloads the images embedded in EPUB files.
+type ImageFetcher interface {
+	// FetchImage returns the content and media type of the image at urlStr.
+	FetchImage(ctx context.Context, urlStr string) ([]byte, string, error)
+}
+
+// imageExtensions maps the media types of embeddable images to their file extension.
+var imageExtensions = map[string]string{
+	"image/jpeg":    ".jpg",
+	"image/png":     ".png",
+	"image/gif":     ".gif",
+	"image/webp":    ".webp",
+	"image/svg+xml": ".svg",
+}
+
+// embedImages removes the images of content whose source is not an absolute http(s) URL once resolved against
+// baseURL, e.g. local file paths, and downloads the others with the fetcher of the generator, if any, adding them
+// to e. It returns content with the sources of the embedded images pointing to their file in the EPUB, images that
+// can't be downloaded are left as remote references. Image sources are never handed over to go-epub, which would
+// read local files and download URLs with its own client, without SSRF protection.
+func (g *Generator) embedImages(ctx context.Context, e *epub.Epub, content, baseURL string) (string, error) {
+	if !strings.Contains(content, "<img") {
+		return content, nil
+	}
+
+	root, err := parseContent(content)
+	if err != nil {
+		return "", err
+	}
+
+	base, _ := url.Parse(baseURL)
+	if !isRemoteURL(base) {
+		base = nil
+	}
+
+	embedded := make(map[string]string)
+	walkElements(root, func(node *html.Node) {
+		if node.DataAtom != atom.Img {
+			return
+		}
+
+		src := resolveImage(base, getAttr(node, "src"))
+		if src == "" {
+			if node.Parent != nil {
+				node.Parent.RemoveChild(node)
+			}
+			return
+		}
+		setAttr(node, "src", src)
+
+		if g.images == nil {
+			return
+		}
+		path, ok := embedded[src]
+		if !ok {
+			if len(embedded) >= consts.EPUBMaxImages {
+				return
+			}
+			path = g.embedImage(ctx, e, src, len(embedded)+1)
+			embedded[src] = path
+		}
+		if path != "" {
+			setAttr(node, "src", path)
+		}
+	})
+
+	return renderContent(root)
+}
+
+// embedImage downloads the image at src and adds it to e as the n-th image, returning its path in the EPUB,
+// empty if it can't be downloaded.
+func (g *Generator) embedImage(ctx context.Context, e *epub.Epub, src string, n int) string {
+	data, contentType, err := g.images.FetchImage(ctx, src)
+	if err != nil {
+		log.Printf("warning: image %s not embedded: %v", src, err)
+		return ""
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	ext, ok := imageExtensions[mediaType]
+	if !ok {
+		log.Printf("warning: image %s not embedded: unsupported media type %q", src, contentType)
+		return ""
+	}
+
+	source := "data:" + mediaType + ";base64," + base64.StdEncoding.EncodeToString(data)
+	path, err := e.AddImage(source, fmt.Sprintf(consts.ImageFilenameFormat, n, ext))
+	if err != nil {
+		log.Printf("warning: image %s not embedded: %v", src, err)
+		return ""
+	}
+
+	return path
+}
+
+// resolveImage resolves the image source src against base, if any, returning an empty string unless it is an
+// absolute http(s) URL.
+func resolveImage(base *url.URL, src string) string {
+	u, err := url.Parse(strings.TrimSpace(src))
+	if err != nil || src == "" {
+		return ""
+	}
+	if base != nil {
+		u = base.ResolveReference(u)
+	}
+	if !isRemoteURL(u) {
+		return ""
+	}
+	return u.String()
+}
+
+// isRemoteURL reports whether u is an absolute http(s) URL.
+func isRemoteURL(u *url.URL) bool {
+	return u != nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+func parseContent(content string) (*html.Node, error) {
+	body := &html.Node{Type: html.ElementNode, Data: "body", DataAtom: atom.Body}
+
+	nodes, err := html.ParseFragment(strings.NewReader(content), body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse content: %w", err)
+	}
+	for _, node := range nodes {
+		body.AppendChild(node)
+	}
+
+	return body, nil
+}
+
+func renderContent(root *html.Node) (string, error) {
+	var sb strings.Builder
+	for child := root.FirstChild; child != nil; child = child.NextSibling {
+		if err := html.Render(&sb, child); err != nil {
+			return "", fmt.Errorf("failed to render content: %w", err)
+		}
+	}
+	return sb.String(), nil
+}
+
+func getAttr(node *html.Node, key string) string {
+	for _, attr := range node.Attr {
+		if attr.Key == key {
+			return attr.Val
+		}
+	}
+	return ""
+}
+
+func setAttr(node *html.Node, key, val string) {
+	for i, attr := range node.Attr {
+		if attr.Key == key {
+			node.Attr[i].Val = val
+			return
+		}
+	}
+	node.Attr = append(node.Attr, html.Attribute{Key: key, Val: val})
+}
@@ -0,0 +1,130 @@
+package epub
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/shaftoe/savetoink/internal/model"
+)
+
+// stubFetcher serves the images it holds and records the URLs requested.
+type stubFetcher struct {
+	images    map[string]string
+	requested []string
+}
+
+func (f *stubFetcher) FetchImage(_ context.Context, urlStr string) ([]byte, string, error) {
+	f.requested = append(f.requested, urlStr)
+	image, ok := f.images[urlStr]
+	if !ok {
+		return nil, "", errors.New("not found")
+	}
+	return []byte(image), "image/png", nil
+}
+
+func TestGenerate_EmbedsImagesWithFetcher(t *testing.T) {
+	fetcher := &stubFetcher{images: map[string]string{"https://example.com/a.png": "png data"}}
+	gen := NewGenerator(WithImageFetcher(fetcher))
+
+	data, err := gen.Generate(context.Background(), &model.Article{
+		Title: "Images",
+		Content: `<p><img src="https://example.com/a.png"/><img src="https://example.com/a.png"/>` +
+			`<img src="https://example.com/missing.png"/></p>`,
+	})
+	if err != nil {
+		t.Fatalf("Generate() unexpected error = %v", err)
+	}
+
+	if got := strings.Join(fetcher.requested, ","); got != "https://example.com/a.png,https://example.com/missing.png" {
+		t.Errorf("expected each image to be fetched once, got %s", got)
+	}
+
+	files := readEPUBFiles(t, data)
+	if image, ok := files["EPUB/images/image001.png"]; !ok || !strings.HasSuffix(image, "png data") {
+		t.Errorf("expected the fetched image to be embedded, got files %v", fileNames(files))
+	}
+
+	chapter := filesWithSuffix(files, ".xhtml")
+	if strings.Contains(chapter, `src="https://example.com/a.png"`) {
+		t.Error("expected the embedded image to point to the EPUB file")
+	}
+	if !strings.Contains(chapter, `src="https://example.com/missing.png"`) {
+		t.Error("expected the image failing to download to stay a remote reference")
+	}
+}
+
+func TestGenerate_WithoutFetcherDoesNotDownloadImages(t *testing.T) {
+	data, err := NewGenerator().Generate(context.Background(), &model.Article{
+		Title:   "Images",
+		Content: `<p><img src="http://169.254.169.254/latest/meta-data/image.png"/></p>`,
+	})
+	if err != nil {
+		t.Fatalf("Generate() unexpected error = %v", err)
+	}
+
+	for name := range readEPUBFiles(t, data) {
+		if strings.HasPrefix(name, "EPUB/images/") {
+			t.Errorf("expected no image to be embedded, got %s", name)
+		}
+	}
+}
+
+func TestGenerate_RemovesLocalImages(t *testing.T) {
+	tests := []struct {
+		name          string
+		url           string
+		src           string
+		wantRequested string
+	}{
+		{"absolute path without base", "", "/etc/hostname", ""},
+		{"file URL", "https://en.wikipedia.org/wiki/Go", "file:///etc/hostname", ""},
+		{"data URL", "https://en.wikipedia.org/wiki/Go", "data:text/plain;base64,aG9zdG5hbWU=", ""},
+		{"absolute path resolved against the article URL", "https://en.wikipedia.org/wiki/Go", "/etc/hostname",
+			"https://en.wikipedia.org/etc/hostname"},
+		{"relative path resolved against the article URL", "https://example.com/blog/post", "img/a.png",
+			"https://example.com/blog/img/a.png"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fetcher := &stubFetcher{}
+			data, err := NewGenerator(WithImageFetcher(fetcher)).Generate(context.Background(), &model.Article{
+				Title:   "Images",
+				URL:     tt.url,
+				Content: `<p>text<img src="` + tt.src + `"/></p>`,
+			})
+			if err != nil {
+				t.Fatalf("Generate() unexpected error = %v", err)
+			}
+
+			if got := strings.Join(fetcher.requested, ","); got != tt.wantRequested {
+				t.Errorf("expected requested images %q, got %q", tt.wantRequested, got)
+			}
+
+			files := readEPUBFiles(t, data)
+			for name := range files {
+				if strings.HasPrefix(name, "EPUB/images/") {
+					t.Errorf("expected no image to be embedded, got %s", name)
+				}
+			}
+
+			chapter := filesWithSuffix(files, ".xhtml")
+			if tt.wantRequested == "" && strings.Contains(chapter, "<img") {
+				t.Errorf("expected the image to be removed, got %s", chapter)
+			}
+			if tt.wantRequested != "" && !strings.Contains(chapter, `src="`+tt.wantRequested+`"`) {
+				t.Errorf("expected the image to point to %s, got %s", tt.wantRequested, chapter)
+			}
+		})
+	}
+}
+
+func fileNames(files map[string]string) []string {
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	return names
+}
@@ -0,0 +1,118 @@
+package epub
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"github.com/go-shiori/go-epub"
+	"github.com/shaftoe/savetoink/internal/consts"
+	"github.com/shaftoe/savetoink/internal/model"
+)
+
+// rtlLanguages lists the base language subtags written in right-to-left scripts.
+var rtlLanguages = map[string]bool{
+	"ar":  true, // Arabic
+	"ckb": true, // Central Kurdish
+	"dv":  true, // Dhivehi
+	"fa":  true, // Persian
+	"he":  true, // Hebrew
+	"iw":  true, // Hebrew, deprecated code
+	"ps":  true, // Pashto
+	"sd":  true, // Sindhi
+	"ug":  true, // Uyghur
+	"ur":  true, // Urdu
+	"yi":  true, // Yiddish
+}
+
+// verticalLanguages lists the base language subtags supporting vertical writing mode.
+var verticalLanguages = map[string]bool{
+	"ja": true, // Japanese
+}
+
+const verticalCSS = `html {
+  -epub-writing-mode: vertical-rl;
+  -webkit-writing-mode: vertical-rl;
+  writing-mode: vertical-rl;
+}
+`
+
+// layout holds the text direction and writing mode applied to every EPUB section.
+type layout struct {
+	direction consts.TextDirection
+	vertical  bool
+	cssPath   string
+}
+
+// layoutFor returns the layout of the article: the explicit Direction if set, the one of
+// its Language otherwise. Vertical writing is applied only to languages supporting it.
+func layoutFor(article *model.Article) layout {
+	l := layout{
+		direction: article.Direction,
+		vertical:  article.VerticalWriting && verticalLanguages[baseLanguage(article.Language)],
+	}
+
+	if l.direction == "" {
+		l.direction = directionFor(article.Language)
+	}
+
+	return l
+}
+
+// ParseDirection parses a text direction override, "auto" or empty meaning the direction
+// is derived from the article language.
+func ParseDirection(direction string) (consts.TextDirection, error) {
+	switch consts.TextDirection(direction) {
+	case "", "auto":
+		return "", nil
+	case consts.DirectionLTR, consts.DirectionRTL:
+		return consts.TextDirection(direction), nil
+	default:
+		return "", fmt.Errorf("unsupported text direction %q, must be one of: auto, %s, %s",
+			direction, consts.DirectionLTR, consts.DirectionRTL)
+	}
+}
+
+// directionFor returns the text direction of the script used by a BCP 47 language tag, e.g. "ar-EG".
+func directionFor(lang string) consts.TextDirection {
+	if rtlLanguages[baseLanguage(lang)] {
+		return consts.DirectionRTL
+	}
+	return consts.DirectionLTR
+}
+
+func baseLanguage(lang string) string {
+	base, _, _ := strings.Cut(strings.ToLower(strings.TrimSpace(lang)), "-")
+	base, _, _ = strings.Cut(base, "_")
+	return base
+}
+
+// apply sets the page progression direction and adds the vertical writing stylesheet if needed.
+// Vertical text flows right-to-left across pages, like right-to-left scripts.
+func (l *layout) apply(e *epub.Epub) error {
+	if l.direction == consts.DirectionRTL || l.vertical {
+		e.SetPpd(string(consts.DirectionRTL))
+	}
+
+	if !l.vertical {
+		return nil
+	}
+
+	source := "data:text/css;base64," + base64.StdEncoding.EncodeToString([]byte(verticalCSS))
+	cssPath, err := e.AddCSS(source, consts.VerticalCSSFilename)
+	if err != nil {
+		return fmt.Errorf("failed to add vertical writing CSS: %w", err)
+	}
+	l.cssPath = cssPath
+
+	return nil
+}
+
+// wrap sets the text direction on section content. go-epub always renders <body dir="auto">,
+// which is not enough when the article mixes scripts.
+func (l *layout) wrap(body string) string {
+	if l.direction != consts.DirectionRTL {
+		return body
+	}
+	return `<div dir="rtl">` + body + `</div>`
+}
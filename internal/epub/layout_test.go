@@ -0,0 +1,140 @@
+package epub
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/shaftoe/savetoink/internal/consts"
+	"github.com/shaftoe/savetoink/internal/model"
+)
+
+func TestDirectionFor(t *testing.T) {
+	tests := []struct {
+		lang     string
+		expected consts.TextDirection
+	}{
+		{lang: "ar", expected: consts.DirectionRTL},
+		{lang: "he-IL", expected: consts.DirectionRTL},
+		{lang: "FA_ir", expected: consts.DirectionRTL},
+		{lang: "en", expected: consts.DirectionLTR},
+		{lang: "ja", expected: consts.DirectionLTR},
+		{lang: "", expected: consts.DirectionLTR},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.lang, func(t *testing.T) {
+			if direction := directionFor(tt.lang); direction != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, direction)
+			}
+		})
+	}
+}
+
+func TestGenerate_Layout(t *testing.T) {
+	tests := []struct {
+		name        string
+		article     *model.Article
+		contains    map[string]string
+		notContains map[string]string
+	}{
+		{
+			name:    "rtl from language",
+			article: &model.Article{Title: "مقال", Content: "<p>مرحبا</p>", Language: "ar"},
+			contains: map[string]string{
+				".opf":           `page-progression-direction="rtl"`,
+				"chapter1.xhtml": `<div dir="rtl">`,
+			},
+			notContains: map[string]string{"EPUB/": consts.VerticalCSSFilename},
+		},
+		{
+			name: "ltr override",
+			article: &model.Article{
+				Title: "Article", Content: "<p>Hello</p>", Language: "ar", Direction: consts.DirectionLTR,
+			},
+			notContains: map[string]string{
+				".opf":           `page-progression-direction="rtl"`,
+				"chapter1.xhtml": `dir="rtl"`,
+			},
+		},
+		{
+			name:    "vertical japanese",
+			article: &model.Article{Title: "記事", Content: "<p>こんにちは</p>", Language: "ja", VerticalWriting: true},
+			contains: map[string]string{
+				".opf":                     `page-progression-direction="rtl"`,
+				consts.VerticalCSSFilename: "writing-mode: vertical-rl",
+				"chapter1.xhtml":           consts.VerticalCSSFilename,
+			},
+			notContains: map[string]string{"chapter1.xhtml": `dir="rtl"`},
+		},
+		{
+			name:        "vertical ignored for latin script",
+			article:     &model.Article{Title: "Article", Content: "<p>Hello</p>", Language: "en", VerticalWriting: true},
+			notContains: map[string]string{".opf": `page-progression-direction`, "EPUB/": consts.VerticalCSSFilename},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := NewGenerator().Generate(context.Background(), tt.article)
+			if err != nil {
+				t.Fatalf("Generate() unexpected error = %v", err)
+			}
+
+			if reportErr := Validate(data).Err(); reportErr != nil {
+				t.Fatalf("Validate() unexpected error = %v", reportErr)
+			}
+
+			files := readEPUBFiles(t, data)
+
+			for suffix, expected := range tt.contains {
+				if !strings.Contains(filesWithSuffix(files, suffix), expected) {
+					t.Errorf("expected %s to contain %q", suffix, expected)
+				}
+			}
+
+			for suffix, unexpected := range tt.notContains {
+				if strings.Contains(filesWithSuffix(files, suffix), unexpected) {
+					t.Errorf("expected %s not to contain %q", suffix, unexpected)
+				}
+			}
+		})
+	}
+}
+
+// readEPUBFiles returns the EPUB archive entries keyed by name, the name itself being
+// prepended to the content so file names can be matched too.
+func readEPUBFiles(t *testing.T, data []byte) map[string]string {
+	t.Helper()
+
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("failed to read EPUB: %v", err)
+	}
+
+	files := make(map[string]string, len(zr.File))
+	for _, f := range zr.File {
+		rc, openErr := f.Open()
+		if openErr != nil {
+			t.Fatalf("failed to open %s: %v", f.Name, openErr)
+		}
+		content, _ := io.ReadAll(rc)
+		_ = rc.Close()
+		files[f.Name] = f.Name + "\n" + string(content)
+	}
+
+	return files
+}
+
+func filesWithSuffix(files map[string]string, suffix string) string {
+	var sb strings.Builder
+	for name, content := range files {
+		if strings.HasSuffix(name, suffix) || strings.HasPrefix(name, suffix) {
+			sb.WriteString(content)
+		}
+	}
+	return sb.String()
+}
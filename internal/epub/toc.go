@@ -0,0 +1,264 @@
+package epub
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/go-shiori/go-epub"
+	"github.com/shaftoe/savetoink/internal/consts"
+	"github.com/shaftoe/savetoink/internal/model"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// chapter is a section of the article content delimited by a heading.
+type chapter struct {
+	title    string
+	level    int
+	filename string
+	nodes    []*html.Node
+	body     string
+	children []*chapter
+}
+
+var headingLevels = map[atom.Atom]int{
+	atom.H1: 1,
+	atom.H2: 2,
+	atom.H3: 3,
+}
+
+var wrapperElements = map[atom.Atom]bool{
+	atom.Div:     true,
+	atom.Section: true,
+	atom.Article: true,
+	atom.Main:    true,
+	atom.Body:    true,
+}
+
+// splitChapters splits the article content at h1/h2/h3 boundaries and nests the resulting
+// chapters by heading level. Content preceding the first heading becomes an introductory
+// chapter titled after the article. Returns nil if the content should stay a single section.
+func splitChapters(article *model.Article) ([]*chapter, error) {
+	if article.WordCount < consts.TOCMinWords {
+		return nil, nil
+	}
+
+	nodes, err := html.ParseFragment(strings.NewReader(article.Content), &html.Node{
+		Type:     html.ElementNode,
+		Data:     "body",
+		DataAtom: atom.Body,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse content: %w", err)
+	}
+
+	var flat []*chapter
+	current := &chapter{title: introTitle(article)}
+	headings := 0
+
+	for _, node := range unwrapContainer(nodes) {
+		if level, title := headingOf(node); level > 0 {
+			if current.level > 0 || !isBlank(current.nodes) {
+				flat = append(flat, current)
+			}
+			current = &chapter{title: title, level: level}
+			headings++
+		}
+		current.nodes = append(current.nodes, node)
+	}
+	flat = append(flat, current)
+
+	if headings < consts.TOCMinHeadings {
+		return nil, nil
+	}
+
+	// chapters are added to the EPUB in document order, see addChapters
+	for i, c := range flat {
+		c.filename = fmt.Sprintf(consts.ChapterFilenameFormat, i+1)
+	}
+	resolveFragmentLinks(flat)
+
+	for _, c := range flat {
+		var buf bytes.Buffer
+		for _, node := range c.nodes {
+			if renderErr := html.Render(&buf, node); renderErr != nil {
+				return nil, fmt.Errorf("failed to render content: %w", renderErr)
+			}
+		}
+		c.body = buf.String()
+	}
+
+	return nestChapters(flat), nil
+}
+
+// resolveFragmentLinks rewrites "#id" links pointing to an element that ended up
+// in another chapter, e.g. footnote references, to "chapterN.xhtml#id".
+func resolveFragmentLinks(chapters []*chapter) {
+	idFiles := make(map[string]string)
+	for _, c := range chapters {
+		for _, node := range c.nodes {
+			walkElements(node, func(n *html.Node) {
+				for _, attr := range n.Attr {
+					if attr.Key == "id" && attr.Val != "" {
+						idFiles[attr.Val] = c.filename
+					}
+				}
+			})
+		}
+	}
+
+	for _, c := range chapters {
+		for _, node := range c.nodes {
+			walkElements(node, func(n *html.Node) {
+				if n.DataAtom != atom.A {
+					return
+				}
+				for i, attr := range n.Attr {
+					if attr.Key != "href" || !strings.HasPrefix(attr.Val, "#") {
+						continue
+					}
+					if file, ok := idFiles[attr.Val[1:]]; ok && file != c.filename {
+						n.Attr[i].Val = file + attr.Val
+					}
+				}
+			})
+		}
+	}
+}
+
+// nestChapters builds the chapter tree: each heading chapter becomes a child of the
+// closest preceding chapter with a lower heading level.
+func nestChapters(flat []*chapter) []*chapter {
+	var roots, stack []*chapter
+
+	for _, c := range flat {
+		if c.level == 0 {
+			roots = append(roots, c)
+			continue
+		}
+
+		for len(stack) > 0 && stack[len(stack)-1].level >= c.level {
+			stack = stack[:len(stack)-1]
+		}
+
+		if len(stack) == 0 {
+			roots = append(roots, c)
+		} else {
+			parent := stack[len(stack)-1]
+			parent.children = append(parent.children, c)
+		}
+
+		stack = append(stack, c)
+	}
+
+	return roots
+}
+
+// addChapters adds the chapter tree to the EPUB with the given layout,
+// the first chapter being prefixed with header.
+func addChapters(e *epub.Epub, parentFilename string, chapters []*chapter, header string, l *layout) error {
+	for _, c := range chapters {
+		body := c.body
+		if c.filename == fmt.Sprintf(consts.ChapterFilenameFormat, 1) {
+			body = header + body
+		}
+		body = l.wrap(body)
+
+		var err error
+		if parentFilename == "" {
+			_, err = e.AddSection(body, c.title, c.filename, l.cssPath)
+		} else {
+			_, err = e.AddSubSection(parentFilename, body, c.title, c.filename, l.cssPath)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to add chapter %q: %w", c.title, err)
+		}
+
+		if err = addChapters(e, c.filename, c.children, header, l); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// unwrapContainer descends into wrapper elements that hold the whole content,
+// so headings nested in a single <div> or <article> are still found.
+func unwrapContainer(nodes []*html.Node) []*html.Node {
+	for {
+		var element *html.Node
+		count := 0
+		for _, node := range nodes {
+			if node.Type == html.TextNode && strings.TrimSpace(node.Data) == "" {
+				continue
+			}
+			element = node
+			count++
+		}
+
+		if count != 1 || element.Type != html.ElementNode || !wrapperElements[element.DataAtom] {
+			return nodes
+		}
+
+		nodes = nil
+		for child := element.FirstChild; child != nil; child = child.NextSibling {
+			nodes = append(nodes, child)
+		}
+	}
+}
+
+func headingOf(node *html.Node) (level int, title string) {
+	if node.Type != html.ElementNode {
+		return 0, ""
+	}
+
+	level, ok := headingLevels[node.DataAtom]
+	if !ok {
+		return 0, ""
+	}
+
+	title = strings.Join(strings.Fields(textContent(node)), " ")
+	if title == "" {
+		return 0, ""
+	}
+
+	return level, title
+}
+
+func walkElements(node *html.Node, fn func(*html.Node)) {
+	if node.Type == html.ElementNode {
+		fn(node)
+	}
+	for child := node.FirstChild; child != nil; child = child.NextSibling {
+		walkElements(child, fn)
+	}
+}
+
+func isBlank(nodes []*html.Node) bool {
+	for _, node := range nodes {
+		if node.Type != html.TextNode || strings.TrimSpace(node.Data) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+func textContent(node *html.Node) string {
+	if node.Type == html.TextNode {
+		return node.Data
+	}
+
+	var sb strings.Builder
+	for child := node.FirstChild; child != nil; child = child.NextSibling {
+		sb.WriteString(textContent(child))
+	}
+	return sb.String()
+}
+
+func introTitle(article *model.Article) string {
+	if article.Title != "" {
+		return article.Title
+	}
+	return consts.DefaultChapterTitle
+}
@@ -0,0 +1,165 @@
+package epub
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/shaftoe/savetoink/internal/consts"
+	"github.com/shaftoe/savetoink/internal/model"
+)
+
+const testLongContent = `<div><p>Introduction paragraph.</p>` +
+	`<h2>Installation</h2><p>Install it.</p>` +
+	`<h3>From source</h3><p>Build it.</p>` +
+	`<h3>From packages</h3><p>Download it.</p>` +
+	`<h2>Usage</h2><p>Use it.</p></div>`
+
+func TestSplitChapters_Nested(t *testing.T) {
+	article := &model.Article{
+		Title:     "Manual",
+		Content:   testLongContent,
+		WordCount: consts.TOCMinWords,
+	}
+
+	chapters, err := splitChapters(article)
+	if err != nil {
+		t.Fatalf("splitChapters() unexpected error = %v", err)
+	}
+
+	if len(chapters) != 3 {
+		t.Fatalf("expected 3 root chapters, got %d", len(chapters))
+	}
+
+	if chapters[0].title != "Manual" || !strings.Contains(chapters[0].body, "Introduction") {
+		t.Errorf("expected intro chapter titled after article, got %q: %q", chapters[0].title, chapters[0].body)
+	}
+
+	installation := chapters[1]
+	if installation.title != "Installation" {
+		t.Errorf("expected second chapter 'Installation', got %q", installation.title)
+	}
+	if len(installation.children) != 2 {
+		t.Fatalf("expected 2 subchapters, got %d", len(installation.children))
+	}
+	if installation.children[1].title != "From packages" {
+		t.Errorf("expected subchapter 'From packages', got %q", installation.children[1].title)
+	}
+	if !strings.Contains(installation.body, "<h2>Installation</h2>") {
+		t.Errorf("expected heading kept in chapter body, got %q", installation.body)
+	}
+
+	if chapters[2].title != "Usage" || len(chapters[2].children) != 0 {
+		t.Errorf("expected last chapter 'Usage' without children, got %q", chapters[2].title)
+	}
+}
+
+func TestSplitChapters_ShortArticle(t *testing.T) {
+	tests := []struct {
+		name    string
+		article *model.Article
+	}{
+		{
+			name:    "below word threshold",
+			article: &model.Article{Content: testLongContent, WordCount: consts.TOCMinWords - 1},
+		},
+		{
+			name: "not enough headings",
+			article: &model.Article{
+				Content:   "<p>Intro</p><h2>Only heading</h2><p>Body</p>",
+				WordCount: consts.TOCMinWords,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chapters, err := splitChapters(tt.article)
+			if err != nil {
+				t.Fatalf("splitChapters() unexpected error = %v", err)
+			}
+			if chapters != nil {
+				t.Errorf("expected single section, got %d chapters", len(chapters))
+			}
+		})
+	}
+}
+
+func TestGenerate_TableOfContents(t *testing.T) {
+	article := &model.Article{
+		Title:     "Manual",
+		Content:   testLongContent,
+		WordCount: consts.TOCMinWords,
+	}
+
+	data, err := NewGenerator().Generate(context.Background(), article)
+	if err != nil {
+		t.Fatalf("Generate() unexpected error = %v", err)
+	}
+
+	if reportErr := Validate(data).Err(); reportErr != nil {
+		t.Fatalf("Validate() unexpected error = %v", reportErr)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("failed to read EPUB: %v", err)
+	}
+
+	var ncx string
+	sections := 0
+	for _, f := range zr.File {
+		if strings.HasPrefix(f.Name, "EPUB/xhtml/chapter") {
+			sections++
+		}
+		if strings.HasSuffix(f.Name, ".ncx") {
+			rc, openErr := f.Open()
+			if openErr != nil {
+				t.Fatalf("failed to open NCX: %v", openErr)
+			}
+			content, _ := io.ReadAll(rc)
+			_ = rc.Close()
+			ncx = string(content)
+		}
+	}
+
+	if sections != 5 {
+		t.Errorf("expected 5 chapter files, got %d", sections)
+	}
+
+	for _, title := range []string{"Manual", "Installation", "From source", "From packages", "Usage"} {
+		if !strings.Contains(ncx, title) {
+			t.Errorf("expected NCX to contain %q", title)
+		}
+	}
+}
+
+func TestSplitChapters_ResolvesCrossChapterLinks(t *testing.T) {
+	article := &model.Article{
+		Title: "Manual",
+		Content: `<h2>First</h2><p>See note<a href="#note-1">1</a> and <a href="#local">here</a>.</p>` +
+			`<p id="local">Local target</p>` +
+			`<h2>Notes</h2><aside id="note-1">The note.</aside>`,
+		WordCount: consts.TOCMinWords,
+	}
+
+	chapters, err := splitChapters(article)
+	if err != nil {
+		t.Fatalf("splitChapters() unexpected error = %v", err)
+	}
+
+	if len(chapters) != 2 {
+		t.Fatalf("expected 2 chapters, got %d", len(chapters))
+	}
+
+	if !strings.Contains(chapters[0].body, `href="chapter2.xhtml#note-1"`) {
+		t.Errorf("expected link to other chapter to be rewritten, got %q", chapters[0].body)
+	}
+
+	if !strings.Contains(chapters[0].body, `href="#local"`) {
+		t.Errorf("expected link within the same chapter to be kept, got %q", chapters[0].body)
+	}
+}
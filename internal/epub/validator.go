@@ -0,0 +1,384 @@
+package epub
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"path"
+	"strings"
+)
+
+const (
+	mimetypeFilename  = "mimetype"
+	mimetypeContent   = "application/epub+zip"
+	containerFilename = "META-INF/container.xml"
+	mediaTypeOPF      = "application/oebps-package+xml"
+	mediaTypeXHTML    = "application/xhtml+xml"
+	mediaTypeNCX      = "application/x-dtbncx+xml"
+	navProperty       = "nav"
+)
+
+// ErrInvalidEPUB is returned when an EPUB package contains fatal structural problems.
+var ErrInvalidEPUB = errors.New("invalid EPUB")
+
+// Severity classifies a validation issue.
+type Severity string
+
+const (
+	// SeverityWarning indicates a problem that e-readers usually tolerate.
+	SeverityWarning Severity = "warning"
+	// SeverityFatal indicates a problem that makes e-readers reject the EPUB.
+	SeverityFatal Severity = "fatal"
+)
+
+// Issue describes a single problem found in an EPUB package.
+type Issue struct {
+	Severity Severity
+	File     string
+	Message  string
+}
+
+// String returns a human readable representation of the issue.
+func (i Issue) String() string {
+	if i.File == "" {
+		return i.Message
+	}
+	return i.File + ": " + i.Message
+}
+
+// ValidationReport holds all issues found while validating an EPUB package.
+type ValidationReport struct {
+	Issues []Issue
+}
+
+// Warnings returns the non-fatal issues as strings.
+func (r *ValidationReport) Warnings() []string {
+	return r.messages(SeverityWarning)
+}
+
+// Fatal returns the fatal issues as strings.
+func (r *ValidationReport) Fatal() []string {
+	return r.messages(SeverityFatal)
+}
+
+// Err returns an error wrapping ErrInvalidEPUB if any fatal issue was found, nil otherwise.
+func (r *ValidationReport) Err() error {
+	fatal := r.Fatal()
+	if len(fatal) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%w: %s", ErrInvalidEPUB, strings.Join(fatal, "; "))
+}
+
+func (r *ValidationReport) messages(severity Severity) []string {
+	var messages []string
+	for _, issue := range r.Issues {
+		if issue.Severity == severity {
+			messages = append(messages, issue.String())
+		}
+	}
+	return messages
+}
+
+func (r *ValidationReport) addf(severity Severity, file, format string, args ...any) {
+	r.Issues = append(r.Issues, Issue{
+		Severity: severity,
+		File:     file,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+type containerXML struct {
+	Rootfiles []struct {
+		FullPath  string `xml:"full-path,attr"`
+		MediaType string `xml:"media-type,attr"`
+	} `xml:"rootfiles>rootfile"`
+}
+
+type opfItem struct {
+	ID         string `xml:"id,attr"`
+	Href       string `xml:"href,attr"`
+	MediaType  string `xml:"media-type,attr"`
+	Properties string `xml:"properties,attr"`
+}
+
+type opfPackage struct {
+	Manifest []opfItem `xml:"manifest>item"`
+	Spine    struct {
+		Toc      string `xml:"toc,attr"`
+		Itemrefs []struct {
+			IDRef string `xml:"idref,attr"`
+		} `xml:"itemref"`
+	} `xml:"spine"`
+}
+
+// Validate performs structural validation of an EPUB package: mimetype ordering,
+// container.xml, OPF manifest and spine consistency, XHTML well-formedness of every
+// section and resources referenced by sections but missing from the package.
+func Validate(data []byte) *ValidationReport {
+	report := &ValidationReport{}
+
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		report.addf(SeverityFatal, "", "not a valid zip archive: %v", err)
+		return report
+	}
+
+	files := make(map[string]*zip.File, len(zr.File))
+	for _, f := range zr.File {
+		files[f.Name] = f
+	}
+
+	validateMimetype(report, zr.File)
+
+	opfPath := validateContainer(report, files)
+	if opfPath == "" {
+		return report
+	}
+
+	pkg := parseOPF(report, files, opfPath)
+	if pkg == nil {
+		return report
+	}
+
+	validatePackage(report, files, opfPath, pkg)
+
+	return report
+}
+
+func validateMimetype(report *ValidationReport, files []*zip.File) {
+	if len(files) == 0 || files[0].Name != mimetypeFilename {
+		report.addf(SeverityFatal, mimetypeFilename, "must be the first entry of the archive")
+		return
+	}
+
+	if files[0].Method != zip.Store {
+		report.addf(SeverityFatal, mimetypeFilename, "must be stored uncompressed")
+	}
+
+	content, err := readZipFile(files[0])
+	if err != nil {
+		report.addf(SeverityFatal, mimetypeFilename, "unreadable: %v", err)
+		return
+	}
+
+	if string(content) != mimetypeContent {
+		report.addf(SeverityFatal, mimetypeFilename, "unexpected content %q", string(content))
+	}
+}
+
+func validateContainer(report *ValidationReport, files map[string]*zip.File) string {
+	f, ok := files[containerFilename]
+	if !ok {
+		report.addf(SeverityFatal, containerFilename, "missing")
+		return ""
+	}
+
+	content, err := readZipFile(f)
+	if err != nil {
+		report.addf(SeverityFatal, containerFilename, "unreadable: %v", err)
+		return ""
+	}
+
+	var container containerXML
+	if unmarshalErr := xml.Unmarshal(content, &container); unmarshalErr != nil {
+		report.addf(SeverityFatal, containerFilename, "not well-formed: %v", unmarshalErr)
+		return ""
+	}
+
+	for _, rootfile := range container.Rootfiles {
+		if rootfile.MediaType != mediaTypeOPF {
+			continue
+		}
+		if _, exists := files[rootfile.FullPath]; !exists {
+			report.addf(SeverityFatal, containerFilename, "rootfile %q not found in archive", rootfile.FullPath)
+			return ""
+		}
+		return rootfile.FullPath
+	}
+
+	report.addf(SeverityFatal, containerFilename, "no rootfile with media type %s", mediaTypeOPF)
+	return ""
+}
+
+func parseOPF(report *ValidationReport, files map[string]*zip.File, opfPath string) *opfPackage {
+	content, err := readZipFile(files[opfPath])
+	if err != nil {
+		report.addf(SeverityFatal, opfPath, "unreadable: %v", err)
+		return nil
+	}
+
+	var pkg opfPackage
+	if unmarshalErr := xml.Unmarshal(content, &pkg); unmarshalErr != nil {
+		report.addf(SeverityFatal, opfPath, "not well-formed: %v", unmarshalErr)
+		return nil
+	}
+
+	return &pkg
+}
+
+func validatePackage(report *ValidationReport, files map[string]*zip.File, opfPath string, pkg *opfPackage) {
+	baseDir := path.Dir(opfPath)
+	items := make(map[string]opfItem, len(pkg.Manifest))
+	manifested := map[string]bool{opfPath: true}
+	hasNav := false
+
+	for _, item := range pkg.Manifest {
+		if _, duplicate := items[item.ID]; duplicate {
+			report.addf(SeverityFatal, opfPath, "duplicate manifest id %q", item.ID)
+			continue
+		}
+		items[item.ID] = item
+
+		itemPath := resolvePath(baseDir, item.Href)
+		manifested[itemPath] = true
+		if _, exists := files[itemPath]; !exists {
+			report.addf(SeverityFatal, opfPath, "manifest item %q references missing file %q", item.ID, item.Href)
+			continue
+		}
+
+		if hasProperty(item.Properties, navProperty) {
+			hasNav = true
+		}
+
+		if item.MediaType == mediaTypeXHTML {
+			validateXHTML(report, files, itemPath)
+		}
+	}
+
+	if !hasNav {
+		report.addf(SeverityWarning, opfPath, "no navigation document in manifest")
+	}
+
+	if pkg.Spine.Toc != "" {
+		if item, ok := items[pkg.Spine.Toc]; !ok || item.MediaType != mediaTypeNCX {
+			report.addf(SeverityWarning, opfPath, "spine toc %q does not reference an NCX manifest item", pkg.Spine.Toc)
+		}
+	}
+
+	if len(pkg.Spine.Itemrefs) == 0 {
+		report.addf(SeverityFatal, opfPath, "spine is empty")
+	}
+
+	for _, itemref := range pkg.Spine.Itemrefs {
+		item, ok := items[itemref.IDRef]
+		if !ok {
+			report.addf(SeverityFatal, opfPath, "spine references unknown manifest id %q", itemref.IDRef)
+			continue
+		}
+		if item.MediaType != mediaTypeXHTML {
+			report.addf(SeverityFatal, opfPath, "spine item %q has non-XHTML media type %q", item.ID, item.MediaType)
+		}
+	}
+
+	for name := range files {
+		if name == mimetypeFilename || strings.HasPrefix(name, "META-INF/") || strings.HasSuffix(name, "/") {
+			continue
+		}
+		if !manifested[name] {
+			report.addf(SeverityWarning, name, "file is not listed in the manifest")
+		}
+	}
+}
+
+func validateXHTML(report *ValidationReport, files map[string]*zip.File, xhtmlPath string) {
+	content, err := readZipFile(files[xhtmlPath])
+	if err != nil {
+		report.addf(SeverityFatal, xhtmlPath, "unreadable: %v", err)
+		return
+	}
+
+	var references []string
+	decoder := xml.NewDecoder(bytes.NewReader(content))
+	decoder.Strict = true
+	for {
+		token, tokenErr := decoder.Token()
+		if errors.Is(tokenErr, io.EOF) {
+			break
+		}
+		if tokenErr != nil {
+			report.addf(SeverityFatal, xhtmlPath, "not well-formed XHTML: %v", tokenErr)
+			return
+		}
+		if start, ok := token.(xml.StartElement); ok {
+			if ref := resourceReference(start); ref != "" {
+				references = append(references, ref)
+			}
+		}
+	}
+
+	baseDir := path.Dir(xhtmlPath)
+	for _, ref := range references {
+		parsed, parseErr := url.Parse(ref)
+		if parseErr != nil {
+			report.addf(SeverityWarning, xhtmlPath, "invalid resource reference %q", ref)
+			continue
+		}
+		if parsed.Scheme == "data" {
+			continue
+		}
+		if parsed.Scheme != "" || parsed.Host != "" {
+			report.addf(SeverityWarning, xhtmlPath, "remote resource %q was not embedded", ref)
+			continue
+		}
+		if _, exists := files[resolvePath(baseDir, ref)]; !exists {
+			report.addf(SeverityWarning, xhtmlPath, "referenced resource %q is missing", ref)
+		}
+	}
+}
+
+func resourceReference(start xml.StartElement) string {
+	var attrName string
+	switch start.Name.Local {
+	case "img", "source", "audio", "video":
+		attrName = "src"
+	case "link", "image":
+		attrName = "href"
+	default:
+		return ""
+	}
+
+	for _, attr := range start.Attr {
+		if attr.Name.Local == attrName {
+			return strings.TrimSpace(attr.Value)
+		}
+	}
+	return ""
+}
+
+func resolvePath(baseDir, href string) string {
+	if unescaped, err := url.PathUnescape(href); err == nil {
+		href = unescaped
+	}
+	if i := strings.IndexAny(href, "#?"); i >= 0 {
+		href = href[:i]
+	}
+	return strings.TrimPrefix(path.Join(baseDir, href), "./")
+}
+
+func hasProperty(properties, property string) bool {
+	for _, p := range strings.Fields(properties) {
+		if p == property {
+			return true
+		}
+	}
+	return false
+}
+
+func readZipFile(f *zip.File) ([]byte, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", f.Name, err)
+	}
+	defer func() { _ = rc.Close() }()
+
+	content, err := io.ReadAll(rc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", f.Name, err)
+	}
+	return content, nil
+}
@@ -0,0 +1,203 @@
+package epub
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/shaftoe/savetoink/internal/model"
+)
+
+const (
+	testContainerXML = `<?xml version="1.0" encoding="UTF-8"?>
+<container version="1.0" xmlns="urn:oasis:names:tc:opendocument:xmlns:container">
+  <rootfiles>
+    <rootfile full-path="EPUB/package.opf" media-type="application/oebps-package+xml"/>
+  </rootfiles>
+</container>`
+
+	testPackageOPF = `<?xml version="1.0" encoding="UTF-8"?>
+<package xmlns="http://www.idpf.org/2007/opf" version="3.0">
+  <manifest>
+    <item id="nav" href="nav.xhtml" media-type="application/xhtml+xml" properties="nav"/>
+    <item id="chapter1" href="xhtml/chapter1.xhtml" media-type="application/xhtml+xml"/>
+  </manifest>
+  <spine>
+    <itemref idref="chapter1"/>
+  </spine>
+</package>`
+
+	testNavXHTML = `<?xml version="1.0" encoding="UTF-8"?>
+<html xmlns="http://www.w3.org/1999/xhtml"><head><title>nav</title></head><body></body></html>`
+
+	testChapterXHTML = `<?xml version="1.0" encoding="UTF-8"?>
+<html xmlns="http://www.w3.org/1999/xhtml"><head><title>Chapter</title></head>
+<body><p>Hello<br/>world</p></body></html>`
+)
+
+type zipEntry struct {
+	name    string
+	content string
+	method  uint16
+}
+
+func buildZip(t *testing.T, entries []zipEntry) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, entry := range entries {
+		w, err := zw.CreateHeader(&zip.FileHeader{Name: entry.name, Method: entry.method})
+		if err != nil {
+			t.Fatalf("failed to create zip entry: %v", err)
+		}
+		if _, err = w.Write([]byte(entry.content)); err != nil {
+			t.Fatalf("failed to write zip entry: %v", err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("failed to close zip: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func validEntries() []zipEntry {
+	return []zipEntry{
+		{name: mimetypeFilename, content: mimetypeContent, method: zip.Store},
+		{name: containerFilename, content: testContainerXML, method: zip.Deflate},
+		{name: "EPUB/package.opf", content: testPackageOPF, method: zip.Deflate},
+		{name: "EPUB/nav.xhtml", content: testNavXHTML, method: zip.Deflate},
+		{name: "EPUB/xhtml/chapter1.xhtml", content: testChapterXHTML, method: zip.Deflate},
+	}
+}
+
+func TestValidate_GeneratedEPUB(t *testing.T) {
+	data, err := NewGenerator().Generate(context.Background(), &model.Article{
+		Title:   "Test Article",
+		Content: "<p>This is test content<br/>with a line break</p>",
+	})
+	if err != nil {
+		t.Fatalf("Generate() unexpected error = %v", err)
+	}
+
+	report := Validate(data)
+	if reportErr := report.Err(); reportErr != nil {
+		t.Fatalf("Validate() unexpected error = %v", reportErr)
+	}
+}
+
+func TestValidate_ValidPackage(t *testing.T) {
+	report := Validate(buildZip(t, validEntries()))
+
+	if len(report.Issues) != 0 {
+		t.Errorf("expected no issues, got %v", report.Issues)
+	}
+}
+
+func TestValidate_FatalIssues(t *testing.T) {
+	tests := []struct {
+		name        string
+		mutate      func([]zipEntry) []zipEntry
+		errContains string
+	}{
+		{
+			name: "mimetype not first",
+			mutate: func(entries []zipEntry) []zipEntry {
+				entries[0], entries[1] = entries[1], entries[0]
+				return entries
+			},
+			errContains: "must be the first entry",
+		},
+		{
+			name: "mimetype compressed",
+			mutate: func(entries []zipEntry) []zipEntry {
+				entries[0].method = zip.Deflate
+				return entries
+			},
+			errContains: "must be stored uncompressed",
+		},
+		{
+			name: "missing container",
+			mutate: func(entries []zipEntry) []zipEntry {
+				return append(entries[:1], entries[2:]...)
+			},
+			errContains: "META-INF/container.xml: missing",
+		},
+		{
+			name: "spine references unknown item",
+			mutate: func(entries []zipEntry) []zipEntry {
+				entries[2].content = strings.Replace(testPackageOPF, `idref="chapter1"`, `idref="chapter2"`, 1)
+				return entries
+			},
+			errContains: `unknown manifest id "chapter2"`,
+		},
+		{
+			name: "manifest references missing file",
+			mutate: func(entries []zipEntry) []zipEntry {
+				return entries[:4]
+			},
+			errContains: "references missing file",
+		},
+		{
+			name: "malformed section",
+			mutate: func(entries []zipEntry) []zipEntry {
+				entries[4].content = strings.Replace(testChapterXHTML, "<br/>", "<br>", 1)
+				return entries
+			},
+			errContains: "not well-formed XHTML",
+		},
+		{
+			name: "undeclared entity",
+			mutate: func(entries []zipEntry) []zipEntry {
+				entries[4].content = strings.Replace(testChapterXHTML, "Hello", "Hello&nbsp;", 1)
+				return entries
+			},
+			errContains: "not well-formed XHTML",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := Validate(buildZip(t, tt.mutate(validEntries())))
+
+			err := report.Err()
+			if !errors.Is(err, ErrInvalidEPUB) {
+				t.Fatalf("expected ErrInvalidEPUB, got %v", err)
+			}
+			if !strings.Contains(err.Error(), tt.errContains) {
+				t.Errorf("expected error to contain %q, got %q", tt.errContains, err.Error())
+			}
+		})
+	}
+}
+
+func TestValidate_Warnings(t *testing.T) {
+	entries := validEntries()
+	entries[4].content = strings.Replace(testChapterXHTML, "<br/>",
+		`<img src="https://example.com/remote.jpg"/><img src="../images/missing.jpg"/>`, 1)
+	entries = append(entries, zipEntry{name: "EPUB/extra.txt", content: "extra", method: zip.Deflate})
+
+	report := Validate(buildZip(t, entries))
+
+	if err := report.Err(); err != nil {
+		t.Fatalf("expected no fatal issues, got %v", err)
+	}
+
+	warnings := strings.Join(report.Warnings(), "\n")
+	for _, expected := range []string{"was not embedded", "is missing", "not listed in the manifest"} {
+		if !strings.Contains(warnings, expected) {
+			t.Errorf("expected warnings to contain %q, got %q", expected, warnings)
+		}
+	}
+}
+
+func TestValidate_NotAZip(t *testing.T) {
+	report := Validate([]byte("not a zip"))
+
+	if !errors.Is(report.Err(), ErrInvalidEPUB) {
+		t.Errorf("expected ErrInvalidEPUB, got %v", report.Err())
+	}
+}
@@ -0,0 +1,105 @@
+// Package exporter writes the files of a library export: a Netscape bookmark file listing the saved articles,
+// readable enough to be imported by browsers and read-it-later services, and standalone HTML documents.
+package exporter
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shaftoe/savetoink/internal/consts"
+	"github.com/shaftoe/savetoink/internal/model"
+)
+
+// Bookmark is an entry of a bookmark file.
+type Bookmark struct {
+	URL     string
+	Title   string
+	AddedAt time.Time
+	Tags    []string
+}
+
+// BookmarkOf returns the bookmark of a saved article.
+func BookmarkOf(article *model.Article) Bookmark {
+	return Bookmark{URL: article.URL, Title: article.Title, AddedAt: article.CreatedAt, Tags: article.Tags}
+}
+
+// WriteBookmarks writes a Netscape bookmark file listing bookmarks in a single folder, with the time they were
+// added and their tags.
+func WriteBookmarks(w io.Writer, bookmarks []Bookmark) error {
+	var b strings.Builder
+	b.WriteString("<!DOCTYPE NETSCAPE-Bookmark-file-1>\n")
+	b.WriteString(`<META HTTP-EQUIV="Content-Type" CONTENT="text/html; charset=UTF-8">` + "\n")
+	b.WriteString("<TITLE>Bookmarks</TITLE>\n<H1>Bookmarks</H1>\n<DL><p>\n")
+	b.WriteString("    <DT><H3>Save To Ink</H3>\n    <DL><p>\n")
+
+	for _, bookmark := range bookmarks {
+		title := bookmark.Title
+		if title == "" {
+			title = bookmark.URL
+		}
+
+		b.WriteString(`        <DT><A HREF="` + html.EscapeString(bookmark.URL) + `"`)
+		if !bookmark.AddedAt.IsZero() {
+			b.WriteString(` ADD_DATE="` + strconv.FormatInt(bookmark.AddedAt.Unix(), 10) + `"`)
+		}
+		if len(bookmark.Tags) > 0 {
+			b.WriteString(` TAGS="` + html.EscapeString(strings.Join(bookmark.Tags, ",")) + `"`)
+		}
+		b.WriteString(">" + html.EscapeString(title) + "</A>\n")
+	}
+
+	b.WriteString("    </DL><p>\n</DL><p>\n")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// HTMLDocument returns the content of an article as a standalone HTML document, titled and linking to the
+// original page.
+func HTMLDocument(article *model.Article) string {
+	title := html.EscapeString(article.Title)
+	lang := ""
+	if article.Language != "" {
+		lang = ` lang="` + html.EscapeString(article.Language) + `"`
+	}
+
+	var b strings.Builder
+	b.WriteString("<!DOCTYPE html>\n<html" + lang + ">\n<head>\n<meta charset=\"utf-8\">\n")
+	b.WriteString("<title>" + title + "</title>\n</head>\n<body>\n")
+	b.WriteString("<h1>" + title + "</h1>\n")
+	b.WriteString(`<p><a href="` + html.EscapeString(article.URL) + `">` + html.EscapeString(article.URL) + "</a>")
+	if article.Author != "" {
+		b.WriteString(" · " + html.EscapeString(article.Author))
+	}
+	b.WriteString("</p>\n")
+	b.WriteString(article.Content)
+	b.WriteString("\n</body>\n</html>\n")
+
+	return b.String()
+}
+
+var nonSlugChars = regexp.MustCompile(`[^a-z0-9]+`)
+
+// Filename returns the name of the file of an article with extension ext, its save date and title followed by
+// the beginning of its ID to keep names unique, e.g. 2024-05-01-writing-an-epub-generator-3f2a1b7c.epub.
+func Filename(article *model.Article, ext string) string {
+	slug := strings.Trim(nonSlugChars.ReplaceAllString(strings.ToLower(article.Title), "-"), "-")
+	if len(slug) > consts.ExportSlugMaxLength {
+		slug = strings.TrimRight(slug[:consts.ExportSlugMaxLength], "-")
+	}
+	if slug == "" {
+		slug = "article"
+	}
+
+	id := article.ID
+	if len(id) > consts.ExportIDPrefixLength {
+		id = id[:consts.ExportIDPrefixLength]
+	}
+
+	return fmt.Sprintf("%s-%s-%s.%s", article.CreatedAt.UTC().Format(time.DateOnly), slug, id, ext)
+}
@@ -0,0 +1,90 @@
+package exporter
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shaftoe/savetoink/internal/consts"
+	"github.com/shaftoe/savetoink/internal/importer"
+	"github.com/shaftoe/savetoink/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteBookmarks(t *testing.T) {
+	addedAt := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	bookmarks := []Bookmark{
+		{URL: "https://example.com/a?x=1&y=2", Title: "Fish & <Chips>", AddedAt: addedAt, Tags: []string{"food", "uk"}},
+		{URL: "https://example.com/b"},
+	}
+
+	var b strings.Builder
+	require.NoError(t, WriteBookmarks(&b, bookmarks))
+	out := b.String()
+
+	assert.Contains(t, out, "<!DOCTYPE NETSCAPE-Bookmark-file-1>")
+	assert.Contains(t, out, `HREF="https://example.com/a?x=1&amp;y=2" ADD_DATE="1714557600" TAGS="food,uk">`+
+		"Fish &amp; &lt;Chips&gt;</A>")
+	assert.Contains(t, out, `HREF="https://example.com/b">https://example.com/b</A>`, "the URL is the default title")
+
+	items, format, err := importer.Parse([]byte(out), "")
+	require.NoError(t, err)
+	assert.Equal(t, consts.ImportFormatBookmarks, format)
+	require.Len(t, items, 2)
+	assert.Equal(t, importer.Item{
+		URL: "https://example.com/a?x=1&y=2", Title: "Fish & <Chips>", SavedAt: addedAt, Tags: []string{"food", "uk"},
+	}, items[0])
+	assert.True(t, items[1].SavedAt.IsZero())
+}
+
+func TestHTMLDocument(t *testing.T) {
+	doc := HTMLDocument(&model.Article{
+		URL:      "https://example.com/post",
+		Title:    "A <post>",
+		Author:   "Jane Doe",
+		Language: "en",
+		Content:  "<p>Hello</p>",
+	})
+
+	assert.True(t, strings.HasPrefix(doc, "<!DOCTYPE html>\n<html lang=\"en\">"))
+	assert.Contains(t, doc, "<title>A &lt;post&gt;</title>")
+	assert.Contains(t, doc, `<a href="https://example.com/post">https://example.com/post</a> · Jane Doe`)
+	assert.Contains(t, doc, "<p>Hello</p>")
+}
+
+func TestFilename(t *testing.T) {
+	createdAt := time.Date(2024, 5, 1, 23, 30, 0, 0, time.FixedZone("CET", 3600))
+
+	tests := []struct {
+		name     string
+		article  *model.Article
+		ext      string
+		expected string
+	}{
+		{
+			name:     "title slug",
+			article:  &model.Article{ID: "3f2a1b7c9d8e", Title: "Writing an EPUB Generator!", CreatedAt: createdAt},
+			ext:      "epub",
+			expected: "2024-05-01-writing-an-epub-generator-3f2a1b7c.epub",
+		},
+		{
+			name:     "no title",
+			article:  &model.Article{ID: "abc", Title: "日本語", CreatedAt: createdAt},
+			ext:      "html",
+			expected: "2024-05-01-article-abc.html",
+		},
+		{
+			name:     "long title",
+			article:  &model.Article{ID: "3f2a1b7c", Title: strings.Repeat("word ", 20), CreatedAt: createdAt},
+			ext:      "html",
+			expected: "2024-05-01-" + strings.TrimSuffix(strings.Repeat("word-", 12), "-") + "-3f2a1b7c.html",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, Filename(tt.article, tt.ext))
+		})
+	}
+}
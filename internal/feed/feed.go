@@ -0,0 +1,110 @@
+// Package feed parses RSS 2.0, Atom and JSON Feed documents and selects their new entries.
+package feed
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/mmcdole/gofeed"
+)
+
+// ErrInvalidFeed is returned when a document is not a valid RSS, Atom or JSON feed.
+var ErrInvalidFeed = errors.New("invalid feed")
+
+// Feed is a parsed feed.
+type Feed struct {
+	Title string
+	// Entries are in document order, usually the most recent first.
+	Entries []Entry
+}
+
+// Entry is a feed entry linking to an article.
+type Entry struct {
+	// ID is the GUID of the entry, its URL when it has none.
+	ID    string
+	URL   string
+	Title string
+	// Published is the publication or, if missing, update time, zero when the feed has none.
+	Published time.Time
+}
+
+// Parse parses an RSS 2.0, Atom or JSON feed fetched from feedURL. Relative entry links are resolved
+// against feedURL, entries without a link are skipped.
+func Parse(body []byte, feedURL *url.URL) (*Feed, error) {
+	parsed, err := gofeed.NewParser().Parse(bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidFeed, err)
+	}
+
+	feed := &Feed{Title: strings.TrimSpace(parsed.Title)}
+	for _, item := range parsed.Items {
+		link, linkErr := feedURL.Parse(strings.TrimSpace(item.Link))
+		if item.Link == "" || linkErr != nil || (link.Scheme != "http" && link.Scheme != "https") {
+			continue
+		}
+
+		entry := Entry{ID: strings.TrimSpace(item.GUID), URL: link.String(), Title: strings.TrimSpace(item.Title)}
+		if entry.ID == "" {
+			entry.ID = entry.URL
+		}
+		if item.PublishedParsed != nil {
+			entry.Published = item.PublishedParsed.UTC()
+		} else if item.UpdatedParsed != nil {
+			entry.Published = item.UpdatedParsed.UTC()
+		}
+
+		feed.Entries = append(feed.Entries, entry)
+	}
+
+	return feed, nil
+}
+
+// Newest returns the most recent entry, the first one in document order when some entries are not dated.
+func (f *Feed) Newest() (Entry, bool) {
+	if len(f.Entries) == 0 {
+		return Entry{}, false
+	}
+	if !f.dated() {
+		return f.Entries[0], true
+	}
+	return slices.MaxFunc(f.Entries, func(a, b Entry) int { return a.Published.Compare(b.Published) }), true
+}
+
+// NewEntries returns the entries more recent than the last one already seen, identified by lastID and
+// published at lastAt, that are not in seen, oldest first and at most limit, the most recent ones. When all
+// entries are dated they are compared with lastAt, otherwise entries listed before lastID in document order are
+// new. All entries not in seen are new when there is no last entry, or it is no longer in the feed.
+func (f *Feed) NewEntries(lastID string, lastAt *time.Time, seen []string, limit int) []Entry {
+	var entries []Entry
+	if f.dated() {
+		for _, entry := range f.Entries {
+			if lastAt == nil || entry.Published.After(*lastAt) {
+				entries = append(entries, entry)
+			}
+		}
+		slices.SortStableFunc(entries, func(a, b Entry) int { return a.Published.Compare(b.Published) })
+	} else {
+		entries = slices.Clone(f.Entries)
+		if i := slices.IndexFunc(entries, func(entry Entry) bool { return entry.ID == lastID }); i >= 0 {
+			entries = entries[:i]
+		}
+		slices.Reverse(entries)
+	}
+
+	entries = slices.DeleteFunc(entries, func(entry Entry) bool { return slices.Contains(seen, entry.ID) })
+	if len(entries) > limit {
+		entries = entries[len(entries)-limit:]
+	}
+
+	return entries
+}
+
+// dated reports whether all entries have a publication time.
+func (f *Feed) dated() bool {
+	return !slices.ContainsFunc(f.Entries, func(entry Entry) bool { return entry.Published.IsZero() })
+}
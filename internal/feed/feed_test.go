@@ -0,0 +1,199 @@
+package feed
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const rssFeed = `<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0">
+<channel>
+  <title> Example Blog </title>
+  <link>https://example.com/</link>
+  <item>
+    <title>Second post</title>
+    <link>/posts/2</link>
+    <guid>post-2</guid>
+    <pubDate>Tue, 02 Jan 2024 10:00:00 GMT</pubDate>
+  </item>
+  <item>
+    <title>No link</title>
+    <guid>post-x</guid>
+    <pubDate>Tue, 02 Jan 2024 09:00:00 GMT</pubDate>
+  </item>
+  <item>
+    <title>Mailto</title>
+    <link>mailto:author@example.com</link>
+    <pubDate>Tue, 02 Jan 2024 08:00:00 GMT</pubDate>
+  </item>
+  <item>
+    <title>First post</title>
+    <link>https://example.com/posts/1</link>
+    <pubDate>Mon, 01 Jan 2024 10:00:00 GMT</pubDate>
+  </item>
+</channel>
+</rss>`
+
+const atomFeed = `<?xml version="1.0" encoding="utf-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom">
+  <title>Example Atom</title>
+  <id>urn:uuid:feed</id>
+  <updated>2024-01-02T10:00:00Z</updated>
+  <entry>
+    <title>Updated entry</title>
+    <link href="https://example.com/atom/2"/>
+    <id>urn:uuid:2</id>
+    <updated>2024-01-02T10:00:00Z</updated>
+  </entry>
+  <entry>
+    <title>Published entry</title>
+    <link href="https://example.com/atom/1"/>
+    <id>urn:uuid:1</id>
+    <published>2024-01-01T10:00:00Z</published>
+    <updated>2024-01-03T10:00:00Z</updated>
+  </entry>
+</feed>`
+
+const jsonFeed = `{
+  "version": "https://jsonfeed.org/version/1.1",
+  "title": "Example JSON",
+  "items": [
+    {"id": "2", "url": "https://example.com/json/2", "title": "Two"},
+    {"id": "1", "url": "https://example.com/json/1", "title": "One"}
+  ]
+}`
+
+func mustParseURL(t *testing.T, raw string) *url.URL {
+	t.Helper()
+	u, err := url.Parse(raw)
+	require.NoError(t, err)
+	return u
+}
+
+func date(day, hour int) time.Time {
+	return time.Date(2024, time.January, day, hour, 0, 0, 0, time.UTC)
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name        string
+		body        string
+		wantTitle   string
+		wantEntries []Entry
+	}{
+		{
+			name:      "rss",
+			body:      rssFeed,
+			wantTitle: "Example Blog",
+			wantEntries: []Entry{
+				{ID: "post-2", URL: "https://example.com/posts/2", Title: "Second post", Published: date(2, 10)},
+				{ID: "https://example.com/posts/1", URL: "https://example.com/posts/1", Title: "First post",
+					Published: date(1, 10)},
+			},
+		},
+		{
+			name:      "atom",
+			body:      atomFeed,
+			wantTitle: "Example Atom",
+			wantEntries: []Entry{
+				{ID: "urn:uuid:2", URL: "https://example.com/atom/2", Title: "Updated entry", Published: date(2, 10)},
+				{ID: "urn:uuid:1", URL: "https://example.com/atom/1", Title: "Published entry", Published: date(1, 10)},
+			},
+		},
+		{
+			name:      "json feed",
+			body:      jsonFeed,
+			wantTitle: "Example JSON",
+			wantEntries: []Entry{
+				{ID: "2", URL: "https://example.com/json/2", Title: "Two"},
+				{ID: "1", URL: "https://example.com/json/1", Title: "One"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			feed, err := Parse([]byte(tt.body), mustParseURL(t, "https://example.com/feed"))
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantTitle, feed.Title)
+			assert.Equal(t, tt.wantEntries, feed.Entries)
+		})
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	_, err := Parse([]byte("<html><body>not a feed</body></html>"), mustParseURL(t, "https://example.com/"))
+	if !errors.Is(err, ErrInvalidFeed) {
+		t.Errorf("expected ErrInvalidFeed, got %v", err)
+	}
+}
+
+func TestNewest(t *testing.T) {
+	dated := &Feed{Entries: []Entry{
+		{ID: "b", Published: date(2, 0)},
+		{ID: "c", Published: date(3, 0)},
+		{ID: "a", Published: date(1, 0)},
+	}}
+	newest, ok := dated.Newest()
+	assert.True(t, ok)
+	assert.Equal(t, "c", newest.ID)
+
+	undated := &Feed{Entries: []Entry{{ID: "b"}, {ID: "a", Published: date(1, 0)}}}
+	newest, ok = undated.Newest()
+	assert.True(t, ok)
+	assert.Equal(t, "b", newest.ID)
+
+	_, ok = (&Feed{}).Newest()
+	assert.False(t, ok)
+}
+
+func TestNewEntries(t *testing.T) {
+	lastAt := date(2, 0)
+	dated := &Feed{Entries: []Entry{
+		{ID: "d", Published: date(4, 0)},
+		{ID: "b", Published: date(2, 0)},
+		{ID: "c", Published: date(3, 0)},
+		{ID: "a", Published: date(1, 0)},
+	}}
+	undated := &Feed{Entries: []Entry{{ID: "d"}, {ID: "c"}, {ID: "b"}, {ID: "a"}}}
+
+	tests := []struct {
+		name   string
+		feed   *Feed
+		lastID string
+		lastAt *time.Time
+		seen   []string
+		limit  int
+		want   []string
+	}{
+		{name: "dated after last", feed: dated, lastID: "b", lastAt: &lastAt, limit: 10, want: []string{"c", "d"}},
+		{name: "dated without last", feed: dated, limit: 10, want: []string{"a", "b", "c", "d"}},
+		{name: "dated limited to most recent", feed: dated, limit: 2, want: []string{"c", "d"}},
+		{name: "undated before last", feed: undated, lastID: "b", limit: 10, want: []string{"c", "d"}},
+		{name: "undated last gone", feed: undated, lastID: "z", limit: 3, want: []string{"b", "c", "d"}},
+		{name: "nothing new", feed: undated, lastID: "d", limit: 10, want: nil},
+		{name: "dated seen", feed: dated, seen: []string{"c"}, limit: 10, want: []string{"a", "b", "d"}},
+		{
+			name:  "undated last gone but seen",
+			feed:  undated,
+			seen:  []string{"a", "b", "z"},
+			limit: 10,
+			want:  []string{"c", "d"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, entry := range tt.feed.NewEntries(tt.lastID, tt.lastAt, tt.seen, tt.limit) {
+				got = append(got, entry.ID)
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
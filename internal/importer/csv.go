@@ -0,0 +1,129 @@
+package importer
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"slices"
+	"strings"
+)
+
+// instapaperFolders are the built-in Instapaper folders, other folders are imported as tags.
+var instapaperFolders = []string{"unread", "archive", "starred"}
+
+// columnIndex maps the lowercase names of the columns of a CSV header to their index.
+type columnIndex map[string]int
+
+func newColumnIndex(header []string) columnIndex {
+	columns := make(columnIndex, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	return columns
+}
+
+// has reports whether all the named columns are present.
+func (c columnIndex) has(names ...string) bool {
+	for _, name := range names {
+		if _, ok := c[name]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// get returns the value of the named column in record, empty if the column or the value is missing.
+func (c columnIndex) get(record []string, name string) string {
+	if i, ok := c[name]; ok && i < len(record) {
+		return record[i]
+	}
+	return ""
+}
+
+// readCSV reads the records of a CSV file with a header naming the required columns.
+func readCSV(data []byte, required ...string) (columnIndex, [][]string, error) {
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	columns := newColumnIndex(header)
+	if !columns.has(required...) {
+		return nil, nil, errors.New("missing columns, expected " + strings.Join(required, ", "))
+	}
+
+	var records [][]string
+	for {
+		record, readErr := reader.Read()
+		if errors.Is(readErr, io.EOF) {
+			break
+		}
+		if readErr != nil {
+			return nil, nil, readErr
+		}
+		records = append(records, record)
+	}
+
+	return columns, records, nil
+}
+
+// parsePocketCSV parses a Pocket CSV export: title, url, time_added in seconds, tags separated by "|" and status.
+func parsePocketCSV(data []byte) ([]Item, error) {
+	columns, records, err := readCSV(data, "url", "time_added")
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]Item, 0, len(records))
+	for _, record := range records {
+		items = append(items, Item{
+			URL:     columns.get(record, "url"),
+			Title:   columns.get(record, "title"),
+			SavedAt: unixTime(columns.get(record, "time_added")),
+			Tags:    splitTags(columns.get(record, "tags"), "|"),
+		})
+	}
+
+	return items, nil
+}
+
+// parseInstapaperCSV parses an Instapaper CSV export: URL, Title, Selection, Folder, Timestamp in seconds and,
+// in recent exports, Tags as a JSON array. Folders other than the built-in ones are imported as tags.
+func parseInstapaperCSV(data []byte) ([]Item, error) {
+	columns, records, err := readCSV(data, "url", "timestamp")
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]Item, 0, len(records))
+	for _, record := range records {
+		item := Item{
+			URL:     columns.get(record, "url"),
+			Title:   columns.get(record, "title"),
+			SavedAt: unixTime(columns.get(record, "timestamp")),
+			Tags:    instapaperTags(columns.get(record, "tags")),
+		}
+		if folder := strings.TrimSpace(columns.get(record, "folder")); folder != "" &&
+			!slices.ContainsFunc(instapaperFolders, func(name string) bool { return strings.EqualFold(name, folder) }) {
+			item.Tags = append(item.Tags, folder)
+		}
+		items = append(items, item)
+	}
+
+	return items, nil
+}
+
+// instapaperTags parses the tags of an Instapaper item, a JSON array or comma separated.
+func instapaperTags(value string) []string {
+	var tags []string
+	if err := json.Unmarshal([]byte(value), &tags); err == nil {
+		return tags
+	}
+	return splitTags(value, ",")
+}
@@ -0,0 +1,33 @@
+package importer
+
+import (
+	"bytes"
+
+	"github.com/go-shiori/dom"
+)
+
+// parseHTML parses the links of a Pocket HTML export or a Netscape bookmark file, both listing links with
+// the time they were added in seconds and their comma separated tags as attributes.
+func parseHTML(data []byte) ([]Item, error) {
+	root, err := dom.Parse(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	var items []Item
+	for _, link := range dom.QuerySelectorAll(root, "a[href]") {
+		added := dom.GetAttribute(link, "time_added")
+		if added == "" {
+			added = dom.GetAttribute(link, "add_date")
+		}
+
+		items = append(items, Item{
+			URL:     dom.GetAttribute(link, "href"),
+			Title:   dom.TextContent(link),
+			SavedAt: unixTime(added),
+			Tags:    splitTags(dom.GetAttribute(link, "tags"), ","),
+		})
+	}
+
+	return items, nil
+}
@@ -0,0 +1,146 @@
+// Package importer parses the files exported by Pocket, Instapaper, Omnivore and browsers into the links to save,
+// with the time they were originally saved and their tags.
+package importer
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shaftoe/savetoink/internal/consts"
+)
+
+var (
+	// ErrUnknownFormat is returned when the format of a file can't be detected or is not supported.
+	ErrUnknownFormat = errors.New("unknown import format")
+	// ErrInvalidFile is returned when a file can't be parsed in its format.
+	ErrInvalidFile = errors.New("invalid import file")
+)
+
+// Item is a link to save.
+type Item struct {
+	URL   string
+	Title string
+	// SavedAt is the time the link was saved in the exporting service, zero when unknown.
+	SavedAt time.Time
+	// Tags are the tags or labels of the link as exported, validated by the importing service.
+	Tags []string
+}
+
+// ParseFormat parses an import format, an empty value is returned as is and means the format is detected.
+func ParseFormat(value string) (consts.ImportFormat, error) {
+	switch format := consts.ImportFormat(strings.ToLower(strings.TrimSpace(value))); format {
+	case "", consts.ImportFormatPocketHTML, consts.ImportFormatPocketCSV, consts.ImportFormatInstapaperCSV,
+		consts.ImportFormatOmnivoreJSON, consts.ImportFormatBookmarks:
+		return format, nil
+	default:
+		return "", fmt.Errorf("%w: %q, must be one of %s, %s, %s, %s or %s", ErrUnknownFormat, value,
+			consts.ImportFormatPocketHTML, consts.ImportFormatPocketCSV, consts.ImportFormatInstapaperCSV,
+			consts.ImportFormatOmnivoreJSON, consts.ImportFormatBookmarks)
+	}
+}
+
+// Detect detects the format of an exported file from its content: JSON arrays are Omnivore exports, HTML
+// files are bookmark files when they have the Netscape doctype and Pocket exports otherwise, CSV files are
+// told apart by their header.
+func Detect(data []byte) (consts.ImportFormat, error) {
+	data = bytes.TrimSpace(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")))
+
+	switch {
+	case bytes.HasPrefix(data, []byte("[")):
+		return consts.ImportFormatOmnivoreJSON, nil
+	case bytes.HasPrefix(data, []byte("<")):
+		if bytes.Contains(bytes.ToUpper(data[:min(len(data), 512)]), []byte("NETSCAPE-BOOKMARK-FILE")) {
+			return consts.ImportFormatBookmarks, nil
+		}
+		return consts.ImportFormatPocketHTML, nil
+	}
+
+	header, err := csv.NewReader(bytes.NewReader(data)).Read()
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrUnknownFormat, err)
+	}
+
+	columns := newColumnIndex(header)
+	switch {
+	case columns.has("url", "time_added"):
+		return consts.ImportFormatPocketCSV, nil
+	case columns.has("url", "timestamp"):
+		return consts.ImportFormatInstapaperCSV, nil
+	default:
+		return "", fmt.Errorf("%w: unexpected CSV columns %s", ErrUnknownFormat, strings.Join(header, ","))
+	}
+}
+
+// Parse parses an exported file in format, detecting it when empty, and returns its items in file order.
+// Links that are not absolute http or https URLs are skipped.
+func Parse(data []byte, format consts.ImportFormat) ([]Item, consts.ImportFormat, error) {
+	if format == "" {
+		var err error
+		if format, err = Detect(data); err != nil {
+			return nil, "", err
+		}
+	}
+
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+
+	var items []Item
+	var err error
+	switch format {
+	case consts.ImportFormatPocketHTML, consts.ImportFormatBookmarks:
+		items, err = parseHTML(data)
+	case consts.ImportFormatPocketCSV:
+		items, err = parsePocketCSV(data)
+	case consts.ImportFormatInstapaperCSV:
+		items, err = parseInstapaperCSV(data)
+	case consts.ImportFormatOmnivoreJSON:
+		items, err = parseOmnivoreJSON(data)
+	default:
+		return nil, "", fmt.Errorf("%w: %q", ErrUnknownFormat, format)
+	}
+	if err != nil {
+		return nil, format, fmt.Errorf("%w: %s: %w", ErrInvalidFile, format, err)
+	}
+
+	valid := items[:0]
+	for _, item := range items {
+		if item.URL = validURL(item.URL); item.URL != "" {
+			item.Title = strings.TrimSpace(item.Title)
+			valid = append(valid, item)
+		}
+	}
+
+	return valid, format, nil
+}
+
+// validURL returns rawURL trimmed if it is an absolute http or https URL, an empty string otherwise.
+func validURL(rawURL string) string {
+	rawURL = strings.TrimSpace(rawURL)
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return ""
+	}
+	return rawURL
+}
+
+// splitTags splits the tags of an item separated by sep, none when value is blank.
+func splitTags(value, sep string) []string {
+	if strings.TrimSpace(value) == "" {
+		return nil
+	}
+	return strings.Split(value, sep)
+}
+
+// unixTime parses a Unix time in seconds, returning the zero time for invalid or missing values.
+func unixTime(value string) time.Time {
+	seconds, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+	if err != nil || seconds <= 0 {
+		return time.Time{}
+	}
+	return time.Unix(seconds, 0).UTC()
+}
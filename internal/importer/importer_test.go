@@ -0,0 +1,187 @@
+package importer
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/shaftoe/savetoink/internal/consts"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const pocketHTML = `<!DOCTYPE html>
+<html>
+<head><title>Pocket Export</title></head>
+<body>
+<h1>Unread</h1>
+<ul>
+<li><a href="https://example.com/posts/1" time_added="1700000000" tags="go,Reading">First post</a></li>
+<li><a href="javascript:alert(1)" time_added="1700000001" tags="">Script</a></li>
+</ul>
+<h1>Read Archive</h1>
+<ul>
+<li><a href="https://example.com/posts/2" time_added="1600000000" tags="">https://example.com/posts/2</a></li>
+</ul>
+</body>
+</html>`
+
+const bookmarks = `<!DOCTYPE NETSCAPE-Bookmark-file-1>
+<META HTTP-EQUIV="Content-Type" CONTENT="text/html; charset=UTF-8">
+<TITLE>Bookmarks</TITLE>
+<H1>Bookmarks Menu</H1>
+<DL><p>
+    <DT><H3 ADD_DATE="1690000000">Reading</H3>
+    <DL><p>
+        <DT><A HREF="https://example.com/essay" ADD_DATE="1690000100" TAGS="essays,long read">An essay</A>
+        <DT><A HREF="place:sort=8&maxResults=10">Recent tags</A>
+    </DL><p>
+    <DT><A HREF="https://example.org/" ADD_DATE="1690000200">Example</A>
+</DL><p>`
+
+const pocketCSV = `title,url,time_added,cursor,tags,status
+First post,https://example.com/posts/1,1700000000,,go|reading,unread
+"Quoted, title",https://example.com/posts/3,1700000300,,,archive
+Broken,not a url,1700000400,,,unread
+`
+
+const instapaperCSV = "\xef\xbb\xbfURL,Title,Selection,Folder,Timestamp,Tags\n" +
+	`https://example.com/posts/1,First post,,Unread,1700000000,"[""go"",""reading""]"` + "\n" +
+	`https://example.com/posts/4,In a folder,,Recipes,1700000500,[]` + "\n" +
+	`https://example.com/posts/5,Archived,,Archive,,` + "\n"
+
+const omnivoreJSON = `[
+  {"id": "1", "title": "First post", "url": "https://example.com/posts/1", "savedAt": "2023-11-14T22:13:20.000Z",
+   "labels": ["Go", "reading"]},
+  {"id": "2", "title": "Old labels", "url": "https://example.com/posts/6", "savedAt": "",
+   "labels": [{"name": "newsletter"}]}
+]`
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    consts.ImportFormat
+		wantErr bool
+	}{
+		{name: "pocket html", data: pocketHTML, want: consts.ImportFormatPocketHTML},
+		{name: "bookmarks", data: bookmarks, want: consts.ImportFormatBookmarks},
+		{name: "pocket csv", data: pocketCSV, want: consts.ImportFormatPocketCSV},
+		{name: "instapaper csv with BOM", data: instapaperCSV, want: consts.ImportFormatInstapaperCSV},
+		{name: "omnivore json", data: "\n" + omnivoreJSON, want: consts.ImportFormatOmnivoreJSON},
+		{name: "unknown csv", data: "name,email\nalice,alice@example.com\n", wantErr: true},
+		{name: "empty", data: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Detect([]byte(tt.data))
+			if tt.wantErr {
+				if !errors.Is(err, ErrUnknownFormat) {
+					t.Errorf("expected ErrUnknownFormat, got %v", err)
+				}
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	for _, value := range []string{"", "pocket-html", " Pocket-CSV ", "instapaper-csv", "omnivore-json", "bookmarks"} {
+		_, err := ParseFormat(value)
+		assert.NoError(t, err, value)
+	}
+
+	_, err := ParseFormat("evernote")
+	assert.ErrorIs(t, err, ErrUnknownFormat)
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name   string
+		data   string
+		format consts.ImportFormat
+		want   []Item
+	}{
+		{
+			name: "pocket html",
+			data: pocketHTML,
+			want: []Item{
+				{URL: "https://example.com/posts/1", Title: "First post", SavedAt: time.Unix(1700000000, 0).UTC(),
+					Tags: []string{"go", "Reading"}},
+				{URL: "https://example.com/posts/2", Title: "https://example.com/posts/2",
+					SavedAt: time.Unix(1600000000, 0).UTC()},
+			},
+		},
+		{
+			name: "bookmarks",
+			data: bookmarks,
+			want: []Item{
+				{URL: "https://example.com/essay", Title: "An essay", SavedAt: time.Unix(1690000100, 0).UTC(),
+					Tags: []string{"essays", "long read"}},
+				{URL: "https://example.org/", Title: "Example", SavedAt: time.Unix(1690000200, 0).UTC()},
+			},
+		},
+		{
+			name: "pocket csv",
+			data: pocketCSV,
+			want: []Item{
+				{URL: "https://example.com/posts/1", Title: "First post", SavedAt: time.Unix(1700000000, 0).UTC(),
+					Tags: []string{"go", "reading"}},
+				{URL: "https://example.com/posts/3", Title: "Quoted, title", SavedAt: time.Unix(1700000300, 0).UTC()},
+			},
+		},
+		{
+			name: "instapaper csv",
+			data: instapaperCSV,
+			want: []Item{
+				{URL: "https://example.com/posts/1", Title: "First post", SavedAt: time.Unix(1700000000, 0).UTC(),
+					Tags: []string{"go", "reading"}},
+				{URL: "https://example.com/posts/4", Title: "In a folder", SavedAt: time.Unix(1700000500, 0).UTC(),
+					Tags: []string{"Recipes"}},
+				{URL: "https://example.com/posts/5", Title: "Archived"},
+			},
+		},
+		{
+			name: "omnivore json",
+			data: omnivoreJSON,
+			want: []Item{
+				{URL: "https://example.com/posts/1", Title: "First post", SavedAt: time.Unix(1700000000, 0).UTC(),
+					Tags: []string{"Go", "reading"}},
+				{URL: "https://example.com/posts/6", Title: "Old labels", Tags: []string{"newsletter"}},
+			},
+		},
+		{
+			name:   "explicit format",
+			data:   pocketHTML,
+			format: consts.ImportFormatBookmarks,
+			want: []Item{
+				{URL: "https://example.com/posts/1", Title: "First post", SavedAt: time.Unix(1700000000, 0).UTC(),
+					Tags: []string{"go", "Reading"}},
+				{URL: "https://example.com/posts/2", Title: "https://example.com/posts/2",
+					SavedAt: time.Unix(1600000000, 0).UTC()},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			items, _, err := Parse([]byte(tt.data), tt.format)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, items)
+		})
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	_, _, err := Parse([]byte(`[{"url": 1}]`), "")
+	assert.ErrorIs(t, err, ErrInvalidFile)
+
+	_, _, err = Parse([]byte("title,link\n"), consts.ImportFormatPocketCSV)
+	assert.ErrorIs(t, err, ErrInvalidFile, "the required columns are missing")
+
+	_, _, err = Parse([]byte(pocketCSV), "evernote")
+	assert.ErrorIs(t, err, ErrUnknownFormat)
+}
@@ -0,0 +1,56 @@
+package importer
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// omnivoreItem is an item of the metadata of an Omnivore export, its labels are names or, in older exports,
+// objects with a name.
+type omnivoreItem struct {
+	URL     string          `json:"url"`
+	Title   string          `json:"title"`
+	SavedAt string          `json:"savedAt"`
+	Labels  []omnivoreLabel `json:"labels"`
+}
+
+type omnivoreLabel string
+
+func (l *omnivoreLabel) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err == nil {
+		*l = omnivoreLabel(name)
+		return nil
+	}
+
+	var label struct {
+		Name string `json:"name"`
+	}
+	if err := json.Unmarshal(data, &label); err != nil {
+		return err
+	}
+	*l = omnivoreLabel(label.Name)
+	return nil
+}
+
+// parseOmnivoreJSON parses the metadata JSON array of an Omnivore export.
+func parseOmnivoreJSON(data []byte) ([]Item, error) {
+	var omnivoreItems []omnivoreItem
+	if err := json.Unmarshal(data, &omnivoreItems); err != nil {
+		return nil, err
+	}
+
+	items := make([]Item, 0, len(omnivoreItems))
+	for _, omnivore := range omnivoreItems {
+		item := Item{URL: omnivore.URL, Title: omnivore.Title}
+		if savedAt, err := time.Parse(time.RFC3339, omnivore.SavedAt); err == nil {
+			item.SavedAt = savedAt.UTC()
+		}
+		for _, label := range omnivore.Labels {
+			item.Tags = append(item.Tags, string(label))
+		}
+		items = append(items, item)
+	}
+
+	return items, nil
+}
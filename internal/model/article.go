@@ -14,6 +14,10 @@ type Article struct {
 	URL       string    `json:"url" dynamodbav:"url"`
 	CreatedAt time.Time `json:"createdAt" dynamodbav:"createdAt"`
 
+	// CanonicalURL is the URL declared by the page with <link rel="canonical">, the article ID is
+	// derived from it when set, from URL otherwise
+	CanonicalURL string `json:"canonicalUrl,omitempty" dynamodbav:"canonicalUrl,omitempty"`
+
 	// optional metadata
 	Title              string     `json:"title,omitempty" dynamodbav:"title,omitempty"`
 	Content            string     `json:"content,omitempty" dynamodbav:"content,omitempty"`
@@ -28,6 +32,27 @@ type Article struct {
 	WordCount          int        `json:"wordCount,omitempty" dynamodbav:"wordCount,omitempty"`
 	ReadingTimeMinutes int        `json:"readingTimeMinutes,omitempty" dynamodbav:"readingTimeMinutes,omitempty"`
 	PublishedAt        *time.Time `json:"publishedAt,omitempty" dynamodbav:"publishedAt,omitempty"`
+	Warnings           []string   `json:"warnings,omitempty" dynamodbav:"warnings,omitempty"`
+	Extractor          string     `json:"extractor,omitempty" dynamodbav:"extractor,omitempty"`
+	ClientCaptured     bool       `json:"clientCaptured,omitempty" dynamodbav:"clientCaptured,omitempty"`
+	Charset            string     `json:"charset,omitempty" dynamodbav:"charset,omitempty"`
+	Tags               []string   `json:"tags,omitempty" dynamodbav:"tags,omitempty"`
+	Collection         string     `json:"collection,omitempty" dynamodbav:"collection,omitempty"`
+
+	// RetryAt is set when fetching was deferred because the site asked to slow down,
+	// the article can be saved again after it
+	RetryAt *time.Time `json:"retryAt,omitempty" dynamodbav:"retryAt,omitempty"`
+
+	// reading workflow, saved articles start unread, an empty state is unread too (articles saved before it existed)
+	ReadState   consts.ReadState `json:"readState,omitempty" dynamodbav:"readState,omitempty"`
+	ReadAt      *time.Time       `json:"readAt,omitempty" dynamodbav:"readAt,omitempty"`
+	ArchivedAt  *time.Time       `json:"archivedAt,omitempty" dynamodbav:"archivedAt,omitempty"`
+	Favorite    bool             `json:"favorite,omitempty" dynamodbav:"favorite,omitempty"`
+	FavoritedAt *time.Time       `json:"favoritedAt,omitempty" dynamodbav:"favoritedAt,omitempty"`
+
+	// layout preferences, the direction defaults to the one of Language when empty
+	Direction       consts.TextDirection `json:"direction,omitempty" dynamodbav:"direction,omitempty"`
+	VerticalWriting bool                 `json:"verticalWriting,omitempty" dynamodbav:"verticalWriting,omitempty"`
 
 	// email delivery metadata
 	DeliveryStatus     consts.Status        `json:"deliveryStatus,omitempty" dynamodbav:"deliveryStatus,omitempty"`
@@ -0,0 +1,31 @@
+package model
+
+import (
+	"time"
+
+	"github.com/shaftoe/savetoink/internal/consts"
+)
+
+// Feed represents the subscription of an account to an RSS, Atom or JSON feed, with its polling state.
+type Feed struct {
+	Account      string              `json:"account" dynamodbav:"account"`
+	ID           string              `json:"id" dynamodbav:"-"`
+	URL          string              `json:"url" dynamodbav:"url"`
+	Title        string              `json:"title,omitempty" dynamodbav:"title,omitempty"`
+	Delivery     consts.FeedDelivery `json:"delivery" dynamodbav:"delivery"`
+	SubscribedAt time.Time           `json:"subscribedAt" dynamodbav:"subscribedAt"`
+
+	// polling state, the validators of the last response, the most recent entry already saved and the IDs of the
+	// entries already seen, which stay seen once the last entry is no longer in the feed
+	ETag         string     `json:"-" dynamodbav:"etag,omitempty"`
+	LastModified string     `json:"-" dynamodbav:"lastModified,omitempty"`
+	LastItemID   string     `json:"lastItemId,omitempty" dynamodbav:"lastItemId,omitempty"`
+	LastItemAt   *time.Time `json:"lastItemAt,omitempty" dynamodbav:"lastItemAt,omitempty"`
+	SeenItemIDs  []string   `json:"-" dynamodbav:"seenItemIds,omitempty"`
+	LastPolledAt *time.Time `json:"lastPolledAt,omitempty" dynamodbav:"lastPolledAt,omitempty"`
+	Error        string     `json:"error,omitempty" dynamodbav:"error,omitempty"`
+
+	// digest state, the articles saved since the last digest
+	DigestArticleIDs []string   `json:"digestArticleIds,omitempty" dynamodbav:"digestArticleIds,omitempty"`
+	LastDigestAt     *time.Time `json:"lastDigestAt,omitempty" dynamodbav:"lastDigestAt,omitempty"`
+}
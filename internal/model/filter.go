@@ -0,0 +1,38 @@
+package model
+
+import (
+	"time"
+
+	"github.com/shaftoe/savetoink/internal/consts"
+)
+
+// ArticleFilter selects the articles of an account to list and their order, zero fields select all articles.
+// Ranges include their bounds.
+type ArticleFilter struct {
+	DeliveryStatus consts.Status
+	// SourceDomain selects articles from the host, with or without www.
+	SourceDomain string
+	// Language selects articles in the language, a BCP 47 tag: "en" selects "en-US" too.
+	Language    string
+	ContentType string
+	// ReadStates selects articles in any of the states, Favorite only favorite articles when true and the others
+	// when false.
+	ReadStates []consts.ReadState
+	Favorite   *bool
+	// Tag selects articles with the tag, Collection articles in the collection.
+	Tag        string
+	Collection string
+
+	CreatedFrom   time.Time
+	CreatedTo     time.Time
+	PublishedFrom time.Time
+	PublishedTo   time.Time
+
+	// MinReadingTime and MaxReadingTime are reading times in minutes.
+	MinReadingTime int
+	MaxReadingTime int
+
+	// Sort defaults to consts.SortCreated, Order to consts.SortOrderDesc.
+	Sort  consts.ArticleSort
+	Order consts.SortOrder
+}
@@ -0,0 +1,27 @@
+package model
+
+import (
+	"time"
+
+	"github.com/shaftoe/savetoink/internal/consts"
+)
+
+// ImportJob tracks the items of a file imported from another service or a browser, saved in the background.
+type ImportJob struct {
+	Account   string              `json:"account" dynamodbav:"account"`
+	ID        string              `json:"id" dynamodbav:"-"`
+	Format    consts.ImportFormat `json:"format" dynamodbav:"format"`
+	Status    consts.ImportStatus `json:"status" dynamodbav:"status"`
+	StartedAt time.Time           `json:"startedAt" dynamodbav:"startedAt"`
+	UpdatedAt time.Time           `json:"updatedAt" dynamodbav:"updatedAt"`
+
+	// progress, Total is the number of items in the file, Skipped counts the items already saved
+	Total    int `json:"total" dynamodbav:"total"`
+	Imported int `json:"imported" dynamodbav:"imported"`
+	Skipped  int `json:"skipped" dynamodbav:"skipped"`
+	Failed   int `json:"failed" dynamodbav:"failed"`
+
+	// Errors are the errors of the first failed items, at most consts.ImportMaxErrors
+	Errors      []string   `json:"errors,omitempty" dynamodbav:"errors,omitempty"`
+	CompletedAt *time.Time `json:"completedAt,omitempty" dynamodbav:"completedAt,omitempty"`
+}
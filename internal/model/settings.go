@@ -0,0 +1,14 @@
+package model
+
+import (
+	"time"
+
+	"github.com/shaftoe/savetoink/internal/consts"
+)
+
+// Settings represents the per-account preferences applied when processing articles.
+type Settings struct {
+	Account   string          `json:"account" dynamodbav:"account"`
+	LinkMode  consts.LinkMode `json:"linkMode,omitempty" dynamodbav:"linkMode,omitempty"`
+	UpdatedAt time.Time       `json:"updatedAt" dynamodbav:"updatedAt"`
+}
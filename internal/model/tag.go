@@ -0,0 +1,13 @@
+package model
+
+// TagCount is a tag or collection of an account with its number of articles.
+type TagCount struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+// Tags lists the tags and collections of an account, the largest first.
+type Tags struct {
+	Tags        []*TagCount `json:"tags"`
+	Collections []*TagCount `json:"collections"`
+}
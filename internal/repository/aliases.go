@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/shaftoe/savetoink/internal/consts"
+)
+
+const attributeNameArticleID = "articleId"
+
+// StoreAlias implements Repository.StoreAlias.
+// Aliases are stored in the articles table with their id prefixed by consts.DynamoDBAliasIDPrefix.
+func (d *DynamoDB) StoreAlias(ctx context.Context, account, id, articleID string) error {
+	_, err := d.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(d.tableName),
+		Item: map[string]types.AttributeValue{
+			attributeNameAccount:   &types.AttributeValueMemberS{Value: account},
+			attributeNameID:        &types.AttributeValueMemberS{Value: consts.DynamoDBAliasIDPrefix + id},
+			attributeNameArticleID: &types.AttributeValueMemberS{Value: articleID},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to store alias: %w", err)
+	}
+
+	return nil
+}
+
+// GetAlias implements Repository.GetAlias.
+func (d *DynamoDB) GetAlias(ctx context.Context, account, id string) (string, error) {
+	resp, err := d.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(d.tableName),
+		Key: map[string]types.AttributeValue{
+			attributeNameAccount: &types.AttributeValueMemberS{Value: account},
+			attributeNameID:      &types.AttributeValueMemberS{Value: consts.DynamoDBAliasIDPrefix + id},
+		},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to get alias: %w", err)
+	}
+
+	articleID, ok := resp.Item[attributeNameArticleID].(*types.AttributeValueMemberS)
+	if !ok {
+		return "", ErrNotFound
+	}
+
+	return articleID.Value, nil
+}
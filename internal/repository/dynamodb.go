@@ -5,6 +5,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
@@ -14,29 +15,64 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
 	"github.com/shaftoe/savetoink/internal/consts"
 	"github.com/shaftoe/savetoink/internal/model"
+	"github.com/shaftoe/savetoink/internal/search"
 )
 
 const (
 	attributeNameAccount = "account"
 	attributeNameID      = "id"
+
+	// metadataProjection projects the attributes of an article except its content, named by
+	// getProjectionAttributeNames
+	metadataProjection = "#a, #i, #u, #c, #t, #au, #sn, #sd, #e, #iurl, #ct, #l, #err, #wc, #rt, #p, #tg, #col, " +
+		"#dst, #df, #dt, #deu, #db, #rs, #ra, #aa, #fv, #fa"
+	// legacyMetadataProjection projects the attributes of metadataProjection projected by consts.DynamoDBGSIName
+	legacyMetadataProjection = "#a, #i, #u, #c, #t, #au, #sn, #sd, #e, #iurl, #ct, #l, #err, #wc, #rt, #p, " +
+		"#dst, #df, #dt, #deu, #db"
 )
 
 // DynamoDB implements Repository interface using AWS DynamoDB.
 type DynamoDB struct {
-	client    *dynamodb.Client
-	tableName string
+	client         *dynamodb.Client
+	tableName      string
+	articleIndexes int
+	index          search.Index
+	indexes        indexCache
+}
+
+// Option configures a DynamoDB repository.
+type Option func(*DynamoDB)
+
+// WithSearchIndex sets the full-text index searched by Search, kept up to date when articles are stored and
+// deleted. Without it, the articles of an account are indexed in memory when searched, for consts.SearchIndexTTL.
+func WithSearchIndex(index search.Index) Option {
+	return func(d *DynamoDB) {
+		d.index = index
+	}
+}
+
+// WithArticleIndexes sets the number of article list index changes deployed to the table, see
+// consts.ArticleIndexesNone. Without it, articles are only listed by creation date on consts.DynamoDBGSIName.
+func WithArticleIndexes(indexes int) Option {
+	return func(d *DynamoDB) {
+		d.articleIndexes = indexes
+	}
 }
 
 // NewDynamoDB creates a new DynamoDB repository instance.
-func NewDynamoDB(awsConfig *aws.Config, tableName string) *DynamoDB {
+func NewDynamoDB(awsConfig *aws.Config, tableName string, opts ...Option) *DynamoDB {
 	cfg, _ := config.LoadDefaultConfig(context.TODO())
 	if awsConfig != nil && awsConfig.Region == "" {
 		cfg.Region = awsConfig.Region
 	}
-	return &DynamoDB{
+	d := &DynamoDB{
 		client:    dynamodb.NewFromConfig(cfg),
 		tableName: tableName,
 	}
+	for _, opt := range opts {
+		opt(d)
+	}
+	return d
 }
 
 // Store saves an article to DynamoDB.
@@ -51,24 +87,49 @@ func (d *DynamoDB) Store(ctx context.Context, article *model.Article) error {
 		article.CreatedAt = now
 	}
 
+	// dates are compared as strings when listing articles, they are stored in the same time zone
+	article.CreatedAt = article.CreatedAt.UTC()
+	if article.PublishedAt != nil {
+		publishedAt := article.PublishedAt.UTC()
+		article.PublishedAt = &publishedAt
+	}
+
 	item, err := attributevalue.MarshalMap(article)
 	if err != nil {
 		return fmt.Errorf("failed to marshal article: %w", err)
 	}
 
-	_, err = d.client.PutItem(ctx, &dynamodb.PutItemInput{
-		TableName: aws.String(d.tableName),
-		Item:      item,
+	resp, err := d.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName:    aws.String(d.tableName),
+		Item:         item,
+		ReturnValues: types.ReturnValueAllOld,
 	})
 	if err != nil {
 		return fmt.Errorf("failed to store article: %w", err)
 	}
 
+	var previous *model.Article
+	if len(resp.Attributes) > 0 {
+		previous = &model.Article{}
+		if err = attributevalue.UnmarshalMap(resp.Attributes, previous); err != nil {
+			return fmt.Errorf("failed to unmarshal stored article: %w", err)
+		}
+	}
+	if err = d.updateGroups(ctx, article, previous); err != nil {
+		return fmt.Errorf("failed to update tags: %w", err)
+	}
+
+	d.indexArticle(ctx, article)
+
 	return nil
 }
 
 // GetByAccountAndID implements Repository.GetByAccountAndID.
 func (d *DynamoDB) GetByAccountAndID(ctx context.Context, account, id string) (*model.Article, error) {
+	if isReservedID(id) {
+		return nil, ErrNotFound
+	}
+
 	resp, err := d.client.GetItem(ctx, &dynamodb.GetItemInput{
 		TableName: aws.String(d.tableName),
 		Key: map[string]types.AttributeValue{
@@ -92,6 +153,36 @@ func (d *DynamoDB) GetByAccountAndID(ctx context.Context, account, id string) (*
 	return &article, nil
 }
 
+// getMetadata returns the article of the account with ID id without its content.
+func (d *DynamoDB) getMetadata(ctx context.Context, account, id string) (*model.Article, error) {
+	names := d.getProjectionAttributeNames()
+	delete(names, "#account")
+
+	resp, err := d.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(d.tableName),
+		Key: map[string]types.AttributeValue{
+			attributeNameAccount: &types.AttributeValueMemberS{Value: account},
+			attributeNameID:      &types.AttributeValueMemberS{Value: id},
+		},
+		ProjectionExpression:     aws.String(metadataProjection),
+		ExpressionAttributeNames: names,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get article: %w", err)
+	}
+
+	if resp.Item == nil {
+		return nil, ErrNotFound
+	}
+
+	var article model.Article
+	if unmarshalErr := attributevalue.UnmarshalMap(resp.Item, &article); unmarshalErr != nil {
+		return nil, fmt.Errorf("failed to unmarshal article: %w", unmarshalErr)
+	}
+
+	return &article, nil
+}
+
 func (d *DynamoDB) getProjectionAttributeNames() map[string]string {
 	return map[string]string{
 		"#account": attributeNameAccount,
@@ -111,45 +202,59 @@ func (d *DynamoDB) getProjectionAttributeNames() map[string]string {
 		"#wc":      "wordCount",
 		"#rt":      "readingTimeMinutes",
 		"#p":       "publishedAt",
+		"#tg":      "tags",
+		"#col":     "collection",
 		"#dst":     "deliveryStatus",
 		"#df":      "deliveredFrom",
 		"#dt":      "deliveredTo",
 		"#deu":     "deliveredEmailUUID",
 		"#db":      "deliveredBy",
+		"#rs":      "readState",
+		"#ra":      "readAt",
+		"#aa":      "archivedAt",
+		"#fv":      "favorite",
+		"#fa":      "favoritedAt",
 	}
 }
 
-func (d *DynamoDB) totalCountByAccount(ctx context.Context, account string) (int, error) {
-	resp, err := d.client.Query(ctx, &dynamodb.QueryInput{
-		TableName:              aws.String(d.tableName),
-		IndexName:              aws.String(consts.DynamoDBGSIName),
-		KeyConditionExpression: aws.String("#account = :account"),
-		ExpressionAttributeNames: map[string]string{
-			"#account": attributeNameAccount,
-		},
-		ExpressionAttributeValues: map[string]types.AttributeValue{
-			":account": &types.AttributeValueMemberS{Value: account},
-		},
-		Select: types.SelectCount,
-	})
-	if err != nil {
-		return 0, fmt.Errorf("failed to query count: %w", err)
+// countArticles returns the number of articles selected by a query, reading all of them.
+func (d *DynamoDB) countArticles(ctx context.Context, query *articleQuery) (int, error) {
+	input := query.input(d.tableName, nil)
+	input.Select = types.SelectCount
+
+	total := 0
+	paginator := dynamodb.NewQueryPaginator(d.client, input)
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return 0, fmt.Errorf("failed to query count: %w", err)
+		}
+		total += int(page.Count)
 	}
-	return int(resp.Count), nil
+
+	return total, nil
 }
 
 // GetMetadataByAccount implements Repository.GetMetadataByAccount.
-// Returns articles with all metadata fields except content.
+// Returns articles with all metadata fields except content, selected and sorted by DynamoDB on the index of
+// the sort of filter.
 func (d *DynamoDB) GetMetadataByAccount(
 	ctx context.Context,
 	account string,
+	filter *model.ArticleFilter,
 	page, pageSize int,
 ) (articles []*model.Article, lastEvaluatedKey map[string]types.AttributeValue, total int, err error) {
 	if page < consts.MinPage || pageSize < consts.MinPageSize || pageSize > consts.MaxPageSize {
 		pageSize = consts.DefaultPageSize
 	}
+	page = max(page, consts.MinPage)
+
+	query, err := newArticleQuery(account, filter, d.articleIndexes)
+	if err != nil {
+		return nil, nil, 0, err
+	}
 
-	total, err = d.totalCountByAccount(ctx, account)
+	total, err = d.countArticles(ctx, query)
 	if err != nil {
 		return nil, nil, 0, fmt.Errorf("failed to get count: %w", err)
 	}
@@ -163,64 +268,52 @@ func (d *DynamoDB) GetMetadataByAccount(
 		return []*model.Article{}, nil, total, nil
 	}
 
-	var exclusiveStartKey map[string]types.AttributeValue
-	var resp *dynamodb.QueryOutput
+	input := query.input(d.tableName, d.getProjectionAttributeNames())
 
-	for i := 0; i < offset; i += pageSize {
-		skipSize := min(pageSize, offset-i)
-		resp, err = d.queryArticlesByAccount(ctx, account, skipSize, exclusiveStartKey)
-		if err != nil {
-			return nil, nil, 0, fmt.Errorf("failed to query articles: %w", err)
+	// the articles before the page are read and skipped, DynamoDB has no offset
+	var items []map[string]types.AttributeValue
+	skipped := 0
+	for len(items) < pageSize {
+		if !query.filtered() {
+			remaining := offset - skipped + pageSize - len(items)
+			input.Limit = aws.Int32(int32(remaining)) //nolint:gosec // remaining is at most the article count
 		}
-		exclusiveStartKey = resp.LastEvaluatedKey
-		if exclusiveStartKey == nil {
-			break
+
+		resp, queryErr := d.client.Query(ctx, input)
+		if queryErr != nil {
+			return nil, nil, 0, fmt.Errorf("failed to query articles: %w", queryErr)
 		}
-	}
 
-	resp, err = d.queryArticlesByAccount(ctx, account, pageSize, exclusiveStartKey)
-	if err != nil {
-		return nil, nil, 0, fmt.Errorf("failed to query articles: %w", err)
+		for _, item := range resp.Items {
+			if skipped < offset {
+				skipped++
+				continue
+			}
+			if len(items) < pageSize {
+				items = append(items, item)
+			}
+		}
+
+		if resp.LastEvaluatedKey == nil {
+			break
+		}
+		input.ExclusiveStartKey = resp.LastEvaluatedKey
 	}
 
-	articles, err = d.unmarshalArticles(resp.Items)
+	articles, err = d.unmarshalArticles(items)
 	if err != nil {
 		return nil, nil, 0, err
 	}
-
-	return articles, resp.LastEvaluatedKey, total, nil
-}
-
-func (d *DynamoDB) queryArticlesByAccount(
-	ctx context.Context,
-	account string,
-	pageSize int,
-	exclusiveStartKey map[string]types.AttributeValue,
-) (*dynamodb.QueryOutput, error) {
-	queryInput := &dynamodb.QueryInput{
-		TableName:              aws.String(d.tableName),
-		IndexName:              aws.String(consts.DynamoDBGSIName),
-		KeyConditionExpression: aws.String("#account = :account"),
-		ProjectionExpression: aws.String(
-			"#a, #i, #u, #c, #t, #au, #sn, #sd, #e, #iurl, #ct, #l, #err, #wc, #rt, #p, #dst, #df, #dt, #deu, #db",
-		),
-		ExpressionAttributeNames: d.getProjectionAttributeNames(),
-		ExpressionAttributeValues: map[string]types.AttributeValue{
-			":account": &types.AttributeValueMemberS{Value: account},
-		},
-		ScanIndexForward: aws.Bool(false),
-		Limit:            aws.Int32(int32(pageSize)), //nolint:gosec // pageSize is validated to be <= 20
+	// copies of the articles of a tag or collection are stored in its partition
+	for _, article := range articles {
+		article.Account = account
 	}
 
-	if exclusiveStartKey != nil {
-		queryInput.ExclusiveStartKey = exclusiveStartKey
+	if len(items) > 0 && offset+len(items) < total {
+		lastEvaluatedKey = query.lastKey(items[len(items)-1])
 	}
 
-	resp, err := d.client.Query(ctx, queryInput)
-	if err != nil {
-		return nil, fmt.Errorf("failed to query dynamodb: %w", err)
-	}
-	return resp, nil
+	return articles, lastEvaluatedKey, total, nil
 }
 
 func (d *DynamoDB) unmarshalArticles(items []map[string]types.AttributeValue) ([]*model.Article, error) {
@@ -237,23 +330,40 @@ func (d *DynamoDB) unmarshalArticles(items []map[string]types.AttributeValue) ([
 
 // DeleteByAccountAndID implements Repository.DeleteByAccountAndID.
 func (d *DynamoDB) DeleteByAccountAndID(ctx context.Context, account, id string) error {
-	_, err := d.client.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+	if isReservedID(id) {
+		return nil
+	}
+
+	resp, err := d.client.DeleteItem(ctx, &dynamodb.DeleteItemInput{
 		TableName: aws.String(d.tableName),
 		Key: map[string]types.AttributeValue{
 			attributeNameAccount: &types.AttributeValueMemberS{Value: account},
 			attributeNameID:      &types.AttributeValueMemberS{Value: id},
 		},
+		ReturnValues: types.ReturnValueAllOld,
 	})
 	if err != nil {
 		return fmt.Errorf("failed to delete article: %w", err)
 	}
 
+	d.unindex(ctx, account, id)
+
+	if len(resp.Attributes) > 0 {
+		var previous model.Article
+		if err = attributevalue.UnmarshalMap(resp.Attributes, &previous); err != nil {
+			return fmt.Errorf("failed to unmarshal deleted article: %w", err)
+		}
+		if err = d.updateGroups(ctx, &model.Article{Account: account, ID: id}, &previous); err != nil {
+			return fmt.Errorf("failed to update tags: %w", err)
+		}
+	}
+
 	return nil
 }
 
 // DeleteByAccount implements Repository.DeleteByAccount.
 func (d *DynamoDB) DeleteByAccount(ctx context.Context, account string) (int, error) {
-	articles, _, _, err := d.GetMetadataByAccount(ctx, account, 1, consts.MaxPageSize)
+	articles, _, _, err := d.GetMetadataByAccount(ctx, account, nil, 1, consts.MaxPageSize)
 	if err != nil {
 		return 0, fmt.Errorf("failed to get articles for deletion: %w", err)
 	}
@@ -285,10 +395,119 @@ func (d *DynamoDB) DeleteByAccount(ctx context.Context, account string) (int, er
 		if err != nil {
 			return i, fmt.Errorf("failed to delete batch of articles: %w", err)
 		}
+
+		for _, article := range articles[i:end] {
+			d.unindex(ctx, article.Account, article.ID)
+		}
+	}
+
+	if err = d.deleteFromGroups(ctx, account, articles); err != nil {
+		return len(articles), fmt.Errorf("failed to update tags: %w", err)
 	}
 
 	return len(articles), nil
 }
 
-// ErrNotFound is returned when an article is not found.
+// EachByAccount implements Repository.EachByAccount, querying the table one page at a time so that only a page of
+// articles is held in memory. Articles are visited in id order.
+func (d *DynamoDB) EachByAccount(ctx context.Context, account string, fn func(*model.Article) error) error {
+	paginator := dynamodb.NewQueryPaginator(d.client, &dynamodb.QueryInput{
+		TableName:              aws.String(d.tableName),
+		KeyConditionExpression: aws.String("#account = :account"),
+		ExpressionAttributeNames: map[string]string{
+			"#account": attributeNameAccount,
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":account": &types.AttributeValueMemberS{Value: account},
+		},
+	})
+
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to query articles: %w", err)
+		}
+
+		for _, item := range page.Items {
+			if id, ok := item[attributeNameID].(*types.AttributeValueMemberS); ok && isReservedID(id.Value) {
+				continue
+			}
+
+			var article model.Article
+			if err = attributevalue.UnmarshalMap(item, &article); err != nil {
+				return fmt.Errorf("failed to unmarshal article: %w", err)
+			}
+			if err = fn(&article); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// GetSettings implements Repository.GetSettings.
+func (d *DynamoDB) GetSettings(ctx context.Context, account string) (*model.Settings, error) {
+	resp, err := d.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(d.tableName),
+		Key: map[string]types.AttributeValue{
+			attributeNameAccount: &types.AttributeValueMemberS{Value: account},
+			attributeNameID:      &types.AttributeValueMemberS{Value: consts.DynamoDBSettingsID},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get settings: %w", err)
+	}
+
+	if resp.Item == nil {
+		return nil, ErrNotFound
+	}
+
+	var settings model.Settings
+	if unmarshalErr := attributevalue.UnmarshalMap(resp.Item, &settings); unmarshalErr != nil {
+		return nil, fmt.Errorf("failed to unmarshal settings: %w", unmarshalErr)
+	}
+
+	return &settings, nil
+}
+
+// StoreSettings implements Repository.StoreSettings.
+// Settings are stored in the articles table under the reserved consts.DynamoDBSettingsID id.
+func (d *DynamoDB) StoreSettings(ctx context.Context, settings *model.Settings) error {
+	if settings.Account == "" {
+		return errors.New("account field is required")
+	}
+
+	settings.UpdatedAt = time.Now().UTC()
+
+	item, err := attributevalue.MarshalMap(settings)
+	if err != nil {
+		return fmt.Errorf("failed to marshal settings: %w", err)
+	}
+	item[attributeNameID] = &types.AttributeValueMemberS{Value: consts.DynamoDBSettingsID}
+
+	_, err = d.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(d.tableName),
+		Item:      item,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to store settings: %w", err)
+	}
+
+	return nil
+}
+
+// isReservedID reports whether id is the id of an item that is not an article, i.e. settings, an article alias,
+// an import job or the count of a tag or collection.
+func isReservedID(id string) bool {
+	return id == consts.DynamoDBSettingsID || strings.HasPrefix(id, consts.DynamoDBAliasIDPrefix) ||
+		strings.HasPrefix(id, consts.DynamoDBImportIDPrefix) || strings.HasPrefix(id, consts.DynamoDBTagIDPrefix) ||
+		strings.HasPrefix(id, consts.DynamoDBCollectionIDPrefix)
+}
+
+// ErrNotFound is returned when an article, an article alias, account settings, a feed or an import job are not
+// found.
 var ErrNotFound = errors.New("article not found")
+
+// ErrIndexUnavailable is returned when articles are listed in an order whose index isn't deployed yet.
+var ErrIndexUnavailable = errors.New("article index not deployed")
@@ -8,6 +8,9 @@ import (
 	"testing"
 	"time"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
 	"github.com/shaftoe/savetoink/internal/consts"
 	"github.com/shaftoe/savetoink/internal/model"
 	"github.com/stretchr/testify/assert"
@@ -191,7 +194,7 @@ func TestDynamoDB_GetMetadataByAccount(t *testing.T) {
 		require.NoError(t, err)
 	}
 
-	retrieved, _, _, err := repo.GetMetadataByAccount(ctx, account, 1, 20)
+	retrieved, _, _, err := repo.GetMetadataByAccount(ctx, account, nil, 1, 20)
 	skipIfTableNotFound(t, err)
 	require.NoError(t, err)
 	assert.Len(t, retrieved, 2)
@@ -214,7 +217,7 @@ func TestDynamoDB_GetByAccount_Empty(t *testing.T) {
 	repo := setupTestDynamoDB(t)
 	ctx := context.Background()
 
-	retrieved, _, _, err := repo.GetMetadataByAccount(ctx, "non-existent@example.com", 1, 20)
+	retrieved, _, _, err := repo.GetMetadataByAccount(ctx, "non-existent@example.com", nil, 1, 20)
 	skipIfTableNotFound(t, err)
 	require.NoError(t, err)
 	assert.Empty(t, retrieved)
@@ -328,17 +331,58 @@ func TestDynamoDB_DeleteByAccount(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, 2, deleted)
 
-	retrieved, _, _, err := repo.GetMetadataByAccount(ctx, account, 1, 20)
+	retrieved, _, _, err := repo.GetMetadataByAccount(ctx, account, nil, 1, 20)
 	skipIfTableNotFound(t, err)
 	require.NoError(t, err)
 	assert.Empty(t, retrieved)
 
-	otherArticles, _, _, err := repo.GetMetadataByAccount(ctx, "other@example.com", 1, 20)
+	otherArticles, _, _, err := repo.GetMetadataByAccount(ctx, "other@example.com", nil, 1, 20)
 	skipIfTableNotFound(t, err)
 	require.NoError(t, err)
 	assert.Len(t, otherArticles, 1)
 }
 
+func TestDynamoDB_EachByAccount(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	repo := setupTestDynamoDB(t)
+	ctx := context.Background()
+
+	for i := range 3 {
+		err := repo.Store(ctx, &model.Article{
+			Account:   testAccount,
+			ID:        fmt.Sprintf("test-id-each-%d", i),
+			URL:       fmt.Sprintf("https://example.com/each%d", i),
+			Content:   fmt.Sprintf("<p>Content %d</p>", i),
+			CreatedAt: time.Now().UTC(),
+		})
+		skipIfTableNotFound(t, err)
+		require.NoError(t, err)
+	}
+	require.NoError(t, repo.StoreSettings(ctx, &model.Settings{Account: testAccount, LinkMode: consts.LinkModeStrip}))
+	t.Cleanup(func() { _, _ = repo.DeleteByAccount(context.Background(), testAccount) })
+
+	var contents []string
+	err := repo.EachByAccount(ctx, testAccount, func(article *model.Article) error {
+		contents = append(contents, article.Content)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"<p>Content 0</p>", "<p>Content 1</p>", "<p>Content 2</p>"}, contents,
+		"articles are visited with their content, settings are skipped")
+
+	stop := errors.New("stop")
+	visited := 0
+	err = repo.EachByAccount(ctx, testAccount, func(*model.Article) error {
+		visited++
+		return stop
+	})
+	assert.ErrorIs(t, err, stop)
+	assert.Equal(t, 1, visited)
+}
+
 func TestDynamoDB_DeleteByAccount_Empty(t *testing.T) {
 	if testing.Short() {
 		t.Skip("skipping integration test in short mode")
@@ -378,19 +422,19 @@ func TestDynamoDB_GetMetadataByAccount_Pagination_MultiplePages(t *testing.T) {
 		require.NoError(t, err)
 	}
 
-	page1, _, total, err := repo.GetMetadataByAccount(ctx, account, 1, 10)
+	page1, _, total, err := repo.GetMetadataByAccount(ctx, account, nil, 1, 10)
 	skipIfTableNotFound(t, err)
 	require.NoError(t, err)
 	assert.Equal(t, 10, len(page1))
 	assert.Equal(t, numArticles, total)
 
-	page2, _, total, err := repo.GetMetadataByAccount(ctx, account, 2, 10)
+	page2, _, total, err := repo.GetMetadataByAccount(ctx, account, nil, 2, 10)
 	skipIfTableNotFound(t, err)
 	require.NoError(t, err)
 	assert.Equal(t, 10, len(page2))
 	assert.Equal(t, numArticles, total)
 
-	page3, _, total, err := repo.GetMetadataByAccount(ctx, account, 3, 10)
+	page3, _, total, err := repo.GetMetadataByAccount(ctx, account, nil, 3, 10)
 	skipIfTableNotFound(t, err)
 	require.NoError(t, err)
 	assert.Equal(t, 5, len(page3))
@@ -436,14 +480,14 @@ func TestDynamoDB_GetMetadataByAccount_Pagination_LastPage(t *testing.T) {
 		require.NoError(t, err)
 	}
 
-	page1, lastKey1, total, err := repo.GetMetadataByAccount(ctx, account, 1, 10)
+	page1, lastKey1, total, err := repo.GetMetadataByAccount(ctx, account, nil, 1, 10)
 	skipIfTableNotFound(t, err)
 	require.NoError(t, err)
 	assert.Equal(t, 10, len(page1))
 	assert.NotNil(t, lastKey1, "lastEvaluatedKey should be non-nil when there are more results")
 	assert.Equal(t, numArticles, total)
 
-	page2, lastKey2, total, err := repo.GetMetadataByAccount(ctx, account, 2, 10)
+	page2, lastKey2, total, err := repo.GetMetadataByAccount(ctx, account, nil, 2, 10)
 	skipIfTableNotFound(t, err)
 	require.NoError(t, err)
 	assert.Equal(t, 5, len(page2))
@@ -476,7 +520,7 @@ func TestDynamoDB_GetMetadataByAccount_Pagination_PageOutOfRange(t *testing.T) {
 		require.NoError(t, err)
 	}
 
-	articles, lastKey, total, err := repo.GetMetadataByAccount(ctx, account, 10, 10)
+	articles, lastKey, total, err := repo.GetMetadataByAccount(ctx, account, nil, 10, 10)
 	skipIfTableNotFound(t, err)
 	require.NoError(t, err)
 	assert.Empty(t, articles)
@@ -509,19 +553,19 @@ func TestDynamoDB_GetMetadataByAccount_Pagination_TotalCountAccuracy(t *testing.
 		require.NoError(t, err)
 	}
 
-	page1, _, total1, err := repo.GetMetadataByAccount(ctx, account, 1, 5)
+	page1, _, total1, err := repo.GetMetadataByAccount(ctx, account, nil, 1, 5)
 	skipIfTableNotFound(t, err)
 	require.NoError(t, err)
 	assert.Equal(t, 5, len(page1))
 	assert.Equal(t, numArticles, total1)
 
-	page2, _, total2, err := repo.GetMetadataByAccount(ctx, account, 2, 5)
+	page2, _, total2, err := repo.GetMetadataByAccount(ctx, account, nil, 2, 5)
 	skipIfTableNotFound(t, err)
 	require.NoError(t, err)
 	assert.Equal(t, 5, len(page2))
 	assert.Equal(t, numArticles, total2)
 
-	page3, _, total3, err := repo.GetMetadataByAccount(ctx, account, 3, 5)
+	page3, _, total3, err := repo.GetMetadataByAccount(ctx, account, nil, 3, 5)
 	skipIfTableNotFound(t, err)
 	require.NoError(t, err)
 	assert.Equal(t, 2, len(page3))
@@ -556,7 +600,7 @@ func TestDynamoDB_GetMetadataByAccount_Pagination_LargePageSize(t *testing.T) {
 		require.NoError(t, err)
 	}
 
-	articles, _, total, err := repo.GetMetadataByAccount(ctx, account, 1, 100)
+	articles, _, total, err := repo.GetMetadataByAccount(ctx, account, nil, 1, 100)
 	skipIfTableNotFound(t, err)
 	require.NoError(t, err)
 	assert.Equal(t, numArticles, len(articles))
@@ -587,7 +631,7 @@ func TestDynamoDB_GetMetadataByAccount_Pagination_InvalidPageDefaults(t *testing
 		require.NoError(t, err)
 	}
 
-	articles, _, _, err := repo.GetMetadataByAccount(ctx, account, 0, 10)
+	articles, _, _, err := repo.GetMetadataByAccount(ctx, account, nil, 0, 10)
 	skipIfTableNotFound(t, err)
 	require.NoError(t, err)
 	assert.Len(t, articles, 5)
@@ -728,7 +772,7 @@ func TestDynamoDB_GetMetadataByAccount_SortsByCreatedAt(t *testing.T) {
 		require.NoError(t, err)
 	}
 
-	retrieved, _, _, err := repo.GetMetadataByAccount(ctx, account, 1, 20)
+	retrieved, _, _, err := repo.GetMetadataByAccount(ctx, account, nil, 1, 20)
 	skipIfTableNotFound(t, err)
 	require.NoError(t, err)
 	assert.Len(t, retrieved, 5)
@@ -781,11 +825,11 @@ func setupTestDynamoDB(t *testing.T) *DynamoDB {
 	t.Helper()
 
 	tableName := "test-savetoink-articles"
-	repo := NewDynamoDB(nil, tableName)
+	repo := NewDynamoDB(nil, tableName, WithArticleIndexes(consts.ArticleIndexesAll))
 
 	t.Cleanup(func() {
 		ctx := context.Background()
-		articles, _, _, err := repo.GetMetadataByAccount(ctx, testAccount, 1, 20)
+		articles, _, _, err := repo.GetMetadataByAccount(ctx, testAccount, nil, 1, 20)
 		if err != nil {
 			return
 		}
@@ -797,3 +841,250 @@ func setupTestDynamoDB(t *testing.T) *DynamoDB {
 
 	return repo
 }
+
+func TestDynamoDB_StoreSettings(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	repo := setupTestDynamoDB(t)
+	ctx := context.Background()
+
+	err := repo.StoreSettings(ctx, &model.Settings{Account: testAccount, LinkMode: consts.LinkModeEndnotes})
+	skipIfTableNotFound(t, err)
+	require.NoError(t, err)
+
+	settings, err := repo.GetSettings(ctx, testAccount)
+	require.NoError(t, err)
+	assert.Equal(t, testAccount, settings.Account)
+	assert.Equal(t, consts.LinkModeEndnotes, settings.LinkMode)
+	assert.False(t, settings.UpdatedAt.IsZero())
+
+	articles, _, _, err := repo.GetMetadataByAccount(ctx, testAccount, nil, 1, consts.MaxPageSize)
+	require.NoError(t, err)
+	for _, article := range articles {
+		assert.NotEqual(t, consts.DynamoDBSettingsID, article.ID, "settings must not be listed as an article")
+	}
+}
+
+func TestDynamoDB_GetSettings_NotFound(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	repo := setupTestDynamoDB(t)
+	ctx := context.Background()
+
+	_, err := repo.GetSettings(ctx, "no-settings@example.com")
+	skipIfTableNotFound(t, err)
+	assert.Equal(t, ErrNotFound, err)
+}
+
+func TestDynamoDB_Feeds(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	repo := setupTestDynamoDB(t)
+	ctx := context.Background()
+
+	lastItemAt := time.Now().UTC().Truncate(time.Second)
+	feed := &model.Feed{
+		Account:    testAccount,
+		ID:         "feed-id-1",
+		URL:        "https://example.com/feed.xml",
+		Title:      "Example Feed",
+		Delivery:   consts.FeedDeliveryDigest,
+		ETag:       `"v1"`,
+		LastItemID: "https://example.com/post",
+		LastItemAt: &lastItemAt,
+	}
+
+	err := repo.StoreFeed(ctx, feed)
+	skipIfTableNotFound(t, err)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = repo.DeleteFeed(context.Background(), testAccount, feed.ID) })
+
+	got, err := repo.GetFeed(ctx, testAccount, feed.ID)
+	require.NoError(t, err)
+	assert.Equal(t, feed.ID, got.ID)
+	assert.Equal(t, feed.URL, got.URL)
+	assert.Equal(t, consts.FeedDeliveryDigest, got.Delivery)
+	assert.Equal(t, `"v1"`, got.ETag)
+	assert.True(t, lastItemAt.Equal(*got.LastItemAt))
+
+	feeds, err := repo.GetFeedsByAccount(ctx, testAccount)
+	require.NoError(t, err)
+	require.Len(t, feeds, 1)
+	assert.Equal(t, feed.ID, feeds[0].ID)
+
+	all, err := repo.GetAllFeeds(ctx)
+	require.NoError(t, err)
+	assert.NotEmpty(t, all)
+
+	var polled bool
+	for _, other := range all {
+		polled = polled || (other.Account == testAccount && other.ID == feed.ID)
+	}
+	assert.True(t, polled, "the feeds of all accounts must include the feed")
+
+	others, err := repo.GetFeedsByAccount(ctx, "other-"+testAccount)
+	require.NoError(t, err)
+	assert.Empty(t, others)
+
+	_, err = repo.GetByAccountAndID(ctx, testAccount, feed.ID)
+	assert.Equal(t, ErrNotFound, err, "feeds must not be returned as articles")
+
+	require.NoError(t, repo.DeleteFeed(ctx, testAccount, feed.ID))
+	_, err = repo.GetFeed(ctx, testAccount, feed.ID)
+	assert.Equal(t, ErrNotFound, err)
+}
+
+func TestDynamoDB_Aliases(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	repo := setupTestDynamoDB(t)
+	ctx := context.Background()
+
+	err := repo.StoreAlias(ctx, testAccount, "requested-id", "stored-id")
+	skipIfTableNotFound(t, err)
+	require.NoError(t, err)
+
+	got, err := repo.GetAlias(ctx, testAccount, "requested-id")
+	require.NoError(t, err)
+	assert.Equal(t, "stored-id", got)
+
+	_, err = repo.GetAlias(ctx, testAccount, "unknown-id")
+	assert.Equal(t, ErrNotFound, err)
+
+	_, err = repo.GetByAccountAndID(ctx, testAccount, consts.DynamoDBAliasIDPrefix+"requested-id")
+	assert.Equal(t, ErrNotFound, err, "aliases must not be returned as articles")
+}
+
+func TestAttributesUpdate(t *testing.T) {
+	now := time.Now().UTC()
+	from := "sender@example.com"
+
+	tests := []struct {
+		name               string
+		attributes         []string
+		values             any
+		expectedExpression string
+	}{
+		{name: "read", attributes: readingStateAttributes,
+			values: readingState{ReadState: consts.ReadStateRead, ReadAt: &now},
+			expectedExpression: "SET #readState = :readState, #readAt = :readAt " +
+				"REMOVE #archivedAt, #favorite, #favoritedAt"},
+		{name: "archived favorite", attributes: readingStateAttributes,
+			values: readingState{ReadState: consts.ReadStateArchived, ReadAt: &now, ArchivedAt: &now, Favorite: true,
+				FavoritedAt: &now},
+			expectedExpression: "SET #readState = :readState, #readAt = :readAt, #archivedAt = :archivedAt, " +
+				"#favorite = :favorite, #favoritedAt = :favoritedAt"},
+		{name: "no reading state", attributes: readingStateAttributes, values: readingState{},
+			expectedExpression: "REMOVE #readState, #readAt, #archivedAt, #favorite, #favoritedAt"},
+		{name: "delivered", attributes: deliveryAttributes,
+			values: delivery{DeliveryStatus: consts.StatusDelivered, DeliveredFrom: &from},
+			expectedExpression: "SET #deliveryStatus = :deliveryStatus, #deliveredFrom = :deliveredFrom " +
+				"REMOVE #deliveredTo, #deliveredEmailUUID, #deliveredBy"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input, err := (&DynamoDB{tableName: "articles"}).attributesUpdate(tt.attributes, tt.values)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedExpression, aws.ToString(input.UpdateExpression))
+			assert.Equal(t, "attribute_exists(#id)", aws.ToString(input.ConditionExpression))
+		})
+	}
+}
+
+func TestDynamoDB_UpdateReadingState(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	repo := setupTestDynamoDB(t)
+	ctx := context.Background()
+
+	article := &model.Article{Account: testAccount, ID: "test-id-reading", URL: "https://example.com/reading",
+		Title: "Reading", Content: "<p>Reading.</p>", Tags: []string{"go"}, ReadState: consts.ReadStateUnread}
+	err := repo.Store(ctx, article)
+	skipIfTableNotFound(t, err)
+	require.NoError(t, err)
+	t.Cleanup(func() { _, _ = repo.DeleteByAccount(context.Background(), testAccount) })
+
+	readAt := time.Now().UTC().Truncate(time.Second)
+	updated, err := repo.UpdateReadingState(ctx, &model.Article{Account: testAccount, ID: article.ID,
+		ReadState: consts.ReadStateRead, ReadAt: &readAt, Favorite: true, FavoritedAt: &readAt})
+	require.NoError(t, err)
+	assert.Equal(t, "<p>Reading.</p>", updated.Content, "other attributes must be kept")
+	assert.Equal(t, consts.ReadStateRead, updated.ReadState)
+	assert.True(t, updated.Favorite)
+
+	tagged, _, _, err := repo.GetMetadataByAccount(ctx, testAccount, &model.ArticleFilter{Tag: "go",
+		ReadStates: []consts.ReadState{consts.ReadStateRead}}, 1, 10)
+	require.NoError(t, err)
+	require.Len(t, tagged, 1, "the tag copy must be updated")
+	assert.True(t, tagged[0].Favorite)
+
+	delivered, err := repo.UpdateDelivery(ctx, &model.Article{Account: testAccount, ID: article.ID,
+		DeliveryStatus: consts.StatusDelivered})
+	require.NoError(t, err)
+	assert.Equal(t, consts.StatusDelivered, delivered.DeliveryStatus)
+	assert.Equal(t, consts.ReadStateRead, delivered.ReadState, "the reading state must be kept")
+
+	_, err = repo.UpdateReadingState(ctx, &model.Article{Account: testAccount, ID: "unknown-id",
+		ReadState: consts.ReadStateRead})
+	assert.Equal(t, ErrNotFound, err)
+
+	_, err = repo.GetByAccountAndID(ctx, testAccount, "unknown-id")
+	assert.Equal(t, ErrNotFound, err, "updating an unknown article must not create it")
+}
+
+func TestDynamoDB_ImportJobs(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	repo := setupTestDynamoDB(t)
+	ctx := context.Background()
+
+	job := &model.ImportJob{
+		Account:   testAccount,
+		ID:        "import-id-1",
+		Format:    consts.ImportFormatPocketCSV,
+		Status:    consts.ImportStatusRunning,
+		StartedAt: time.Now().UTC().Truncate(time.Second),
+		Total:     3,
+		Imported:  1,
+		Errors:    []string{"failed to fetch"},
+	}
+
+	err := repo.StoreImportJob(ctx, job)
+	skipIfTableNotFound(t, err)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_, _ = repo.client.DeleteItem(context.Background(), &dynamodb.DeleteItemInput{
+			TableName: aws.String(repo.tableName),
+			Key: map[string]types.AttributeValue{
+				attributeNameAccount: &types.AttributeValueMemberS{Value: testAccount},
+				attributeNameID:      &types.AttributeValueMemberS{Value: consts.DynamoDBImportIDPrefix + job.ID},
+			},
+		})
+	})
+
+	got, err := repo.GetImportJob(ctx, testAccount, job.ID)
+	require.NoError(t, err)
+	assert.Equal(t, job.ID, got.ID)
+	assert.Equal(t, consts.ImportStatusRunning, got.Status)
+	assert.Equal(t, 3, got.Total)
+	assert.Equal(t, []string{"failed to fetch"}, got.Errors)
+
+	_, err = repo.GetByAccountAndID(ctx, testAccount, consts.DynamoDBImportIDPrefix+job.ID)
+	assert.Equal(t, ErrNotFound, err, "import jobs must not be returned as articles")
+
+	_, err = repo.GetImportJob(ctx, testAccount, "missing")
+	assert.Equal(t, ErrNotFound, err)
+}
@@ -0,0 +1,158 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/shaftoe/savetoink/internal/consts"
+	"github.com/shaftoe/savetoink/internal/model"
+)
+
+const attributeNameOwner = "owner"
+
+// StoreFeed implements Repository.StoreFeed.
+// Feeds of all accounts are stored in the articles table under the consts.DynamoDBFeedsAccount partition, so that
+// polling them is a query, with their id prefixed by the account, recorded in the owner attribute.
+func (d *DynamoDB) StoreFeed(ctx context.Context, feed *model.Feed) error {
+	if feed.Account == "" {
+		return errors.New("account field is required")
+	}
+
+	if feed.SubscribedAt.IsZero() {
+		feed.SubscribedAt = time.Now().UTC()
+	}
+
+	item, err := attributevalue.MarshalMap(feed)
+	if err != nil {
+		return fmt.Errorf("failed to marshal feed: %w", err)
+	}
+	for name, value := range feedKey(feed.Account, feed.ID) {
+		item[name] = value
+	}
+	item[attributeNameOwner] = &types.AttributeValueMemberS{Value: feed.Account}
+
+	_, err = d.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(d.tableName),
+		Item:      item,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to store feed: %w", err)
+	}
+
+	return nil
+}
+
+// GetFeed implements Repository.GetFeed.
+func (d *DynamoDB) GetFeed(ctx context.Context, account, id string) (*model.Feed, error) {
+	resp, err := d.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(d.tableName),
+		Key:       feedKey(account, id),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get feed: %w", err)
+	}
+
+	if resp.Item == nil {
+		return nil, ErrNotFound
+	}
+
+	return unmarshalFeed(resp.Item)
+}
+
+// GetFeedsByAccount implements Repository.GetFeedsByAccount.
+func (d *DynamoDB) GetFeedsByAccount(ctx context.Context, account string) ([]*model.Feed, error) {
+	return d.queryFeeds(ctx, &dynamodb.QueryInput{
+		TableName:              aws.String(d.tableName),
+		KeyConditionExpression: aws.String("#account = :feeds AND begins_with(#id, :prefix)"),
+		FilterExpression:       aws.String("#owner = :owner"),
+		ExpressionAttributeNames: map[string]string{
+			"#account": attributeNameAccount,
+			"#id":      attributeNameID,
+			"#owner":   attributeNameOwner,
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":feeds":  &types.AttributeValueMemberS{Value: consts.DynamoDBFeedsAccount},
+			":prefix": &types.AttributeValueMemberS{Value: account + "#"},
+			":owner":  &types.AttributeValueMemberS{Value: account},
+		},
+	})
+}
+
+// GetAllFeeds implements Repository.GetAllFeeds, querying the partition holding the feeds of all accounts.
+func (d *DynamoDB) GetAllFeeds(ctx context.Context) ([]*model.Feed, error) {
+	return d.queryFeeds(ctx, &dynamodb.QueryInput{
+		TableName:              aws.String(d.tableName),
+		KeyConditionExpression: aws.String("#account = :feeds"),
+		ExpressionAttributeNames: map[string]string{
+			"#account": attributeNameAccount,
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":feeds": &types.AttributeValueMemberS{Value: consts.DynamoDBFeedsAccount},
+		},
+	})
+}
+
+// DeleteFeed implements Repository.DeleteFeed.
+func (d *DynamoDB) DeleteFeed(ctx context.Context, account, id string) error {
+	_, err := d.client.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName: aws.String(d.tableName),
+		Key:       feedKey(account, id),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete feed: %w", err)
+	}
+
+	return nil
+}
+
+func (d *DynamoDB) queryFeeds(ctx context.Context, input *dynamodb.QueryInput) ([]*model.Feed, error) {
+	paginator := dynamodb.NewQueryPaginator(d.client, input)
+
+	feeds := []*model.Feed{}
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query feeds: %w", err)
+		}
+		for _, item := range page.Items {
+			feed, err := unmarshalFeed(item)
+			if err != nil {
+				return nil, err
+			}
+			feeds = append(feeds, feed)
+		}
+	}
+
+	return feeds, nil
+}
+
+// feedKey returns the key of the item holding the feed with id of account.
+func feedKey(account, id string) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		attributeNameAccount: &types.AttributeValueMemberS{Value: consts.DynamoDBFeedsAccount},
+		attributeNameID:      &types.AttributeValueMemberS{Value: account + "#" + id},
+	}
+}
+
+func unmarshalFeed(item map[string]types.AttributeValue) (*model.Feed, error) {
+	var feed model.Feed
+	if err := attributevalue.UnmarshalMap(item, &feed); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal feed: %w", err)
+	}
+
+	if owner, ok := item[attributeNameOwner].(*types.AttributeValueMemberS); ok {
+		feed.Account = owner.Value
+	}
+	if id, ok := item[attributeNameID].(*types.AttributeValueMemberS); ok {
+		feed.ID = strings.TrimPrefix(id.Value, feed.Account+"#")
+	}
+
+	return &feed, nil
+}
@@ -0,0 +1,240 @@
+package repository
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/shaftoe/savetoink/internal/consts"
+	"github.com/shaftoe/savetoink/internal/model"
+)
+
+// articleQuery queries the articles of an account selected by a filter on the index of its sort: ranges of the
+// sort attribute are key conditions, the other fields filter expressions evaluated by DynamoDB. Articles of a tag
+// or collection are read from its partition, see groupPartition.
+type articleQuery struct {
+	index        string
+	projection   string
+	sortKey      string
+	keyCondition []string
+	conditions   []string
+	names        map[string]string
+	values       map[string]types.AttributeValue
+	forward      bool
+}
+
+// newArticleQuery returns the query of filter on the article list indexes deployed, see consts.ArticleIndexesNone.
+// Returns ErrIndexUnavailable when the index of the sort of filter isn't deployed yet.
+func newArticleQuery(account string, filter *model.ArticleFilter, indexes int) (*articleQuery, error) {
+	if filter == nil {
+		filter = &model.ArticleFilter{}
+	}
+
+	partition := account
+	switch {
+	case filter.Tag != "":
+		partition = groupPartition(account, consts.DynamoDBTagIDPrefix+filter.Tag)
+	case filter.Collection != "":
+		partition = groupPartition(account, consts.DynamoDBCollectionIDPrefix+filter.Collection)
+	}
+
+	q := &articleQuery{
+		index:        consts.DynamoDBGSINameV2,
+		projection:   metadataProjection,
+		sortKey:      "createdAt",
+		keyCondition: []string{"#account = :account"},
+		names:        map[string]string{"#account": attributeNameAccount},
+		values:       map[string]types.AttributeValue{":account": &types.AttributeValueMemberS{Value: partition}},
+		forward:      filter.Order == consts.SortOrderAsc,
+	}
+
+	switch filter.Sort {
+	case consts.SortPublished:
+		if indexes < consts.ArticleIndexesPublishedAt {
+			return nil, fmt.Errorf("%w: %s", ErrIndexUnavailable, consts.DynamoDBPublishedAtGSIName)
+		}
+		q.index, q.sortKey = consts.DynamoDBPublishedAtGSIName, "publishedAt"
+	case consts.SortReadingTime:
+		if indexes < consts.ArticleIndexesReadingTime {
+			return nil, fmt.Errorf("%w: %s", ErrIndexUnavailable, consts.DynamoDBReadingTimeGSIName)
+		}
+		q.index, q.sortKey = consts.DynamoDBReadingTimeGSIName, "readingTimeMinutes"
+	case consts.SortCreated:
+	default:
+	}
+	if q.index == consts.DynamoDBGSINameV2 && indexes < consts.ArticleIndexesCreatedAtV2 {
+		// the first index only projects the attributes of the articles listed before tags and reading states
+		q.index, q.projection = consts.DynamoDBGSIName, legacyMetadataProjection
+	}
+
+	q.addRange("createdAt", timeValue(filter.CreatedFrom), timeValue(filter.CreatedTo))
+	q.addRange("publishedAt", timeValue(filter.PublishedFrom), timeValue(filter.PublishedTo))
+	q.addRange("readingTimeMinutes", numberValue(filter.MinReadingTime), numberValue(filter.MaxReadingTime))
+
+	if filter.DeliveryStatus != "" {
+		q.addCondition("#deliveryStatus = :deliveryStatus", "deliveryStatus", map[string]types.AttributeValue{
+			":deliveryStatus": &types.AttributeValueMemberS{Value: string(filter.DeliveryStatus)},
+		})
+	}
+	if filter.SourceDomain != "" {
+		domain := strings.TrimPrefix(strings.ToLower(filter.SourceDomain), "www.")
+		q.addCondition("#sourceDomain IN (:sourceDomain, :wwwSourceDomain)", "sourceDomain",
+			map[string]types.AttributeValue{
+				":sourceDomain":    &types.AttributeValueMemberS{Value: domain},
+				":wwwSourceDomain": &types.AttributeValueMemberS{Value: "www." + domain},
+			})
+	}
+	if filter.Language != "" {
+		language := strings.ReplaceAll(filter.Language, "_", "-")
+		q.addCondition("(#language = :language OR begins_with(#language, :languagePrefix))", "language",
+			map[string]types.AttributeValue{
+				":language":       &types.AttributeValueMemberS{Value: language},
+				":languagePrefix": &types.AttributeValueMemberS{Value: language + "-"},
+			})
+	}
+	if filter.ContentType != "" {
+		q.addCondition("#contentType = :contentType", "contentType", map[string]types.AttributeValue{
+			":contentType": &types.AttributeValueMemberS{Value: filter.ContentType},
+		})
+	}
+	if len(filter.ReadStates) > 0 {
+		placeholders := make([]string, 0, len(filter.ReadStates))
+		values := make(map[string]types.AttributeValue, len(filter.ReadStates))
+		for i, state := range filter.ReadStates {
+			placeholder := ":readState" + strconv.Itoa(i)
+			placeholders = append(placeholders, placeholder)
+			values[placeholder] = &types.AttributeValueMemberS{Value: string(state)}
+		}
+		condition := "#readState IN (" + strings.Join(placeholders, ", ") + ")"
+		if slices.Contains(filter.ReadStates, consts.ReadStateUnread) {
+			// Articles saved before reading states, or never delivered, have none and are unread.
+			condition = "(attribute_not_exists(#readState) OR " + condition + ")"
+		}
+		q.addCondition(condition, "readState", values)
+	}
+	if filter.Favorite != nil {
+		condition := "#favorite = :favorite"
+		if !*filter.Favorite {
+			// articles are stored without favorite until favorited
+			condition = "(attribute_not_exists(#favorite) OR #favorite = :favorite)"
+		}
+		q.addCondition(condition, "favorite", map[string]types.AttributeValue{
+			":favorite": &types.AttributeValueMemberBOOL{Value: *filter.Favorite},
+		})
+	}
+	if filter.Tag != "" && filter.Collection != "" {
+		q.addCondition("#collection = :collection", "collection", map[string]types.AttributeValue{
+			":collection": &types.AttributeValueMemberS{Value: filter.Collection},
+		})
+	}
+
+	return q, nil
+}
+
+// addRange selects articles with attribute between from and to, ignoring nil bounds. Ranges of the sort
+// attribute are key conditions, reading only the selected articles.
+func (q *articleQuery) addRange(attribute string, from, to types.AttributeValue) {
+	name, fromValue, toValue := "#"+attribute, ":"+attribute+"From", ":"+attribute+"To"
+
+	var condition string
+	switch {
+	case from != nil && to != nil:
+		condition = name + " BETWEEN " + fromValue + " AND " + toValue
+	case from != nil:
+		condition = name + " >= " + fromValue
+	case to != nil:
+		condition = name + " <= " + toValue
+	default:
+		return
+	}
+
+	q.names[name] = attribute
+	if from != nil {
+		q.values[fromValue] = from
+	}
+	if to != nil {
+		q.values[toValue] = to
+	}
+
+	if attribute == q.sortKey {
+		q.keyCondition = append(q.keyCondition, condition)
+	} else {
+		q.conditions = append(q.conditions, condition)
+	}
+}
+
+func (q *articleQuery) addCondition(condition, attribute string, values map[string]types.AttributeValue) {
+	q.conditions = append(q.conditions, condition)
+	q.names["#"+attribute] = attribute
+	maps.Copy(q.values, values)
+}
+
+// filtered reports whether DynamoDB drops some of the articles read by the query.
+func (q *articleQuery) filtered() bool {
+	return len(q.conditions) > 0
+}
+
+// input returns the input of the query, projecting the attributes of q.projection named by projectionNames when
+// not nil.
+func (q *articleQuery) input(tableName string, projectionNames map[string]string) *dynamodb.QueryInput {
+	names := maps.Clone(q.names)
+	var projection string
+	if projectionNames != nil {
+		projection = q.projection
+		placeholders := strings.Split(projection, ", ")
+		for placeholder, name := range projectionNames {
+			// DynamoDB refuses names not used by the expressions
+			if slices.Contains(placeholders, placeholder) {
+				names[placeholder] = name
+			}
+		}
+	}
+
+	input := &dynamodb.QueryInput{
+		TableName:                 aws.String(tableName),
+		IndexName:                 aws.String(q.index),
+		KeyConditionExpression:    aws.String(strings.Join(q.keyCondition, " AND ")),
+		ExpressionAttributeNames:  names,
+		ExpressionAttributeValues: q.values,
+		ScanIndexForward:          aws.Bool(q.forward),
+	}
+	if projection != "" {
+		input.ProjectionExpression = aws.String(projection)
+	}
+	if q.filtered() {
+		input.FilterExpression = aws.String(strings.Join(q.conditions, " AND "))
+	}
+
+	return input
+}
+
+// lastKey returns the key of an item of the index of the query, to resume the query after it.
+func (q *articleQuery) lastKey(item map[string]types.AttributeValue) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		attributeNameAccount: item[attributeNameAccount],
+		attributeNameID:      item[attributeNameID],
+		q.sortKey:            item[q.sortKey],
+	}
+}
+
+// timeValue returns the attribute value of t as stored by attributevalue, nil for the zero time.
+func timeValue(t time.Time) types.AttributeValue {
+	if t.IsZero() {
+		return nil
+	}
+	return &types.AttributeValueMemberS{Value: t.UTC().Format(time.RFC3339Nano)}
+}
+
+// numberValue returns the attribute value of n, nil for 0.
+func numberValue(n int) types.AttributeValue {
+	if n == 0 {
+		return nil
+	}
+	return &types.AttributeValueMemberN{Value: strconv.Itoa(n)}
+}
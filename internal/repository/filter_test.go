@@ -0,0 +1,268 @@
+package repository
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/shaftoe/savetoink/internal/consts"
+	"github.com/shaftoe/savetoink/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewArticleQuery(t *testing.T) {
+	from := time.Date(2024, 5, 1, 12, 0, 0, 0, time.FixedZone("CEST", 2*3600))
+	to := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name                 string
+		filter               *model.ArticleFilter
+		expectedIndex        string
+		expectedKeyCondition string
+		expectedFilter       string
+		expectedForward      bool
+		expectedValues       map[string]string
+	}{
+		{
+			name:                 "no filter",
+			expectedIndex:        consts.DynamoDBGSINameV2,
+			expectedKeyCondition: "#account = :account",
+		},
+		{
+			name:                 "created range on created sort",
+			filter:               &model.ArticleFilter{CreatedFrom: from, CreatedTo: to, Order: consts.SortOrderAsc},
+			expectedIndex:        consts.DynamoDBGSINameV2,
+			expectedKeyCondition: "#account = :account AND #createdAt BETWEEN :createdAtFrom AND :createdAtTo",
+			expectedForward:      true,
+			expectedValues: map[string]string{
+				":createdAtFrom": "2024-05-01T10:00:00Z",
+				":createdAtTo":   "2024-06-01T00:00:00Z",
+			},
+		},
+		{
+			name:                 "created range on published sort",
+			filter:               &model.ArticleFilter{CreatedFrom: from, Sort: consts.SortPublished},
+			expectedIndex:        consts.DynamoDBPublishedAtGSIName,
+			expectedKeyCondition: "#account = :account",
+			expectedFilter:       "#createdAt >= :createdAtFrom",
+		},
+		{
+			name: "reading time range on reading time sort",
+			filter: &model.ArticleFilter{MaxReadingTime: 10, PublishedTo: to, Sort: consts.SortReadingTime,
+				Order: consts.SortOrderDesc},
+			expectedIndex:        consts.DynamoDBReadingTimeGSIName,
+			expectedKeyCondition: "#account = :account AND #readingTimeMinutes <= :readingTimeMinutesTo",
+			expectedFilter:       "#publishedAt <= :publishedAtTo",
+		},
+		{
+			name: "attributes",
+			filter: &model.ArticleFilter{
+				DeliveryStatus: consts.StatusFailed,
+				SourceDomain:   "WWW.Example.com",
+				Language:       "en_US",
+				ContentType:    "article",
+			},
+			expectedIndex:        consts.DynamoDBGSINameV2,
+			expectedKeyCondition: "#account = :account",
+			expectedFilter: "#deliveryStatus = :deliveryStatus AND " +
+				"#sourceDomain IN (:sourceDomain, :wwwSourceDomain) AND " +
+				"(#language = :language OR begins_with(#language, :languagePrefix)) AND " +
+				"#contentType = :contentType",
+			expectedValues: map[string]string{
+				":sourceDomain":    "example.com",
+				":wwwSourceDomain": "www.example.com",
+				":languagePrefix":  "en-US-",
+			},
+		},
+		{
+			name: "read states and favorite",
+			filter: &model.ArticleFilter{
+				ReadStates: []consts.ReadState{consts.ReadStateUnread, consts.ReadStateRead},
+				Favorite:   aws.Bool(true),
+			},
+			expectedIndex:        consts.DynamoDBGSINameV2,
+			expectedKeyCondition: "#account = :account",
+			expectedFilter: "(attribute_not_exists(#readState) OR #readState IN (:readState0, :readState1)) AND " +
+				"#favorite = :favorite",
+			expectedValues: map[string]string{":readState0": "unread", ":readState1": "read"},
+		},
+		{
+			name:                 "not favorite",
+			filter:               &model.ArticleFilter{Favorite: aws.Bool(false)},
+			expectedIndex:        consts.DynamoDBGSINameV2,
+			expectedKeyCondition: "#account = :account",
+			expectedFilter:       "(attribute_not_exists(#favorite) OR #favorite = :favorite)",
+		},
+		{
+			name:                 "archived",
+			filter:               &model.ArticleFilter{ReadStates: []consts.ReadState{consts.ReadStateArchived}},
+			expectedIndex:        consts.DynamoDBGSINameV2,
+			expectedKeyCondition: "#account = :account",
+			expectedFilter:       "#readState IN (:readState0)",
+			expectedValues:       map[string]string{":readState0": "archived"},
+		},
+		{
+			name:                 "tag and collection",
+			filter:               &model.ArticleFilter{Tag: "go", Collection: "Reading list"},
+			expectedIndex:        consts.DynamoDBGSINameV2,
+			expectedKeyCondition: "#account = :account",
+			expectedFilter:       "#collection = :collection",
+			expectedValues: map[string]string{
+				":account":    testAccount + "#tag#go",
+				":collection": "Reading list",
+			},
+		},
+		{
+			name:                 "collection",
+			filter:               &model.ArticleFilter{Collection: "Reading list"},
+			expectedIndex:        consts.DynamoDBGSINameV2,
+			expectedKeyCondition: "#account = :account",
+			expectedValues:       map[string]string{":account": testAccount + "#collection#Reading list"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, err := newArticleQuery(testAccount, tt.filter, consts.ArticleIndexesAll)
+			require.NoError(t, err)
+			input := query.input("articles", nil)
+
+			assert.Equal(t, tt.expectedIndex, aws.ToString(input.IndexName))
+			assert.Equal(t, tt.expectedKeyCondition, aws.ToString(input.KeyConditionExpression))
+			assert.Equal(t, tt.expectedFilter, aws.ToString(input.FilterExpression))
+			assert.Equal(t, tt.expectedFilter != "", query.filtered())
+			assert.Equal(t, tt.expectedForward, aws.ToBool(input.ScanIndexForward))
+			for placeholder, value := range tt.expectedValues {
+				assert.Equal(t, &types.AttributeValueMemberS{Value: value}, input.ExpressionAttributeValues[placeholder])
+			}
+			assert.Len(t, input.ExpressionAttributeNames, len(query.names), "only used names are set")
+		})
+	}
+}
+
+func TestNewArticleQueryIndexes(t *testing.T) {
+	tests := []struct {
+		name               string
+		sort               consts.ArticleSort
+		indexes            int
+		expectedIndex      string
+		expectedProjection string
+		expectedErr        error
+	}{
+		{name: "first index", indexes: consts.ArticleIndexesNone, expectedIndex: consts.DynamoDBGSIName,
+			expectedProjection: legacyMetadataProjection},
+		{name: "before the second created index", indexes: consts.ArticleIndexesReadingTime,
+			expectedIndex: consts.DynamoDBGSIName, expectedProjection: legacyMetadataProjection},
+		{name: "second created index", indexes: consts.ArticleIndexesCreatedAtV2,
+			expectedIndex: consts.DynamoDBGSINameV2, expectedProjection: metadataProjection},
+		{name: "published sort", sort: consts.SortPublished, indexes: consts.ArticleIndexesPublishedAt,
+			expectedIndex: consts.DynamoDBPublishedAtGSIName, expectedProjection: metadataProjection},
+		{name: "published sort not deployed", sort: consts.SortPublished, indexes: consts.ArticleIndexesNone,
+			expectedErr: ErrIndexUnavailable},
+		{name: "reading time sort not deployed", sort: consts.SortReadingTime,
+			indexes: consts.ArticleIndexesPublishedAt, expectedErr: ErrIndexUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, err := newArticleQuery(testAccount, &model.ArticleFilter{Sort: tt.sort}, tt.indexes)
+			if tt.expectedErr != nil {
+				require.ErrorIs(t, err, tt.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+
+			input := query.input("articles", (&DynamoDB{}).getProjectionAttributeNames())
+			assert.Equal(t, tt.expectedIndex, aws.ToString(input.IndexName))
+			assert.Equal(t, tt.expectedProjection, aws.ToString(input.ProjectionExpression))
+			for placeholder := range input.ExpressionAttributeNames {
+				if placeholder != "#account" {
+					assert.Contains(t, strings.Split(tt.expectedProjection, ", "), placeholder,
+						"only names of the projection are set")
+				}
+			}
+		})
+	}
+}
+
+func TestDynamoDB_GetMetadataByAccount_Filter(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	repo := setupTestDynamoDB(t)
+	ctx := context.Background()
+
+	now := time.Now().UTC()
+	published := now.Add(-48 * time.Hour)
+	articles := []*model.Article{
+		{Account: testAccount, ID: "test-id-filter-1", URL: "https://go.dev/1", SourceDomain: "go.dev",
+			Language: "en-US", ReadingTimeMinutes: 12, PublishedAt: &published, CreatedAt: now.Add(-2 * time.Hour),
+			DeliveryStatus: consts.StatusDelivered, ReadState: consts.ReadStateArchived, Favorite: true},
+		{Account: testAccount, ID: "test-id-filter-2", URL: "https://example.com/2", SourceDomain: "www.example.com",
+			Language: "fr", ReadingTimeMinutes: 3, CreatedAt: now.Add(-1 * time.Hour),
+			DeliveryStatus: consts.StatusFailed},
+		{Account: testAccount, ID: "test-id-filter-3", URL: "https://go.dev/3", SourceDomain: "go.dev",
+			Language: "en", ReadingTimeMinutes: 7, CreatedAt: now, DeliveryStatus: consts.StatusDelivered,
+			ReadState: consts.ReadStateUnread},
+	}
+	for _, article := range articles {
+		err := repo.Store(ctx, article)
+		skipIfTableNotFound(t, err)
+		require.NoError(t, err)
+	}
+	t.Cleanup(func() { _, _ = repo.DeleteByAccount(context.Background(), testAccount) })
+
+	tests := []struct {
+		name        string
+		filter      *model.ArticleFilter
+		expectedIDs []string
+	}{
+		{name: "newest first", expectedIDs: []string{"test-id-filter-3", "test-id-filter-2", "test-id-filter-1"}},
+		{name: "domain and language", filter: &model.ArticleFilter{SourceDomain: "go.dev", Language: "en"},
+			expectedIDs: []string{"test-id-filter-3", "test-id-filter-1"}},
+		{name: "delivery status", filter: &model.ArticleFilter{DeliveryStatus: consts.StatusFailed},
+			expectedIDs: []string{"test-id-filter-2"}},
+		{name: "created range", filter: &model.ArticleFilter{CreatedFrom: now.Add(-90 * time.Minute), CreatedTo: now},
+			expectedIDs: []string{"test-id-filter-3", "test-id-filter-2"}},
+		{name: "reading time sort",
+			filter:      &model.ArticleFilter{MinReadingTime: 5, Sort: consts.SortReadingTime, Order: consts.SortOrderAsc},
+			expectedIDs: []string{"test-id-filter-3", "test-id-filter-1"}},
+		{name: "read states", filter: &model.ArticleFilter{
+			ReadStates: []consts.ReadState{consts.ReadStateUnread, consts.ReadStateArchived}},
+			expectedIDs: []string{"test-id-filter-3", "test-id-filter-2", "test-id-filter-1"}},
+		{name: "archived", filter: &model.ArticleFilter{ReadStates: []consts.ReadState{consts.ReadStateArchived}},
+			expectedIDs: []string{"test-id-filter-1"}},
+		{name: "favorite", filter: &model.ArticleFilter{Favorite: aws.Bool(true)},
+			expectedIDs: []string{"test-id-filter-1"}},
+		{name: "not favorite", filter: &model.ArticleFilter{Favorite: aws.Bool(false)},
+			expectedIDs: []string{"test-id-filter-3", "test-id-filter-2"}},
+		{name: "published sort", filter: &model.ArticleFilter{Sort: consts.SortPublished},
+			expectedIDs: []string{"test-id-filter-1"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			retrieved, _, total, err := repo.GetMetadataByAccount(ctx, testAccount, tt.filter, 1, 20)
+			require.NoError(t, err)
+			assert.Equal(t, len(tt.expectedIDs), total)
+
+			ids := make([]string, 0, len(retrieved))
+			for _, article := range retrieved {
+				ids = append(ids, article.ID)
+			}
+			assert.Equal(t, tt.expectedIDs, ids)
+
+			if len(tt.expectedIDs) > 1 {
+				page2, _, _, err := repo.GetMetadataByAccount(ctx, testAccount, tt.filter, 2, 1)
+				require.NoError(t, err)
+				require.Len(t, page2, 1)
+				assert.Equal(t, tt.expectedIDs[1], page2[0].ID)
+			}
+		})
+	}
+}
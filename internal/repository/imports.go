@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/shaftoe/savetoink/internal/consts"
+	"github.com/shaftoe/savetoink/internal/model"
+)
+
+// StoreImportJob implements Repository.StoreImportJob.
+// Import jobs are stored in the articles table with their id prefixed by consts.DynamoDBImportIDPrefix.
+func (d *DynamoDB) StoreImportJob(ctx context.Context, job *model.ImportJob) error {
+	if job.Account == "" {
+		return errors.New("account field is required")
+	}
+
+	item, err := attributevalue.MarshalMap(job)
+	if err != nil {
+		return fmt.Errorf("failed to marshal import job: %w", err)
+	}
+	item[attributeNameID] = &types.AttributeValueMemberS{Value: consts.DynamoDBImportIDPrefix + job.ID}
+
+	_, err = d.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(d.tableName),
+		Item:      item,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to store import job: %w", err)
+	}
+
+	return nil
+}
+
+// GetImportJob implements Repository.GetImportJob.
+func (d *DynamoDB) GetImportJob(ctx context.Context, account, id string) (*model.ImportJob, error) {
+	resp, err := d.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(d.tableName),
+		Key: map[string]types.AttributeValue{
+			attributeNameAccount: &types.AttributeValueMemberS{Value: account},
+			attributeNameID:      &types.AttributeValueMemberS{Value: consts.DynamoDBImportIDPrefix + id},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get import job: %w", err)
+	}
+
+	if resp.Item == nil {
+		return nil, ErrNotFound
+	}
+
+	var job model.ImportJob
+	if err = attributevalue.UnmarshalMap(resp.Item, &job); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal import job: %w", err)
+	}
+
+	if itemID, ok := resp.Item[attributeNameID].(*types.AttributeValueMemberS); ok {
+		job.ID = strings.TrimPrefix(itemID.Value, consts.DynamoDBImportIDPrefix)
+	}
+
+	return &job, nil
+}
@@ -5,17 +5,46 @@ import (
 
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
 	"github.com/shaftoe/savetoink/internal/model"
+	"github.com/shaftoe/savetoink/internal/search"
 )
 
-// Repository defines the interface for article persistence.
+// Repository defines the interface for article, article aliases, tags, account settings, feed subscriptions and
+// import jobs persistence.
 type Repository interface {
 	Store(ctx context.Context, article *model.Article) error
 	GetByAccountAndID(ctx context.Context, account, id string) (*model.Article, error)
+	// UpdateReadingState stores the read state, favorite flag and their times of an existing article, leaving its
+	// other attributes untouched, and returns the updated article. Returns ErrNotFound when it isn't saved.
+	UpdateReadingState(ctx context.Context, article *model.Article) (*model.Article, error)
+	// UpdateDelivery stores the delivery status and details of an existing article, leaving its other attributes
+	// untouched, and returns the updated article. Returns ErrNotFound when it isn't saved.
+	UpdateDelivery(ctx context.Context, article *model.Article) (*model.Article, error)
 	GetMetadataByAccount(
 		ctx context.Context,
 		account string,
+		filter *model.ArticleFilter,
 		page, pageSize int,
 	) ([]*model.Article, map[string]types.AttributeValue, int, error)
+	// EachByAccount calls fn with each article of the account, including its content, until fn returns an error.
+	EachByAccount(ctx context.Context, account string, fn func(*model.Article) error) error
+	// Search returns a page of the articles of the account matching query, without content, the most relevant
+	// first, and the number of matching articles.
+	Search(ctx context.Context, account string, query *search.Query, page, pageSize int) ([]*model.Article, int, error)
+	// GetTags returns the tags and collections of the account with their number of articles.
+	GetTags(ctx context.Context, account string) (*model.Tags, error)
+	// StoreAlias records that the article saved from the URL with ID id is stored with ID articleID.
+	StoreAlias(ctx context.Context, account, id, articleID string) error
+	// GetAlias returns the ID the article saved from the URL with ID id is stored with.
+	GetAlias(ctx context.Context, account, id string) (string, error)
 	DeleteByAccountAndID(ctx context.Context, account, id string) error
 	DeleteByAccount(ctx context.Context, account string) (int, error)
+	GetSettings(ctx context.Context, account string) (*model.Settings, error)
+	StoreSettings(ctx context.Context, settings *model.Settings) error
+	StoreFeed(ctx context.Context, feed *model.Feed) error
+	GetFeed(ctx context.Context, account, id string) (*model.Feed, error)
+	GetFeedsByAccount(ctx context.Context, account string) ([]*model.Feed, error)
+	GetAllFeeds(ctx context.Context) ([]*model.Feed, error)
+	DeleteFeed(ctx context.Context, account, id string) error
+	StoreImportJob(ctx context.Context, job *model.ImportJob) error
+	GetImportJob(ctx context.Context, account, id string) (*model.ImportJob, error)
 }
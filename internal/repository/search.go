@@ -0,0 +1,158 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/shaftoe/savetoink/internal/consts"
+	"github.com/shaftoe/savetoink/internal/model"
+	"github.com/shaftoe/savetoink/internal/search"
+)
+
+// Search implements Repository.Search. The query runs on the index set by WithSearchIndex or, without one, on the
+// articles of the account indexed in memory, and the page of matching articles is read from the table.
+func (d *DynamoDB) Search(
+	ctx context.Context,
+	account string,
+	query *search.Query,
+	page, pageSize int,
+) ([]*model.Article, int, error) {
+	page = max(page, consts.MinPage)
+	if pageSize < consts.MinPageSize || pageSize > consts.MaxPageSize {
+		pageSize = consts.DefaultPageSize
+	}
+
+	index, err := d.searchIndex(ctx, account)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	results, err := index.Search(ctx, account, query)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to search index: %w", err)
+	}
+
+	articles := make([]*model.Article, 0, pageSize)
+	for _, result := range resultsPage(results, page, pageSize) {
+		article, err := d.getMetadata(ctx, account, result.ID)
+		if err != nil {
+			// the index lags behind the table
+			if errors.Is(err, ErrNotFound) {
+				continue
+			}
+			return nil, 0, err
+		}
+		articles = append(articles, article)
+	}
+
+	return articles, len(results), nil
+}
+
+// searchIndex returns the index set by WithSearchIndex or, without one, the articles of the account indexed in
+// memory, read and indexed again when not searched for consts.SearchIndexTTL.
+func (d *DynamoDB) searchIndex(ctx context.Context, account string) (search.Index, error) {
+	if d.index != nil {
+		return d.index, nil
+	}
+
+	if index := d.indexes.get(account, time.Now()); index != nil {
+		return index, nil
+	}
+
+	index := search.NewMemoryIndex()
+	err := d.EachByAccount(ctx, account, func(article *model.Article) error {
+		return index.Add(ctx, search.DocumentOf(article))
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to index articles: %w", err)
+	}
+	d.indexes.put(account, index, time.Now())
+
+	return index, nil
+}
+
+// resultsPage returns the results of a page, numbered from 1.
+func resultsPage(results []search.Result, page, pageSize int) []search.Result {
+	start := (page - 1) * pageSize
+	if start >= len(results) {
+		return nil
+	}
+	return results[start:min(start+pageSize, len(results))]
+}
+
+// indexArticle adds a stored article to the index set by WithSearchIndex or, without one, to the articles of its
+// account indexed in memory, if any.
+func (d *DynamoDB) indexArticle(ctx context.Context, article *model.Article) {
+	index := d.index
+	if index == nil {
+		cached := d.indexes.get(article.Account, time.Now())
+		if cached == nil {
+			return
+		}
+		index = cached
+	}
+	if err := index.Add(ctx, search.DocumentOf(article)); err != nil {
+		slog.Warn("failed to index article", "article_id", article.ID, "error", err)
+	}
+}
+
+// unindex removes a deleted article from the index set by WithSearchIndex or, without one, from the articles of
+// its account indexed in memory, if any.
+func (d *DynamoDB) unindex(ctx context.Context, account, id string) {
+	index := d.index
+	if index == nil {
+		cached := d.indexes.get(account, time.Now())
+		if cached == nil {
+			return
+		}
+		index = cached
+	}
+	if err := index.Remove(ctx, account, id); err != nil {
+		slog.Warn("failed to remove article from index", "article_id", id, "error", err)
+	}
+}
+
+// indexCache holds the articles of the accounts searched recently indexed in memory, each account for
+// consts.SearchIndexTTL. The zero value is an empty cache.
+type indexCache struct {
+	mu      sync.Mutex
+	entries map[string]*cachedIndex
+}
+
+type cachedIndex struct {
+	index   *search.MemoryIndex
+	builtAt time.Time
+}
+
+// get returns the index of account if built at most consts.SearchIndexTTL before now, nil otherwise.
+func (c *indexCache) get(account string, now time.Time) *search.MemoryIndex {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[account]
+	if !ok || now.Sub(entry.builtAt) > consts.SearchIndexTTL {
+		return nil
+	}
+
+	return entry.index
+}
+
+// put caches the index of account built at now, evicting the expired ones.
+func (c *indexCache) put(account string, index *search.MemoryIndex, now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.entries == nil {
+		c.entries = make(map[string]*cachedIndex)
+	}
+	for cachedAccount, entry := range c.entries {
+		if now.Sub(entry.builtAt) > consts.SearchIndexTTL {
+			delete(c.entries, cachedAccount)
+		}
+	}
+	c.entries[account] = &cachedIndex{index: index, builtAt: now}
+}
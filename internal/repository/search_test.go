@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/shaftoe/savetoink/internal/consts"
+	"github.com/shaftoe/savetoink/internal/model"
+	"github.com/shaftoe/savetoink/internal/search"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResultsPage(t *testing.T) {
+	results := []search.Result{{ID: "1"}, {ID: "2"}, {ID: "3"}}
+
+	tests := []struct {
+		name     string
+		page     int
+		pageSize int
+		expected []search.Result
+	}{
+		{name: "first page", page: 1, pageSize: 2, expected: results[:2]},
+		{name: "last page", page: 2, pageSize: 2, expected: results[2:]},
+		{name: "past the end", page: 3, pageSize: 2, expected: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, resultsPage(results, tt.page, tt.pageSize))
+		})
+	}
+}
+
+func TestDynamoDB_Search(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	ctx := context.Background()
+	index := search.NewMemoryIndex()
+	repos := map[string]*DynamoDB{
+		"indexed in memory": setupTestDynamoDB(t),
+		"search index":      NewDynamoDB(nil, "test-savetoink-articles", WithSearchIndex(index)),
+	}
+
+	articles := []*model.Article{
+		{Account: testAccount, ID: "test-id-search-1", URL: "https://go.dev/blog/errors", Title: "Error handling in Go",
+			Content: "<p>Errors are values.</p>", Language: "en"},
+		{Account: testAccount, ID: "test-id-search-2", URL: "https://example.com/tour", Title: "A tour",
+			Content: "<p>Handling errors in Go is explicit.</p>", Language: "en"},
+	}
+	for _, article := range articles {
+		article.CreatedAt = time.Now().UTC()
+		err := repos["search index"].Store(ctx, article)
+		skipIfTableNotFound(t, err)
+		require.NoError(t, err)
+	}
+	t.Cleanup(func() { _, _ = repos["search index"].DeleteByAccount(context.Background(), testAccount) })
+
+	for name, repo := range repos {
+		t.Run(name, func(t *testing.T) {
+			query, err := search.ParseQuery(`"handling errors"`)
+			require.NoError(t, err)
+
+			found, total, err := repo.Search(ctx, testAccount, query, 1, 10)
+			require.NoError(t, err)
+			assert.Equal(t, 1, total)
+			require.Len(t, found, 1)
+			assert.Equal(t, "test-id-search-2", found[0].ID)
+			assert.Empty(t, found[0].Content, "articles are returned without content")
+
+			found, total, err = repo.Search(ctx, testAccount, query.WithFilters("go.dev", ""), 1, 10)
+			require.NoError(t, err)
+			assert.Zero(t, total)
+			assert.Empty(t, found)
+		})
+	}
+
+	require.NoError(t, repos["search index"].DeleteByAccountAndID(ctx, testAccount, "test-id-search-2"))
+	results, err := index.Search(ctx, testAccount, &search.Query{Terms: []string{"explicit"}})
+	require.NoError(t, err)
+	assert.Empty(t, results, "deleted articles are removed from the index")
+
+	// the articles indexed in memory are kept up to date by the repository that indexed them
+	inMemory := repos["indexed in memory"]
+	require.NoError(t, inMemory.Store(ctx, articles[1]))
+	query, err := search.ParseQuery("explicit")
+	require.NoError(t, err)
+	_, total, err := inMemory.Search(ctx, testAccount, query, 1, 10)
+	require.NoError(t, err)
+	assert.Equal(t, 1, total)
+}
+
+func TestIndexCache(t *testing.T) {
+	var cache indexCache
+	now := time.Now()
+	index := search.NewMemoryIndex()
+
+	assert.Nil(t, cache.get("user1", now))
+
+	cache.put("user1", index, now)
+	assert.Same(t, index, cache.get("user1", now.Add(consts.SearchIndexTTL)))
+	assert.Nil(t, cache.get("user1", now.Add(consts.SearchIndexTTL+time.Second)), "expired indexes are built again")
+
+	cache.put("user2", search.NewMemoryIndex(), now.Add(consts.SearchIndexTTL+time.Second))
+	assert.NotContains(t, cache.entries, "user1", "expired indexes are evicted")
+	assert.Contains(t, cache.entries, "user2")
+}
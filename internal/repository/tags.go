@@ -0,0 +1,333 @@
+package repository
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/shaftoe/savetoink/internal/consts"
+	"github.com/shaftoe/savetoink/internal/model"
+)
+
+// Articles are listed by tag or collection from a partition holding a copy of their metadata, queried on the same
+// indexes and with the same filters as the partition of the account. The number of articles of each tag and
+// collection of an account is kept in an item of the account partition, with the group id as id:
+//
+//	account             id              attributes
+//	alice               a1b2c3          the article, tags ["go"], collection "Reading list"
+//	alice#tag#go        a1b2c3          the article metadata, without content
+//	alice#collection#…  a1b2c3          the article metadata, without content
+//	alice               tag#go          count 1
+//	alice               collection#…    count 1
+
+// groupCounter is the item counting the articles of a tag or collection.
+type groupCounter struct {
+	ID    string `dynamodbav:"id"`
+	Count int    `dynamodbav:"count"`
+}
+
+// groupPartition returns the partition of the copies of the articles of the group of an account, a tag or
+// collection identified by the id of its counter item.
+func groupPartition(account, group string) string {
+	return account + "#" + group
+}
+
+// articleGroups returns the groups of the tags and collection of article.
+func articleGroups(article *model.Article) []string {
+	groups := make([]string, 0, len(article.Tags)+1)
+	for _, tag := range article.Tags {
+		groups = append(groups, consts.DynamoDBTagIDPrefix+tag)
+	}
+	if article.Collection != "" {
+		groups = append(groups, consts.DynamoDBCollectionIDPrefix+article.Collection)
+	}
+	return groups
+}
+
+// updateGroups copies article to the partitions of its groups and deletes its copies from the groups of previous,
+// the stored article it replaces, counting the articles added to and removed from each group. Copies are rewritten
+// at each store so that their metadata, e.g. the delivery status, stays filterable.
+func (d *DynamoDB) updateGroups(ctx context.Context, article, previous *model.Article) error {
+	groups := articleGroups(article)
+	var previousGroups []string
+	if previous != nil {
+		previousGroups = articleGroups(previous)
+	}
+
+	var requests []types.WriteRequest
+	if len(groups) > 0 {
+		item, err := attributevalue.MarshalMap(article)
+		if err != nil {
+			return fmt.Errorf("failed to marshal article: %w", err)
+		}
+		delete(item, "content")
+
+		for _, group := range groups {
+			copied := maps.Clone(item)
+			copied[attributeNameAccount] = &types.AttributeValueMemberS{Value: groupPartition(article.Account, group)}
+			requests = append(requests, types.WriteRequest{PutRequest: &types.PutRequest{Item: copied}})
+		}
+	}
+	for _, group := range previousGroups {
+		if !slices.Contains(groups, group) {
+			requests = append(requests, deleteRequest(groupPartition(article.Account, group), article.ID))
+		}
+	}
+
+	if err := d.batchWrite(ctx, requests); err != nil {
+		return err
+	}
+
+	for _, group := range groups {
+		if !slices.Contains(previousGroups, group) {
+			if err := d.countGroup(ctx, article.Account, group, 1); err != nil {
+				return err
+			}
+		}
+	}
+	for _, group := range previousGroups {
+		if !slices.Contains(groups, group) {
+			if err := d.countGroup(ctx, article.Account, group, -1); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// countGroup adds delta to the number of articles of a group, deleting its counter when no article is left.
+func (d *DynamoDB) countGroup(ctx context.Context, account, group string, delta int) error {
+	key := map[string]types.AttributeValue{
+		attributeNameAccount: &types.AttributeValueMemberS{Value: account},
+		attributeNameID:      &types.AttributeValueMemberS{Value: group},
+	}
+
+	resp, err := d.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:                 aws.String(d.tableName),
+		Key:                       key,
+		UpdateExpression:          aws.String("ADD #count :delta"),
+		ExpressionAttributeNames:  map[string]string{"#count": "count"},
+		ExpressionAttributeValues: map[string]types.AttributeValue{":delta": numberValue(delta)},
+		ReturnValues:              types.ReturnValueUpdatedNew,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to count articles of %s: %w", group, err)
+	}
+
+	var counter groupCounter
+	if err = attributevalue.UnmarshalMap(resp.Attributes, &counter); err != nil {
+		return fmt.Errorf("failed to unmarshal count of %s: %w", group, err)
+	}
+	if counter.Count > 0 {
+		return nil
+	}
+
+	// an article may have been added to the group since the update
+	_, err = d.client.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName:                 aws.String(d.tableName),
+		Key:                       key,
+		ConditionExpression:       aws.String("#count <= :zero"),
+		ExpressionAttributeNames:  map[string]string{"#count": "count"},
+		ExpressionAttributeValues: map[string]types.AttributeValue{":zero": &types.AttributeValueMemberN{Value: "0"}},
+	})
+	var conditionErr *types.ConditionalCheckFailedException
+	if err != nil && !errors.As(err, &conditionErr) {
+		return fmt.Errorf("failed to delete count of %s: %w", group, err)
+	}
+
+	return nil
+}
+
+// GetTags implements Repository.GetTags.
+func (d *DynamoDB) GetTags(ctx context.Context, account string) (*model.Tags, error) {
+	tags, err := d.groupCounts(ctx, account, consts.DynamoDBTagIDPrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	collections, err := d.groupCounts(ctx, account, consts.DynamoDBCollectionIDPrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.Tags{Tags: tags, Collections: collections}, nil
+}
+
+// groupCounts returns the groups of an account with prefix and their number of articles, the largest first.
+func (d *DynamoDB) groupCounts(ctx context.Context, account, prefix string) ([]*model.TagCount, error) {
+	paginator := dynamodb.NewQueryPaginator(d.client, &dynamodb.QueryInput{
+		TableName:              aws.String(d.tableName),
+		KeyConditionExpression: aws.String("#account = :account AND begins_with(#id, :prefix)"),
+		ExpressionAttributeNames: map[string]string{
+			"#account": attributeNameAccount,
+			"#id":      attributeNameID,
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":account": &types.AttributeValueMemberS{Value: account},
+			":prefix":  &types.AttributeValueMemberS{Value: prefix},
+		},
+	})
+
+	counts := []*model.TagCount{}
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query tags: %w", err)
+		}
+
+		var counters []groupCounter
+		if err = attributevalue.UnmarshalListOfMaps(page.Items, &counters); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal tags: %w", err)
+		}
+		for _, counter := range counters {
+			if counter.Count > 0 {
+				counts = append(counts, &model.TagCount{Name: strings.TrimPrefix(counter.ID, prefix), Count: counter.Count})
+			}
+		}
+	}
+
+	slices.SortFunc(counts, func(a, b *model.TagCount) int {
+		return cmp.Or(cmp.Compare(b.Count, a.Count), cmp.Compare(a.Name, b.Name))
+	})
+
+	return counts, nil
+}
+
+// deleteFromGroups deletes the copies of the deleted articles of an account from the partitions of its groups.
+func (d *DynamoDB) deleteFromGroups(ctx context.Context, account string, deleted []*model.Article) error {
+	ids := make(map[string]bool, len(deleted))
+	for _, article := range deleted {
+		ids[article.ID] = true
+	}
+
+	for _, prefix := range []string{consts.DynamoDBTagIDPrefix, consts.DynamoDBCollectionIDPrefix} {
+		counts, err := d.groupCounts(ctx, account, prefix)
+		if err != nil {
+			return err
+		}
+
+		for _, count := range counts {
+			group := prefix + count.Name
+			requests, err := d.groupDeleteRequests(ctx, groupPartition(account, group), ids)
+			if err != nil {
+				return err
+			}
+			if len(requests) == 0 {
+				continue
+			}
+
+			if err = d.batchWrite(ctx, requests); err != nil {
+				return err
+			}
+			if err = d.countGroup(ctx, account, group, -len(requests)); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// groupDeleteRequests returns the requests deleting the copies in partition of the articles with ids.
+func (d *DynamoDB) groupDeleteRequests(
+	ctx context.Context,
+	partition string,
+	ids map[string]bool,
+) ([]types.WriteRequest, error) {
+	paginator := dynamodb.NewQueryPaginator(d.client, &dynamodb.QueryInput{
+		TableName:                aws.String(d.tableName),
+		KeyConditionExpression:   aws.String("#account = :account"),
+		ProjectionExpression:     aws.String("#id"),
+		ExpressionAttributeNames: map[string]string{"#account": attributeNameAccount, "#id": attributeNameID},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":account": &types.AttributeValueMemberS{Value: partition},
+		},
+	})
+
+	var requests []types.WriteRequest
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query tagged articles: %w", err)
+		}
+
+		for _, item := range page.Items {
+			if id, ok := item[attributeNameID].(*types.AttributeValueMemberS); ok && ids[id.Value] {
+				requests = append(requests, deleteRequest(partition, id.Value))
+			}
+		}
+	}
+
+	return requests, nil
+}
+
+// batchWrite writes requests in batches of consts.DynamoDBBatchSize, retrying the items DynamoDB leaves
+// unprocessed.
+func (d *DynamoDB) batchWrite(ctx context.Context, requests []types.WriteRequest) error {
+	for batch := range slices.Chunk(requests, consts.DynamoDBBatchSize) {
+		err := writeBatch(ctx, batch, func(batch []types.WriteRequest) ([]types.WriteRequest, error) {
+			result, err := d.client.BatchWriteItem(ctx, &dynamodb.BatchWriteItemInput{
+				RequestItems: map[string][]types.WriteRequest{d.tableName: batch},
+			})
+			if err != nil {
+				return nil, err
+			}
+			return result.UnprocessedItems[d.tableName], nil
+		})
+		if err != nil {
+			return fmt.Errorf("failed to write batch of tagged articles: %w", err)
+		}
+	}
+	return nil
+}
+
+// writeBatch writes batch with write, which returns the requests left unprocessed, retrying them with an
+// exponential backoff up to consts.DynamoDBBatchAttempts times.
+func writeBatch(
+	ctx context.Context,
+	batch []types.WriteRequest,
+	write func([]types.WriteRequest) ([]types.WriteRequest, error),
+) error {
+	backoff := consts.DynamoDBBatchBackoff
+	for attempt := 1; ; attempt++ {
+		unprocessed, err := write(batch)
+		if err != nil {
+			return err
+		}
+		if len(unprocessed) == 0 {
+			return nil
+		}
+		if attempt == consts.DynamoDBBatchAttempts {
+			return fmt.Errorf("%d items left unprocessed after %d attempts", len(unprocessed), attempt)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		batch = unprocessed
+		backoff *= 2
+	}
+}
+
+func deleteRequest(account, id string) types.WriteRequest {
+	return types.WriteRequest{
+		DeleteRequest: &types.DeleteRequest{
+			Key: map[string]types.AttributeValue{
+				attributeNameAccount: &types.AttributeValueMemberS{Value: account},
+				attributeNameID:      &types.AttributeValueMemberS{Value: id},
+			},
+		},
+	}
+}
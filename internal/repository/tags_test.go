@@ -0,0 +1,154 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/shaftoe/savetoink/internal/consts"
+	"github.com/shaftoe/savetoink/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestArticleGroups(t *testing.T) {
+	tests := []struct {
+		name     string
+		article  *model.Article
+		expected []string
+	}{
+		{name: "none", article: &model.Article{}, expected: []string{}},
+		{name: "tags", article: &model.Article{Tags: []string{"go", "errors"}},
+			expected: []string{"tag#go", "tag#errors"}},
+		{name: "tags and collection", article: &model.Article{Tags: []string{"go"}, Collection: "Reading list"},
+			expected: []string{"tag#go", "collection#Reading list"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, articleGroups(tt.article))
+		})
+	}
+
+	assert.Equal(t, testAccount+"#tag#go", groupPartition(testAccount, "tag#go"))
+	assert.True(t, isReservedID("tag#go"))
+	assert.True(t, isReservedID("collection#Reading list"))
+}
+
+func TestWriteBatch(t *testing.T) {
+	batch := []types.WriteRequest{deleteRequest("a", "1"), deleteRequest("a", "2")}
+
+	tests := []struct {
+		name          string
+		unprocessed   int
+		failures      int
+		expectedCalls int
+		expectedError string
+	}{
+		{name: "processed", expectedCalls: 1},
+		{name: "unprocessed once", unprocessed: 1, failures: 1, expectedCalls: 2},
+		{name: "always unprocessed", unprocessed: 1, failures: consts.DynamoDBBatchAttempts,
+			expectedCalls: consts.DynamoDBBatchAttempts,
+			expectedError: "1 items left unprocessed after 5 attempts"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			err := writeBatch(context.Background(), batch, func(requests []types.WriteRequest) (
+				[]types.WriteRequest, error,
+			) {
+				calls++
+				if calls > tt.failures {
+					return nil, nil
+				}
+				return requests[:tt.unprocessed], nil
+			})
+
+			assert.Equal(t, tt.expectedCalls, calls)
+			if tt.expectedError != "" {
+				assert.EqualError(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestDynamoDB_Tags(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	repo := setupTestDynamoDB(t)
+	ctx := context.Background()
+
+	now := time.Now().UTC()
+	publishedAt := now.Add(-24 * time.Hour)
+	articles := []*model.Article{
+		{Account: testAccount, ID: "test-id-tags-1", URL: "https://go.dev/1", CreatedAt: now.Add(-time.Hour),
+			PublishedAt: &publishedAt, ReadingTimeMinutes: 5, Content: "<p>content</p>", Tags: []string{"go", "errors"},
+			Collection: "Reading list"},
+		{Account: testAccount, ID: "test-id-tags-2", URL: "https://go.dev/2", CreatedAt: now, Tags: []string{"go"}},
+	}
+	for _, article := range articles {
+		err := repo.Store(ctx, article)
+		skipIfTableNotFound(t, err)
+		require.NoError(t, err)
+	}
+	t.Cleanup(func() { _, _ = repo.DeleteByAccount(context.Background(), testAccount) })
+
+	tags, err := repo.GetTags(ctx, testAccount)
+	require.NoError(t, err)
+	assert.Equal(t, []*model.TagCount{{Name: "go", Count: 2}, {Name: "errors", Count: 1}}, tags.Tags)
+	assert.Equal(t, []*model.TagCount{{Name: "Reading list", Count: 1}}, tags.Collections)
+
+	tagged, _, total, err := repo.GetMetadataByAccount(ctx, testAccount, &model.ArticleFilter{Tag: "go"}, 1, 20)
+	require.NoError(t, err)
+	assert.Equal(t, 2, total)
+	require.Len(t, tagged, 2)
+	assert.Equal(t, "test-id-tags-2", tagged[0].ID)
+	assert.Equal(t, testAccount, tagged[0].Account)
+	assert.Empty(t, tagged[1].Content)
+	assert.Equal(t, []string{"go", "errors"}, tagged[1].Tags, "tags are projected in the index")
+	assert.Equal(t, "Reading list", tagged[1].Collection, "the collection is projected in the index")
+
+	for _, sort := range []consts.ArticleSort{consts.SortCreated, consts.SortPublished, consts.SortReadingTime} {
+		filter := &model.ArticleFilter{Tag: "errors", Collection: "Reading list", Sort: sort}
+		tagged, _, total, err = repo.GetMetadataByAccount(ctx, testAccount, filter, 1, 20)
+		require.NoError(t, err)
+		assert.Equal(t, 1, total, "articles of a tag are filtered by collection sorted by %s", sort)
+		require.Len(t, tagged, 1)
+		assert.Equal(t, "test-id-tags-1", tagged[0].ID)
+	}
+
+	// storing again moves the article between groups
+	articles[0].Tags = []string{"go"}
+	articles[0].Collection = ""
+	require.NoError(t, repo.Store(ctx, articles[0]))
+
+	tags, err = repo.GetTags(ctx, testAccount)
+	require.NoError(t, err)
+	assert.Equal(t, []*model.TagCount{{Name: "go", Count: 2}}, tags.Tags)
+	assert.Empty(t, tags.Collections)
+
+	tagged, _, total, err = repo.GetMetadataByAccount(ctx, testAccount, &model.ArticleFilter{Tag: "errors"}, 1, 20)
+	require.NoError(t, err)
+	assert.Zero(t, total)
+	assert.Empty(t, tagged)
+
+	require.NoError(t, repo.DeleteByAccountAndID(ctx, testAccount, "test-id-tags-2"))
+
+	tags, err = repo.GetTags(ctx, testAccount)
+	require.NoError(t, err)
+	assert.Equal(t, []*model.TagCount{{Name: "go", Count: 1}}, tags.Tags)
+
+	deleted, err := repo.DeleteByAccount(ctx, testAccount)
+	require.NoError(t, err)
+	assert.Equal(t, 1, deleted)
+
+	tags, err = repo.GetTags(ctx, testAccount)
+	require.NoError(t, err)
+	assert.Empty(t, tags.Tags)
+}
@@ -0,0 +1,159 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/shaftoe/savetoink/internal/consts"
+	"github.com/shaftoe/savetoink/internal/model"
+)
+
+// readingState holds the reading workflow attributes of an article, marshalled as in model.Article.
+type readingState struct {
+	ReadState   consts.ReadState `dynamodbav:"readState,omitempty"`
+	ReadAt      *time.Time       `dynamodbav:"readAt,omitempty"`
+	ArchivedAt  *time.Time       `dynamodbav:"archivedAt,omitempty"`
+	Favorite    bool             `dynamodbav:"favorite,omitempty"`
+	FavoritedAt *time.Time       `dynamodbav:"favoritedAt,omitempty"`
+}
+
+// readingStateAttributes are the attributes of readingState, in the order of the update expression.
+var readingStateAttributes = []string{"readState", "readAt", "archivedAt", "favorite", "favoritedAt"}
+
+// delivery holds the delivery attributes of an article, marshalled as in model.Article.
+type delivery struct {
+	DeliveryStatus     consts.Status        `dynamodbav:"deliveryStatus,omitempty"`
+	DeliveredFrom      *string              `dynamodbav:"deliveredFrom,omitempty"`
+	DeliveredTo        *string              `dynamodbav:"deliveredTo,omitempty"`
+	DeliveredEmailUUID *string              `dynamodbav:"deliveredEmailUUID,omitempty"`
+	DeliveredBy        consts.EmailProvider `dynamodbav:"deliveredBy,omitempty"`
+}
+
+// deliveryAttributes are the attributes of delivery, in the order of the update expression.
+var deliveryAttributes = []string{"deliveryStatus", "deliveredFrom", "deliveredTo", "deliveredEmailUUID", "deliveredBy"}
+
+// UpdateReadingState implements Repository.UpdateReadingState.
+func (d *DynamoDB) UpdateReadingState(ctx context.Context, article *model.Article) (*model.Article, error) {
+	return d.updateArticle(ctx, article, readingStateAttributes, readingState{
+		ReadState:   article.ReadState,
+		ReadAt:      article.ReadAt,
+		ArchivedAt:  article.ArchivedAt,
+		Favorite:    article.Favorite,
+		FavoritedAt: article.FavoritedAt,
+	})
+}
+
+// UpdateDelivery implements Repository.UpdateDelivery.
+func (d *DynamoDB) UpdateDelivery(ctx context.Context, article *model.Article) (*model.Article, error) {
+	return d.updateArticle(ctx, article, deliveryAttributes, delivery{
+		DeliveryStatus:     article.DeliveryStatus,
+		DeliveredFrom:      article.DeliveredFrom,
+		DeliveredTo:        article.DeliveredTo,
+		DeliveredEmailUUID: article.DeliveredEmailUUID,
+		DeliveredBy:        article.DeliveredBy,
+	})
+}
+
+// updateArticle sets the attributes of the existing article with the key of article to the ones of values, the
+// attributes it doesn't have are removed, and returns the updated article. Its copies listing it by tag and
+// collection are updated too, they are filtered by the same attributes. Returns ErrNotFound when it isn't saved.
+func (d *DynamoDB) updateArticle(
+	ctx context.Context,
+	article *model.Article,
+	attributes []string,
+	values any,
+) (*model.Article, error) {
+	if isReservedID(article.ID) {
+		return nil, ErrNotFound
+	}
+
+	input, err := d.attributesUpdate(attributes, values)
+	if err != nil {
+		return nil, err
+	}
+	input.Key = map[string]types.AttributeValue{
+		attributeNameAccount: &types.AttributeValueMemberS{Value: article.Account},
+		attributeNameID:      &types.AttributeValueMemberS{Value: article.ID},
+	}
+	input.ReturnValues = types.ReturnValueAllNew
+
+	resp, err := d.client.UpdateItem(ctx, input)
+	var conditionErr *types.ConditionalCheckFailedException
+	if errors.As(err, &conditionErr) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to update article: %w", err)
+	}
+
+	updated := &model.Article{}
+	if err = attributevalue.UnmarshalMap(resp.Attributes, updated); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal article: %w", err)
+	}
+
+	for _, group := range articleGroups(updated) {
+		input, err = d.attributesUpdate(attributes, values)
+		if err != nil {
+			return nil, err
+		}
+		input.Key = map[string]types.AttributeValue{
+			attributeNameAccount: &types.AttributeValueMemberS{Value: groupPartition(article.Account, group)},
+			attributeNameID:      &types.AttributeValueMemberS{Value: article.ID},
+		}
+		_, err = d.client.UpdateItem(ctx, input)
+		if err != nil && !errors.As(err, &conditionErr) {
+			return nil, fmt.Errorf("failed to update article of %s: %w", group, err)
+		}
+	}
+
+	return updated, nil
+}
+
+// attributesUpdate returns the update of an existing item setting the attributes of values, a struct marshalled
+// with them, and removing those it doesn't have, without key.
+func (d *DynamoDB) attributesUpdate(attributes []string, values any) (*dynamodb.UpdateItemInput, error) {
+	item, err := attributevalue.MarshalMap(values)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal article attributes: %w", err)
+	}
+
+	names := map[string]string{"#id": attributeNameID}
+	expressionValues := make(map[string]types.AttributeValue, len(item))
+	var set, remove []string
+	for _, attribute := range attributes {
+		names["#"+attribute] = attribute
+		value, ok := item[attribute]
+		if !ok {
+			remove = append(remove, "#"+attribute)
+			continue
+		}
+		set = append(set, "#"+attribute+" = :"+attribute)
+		expressionValues[":"+attribute] = value
+	}
+
+	var expression []string
+	if len(set) > 0 {
+		expression = append(expression, "SET "+strings.Join(set, ", "))
+	}
+	if len(remove) > 0 {
+		expression = append(expression, "REMOVE "+strings.Join(remove, ", "))
+	}
+
+	input := &dynamodb.UpdateItemInput{
+		TableName:                aws.String(d.tableName),
+		UpdateExpression:         aws.String(strings.Join(expression, " ")),
+		ConditionExpression:      aws.String("attribute_exists(#id)"),
+		ExpressionAttributeNames: names,
+	}
+	if len(expressionValues) > 0 {
+		input.ExpressionAttributeValues = expressionValues
+	}
+	return input, nil
+}
@@ -0,0 +1,264 @@
+package search
+
+import (
+	"context"
+	"math"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// fieldWeights are the weights of the occurrences of a term in each field of a document, a match in the
+// title ranks a document higher than one in the content.
+var fieldWeights = struct {
+	title, author, siteName, excerpt, content float64
+}{title: 3, author: 2, siteName: 2, excerpt: 1.5, content: 1}
+
+const (
+	// fieldGap separates the positions of the terms of two fields, so that phrases don't match across fields.
+	fieldGap = 100
+
+	// BM25 parameters: bm25K1 saturates the frequency of a term, bm25B normalizes it by document length.
+	bm25K1 = 1.2
+	bm25B  = 0.75
+)
+
+// MemoryIndex is an inverted index of documents held in memory, mapping each term to the documents
+// containing it and the positions of its occurrences, used to match phrases.
+type MemoryIndex struct {
+	mu       sync.RWMutex
+	accounts map[string]*accountIndex
+}
+
+type accountIndex struct {
+	docs     map[string]*indexedDoc
+	postings map[string]map[string]*posting
+	length   int
+}
+
+type indexedDoc struct {
+	domain    string
+	language  string
+	createdAt time.Time
+	terms     []string
+	length    int
+}
+
+// posting lists the occurrences of a term in a document, weight sums the weights of their fields.
+type posting struct {
+	positions []int
+	weight    float64
+}
+
+// NewMemoryIndex creates an empty index.
+func NewMemoryIndex() *MemoryIndex {
+	return &MemoryIndex{accounts: make(map[string]*accountIndex)}
+}
+
+// Add implements Index.Add.
+func (m *MemoryIndex) Add(_ context.Context, doc *Document) error {
+	fields := []struct {
+		text   string
+		weight float64
+	}{
+		{doc.Title, fieldWeights.title},
+		{doc.Author, fieldWeights.author},
+		{doc.SiteName, fieldWeights.siteName},
+		{doc.Excerpt, fieldWeights.excerpt},
+		{doc.Content, fieldWeights.content},
+	}
+
+	postings := make(map[string]*posting)
+	indexed := &indexedDoc{
+		domain:    normalizeDomain(doc.Domain),
+		language:  doc.Language,
+		createdAt: doc.CreatedAt,
+	}
+
+	position := 0
+	for _, field := range fields {
+		for _, term := range Tokenize(field.text) {
+			p, ok := postings[term]
+			if !ok {
+				p = &posting{}
+				postings[term] = p
+				indexed.terms = append(indexed.terms, term)
+			}
+			p.positions = append(p.positions, position)
+			p.weight += field.weight
+			position++
+			indexed.length++
+		}
+		position += fieldGap
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	account, ok := m.accounts[doc.Account]
+	if !ok {
+		account = &accountIndex{
+			docs:     make(map[string]*indexedDoc),
+			postings: make(map[string]map[string]*posting),
+		}
+		m.accounts[doc.Account] = account
+	}
+
+	account.remove(doc.ID)
+	account.docs[doc.ID] = indexed
+	account.length += indexed.length
+	for term, p := range postings {
+		docs, ok := account.postings[term]
+		if !ok {
+			docs = make(map[string]*posting)
+			account.postings[term] = docs
+		}
+		docs[doc.ID] = p
+	}
+
+	return nil
+}
+
+// Remove implements Index.Remove.
+func (m *MemoryIndex) Remove(_ context.Context, account, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if index, ok := m.accounts[account]; ok {
+		index.remove(id)
+		if len(index.docs) == 0 {
+			delete(m.accounts, account)
+		}
+	}
+
+	return nil
+}
+
+// RemoveAccount implements Index.RemoveAccount.
+func (m *MemoryIndex) RemoveAccount(_ context.Context, account string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.accounts, account)
+
+	return nil
+}
+
+func (a *accountIndex) remove(id string) {
+	doc, ok := a.docs[id]
+	if !ok {
+		return
+	}
+
+	for _, term := range doc.terms {
+		delete(a.postings[term], id)
+		if len(a.postings[term]) == 0 {
+			delete(a.postings, term)
+		}
+	}
+	a.length -= doc.length
+	delete(a.docs, id)
+}
+
+// Search implements Index.Search. Documents are ranked with BM25 on the weighted frequencies of the terms,
+// documents with the same score the most recently saved first.
+func (m *MemoryIndex) Search(_ context.Context, account string, query *Query) ([]Result, error) {
+	terms := slices.Clone(query.Terms)
+	for _, phrase := range query.Phrases {
+		for _, term := range phrase {
+			if !slices.Contains(terms, term) {
+				terms = append(terms, term)
+			}
+		}
+	}
+	if len(terms) == 0 {
+		return nil, ErrEmptyQuery
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	index, ok := m.accounts[account]
+	if !ok {
+		return []Result{}, nil
+	}
+
+	// candidates contain all the terms, starting from the rarest one
+	slices.SortFunc(terms, func(a, b string) int { return len(index.postings[a]) - len(index.postings[b]) })
+	candidates := index.postings[terms[0]]
+
+	docCount := float64(len(index.docs))
+	averageLength := float64(index.length) / docCount
+
+	results := []Result{}
+	for id := range candidates {
+		doc := index.docs[id]
+		if !query.Matches(doc.domain, doc.language) || !index.containsAll(id, terms) {
+			continue
+		}
+		if !index.containsPhrases(id, query.Phrases) {
+			continue
+		}
+
+		score := 0.0
+		for _, term := range terms {
+			frequency := float64(len(index.postings[term]))
+			idf := math.Log(1 + (docCount-frequency+0.5)/(frequency+0.5))
+			weight := index.postings[term][id].weight
+			lengthNorm := bm25K1 * (1 - bm25B + bm25B*float64(doc.length)/averageLength)
+			score += idf * weight * (bm25K1 + 1) / (weight + lengthNorm)
+		}
+		results = append(results, Result{ID: id, Score: score})
+	}
+
+	slices.SortFunc(results, func(a, b Result) int {
+		if a.Score != b.Score {
+			if a.Score > b.Score {
+				return -1
+			}
+			return 1
+		}
+		if c := index.docs[b.ID].createdAt.Compare(index.docs[a.ID].createdAt); c != 0 {
+			return c
+		}
+		return strings.Compare(a.ID, b.ID)
+	})
+
+	return results, nil
+}
+
+func (a *accountIndex) containsAll(id string, terms []string) bool {
+	for _, term := range terms {
+		if _, ok := a.postings[term][id]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// containsPhrases reports whether the document contains each phrase, its terms at consecutive positions.
+func (a *accountIndex) containsPhrases(id string, phrases [][]string) bool {
+	for _, phrase := range phrases {
+		if !a.containsPhrase(id, phrase) {
+			return false
+		}
+	}
+	return true
+}
+
+func (a *accountIndex) containsPhrase(id string, phrase []string) bool {
+	for _, start := range a.postings[phrase[0]][id].positions {
+		found := true
+		for offset, term := range phrase[1:] {
+			if _, ok := slices.BinarySearch(a.postings[term][id].positions, start+offset+1); !ok {
+				found = false
+				break
+			}
+		}
+		if found {
+			return true
+		}
+	}
+	return false
+}
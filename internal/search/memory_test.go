@@ -0,0 +1,129 @@
+package search
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestIndex(t *testing.T) *MemoryIndex {
+	t.Helper()
+
+	savedAt := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	docs := []*Document{
+		{Account: "user1", ID: "title", Title: "Error handling in Go", Domain: "go.dev", Language: "en",
+			Content: "Errors are values.", CreatedAt: savedAt},
+		{Account: "user1", ID: "content", Title: "A tour of the standard library", Domain: "blog.example.com",
+			Language: "en-US", Content: "The errors package wraps errors, and handling errors in Go is explicit.",
+			CreatedAt: savedAt.Add(time.Hour)},
+		{Account: "user1", ID: "french", Title: "La gestion des erreurs en Go", Domain: "exemple.fr", Language: "fr",
+			Author: "Zoé", Content: "Le café est prêt.", CreatedAt: savedAt},
+		{Account: "user1", ID: "japanese", Title: "東京の旅行ガイド", Domain: "example.jp", Language: "ja",
+			CreatedAt: savedAt},
+		{Account: "user2", ID: "other", Title: "Error handling in Go", CreatedAt: savedAt},
+	}
+
+	index := NewMemoryIndex()
+	for _, doc := range docs {
+		require.NoError(t, index.Add(context.Background(), doc))
+	}
+	return index
+}
+
+func resultIDs(results []Result) []string {
+	ids := make([]string, 0, len(results))
+	for _, result := range results {
+		ids = append(ids, result.ID)
+	}
+	return ids
+}
+
+func TestMemoryIndexSearch(t *testing.T) {
+	index := newTestIndex(t)
+
+	tests := []struct {
+		name     string
+		query    string
+		domain   string
+		language string
+		expected []string
+	}{
+		{name: "title ranks first", query: "go handling", expected: []string{"title", "content"}},
+		{name: "all terms required", query: "errors explicit", expected: []string{"content"}},
+		{name: "phrase", query: `"handling errors"`, expected: []string{"content"}},
+		{name: "phrase across fields", query: `"go errors"`, expected: []string{}},
+		{name: "phrase order", query: `"errors handling"`, expected: []string{}},
+		{name: "diacritics", query: "cafe zoe", expected: []string{"french"}},
+		{name: "japanese", query: "東京", expected: []string{"japanese"}},
+		{name: "japanese phrase", query: `"旅行"`, expected: []string{"japanese"}},
+		{name: "domain filter", query: "go", domain: "example.com", expected: []string{"content"}},
+		{name: "language filter", query: "go", language: "en", expected: []string{"title", "content"}},
+		{name: "language region filter", query: "go", language: "en-us", expected: []string{"content"}},
+		{name: "no match", query: "rust", expected: []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, err := ParseQuery(tt.query)
+			require.NoError(t, err)
+
+			results, err := index.Search(context.Background(), "user1", query.WithFilters(tt.domain, tt.language))
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, resultIDs(results))
+		})
+	}
+}
+
+func TestMemoryIndexRemove(t *testing.T) {
+	ctx := context.Background()
+	index := newTestIndex(t)
+	query, err := ParseQuery("error handling")
+	require.NoError(t, err)
+
+	require.NoError(t, index.Remove(ctx, "user1", "title"))
+	results, err := index.Search(ctx, "user1", query)
+	require.NoError(t, err)
+	assert.Empty(t, results, "the removed document isn't found")
+
+	require.NoError(t, index.Add(ctx, &Document{Account: "user1", ID: "content", Title: "Replaced"}))
+	results, err = index.Search(ctx, "user1", &Query{Terms: []string{"errors"}})
+	require.NoError(t, err)
+	assert.Empty(t, results, "adding a document replaces the previous one")
+
+	require.NoError(t, index.RemoveAccount(ctx, "user1"))
+	results, err = index.Search(ctx, "user1", &Query{Terms: []string{"replaced"}})
+	require.NoError(t, err)
+	assert.Empty(t, results)
+
+	results, err = index.Search(ctx, "user2", query)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"other"}, resultIDs(results), "other accounts are kept")
+
+	_, err = index.Search(ctx, "user2", &Query{})
+	require.ErrorIs(t, err, ErrEmptyQuery)
+}
+
+func TestMemoryIndexConcurrent(t *testing.T) {
+	ctx := context.Background()
+	index := NewMemoryIndex()
+	query := &Query{Terms: []string{"cafe"}}
+
+	var wg sync.WaitGroup
+	for i := range 10 {
+		wg.Go(func() {
+			doc := &Document{Account: "user1", ID: string(rune('a' + i)), Title: "Café"}
+			assert.NoError(t, index.Add(ctx, doc))
+			_, err := index.Search(ctx, "user1", query)
+			assert.NoError(t, err)
+		})
+	}
+	wg.Wait()
+
+	results, err := index.Search(ctx, "user1", query)
+	require.NoError(t, err)
+	assert.Len(t, results, 10)
+}
@@ -0,0 +1,218 @@
+// Package search provides full-text search over saved articles: the tokenizer and query parser shared by
+// indexes, the Index interface implemented by search backends and MemoryIndex, an embedded inverted index.
+package search
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/rivo/uniseg"
+	"github.com/shaftoe/savetoink/internal/consts"
+	"github.com/shaftoe/savetoink/internal/content/wordcount"
+	"github.com/shaftoe/savetoink/internal/model"
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+var (
+	// ErrEmptyQuery is returned when a query has no term to search.
+	ErrEmptyQuery = errors.New("empty search query")
+	// ErrQueryTooLong is returned when a query is longer than consts.SearchMaxQueryLength or has more than
+	// consts.SearchMaxQueryTerms terms.
+	ErrQueryTooLong = errors.New("search query too long")
+)
+
+// Index is a full-text index of the articles of the accounts. Implementations are safe for concurrent use.
+type Index interface {
+	// Add indexes a document, replacing the document of the account with the same ID.
+	Add(ctx context.Context, doc *Document) error
+	// Remove removes the document of the account with ID id, if indexed.
+	Remove(ctx context.Context, account, id string) error
+	// RemoveAccount removes all the documents of the account.
+	RemoveAccount(ctx context.Context, account string) error
+	// Search returns the documents of the account matching query, the most relevant first.
+	Search(ctx context.Context, account string, query *Query) ([]Result, error)
+}
+
+// Result is a document matching a query.
+type Result struct {
+	ID    string
+	Score float64
+}
+
+// Document is the searchable text of an article.
+type Document struct {
+	Account   string
+	ID        string
+	Title     string
+	Author    string
+	SiteName  string
+	Excerpt   string
+	Content   string
+	Domain    string
+	Language  string
+	CreatedAt time.Time
+}
+
+// DocumentOf returns the document of an article, with the text of its HTML content.
+func DocumentOf(article *model.Article) *Document {
+	return &Document{
+		Account:   article.Account,
+		ID:        article.ID,
+		Title:     article.Title,
+		Author:    article.Author,
+		SiteName:  article.SiteName,
+		Excerpt:   article.Excerpt,
+		Content:   wordcount.Text(article.Content),
+		Domain:    articleDomain(article),
+		Language:  article.Language,
+		CreatedAt: article.CreatedAt,
+	}
+}
+
+func articleDomain(article *model.Article) string {
+	if article.SourceDomain != "" {
+		return normalizeDomain(article.SourceDomain)
+	}
+	if parsed, err := url.Parse(article.URL); err == nil {
+		return normalizeDomain(parsed.Hostname())
+	}
+	return ""
+}
+
+// Query is a parsed search query. Documents match when they contain all the terms and all the phrases, and
+// are from Domain and in Language when set.
+type Query struct {
+	Terms   []string
+	Phrases [][]string
+	// Domain filters documents from the domain or its subdomains.
+	Domain string
+	// Language filters documents in the language, a BCP 47 tag: "en" matches "en-US" too.
+	Language string
+}
+
+// ParseQuery parses a search query: words are searched anywhere in the document, words in double quotes
+// as a phrase, in that order.
+func ParseQuery(q string) (*Query, error) {
+	if len(q) > consts.SearchMaxQueryLength {
+		return nil, fmt.Errorf("%w: at most %d characters", ErrQueryTooLong, consts.SearchMaxQueryLength)
+	}
+
+	query := &Query{}
+	seen := make(map[string]bool)
+	count := 0
+
+	for i, part := range strings.Split(q, `"`) {
+		tokens := Tokenize(part)
+		count += len(tokens)
+
+		// odd parts are quoted, an unclosed quote quotes the rest of the query
+		if i%2 == 1 && len(tokens) > 1 {
+			query.Phrases = append(query.Phrases, tokens)
+			continue
+		}
+		for _, token := range tokens {
+			if !seen[token] {
+				seen[token] = true
+				query.Terms = append(query.Terms, token)
+			}
+		}
+	}
+
+	if count == 0 {
+		return nil, ErrEmptyQuery
+	}
+	if count > consts.SearchMaxQueryTerms {
+		return nil, fmt.Errorf("%w: at most %d terms", ErrQueryTooLong, consts.SearchMaxQueryTerms)
+	}
+
+	return query, nil
+}
+
+// WithFilters returns a copy of the query filtering documents by domain and language, ignored when empty.
+func (q *Query) WithFilters(domain, language string) *Query {
+	filtered := *q
+	filtered.Domain = normalizeDomain(domain)
+	filtered.Language = normalizeLanguage(language)
+	return &filtered
+}
+
+// Matches reports whether a document from domain in language passes the filters of the query.
+func (q *Query) Matches(domain, language string) bool {
+	if q.Domain != "" && domain != q.Domain && !strings.HasSuffix(domain, "."+q.Domain) {
+		return false
+	}
+	if q.Language != "" {
+		language = normalizeLanguage(language)
+		if language != q.Language && !strings.HasPrefix(language, q.Language+"-") {
+			return false
+		}
+	}
+	return true
+}
+
+func normalizeDomain(domain string) string {
+	return strings.TrimPrefix(strings.ToLower(strings.TrimSpace(domain)), "www.")
+}
+
+func normalizeLanguage(language string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(language), "_", "-"))
+}
+
+// fold lowercases s and removes its accents and other combining marks, so that "Café" matches "cafe".
+func fold(s string) string {
+	s = strings.ToLower(s)
+	// a chain holds state, it can't be shared between goroutines
+	folded, _, err := transform.String(transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC), s)
+	if err != nil {
+		return s
+	}
+	return folded
+}
+
+// Tokenize splits text in lowercase terms without diacritics, with Unicode word segmentation (UAX #29).
+// Segments without letters or digits are skipped. Chinese characters and Japanese kana, written without
+// spaces between words, are a term each, so that words are found as phrases of characters.
+func Tokenize(text string) []string {
+	var tokens []string
+
+	state := -1
+	for text != "" {
+		var segment string
+		segment, text, state = uniseg.FirstWordInString(text, state)
+
+		isWord := false
+		for _, r := range segment {
+			if unicode.IsLetter(r) || unicode.IsNumber(r) {
+				isWord = true
+				break
+			}
+		}
+		if !isWord {
+			continue
+		}
+
+		if !strings.ContainsFunc(segment, isSpacelessScript) {
+			tokens = append(tokens, fold(segment))
+			continue
+		}
+		// kana are not folded, their voiced sound marks are combining marks too
+		for _, r := range segment {
+			if unicode.IsLetter(r) || unicode.IsNumber(r) {
+				tokens = append(tokens, string(unicode.ToLower(r)))
+			}
+		}
+	}
+
+	return tokens
+}
+
+func isSpacelessScript(r rune) bool {
+	return unicode.Is(unicode.Han, r) || unicode.Is(unicode.Hiragana, r) || unicode.Is(unicode.Katakana, r)
+}
@@ -0,0 +1,117 @@
+package search
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shaftoe/savetoink/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		expected []string
+	}{
+		{name: "words", text: "Hello, World! It's 2024.", expected: []string{"hello", "world", "it's", "2024"}},
+		{name: "diacritics", text: "Café Müller naïve", expected: []string{"cafe", "muller", "naive"}},
+		{name: "cyrillic", text: "Привет мир", expected: []string{"привет", "мир"}},
+		{name: "japanese", text: "東京タワー", expected: []string{"東", "京", "タ", "ワ", "ー"}},
+		{name: "voiced kana", text: "ガイド", expected: []string{"ガ", "イ", "ド"}},
+		{name: "punctuation only", text: " -- ... ", expected: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, Tokenize(tt.text))
+		})
+	}
+}
+
+func TestParseQuery(t *testing.T) {
+	tests := []struct {
+		name            string
+		query           string
+		expectedTerms   []string
+		expectedPhrases [][]string
+		expectedErr     error
+	}{
+		{name: "terms", query: "Go generics go", expectedTerms: []string{"go", "generics"}},
+		{
+			name:            "phrase",
+			query:           `epub "reading list" kindle`,
+			expectedTerms:   []string{"epub", "kindle"},
+			expectedPhrases: [][]string{{"reading", "list"}},
+		},
+		{name: "single word phrase", query: `"epub"`, expectedTerms: []string{"epub"}},
+		{name: "unclosed quote", query: `go "error handling`, expectedTerms: []string{"go"},
+			expectedPhrases: [][]string{{"error", "handling"}}},
+		{name: "japanese phrase", query: `"東京"`, expectedPhrases: [][]string{{"東", "京"}}},
+		{name: "empty", query: `  "" `, expectedErr: ErrEmptyQuery},
+		{name: "too long", query: strings.Repeat("a", 501), expectedErr: ErrQueryTooLong},
+		{name: "too many terms", query: strings.Repeat("word ", 33), expectedErr: ErrQueryTooLong},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, err := ParseQuery(tt.query)
+			if tt.expectedErr != nil {
+				require.ErrorIs(t, err, tt.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedTerms, query.Terms)
+			assert.Equal(t, tt.expectedPhrases, query.Phrases)
+		})
+	}
+}
+
+func TestQueryMatches(t *testing.T) {
+	tests := []struct {
+		name     string
+		domain   string
+		language string
+		expected bool
+	}{
+		{name: "no filters", expected: true},
+		{name: "same domain", domain: "go.dev", expected: true},
+		{name: "www prefix", domain: "WWW.Go.dev", expected: true},
+		{name: "parent domain", domain: "dev", expected: true},
+		{name: "other domain", domain: "example.com", expected: false},
+		{name: "suffix of label", domain: "o.dev", expected: false},
+		{name: "language", language: "en", expected: true},
+		{name: "language region", language: "en_US", expected: true},
+		{name: "other region", language: "en-GB", expected: false},
+		{name: "other language", language: "fr", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query := (&Query{Terms: []string{"go"}}).WithFilters(tt.domain, tt.language)
+			assert.Equal(t, tt.expected, query.Matches("blog.go.dev", "en-US"))
+		})
+	}
+}
+
+func TestDocumentOf(t *testing.T) {
+	createdAt := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	doc := DocumentOf(&model.Article{
+		Account:   "user1",
+		ID:        "1",
+		URL:       "https://www.example.com/post",
+		Title:     "Title",
+		Content:   "<p>First paragraph</p><script>ignored()</script><p>Second</p>",
+		Language:  "en",
+		CreatedAt: createdAt,
+	})
+
+	assert.Equal(t, "example.com", doc.Domain, "the domain defaults to the URL host")
+	assert.Equal(t, []string{"first", "paragraph", "second"}, Tokenize(doc.Content))
+	assert.Equal(t, createdAt, doc.CreatedAt)
+
+	doc = DocumentOf(&model.Article{URL: "https://example.com/post", SourceDomain: "Blog.Example.com"})
+	assert.Equal(t, "blog.example.com", doc.Domain)
+}
@@ -3,20 +3,33 @@ package server
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"log/slog"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/shaftoe/savetoink/internal/auth"
 	"github.com/shaftoe/savetoink/internal/consts"
+	"github.com/shaftoe/savetoink/internal/content"
+	"github.com/shaftoe/savetoink/internal/epub"
 	"github.com/shaftoe/savetoink/internal/model"
+	"github.com/shaftoe/savetoink/internal/search"
+	"github.com/shaftoe/savetoink/internal/service"
 )
 
 func (h *handlers) handleCreateArticle(w http.ResponseWriter, r *http.Request) {
 	var req articleRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		w.WriteHeader(http.StatusBadRequest)
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, consts.ArticleRequestMaxSize)).Decode(&req); err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			w.WriteHeader(http.StatusRequestEntityTooLarge)
+		} else {
+			w.WriteHeader(http.StatusBadRequest)
+		}
 		_ = json.NewEncoder(w).Encode(model.ErrorResponse{Error: "failed to decode request body: " + err.Error()})
 		return
 	}
@@ -27,18 +40,52 @@ func (h *handlers) handleCreateArticle(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	direction, err := epub.ParseDirection(req.Direction)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(model.ErrorResponse{Error: err.Error()})
+		return
+	}
+
 	addLogAttr(r.Context(), slog.String("url", req.URL))
+	if req.HTML != "" {
+		addLogAttr(r.Context(), slog.Int("html_size", len(req.HTML)))
+	}
 
-	result, err := h.service.CreateArticle(r.Context(), req.URL, auth.GetAccountID(r.Context()))
+	result, err := h.service.CreateArticle(r.Context(), req.URL, auth.GetAccountID(r.Context()), service.ProcessOptions{
+		Direction:       direction,
+		VerticalWriting: req.VerticalWriting,
+		HTML:            []byte(req.HTML),
+		NoCache:         req.NoCache,
+	})
 	if err != nil {
 		addLogAttr(r.Context(), slog.String("error", err.Error()))
-		w.WriteHeader(http.StatusInternalServerError)
+		switch {
+		case errors.Is(err, content.ErrForbiddenHost):
+			w.WriteHeader(http.StatusBadRequest)
+		case errors.Is(err, content.ErrHTMLTooLarge):
+			w.WriteHeader(http.StatusRequestEntityTooLarge)
+		case errors.Is(err, content.ErrRobotsDisallowed):
+			w.WriteHeader(http.StatusForbidden)
+		case errors.Is(err, content.ErrDeferred):
+			var deferred *content.DeferredError
+			if errors.As(err, &deferred) {
+				retryAfter := max(time.Until(deferred.RetryAt).Round(time.Second), time.Second)
+				w.Header().Set("Retry-After", strconv.Itoa(int(retryAfter.Seconds())))
+			}
+			w.WriteHeader(http.StatusServiceUnavailable)
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
 		_ = json.NewEncoder(w).Encode(model.ErrorResponse{Error: err.Error()})
 		return
 	}
 
 	addLogAttr(r.Context(), slog.String("article_id", result.Article.ID))
 	addLogAttr(r.Context(), slog.String("article_url", result.Article.URL))
+	if result.Article.Charset != "" {
+		addLogAttr(r.Context(), slog.String("charset", result.Article.Charset))
+	}
 	addLogAttr(r.Context(), slog.String("message", result.Message))
 
 	if result.Article.DeliveryStatus != "" {
@@ -64,9 +111,10 @@ func (h *handlers) handleCreateArticle(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
-func (h *handlers) handleGetArticles(w http.ResponseWriter, r *http.Request) {
-	page := consts.DefaultPage
-	pageSize := consts.DefaultPageSize
+// pagination returns the page and page_size query parameters, the defaults when missing or invalid.
+func pagination(r *http.Request) (page, pageSize int) {
+	page = consts.DefaultPage
+	pageSize = consts.DefaultPageSize
 
 	if p := r.URL.Query().Get("page"); p != "" {
 		if parsed, err := strconv.Atoi(p); err == nil && parsed >= consts.MinPage {
@@ -80,9 +128,138 @@ func (h *handlers) handleGetArticles(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	return page, pageSize
+}
+
+// parseArticleFilter returns the filter set by the query parameters of a list of articles. Dates are RFC 3339
+// timestamps or days, a day as upper bound includes the whole day.
+func parseArticleFilter(r *http.Request) (*model.ArticleFilter, error) {
+	query := r.URL.Query()
+	filter := &model.ArticleFilter{
+		DeliveryStatus: consts.Status(query.Get("delivery_status")),
+		SourceDomain:   query.Get("source_domain"),
+		Language:       query.Get("language"),
+		ContentType:    query.Get("content_type"),
+		Tag:            strings.ToLower(strings.TrimSpace(query.Get("tag"))),
+		Collection:     strings.TrimSpace(query.Get("collection")),
+		Sort:           consts.ArticleSort(query.Get("sort")),
+		Order:          consts.SortOrder(query.Get("order")),
+	}
+
+	switch filter.DeliveryStatus {
+	case "", consts.StatusPending, consts.StatusDelivered, consts.StatusFailed:
+	default:
+		return nil, fmt.Errorf("invalid delivery_status %q: must be pending, delivered or failed", filter.DeliveryStatus)
+	}
+
+	if states := query.Get("state"); states != "" {
+		for value := range strings.SplitSeq(states, ",") {
+			state, err := service.ParseReadState(value)
+			if err != nil {
+				return nil, err
+			}
+			filter.ReadStates = append(filter.ReadStates, state)
+		}
+	}
+
+	if favorite := query.Get("favorite"); favorite != "" {
+		value, err := strconv.ParseBool(favorite)
+		if err != nil {
+			return nil, fmt.Errorf("invalid favorite %q: must be true or false", favorite)
+		}
+		filter.Favorite = &value
+	}
+
+	switch filter.Sort {
+	case "", consts.SortCreated, consts.SortPublished, consts.SortReadingTime:
+	default:
+		return nil, fmt.Errorf("invalid sort %q: must be created, published or reading_time", filter.Sort)
+	}
+
+	switch filter.Order {
+	case "", consts.SortOrderAsc, consts.SortOrderDesc:
+	default:
+		return nil, fmt.Errorf("invalid order %q: must be asc or desc", filter.Order)
+	}
+
+	dates := []struct {
+		param string
+		value *time.Time
+		end   bool
+	}{
+		{"created_from", &filter.CreatedFrom, false},
+		{"created_to", &filter.CreatedTo, true},
+		{"published_from", &filter.PublishedFrom, false},
+		{"published_to", &filter.PublishedTo, true},
+	}
+	for _, date := range dates {
+		value := query.Get(date.param)
+		if value == "" {
+			continue
+		}
+		parsed, err := parseDateParam(value, date.end)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s %q: must be a date (2006-01-02) or an RFC 3339 timestamp", date.param, value)
+		}
+		*date.value = parsed
+	}
+
+	readingTimes := []struct {
+		param string
+		value *int
+	}{
+		{"min_reading_time", &filter.MinReadingTime},
+		{"max_reading_time", &filter.MaxReadingTime},
+	}
+	for _, readingTime := range readingTimes {
+		value := query.Get(readingTime.param)
+		if value == "" {
+			continue
+		}
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 {
+			return nil, fmt.Errorf("invalid %s %q: must be a positive number of minutes", readingTime.param, value)
+		}
+		*readingTime.value = parsed
+	}
+
+	return filter, nil
+}
+
+// parseDateParam parses an RFC 3339 timestamp or a day, its last instant when end is set.
+func parseDateParam(value string, end bool) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+
+	day, err := time.Parse(time.DateOnly, value)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if end {
+		return day.AddDate(0, 0, 1).Add(-time.Nanosecond), nil
+	}
+	return day, nil
+}
+
+func (h *handlers) handleGetArticles(w http.ResponseWriter, r *http.Request) {
+	page, pageSize := pagination(r)
+
+	filter, err := parseArticleFilter(r)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(model.ErrorResponse{Error: err.Error()})
+		return
+	}
+
 	accountID := auth.GetAccountID(r.Context())
 
-	result, err := h.service.GetArticlesMetadata(r.Context(), accountID, page, pageSize)
+	result, err := h.service.GetArticlesMetadata(r.Context(), accountID, filter, page, pageSize)
+	if errors.Is(err, service.ErrSortUnavailable) {
+		w.WriteHeader(http.StatusNotImplemented)
+		_ = json.NewEncoder(w).Encode(model.ErrorResponse{Error: err.Error()})
+		return
+	}
 	if err != nil {
 		addLogAttr(r.Context(), slog.String("db_error", err.Error()))
 		w.WriteHeader(http.StatusInternalServerError)
@@ -104,6 +281,46 @@ func (h *handlers) handleGetArticles(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// handleSearchArticles searches the articles for the words of the q query parameter, words in double quotes as
+// a phrase, optionally filtering them by domain and language.
+func (h *handlers) handleSearchArticles(w http.ResponseWriter, r *http.Request) {
+	page, pageSize := pagination(r)
+	opts := service.SearchOptions{
+		Query:    r.URL.Query().Get("q"),
+		Domain:   r.URL.Query().Get("domain"),
+		Language: r.URL.Query().Get("language"),
+		Page:     page,
+		PageSize: pageSize,
+	}
+
+	addLogAttr(r.Context(), slog.String("query", opts.Query))
+
+	result, err := h.service.SearchArticles(r.Context(), auth.GetAccountID(r.Context()), opts)
+	if err != nil {
+		addLogAttr(r.Context(), slog.String("error", err.Error()))
+		if errors.Is(err, search.ErrEmptyQuery) || errors.Is(err, search.ErrQueryTooLong) {
+			w.WriteHeader(http.StatusBadRequest)
+		} else {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+		_ = json.NewEncoder(w).Encode(model.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	addLogAttr(r.Context(), slog.Int("page", page))
+	addLogAttr(r.Context(), slog.Int("page_size", pageSize))
+	addLogAttr(r.Context(), slog.Int("total", result.Total))
+
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(listArticlesResponse{
+		Articles: result.Articles,
+		Page:     result.Page,
+		PageSize: result.PageSize,
+		Total:    result.Total,
+		HasMore:  result.HasMore,
+	})
+}
+
 func (h *handlers) handleGetArticle(w http.ResponseWriter, r *http.Request) {
 	accountID := auth.GetAccountID(r.Context())
 	articleID := chi.URLParam(r, "id")
@@ -124,6 +341,51 @@ func (h *handlers) handleGetArticle(w http.ResponseWriter, r *http.Request) {
 	_ = json.NewEncoder(w).Encode(article)
 }
 
+// handleUpdateArticle updates the read state and favorite flag of an article.
+func (h *handlers) handleUpdateArticle(w http.ResponseWriter, r *http.Request) {
+	articleID := chi.URLParam(r, "id")
+
+	addLogAttr(r.Context(), slog.String("article_id", articleID))
+
+	var req articleUpdateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(model.ErrorResponse{Error: "failed to decode request body: " + err.Error()})
+		return
+	}
+
+	if req.ReadState == nil && req.Favorite == nil {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(model.ErrorResponse{Error: "missing readState or favorite in request body"})
+		return
+	}
+
+	update := service.ArticleUpdate{Favorite: req.Favorite}
+	if req.ReadState != nil {
+		state := consts.ReadState(*req.ReadState)
+		update.ReadState = &state
+		addLogAttr(r.Context(), slog.String("read_state", *req.ReadState))
+	}
+
+	article, err := h.service.UpdateArticle(r.Context(), auth.GetAccountID(r.Context()), articleID, update)
+	if err != nil {
+		addLogAttr(r.Context(), slog.String("error", err.Error()))
+		switch {
+		case errors.Is(err, service.ErrInvalidReadState):
+			w.WriteHeader(http.StatusBadRequest)
+		case errors.Is(err, service.ErrArticleNotFound):
+			w.WriteHeader(http.StatusNotFound)
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+		_ = json.NewEncoder(w).Encode(model.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(article)
+}
+
 func (h *handlers) handleDeleteArticle(w http.ResponseWriter, r *http.Request) {
 	accountID := auth.GetAccountID(r.Context())
 	articleID := chi.URLParam(r, "id")
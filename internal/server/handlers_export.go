@@ -0,0 +1,110 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/shaftoe/savetoink/internal/auth"
+	"github.com/shaftoe/savetoink/internal/consts"
+	"github.com/shaftoe/savetoink/internal/model"
+	"github.com/shaftoe/savetoink/internal/service"
+)
+
+// errExportTooLarge is returned when an export archive exceeds consts.LambdaMaxExportSize.
+var errExportTooLarge = errors.New("export too large")
+
+// limitedBuffer buffers up to limit bytes, writes beyond fail with errExportTooLarge.
+type limitedBuffer struct {
+	bytes.Buffer
+	limit int
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if b.Len()+len(p) > b.limit {
+		return 0, errExportTooLarge
+	}
+	return b.Buffer.Write(p)
+}
+
+// exportResponseWriter sets the headers of the export archive on the first write, so that an export failing
+// before writing anything can still be answered with a JSON error.
+type exportResponseWriter struct {
+	http.ResponseWriter
+	filename string
+	started  bool
+}
+
+func (w *exportResponseWriter) Write(p []byte) (int, error) {
+	if !w.started {
+		w.started = true
+		w.Header().Set("Content-Type", "application/zip")
+		w.Header().Set("Content-Disposition", `attachment; filename="`+w.filename+`"`)
+		w.WriteHeader(http.StatusOK)
+	}
+	return w.ResponseWriter.Write(p)
+}
+
+// handleExport streams the library of the account as a zip archive, adding the EPUB and HTML files of the
+// articles when the epub and html query parameters are true. On Lambda, where the response is buffered and its
+// size capped, the archive is built in memory first and refused with 413 when it exceeds
+// consts.LambdaMaxExportSize, large libraries are exported with the CLI export command.
+func (h *handlers) handleExport(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	opts := service.ExportOptions{}
+	opts.EPUB, _ = strconv.ParseBool(query.Get("epub"))
+	opts.HTML, _ = strconv.ParseBool(query.Get("html"))
+
+	addLogAttr(r.Context(), slog.Bool("epub", opts.EPUB))
+	addLogAttr(r.Context(), slog.Bool("html", opts.HTML))
+
+	ew := &exportResponseWriter{
+		ResponseWriter: w,
+		filename:       "savetoink-export-" + time.Now().UTC().Format(time.DateOnly) + ".zip",
+	}
+
+	if !h.cfg.Lambda {
+		count, err := h.service.Export(r.Context(), auth.GetAccountID(r.Context()), ew, opts)
+		h.writeExportResult(w, r, ew, count, err)
+		return
+	}
+
+	buffer := &limitedBuffer{limit: consts.LambdaMaxExportSize}
+	count, err := h.service.Export(r.Context(), auth.GetAccountID(r.Context()), buffer, opts)
+	if errors.Is(err, errExportTooLarge) {
+		addLogAttr(r.Context(), slog.String("error", err.Error()))
+		w.WriteHeader(http.StatusRequestEntityTooLarge)
+		_ = json.NewEncoder(w).Encode(model.ErrorResponse{
+			Error: fmt.Sprintf("export exceeds %d bytes, export fewer files or use the CLI export command",
+				consts.LambdaMaxExportSize),
+		})
+		return
+	}
+	if err == nil {
+		_, err = buffer.WriteTo(ew)
+	}
+	h.writeExportResult(w, r, ew, count, err)
+}
+
+// writeExportResult logs the result of an export, answering with a JSON error if it failed before writing
+// the archive.
+func (h *handlers) writeExportResult(w http.ResponseWriter, r *http.Request, ew *exportResponseWriter, count int,
+	err error,
+) {
+	if err != nil {
+		addLogAttr(r.Context(), slog.String("error", err.Error()))
+		// once the archive is being streamed the status is sent, the client gets a truncated archive
+		if !ew.started {
+			w.WriteHeader(http.StatusInternalServerError)
+			_ = json.NewEncoder(w).Encode(model.ErrorResponse{Error: err.Error()})
+		}
+		return
+	}
+
+	addLogAttr(r.Context(), slog.Int("count", count))
+}
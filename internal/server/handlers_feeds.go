@@ -0,0 +1,114 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/shaftoe/savetoink/internal/auth"
+	"github.com/shaftoe/savetoink/internal/content"
+	"github.com/shaftoe/savetoink/internal/feed"
+	"github.com/shaftoe/savetoink/internal/model"
+	"github.com/shaftoe/savetoink/internal/service"
+)
+
+func (h *handlers) handleCreateFeed(w http.ResponseWriter, r *http.Request) {
+	var req feedRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(model.ErrorResponse{Error: "failed to decode request body: " + err.Error()})
+		return
+	}
+
+	if req.URL == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(model.ErrorResponse{Error: "missing URL in request body"})
+		return
+	}
+
+	delivery, err := service.ParseFeedDelivery(req.Delivery)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(model.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	addLogAttr(r.Context(), slog.String("url", req.URL))
+	addLogAttr(r.Context(), slog.String("delivery", string(delivery)))
+
+	subscription, err := h.service.CreateFeed(r.Context(), auth.GetAccountID(r.Context()), req.URL, delivery)
+	if err != nil {
+		addLogAttr(r.Context(), slog.String("error", err.Error()))
+		switch {
+		case errors.Is(err, content.ErrForbiddenHost), errors.Is(err, feed.ErrInvalidFeed):
+			w.WriteHeader(http.StatusBadRequest)
+		case errors.Is(err, content.ErrRobotsDisallowed):
+			w.WriteHeader(http.StatusForbidden)
+		case errors.Is(err, content.ErrDeferred):
+			w.WriteHeader(http.StatusServiceUnavailable)
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+		_ = json.NewEncoder(w).Encode(model.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	addLogAttr(r.Context(), slog.String("feed_id", subscription.ID))
+
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(subscription)
+}
+
+func (h *handlers) handleGetFeeds(w http.ResponseWriter, r *http.Request) {
+	feeds, err := h.service.GetFeeds(r.Context(), auth.GetAccountID(r.Context()))
+	if err != nil {
+		addLogAttr(r.Context(), slog.String("db_error", err.Error()))
+		w.WriteHeader(http.StatusInternalServerError)
+		_ = json.NewEncoder(w).Encode(model.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	addLogAttr(r.Context(), slog.Int("total", len(feeds)))
+
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(listFeedsResponse{Feeds: feeds})
+}
+
+func (h *handlers) handleDeleteFeed(w http.ResponseWriter, r *http.Request) {
+	feedID := chi.URLParam(r, "id")
+
+	addLogAttr(r.Context(), slog.String("feed_id", feedID))
+
+	if err := h.service.DeleteFeed(r.Context(), auth.GetAccountID(r.Context()), feedID); err != nil {
+		if errors.Is(err, service.ErrFeedNotFound) {
+			w.WriteHeader(http.StatusNotFound)
+		} else {
+			addLogAttr(r.Context(), slog.String("db_error", err.Error()))
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+		_ = json.NewEncoder(w).Encode(model.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handlePollFeeds polls the feeds of the account right away, e.g. from a scheduled job where the HTTP server
+// poller doesn't run.
+func (h *handlers) handlePollFeeds(w http.ResponseWriter, r *http.Request) {
+	result, err := h.service.PollFeeds(r.Context(), auth.GetAccountID(r.Context()))
+	if err != nil {
+		addLogAttr(r.Context(), slog.String("db_error", err.Error()))
+		w.WriteHeader(http.StatusInternalServerError)
+		_ = json.NewEncoder(w).Encode(model.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	addLogAttr(r.Context(), slog.Int("feeds", result.Feeds))
+	addLogAttr(r.Context(), slog.Int("articles", result.Articles))
+
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(result)
+}
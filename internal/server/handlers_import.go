@@ -0,0 +1,85 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/shaftoe/savetoink/internal/auth"
+	"github.com/shaftoe/savetoink/internal/consts"
+	"github.com/shaftoe/savetoink/internal/importer"
+	"github.com/shaftoe/savetoink/internal/model"
+	"github.com/shaftoe/savetoink/internal/service"
+)
+
+// handleImport starts importing the file sent as request body, in the format set by the format query parameter
+// or detected from its content. The items are saved in the background, the returned job tracks them, except on
+// Lambda where the returned job is already finished.
+func (h *handlers) handleImport(w http.ResponseWriter, r *http.Request) {
+	format, err := importer.ParseFormat(r.URL.Query().Get("format"))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(model.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, consts.ImportMaxSize))
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			w.WriteHeader(http.StatusRequestEntityTooLarge)
+		} else {
+			w.WriteHeader(http.StatusBadRequest)
+		}
+		_ = json.NewEncoder(w).Encode(model.ErrorResponse{Error: "failed to read request body: " + err.Error()})
+		return
+	}
+
+	addLogAttr(r.Context(), slog.Int("import_size", len(data)))
+
+	job, err := h.service.Import(r.Context(), auth.GetAccountID(r.Context()), data, service.ImportOptions{Format: format})
+	if err != nil {
+		addLogAttr(r.Context(), slog.String("error", err.Error()))
+		switch {
+		case errors.Is(err, importer.ErrUnknownFormat), errors.Is(err, importer.ErrInvalidFile):
+			w.WriteHeader(http.StatusBadRequest)
+		case errors.Is(err, service.ErrTooManyItems):
+			w.WriteHeader(http.StatusRequestEntityTooLarge)
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+		_ = json.NewEncoder(w).Encode(model.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	addLogAttr(r.Context(), slog.String("import_id", job.ID))
+	addLogAttr(r.Context(), slog.String("format", string(job.Format)))
+	addLogAttr(r.Context(), slog.Int("total", job.Total))
+
+	w.WriteHeader(http.StatusAccepted)
+	_ = json.NewEncoder(w).Encode(job)
+}
+
+func (h *handlers) handleGetImportJob(w http.ResponseWriter, r *http.Request) {
+	jobID := chi.URLParam(r, "id")
+
+	addLogAttr(r.Context(), slog.String("import_id", jobID))
+
+	job, err := h.service.GetImportJob(r.Context(), auth.GetAccountID(r.Context()), jobID)
+	if err != nil {
+		if errors.Is(err, service.ErrImportNotFound) {
+			w.WriteHeader(http.StatusNotFound)
+		} else {
+			addLogAttr(r.Context(), slog.String("db_error", err.Error()))
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+		_ = json.NewEncoder(w).Encode(model.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(job)
+}
@@ -0,0 +1,56 @@
+package server
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"github.com/shaftoe/savetoink/internal/auth"
+	"github.com/shaftoe/savetoink/internal/model"
+	"github.com/shaftoe/savetoink/internal/transform"
+)
+
+func (h *handlers) handleGetSettings(w http.ResponseWriter, r *http.Request) {
+	settings, err := h.service.GetSettings(r.Context(), auth.GetAccountID(r.Context()))
+	if err != nil {
+		addLogAttr(r.Context(), slog.String("db_error", err.Error()))
+		w.WriteHeader(http.StatusInternalServerError)
+		_ = json.NewEncoder(w).Encode(model.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(settings)
+}
+
+func (h *handlers) handleUpdateSettings(w http.ResponseWriter, r *http.Request) {
+	var req settingsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(model.ErrorResponse{Error: "failed to decode request body: " + err.Error()})
+		return
+	}
+
+	linkMode, err := transform.ParseLinkMode(req.LinkMode)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(model.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	addLogAttr(r.Context(), slog.String("link_mode", string(linkMode)))
+
+	settings, err := h.service.UpdateSettings(r.Context(), &model.Settings{
+		Account:  auth.GetAccountID(r.Context()),
+		LinkMode: linkMode,
+	})
+	if err != nil {
+		addLogAttr(r.Context(), slog.String("db_error", err.Error()))
+		w.WriteHeader(http.StatusInternalServerError)
+		_ = json.NewEncoder(w).Encode(model.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(settings)
+}
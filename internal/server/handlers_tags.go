@@ -0,0 +1,63 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/shaftoe/savetoink/internal/auth"
+	"github.com/shaftoe/savetoink/internal/model"
+	"github.com/shaftoe/savetoink/internal/service"
+)
+
+// handleSetTags replaces the tags and collection of an article.
+func (h *handlers) handleSetTags(w http.ResponseWriter, r *http.Request) {
+	articleID := chi.URLParam(r, "id")
+
+	addLogAttr(r.Context(), slog.String("article_id", articleID))
+
+	var req tagsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(model.ErrorResponse{Error: "failed to decode request body: " + err.Error()})
+		return
+	}
+
+	article, err := h.service.SetTags(r.Context(), auth.GetAccountID(r.Context()), articleID, req.Tags, req.Collection)
+	if err != nil {
+		addLogAttr(r.Context(), slog.String("error", err.Error()))
+		switch {
+		case errors.Is(err, service.ErrInvalidTags):
+			w.WriteHeader(http.StatusBadRequest)
+		case errors.Is(err, service.ErrArticleNotFound):
+			w.WriteHeader(http.StatusNotFound)
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+		_ = json.NewEncoder(w).Encode(model.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	addLogAttr(r.Context(), slog.Int("tags", len(article.Tags)))
+
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(article)
+}
+
+// handleGetTags lists the tags and collections of the account with their number of articles.
+func (h *handlers) handleGetTags(w http.ResponseWriter, r *http.Request) {
+	tags, err := h.service.GetTags(r.Context(), auth.GetAccountID(r.Context()))
+	if err != nil {
+		addLogAttr(r.Context(), slog.String("db_error", err.Error()))
+		w.WriteHeader(http.StatusInternalServerError)
+		_ = json.NewEncoder(w).Encode(model.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	addLogAttr(r.Context(), slog.Int("total", len(tags.Tags)))
+
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(tags)
+}
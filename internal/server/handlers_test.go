@@ -4,15 +4,25 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"fmt"
+	"io"
 	"log/slog"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/go-chi/chi/v5"
 	"github.com/shaftoe/savetoink/internal/config"
+	"github.com/shaftoe/savetoink/internal/consts"
+	"github.com/shaftoe/savetoink/internal/content"
 	"github.com/shaftoe/savetoink/internal/email"
+	"github.com/shaftoe/savetoink/internal/feed"
+	"github.com/shaftoe/savetoink/internal/importer"
 	"github.com/shaftoe/savetoink/internal/model"
+	"github.com/shaftoe/savetoink/internal/search"
 	"github.com/shaftoe/savetoink/internal/service"
 )
 
@@ -23,14 +33,27 @@ const (
 
 type MockService struct {
 	createFunc          func(context.Context, string, string) (*service.CreateArticleResult, error)
-	processFunc         func(context.Context, string) (*service.ProcessResult, error)
+	processFunc         func(context.Context, string, service.ProcessOptions) (*service.ProcessResult, error)
 	sendFunc            func(context.Context, *service.ProcessResult, string) (*email.SendEmailResponse, error)
 	writeFunc           func(*service.ProcessResult, string) error
 	getArticle          func(context.Context, string, string) (*model.Article, error)
-	getArticlesMetadata func(context.Context, string, int, int) (*service.GetArticlesResult, error)
+	getArticlesMetadata func(context.Context, string, *model.ArticleFilter, int, int) (*service.GetArticlesResult, error)
+	searchArticles      func(context.Context, string, service.SearchOptions) (*service.GetArticlesResult, error)
+	updateArticle       func(context.Context, string, string, service.ArticleUpdate) (*model.Article, error)
+	setTags             func(context.Context, string, string, []string, string) (*model.Article, error)
+	getTags             func(context.Context, string) (*model.Tags, error)
 	deleteArticle       func(context.Context, string, string) (*service.DeleteArticleResult, error)
 	deleteAllArticles   func(context.Context, string) (*service.DeleteArticleResult, error)
+	updateSettings      func(context.Context, *model.Settings) (*model.Settings, error)
+	createFeed          func(context.Context, string, string, consts.FeedDelivery) (*model.Feed, error)
+	getFeeds            func(context.Context, string) ([]*model.Feed, error)
+	deleteFeed          func(context.Context, string, string) error
+	pollFeeds           func(context.Context, string) (*service.PollFeedsResult, error)
+	importFunc          func(context.Context, string, []byte, service.ImportOptions) (*model.ImportJob, error)
+	getImportJob        func(context.Context, string, string) (*model.ImportJob, error)
+	export              func(context.Context, string, io.Writer, service.ExportOptions) (int, error)
 	dbError             error
+	createOpts          service.ProcessOptions
 }
 
 func newMockService(
@@ -45,16 +68,22 @@ func (m *MockService) CreateArticle(
 	ctx context.Context,
 	_ string,
 	_ string,
+	opts service.ProcessOptions,
 ) (*service.CreateArticleResult, error) {
+	m.createOpts = opts
 	if m.createFunc != nil {
 		return m.createFunc(ctx, "", "")
 	}
 	return nil, nil
 }
 
-func (m *MockService) Process(ctx context.Context, url string) (*service.ProcessResult, error) {
+func (m *MockService) Process(
+	ctx context.Context,
+	url string,
+	opts service.ProcessOptions,
+) (*service.ProcessResult, error) {
 	if m.processFunc != nil {
-		return m.processFunc(ctx, url)
+		return m.processFunc(ctx, url, opts)
 	}
 	return nil, nil
 }
@@ -99,11 +128,12 @@ func (m *MockService) GetArticle(
 func (m *MockService) GetArticlesMetadata(
 	ctx context.Context,
 	accountID string,
+	filter *model.ArticleFilter,
 	page int,
 	pageSize int,
 ) (*service.GetArticlesResult, error) {
 	if m.getArticlesMetadata != nil {
-		return m.getArticlesMetadata(ctx, accountID, page, pageSize)
+		return m.getArticlesMetadata(ctx, accountID, filter, page, pageSize)
 	}
 	return &service.GetArticlesResult{
 		Articles: []*model.Article{},
@@ -114,6 +144,47 @@ func (m *MockService) GetArticlesMetadata(
 	}, nil
 }
 
+func (m *MockService) SearchArticles(
+	ctx context.Context,
+	accountID string,
+	opts service.SearchOptions,
+) (*service.GetArticlesResult, error) {
+	if m.searchArticles != nil {
+		return m.searchArticles(ctx, accountID, opts)
+	}
+	return &service.GetArticlesResult{Articles: []*model.Article{}, Page: opts.Page, PageSize: opts.PageSize}, nil
+}
+
+func (m *MockService) UpdateArticle(
+	ctx context.Context,
+	accountID, articleID string,
+	update service.ArticleUpdate,
+) (*model.Article, error) {
+	if m.updateArticle != nil {
+		return m.updateArticle(ctx, accountID, articleID, update)
+	}
+	return &model.Article{ID: articleID}, nil
+}
+
+func (m *MockService) SetTags(
+	ctx context.Context,
+	accountID, articleID string,
+	tags []string,
+	collection string,
+) (*model.Article, error) {
+	if m.setTags != nil {
+		return m.setTags(ctx, accountID, articleID, tags, collection)
+	}
+	return &model.Article{ID: articleID, Tags: tags, Collection: collection}, nil
+}
+
+func (m *MockService) GetTags(ctx context.Context, accountID string) (*model.Tags, error) {
+	if m.getTags != nil {
+		return m.getTags(ctx, accountID)
+	}
+	return &model.Tags{Tags: []*model.TagCount{}, Collections: []*model.TagCount{}}, nil
+}
+
 func (m *MockService) DeleteArticle(
 	ctx context.Context,
 	accountID string,
@@ -135,6 +206,80 @@ func (m *MockService) DeleteAllArticles(
 	return &service.DeleteArticleResult{Deleted: 0}, nil
 }
 
+func (m *MockService) GetSettings(_ context.Context, accountID string) (*model.Settings, error) {
+	return &model.Settings{Account: accountID, LinkMode: consts.LinkModeKeep}, nil
+}
+
+func (m *MockService) UpdateSettings(ctx context.Context, settings *model.Settings) (*model.Settings, error) {
+	if m.updateSettings != nil {
+		return m.updateSettings(ctx, settings)
+	}
+	return settings, nil
+}
+
+func (m *MockService) CreateFeed(
+	ctx context.Context,
+	accountID, rawURL string,
+	delivery consts.FeedDelivery,
+) (*model.Feed, error) {
+	if m.createFeed != nil {
+		return m.createFeed(ctx, accountID, rawURL, delivery)
+	}
+	return &model.Feed{Account: accountID, ID: "feed-id", URL: rawURL, Delivery: delivery}, nil
+}
+
+func (m *MockService) GetFeeds(ctx context.Context, accountID string) ([]*model.Feed, error) {
+	if m.getFeeds != nil {
+		return m.getFeeds(ctx, accountID)
+	}
+	return []*model.Feed{}, nil
+}
+
+func (m *MockService) DeleteFeed(ctx context.Context, accountID, feedID string) error {
+	if m.deleteFeed != nil {
+		return m.deleteFeed(ctx, accountID, feedID)
+	}
+	return nil
+}
+
+func (m *MockService) PollFeeds(ctx context.Context, accountID string) (*service.PollFeedsResult, error) {
+	if m.pollFeeds != nil {
+		return m.pollFeeds(ctx, accountID)
+	}
+	return &service.PollFeedsResult{}, nil
+}
+
+func (m *MockService) Import(
+	ctx context.Context,
+	accountID string,
+	data []byte,
+	opts service.ImportOptions,
+) (*model.ImportJob, error) {
+	if m.importFunc != nil {
+		return m.importFunc(ctx, accountID, data, opts)
+	}
+	return &model.ImportJob{Account: accountID, Status: consts.ImportStatusRunning}, nil
+}
+
+func (m *MockService) GetImportJob(ctx context.Context, accountID, jobID string) (*model.ImportJob, error) {
+	if m.getImportJob != nil {
+		return m.getImportJob(ctx, accountID, jobID)
+	}
+	return nil, service.ErrImportNotFound
+}
+
+func (m *MockService) Export(
+	ctx context.Context,
+	accountID string,
+	w io.Writer,
+	opts service.ExportOptions,
+) (int, error) {
+	if m.export != nil {
+		return m.export(ctx, accountID, w, opts)
+	}
+	return 0, nil
+}
+
 func TestHandleHealth(t *testing.T) {
 	h := newHandlers(nil, nil)
 
@@ -307,6 +452,22 @@ func TestHandleCreateArticleMissingURL(t *testing.T) {
 	}
 }
 
+func TestHandleCreateArticleInvalidDirection(t *testing.T) {
+	h := newHandlers(&config.Config{}, nil)
+
+	body := articleRequest{URL: "https://example.com/article", Direction: "ttb"}
+	bodyBytes, _ := json.Marshal(body)
+	req := httptest.NewRequest("POST", "/v1/articles", bytes.NewReader(bodyBytes))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	h.handleCreateArticle(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected status %d, got %d", http.StatusBadRequest, w.Code)
+	}
+}
+
 func TestHandleCreateArticleServiceError(t *testing.T) {
 	cfg := &config.Config{
 		SendEnabled: false,
@@ -346,11 +507,166 @@ func (e *serviceError) Error() string {
 	return e.msg
 }
 
+func TestHandleCreateArticleForbiddenHost(t *testing.T) {
+	cfg := &config.Config{}
+	svc := newMockService(func(_ context.Context, _ string, _ string) (*service.CreateArticleResult, error) {
+		return nil, fmt.Errorf("failed to fetch URL: %w: 127.0.0.1 is an internal address", content.ErrForbiddenHost)
+	})
+	h := newHandlers(cfg, svc)
+
+	bodyBytes, _ := json.Marshal(articleRequest{URL: "http://localhost/admin"})
+	req := httptest.NewRequest("POST", "/v1/articles", bytes.NewReader(bodyBytes))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	h.handleCreateArticle(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected status %d, got %d", http.StatusBadRequest, w.Code)
+	}
+}
+
+func TestHandleCreateArticleClientHTML(t *testing.T) {
+	cfg := &config.Config{}
+	svc := newMockService(func(_ context.Context, _ string, _ string) (*service.CreateArticleResult, error) {
+		return &service.CreateArticleResult{
+			Article: &model.Article{ID: "test-id", URL: "https://example.com/article", ClientCaptured: true},
+		}, nil
+	})
+	h := newHandlers(cfg, svc)
+
+	html := "<html><body><article><p>Rendered by the browser.</p></article></body></html>"
+	bodyBytes, _ := json.Marshal(articleRequest{URL: "https://example.com/article", HTML: html})
+	req := httptest.NewRequest("POST", "/v1/articles", bytes.NewReader(bodyBytes))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	h.handleCreateArticle(w, req)
+
+	if w.Code != http.StatusCreated {
+		t.Errorf("expected status %d, got %d", http.StatusCreated, w.Code)
+	}
+	if string(svc.createOpts.HTML) != html {
+		t.Errorf("expected HTML to be passed to the service, got %q", svc.createOpts.HTML)
+	}
+}
+
+func TestHandleCreateArticleNoCache(t *testing.T) {
+	cfg := &config.Config{}
+	svc := newMockService(func(_ context.Context, _ string, _ string) (*service.CreateArticleResult, error) {
+		return &service.CreateArticleResult{
+			Article: &model.Article{ID: "test-id", URL: "https://example.com/article"},
+		}, nil
+	})
+	h := newHandlers(cfg, svc)
+
+	req := httptest.NewRequest("POST", "/v1/articles",
+		strings.NewReader(`{"url": "https://example.com/article", "noCache": true}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	h.handleCreateArticle(w, req)
+
+	if w.Code != http.StatusCreated {
+		t.Errorf("expected status %d, got %d", http.StatusCreated, w.Code)
+	}
+	if !svc.createOpts.NoCache {
+		t.Error("expected NoCache to be passed to the service")
+	}
+}
+
+func TestHandleCreateArticleFetchRefused(t *testing.T) {
+	tests := []struct {
+		name           string
+		err            error
+		wantStatus     int
+		wantRetryAfter string
+	}{
+		{
+			name:       "disallowed by robots.txt",
+			err:        fmt.Errorf("failed to process article: %w", content.ErrRobotsDisallowed),
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name: "deferred",
+			err: fmt.Errorf("failed to process article: %w", &content.DeferredError{
+				Host:    "example.com",
+				RetryAt: time.Now().Add(2 * time.Minute),
+			}),
+			wantStatus:     http.StatusServiceUnavailable,
+			wantRetryAfter: "120",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{}
+			svc := newMockService(func(_ context.Context, _ string, _ string) (*service.CreateArticleResult, error) {
+				return nil, tt.err
+			})
+			h := newHandlers(cfg, svc)
+
+			req := httptest.NewRequest("POST", "/v1/articles", strings.NewReader(`{"url": "https://example.com/article"}`))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			h.handleCreateArticle(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("expected status %d, got %d", tt.wantStatus, w.Code)
+			}
+			if got := w.Header().Get("Retry-After"); got != tt.wantRetryAfter {
+				t.Errorf("expected Retry-After %q, got %q", tt.wantRetryAfter, got)
+			}
+		})
+	}
+}
+
+func TestHandleCreateArticleHTMLTooLarge(t *testing.T) {
+	tests := []struct {
+		name    string
+		html    string
+		service error
+	}{
+		{
+			name: "request body over limit",
+			html: strings.Repeat("a", consts.ArticleRequestMaxSize),
+		},
+		{
+			name:    "HTML over limit",
+			html:    "<p>big</p>",
+			service: fmt.Errorf("failed to process article: %w", content.ErrHTMLTooLarge),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := newMockService(func(_ context.Context, _ string, _ string) (*service.CreateArticleResult, error) {
+				return nil, tt.service
+			})
+			h := newHandlers(&config.Config{}, svc)
+
+			bodyBytes, _ := json.Marshal(articleRequest{URL: "https://example.com/article", HTML: tt.html})
+			req := httptest.NewRequest("POST", "/v1/articles", bytes.NewReader(bodyBytes))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			h.handleCreateArticle(w, req)
+
+			if w.Code != http.StatusRequestEntityTooLarge {
+				t.Errorf("expected status %d, got %d", http.StatusRequestEntityTooLarge, w.Code)
+			}
+		})
+	}
+}
+
 func TestHandleGetArticlesSuccess(t *testing.T) {
 	cfg := &config.Config{}
 	svc := newMockService(nil)
 	now := time.Now()
-	svc.getArticlesMetadata = func(_ context.Context, _ string, page, pageSize int) (*service.GetArticlesResult, error) {
+	svc.getArticlesMetadata = func(
+		_ context.Context, _ string, _ *model.ArticleFilter, page, pageSize int,
+	) (*service.GetArticlesResult, error) {
 		articles := []*model.Article{
 			{ID: "5", Title: "Article 5", URL: "https://example.com/5", CreatedAt: now},
 			{ID: "4", Title: "Article 4", URL: "https://example.com/4", CreatedAt: now.Add(-1 * time.Hour)},
@@ -399,7 +715,9 @@ func TestHandleGetArticlesSuccess(t *testing.T) {
 func TestHandleGetArticlesDefaultParams(t *testing.T) {
 	cfg := &config.Config{}
 	svc := newMockService(nil)
-	svc.getArticlesMetadata = func(_ context.Context, _ string, page, pageSize int) (*service.GetArticlesResult, error) {
+	svc.getArticlesMetadata = func(
+		_ context.Context, _ string, _ *model.ArticleFilter, page, pageSize int,
+	) (*service.GetArticlesResult, error) {
 		return &service.GetArticlesResult{
 			Articles: []*model.Article{},
 			Page:     page,
@@ -435,7 +753,9 @@ func TestHandleGetArticlesDefaultParams(t *testing.T) {
 func TestHandleGetArticlesInvalidParams(t *testing.T) {
 	cfg := &config.Config{}
 	svc := newMockService(nil)
-	svc.getArticlesMetadata = func(_ context.Context, _ string, page, pageSize int) (*service.GetArticlesResult, error) {
+	svc.getArticlesMetadata = func(
+		_ context.Context, _ string, _ *model.ArticleFilter, page, pageSize int,
+	) (*service.GetArticlesResult, error) {
 		return &service.GetArticlesResult{
 			Articles: []*model.Article{},
 			Page:     page,
@@ -490,7 +810,9 @@ func TestHandleGetArticlesInvalidParams(t *testing.T) {
 func TestHandleGetArticlesServiceError(t *testing.T) {
 	cfg := &config.Config{}
 	svc := newMockService(nil)
-	svc.getArticlesMetadata = func(_ context.Context, _ string, _ int, _ int) (*service.GetArticlesResult, error) {
+	svc.getArticlesMetadata = func(
+		_ context.Context, _ string, _ *model.ArticleFilter, _ int, _ int,
+	) (*service.GetArticlesResult, error) {
 		return nil, &serviceError{msg: "database error"}
 	}
 	h := newHandlers(cfg, svc)
@@ -514,6 +836,193 @@ func TestHandleGetArticlesServiceError(t *testing.T) {
 	}
 }
 
+func TestHandleGetArticlesSortUnavailable(t *testing.T) {
+	svc := newMockService(nil)
+	svc.getArticlesMetadata = func(
+		_ context.Context, _ string, _ *model.ArticleFilter, _ int, _ int,
+	) (*service.GetArticlesResult, error) {
+		return nil, service.ErrSortUnavailable
+	}
+	h := newHandlers(&config.Config{}, svc)
+
+	req := httptest.NewRequest("GET", "/v1/articles?sort=published", http.NoBody)
+	w := httptest.NewRecorder()
+
+	h.handleGetArticles(w, req)
+
+	if w.Code != http.StatusNotImplemented {
+		t.Errorf("expected status %d, got %d", http.StatusNotImplemented, w.Code)
+	}
+}
+
+func TestHandleGetArticlesFilter(t *testing.T) {
+	favorite, notFavorite := true, false
+
+	testCases := []struct {
+		name           string
+		query          string
+		expectedStatus int
+		expectedFilter model.ArticleFilter
+	}{
+		{
+			name: "attributes and sort",
+			query: "delivery_status=failed&source_domain=go.dev&language=en&content_type=article" +
+				"&tag=+Go+&collection=Reading+list&state=unread,read&favorite=true&sort=reading_time&order=asc",
+			expectedStatus: http.StatusOK,
+			expectedFilter: model.ArticleFilter{
+				DeliveryStatus: consts.StatusFailed,
+				SourceDomain:   "go.dev",
+				Language:       "en",
+				ContentType:    "article",
+				ReadStates:     []consts.ReadState{consts.ReadStateUnread, consts.ReadStateRead},
+				Favorite:       &favorite,
+				Tag:            "go",
+				Collection:     "Reading list",
+				Sort:           consts.SortReadingTime,
+				Order:          consts.SortOrderAsc,
+			},
+		},
+		{
+			name: "date and reading time ranges",
+			query: "created_from=2024-05-01&created_to=2024-05-31&published_from=2024-01-01T08:00:00Z" +
+				"&min_reading_time=5&max_reading_time=15",
+			expectedStatus: http.StatusOK,
+			expectedFilter: model.ArticleFilter{
+				CreatedFrom:    time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC),
+				CreatedTo:      time.Date(2024, 5, 31, 23, 59, 59, 999999999, time.UTC),
+				PublishedFrom:  time.Date(2024, 1, 1, 8, 0, 0, 0, time.UTC),
+				MinReadingTime: 5,
+				MaxReadingTime: 15,
+			},
+		},
+		{name: "not favorite", query: "favorite=false", expectedStatus: http.StatusOK,
+			expectedFilter: model.ArticleFilter{Favorite: &notFavorite}},
+		{name: "invalid delivery status", query: "delivery_status=lost", expectedStatus: http.StatusBadRequest},
+		{name: "invalid state", query: "state=unread,later", expectedStatus: http.StatusBadRequest},
+		{name: "invalid favorite", query: "favorite=maybe", expectedStatus: http.StatusBadRequest},
+		{name: "invalid sort", query: "sort=title", expectedStatus: http.StatusBadRequest},
+		{name: "invalid order", query: "order=up", expectedStatus: http.StatusBadRequest},
+		{name: "invalid date", query: "created_from=yesterday", expectedStatus: http.StatusBadRequest},
+		{name: "invalid reading time", query: "min_reading_time=0", expectedStatus: http.StatusBadRequest},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var filter *model.ArticleFilter
+			svc := newMockService(nil)
+			svc.getArticlesMetadata = func(
+				_ context.Context, _ string, f *model.ArticleFilter, page, pageSize int,
+			) (*service.GetArticlesResult, error) {
+				filter = f
+				return &service.GetArticlesResult{Articles: []*model.Article{}, Page: page, PageSize: pageSize}, nil
+			}
+			h := newHandlers(&config.Config{}, svc)
+
+			req := httptest.NewRequest("GET", "/v1/articles?"+tc.query, http.NoBody)
+			w := httptest.NewRecorder()
+
+			h.handleGetArticles(w, req)
+
+			if w.Code != tc.expectedStatus {
+				t.Fatalf("expected status %d, got %d", tc.expectedStatus, w.Code)
+			}
+
+			if tc.expectedStatus != http.StatusOK {
+				var resp model.ErrorResponse
+				if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+					t.Fatalf("failed to decode response: %v", err)
+				}
+				if resp.Error == "" {
+					t.Errorf("expected an error message")
+				}
+				if filter != nil {
+					t.Errorf("expected the service not to be called")
+				}
+				return
+			}
+
+			if filter == nil {
+				t.Fatalf("expected a filter")
+			}
+			if !reflect.DeepEqual(*filter, tc.expectedFilter) {
+				t.Errorf("expected filter %+v, got %+v", tc.expectedFilter, *filter)
+			}
+		})
+	}
+}
+
+func TestHandleSearchArticles(t *testing.T) {
+	tests := []struct {
+		name           string
+		query          string
+		searchErr      error
+		expectedStatus int
+		expectedOpts   service.SearchOptions
+	}{
+		{
+			name:           "query with filters",
+			query:          "?q=%22error+handling%22+go&domain=go.dev&language=en&page=2&page_size=5",
+			expectedStatus: http.StatusOK,
+			expectedOpts: service.SearchOptions{Query: `"error handling" go`, Domain: "go.dev", Language: "en",
+				Page: 2, PageSize: 5},
+		},
+		{name: "empty query", searchErr: search.ErrEmptyQuery, expectedStatus: http.StatusBadRequest,
+			expectedOpts: service.SearchOptions{Page: consts.DefaultPage, PageSize: consts.DefaultPageSize}},
+		{name: "query too long", query: "?q=go", searchErr: search.ErrQueryTooLong,
+			expectedStatus: http.StatusBadRequest,
+			expectedOpts:   service.SearchOptions{Query: "go", Page: consts.DefaultPage, PageSize: consts.DefaultPageSize}},
+		{name: "service error", query: "?q=go", searchErr: &serviceError{msg: testDatabaseError},
+			expectedStatus: http.StatusInternalServerError,
+			expectedOpts:   service.SearchOptions{Query: "go", Page: consts.DefaultPage, PageSize: consts.DefaultPageSize}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotOpts service.SearchOptions
+			svc := newMockService(nil)
+			svc.searchArticles = func(_ context.Context, _ string, opts service.SearchOptions) (
+				*service.GetArticlesResult, error,
+			) {
+				gotOpts = opts
+				if tt.searchErr != nil {
+					return nil, tt.searchErr
+				}
+				return &service.GetArticlesResult{
+					Articles: []*model.Article{{ID: "1", Title: "Error handling in Go"}},
+					Page:     opts.Page,
+					PageSize: opts.PageSize,
+					Total:    6,
+					HasMore:  true,
+				}, nil
+			}
+			h := newHandlers(&config.Config{}, svc)
+
+			req := httptest.NewRequest("GET", "/v1/articles/search"+tt.query, http.NoBody)
+			w := httptest.NewRecorder()
+
+			h.handleSearchArticles(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("expected status %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+			if gotOpts != tt.expectedOpts {
+				t.Errorf("expected options %+v, got %+v", tt.expectedOpts, gotOpts)
+			}
+			if w.Code != http.StatusOK {
+				return
+			}
+
+			var resp listArticlesResponse
+			if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if len(resp.Articles) != 1 || resp.Total != 6 || !resp.HasMore || resp.Page != 2 {
+				t.Errorf("unexpected response %+v", resp)
+			}
+		})
+	}
+}
+
 func TestHandleGetArticleSuccess(t *testing.T) {
 	cfg := &config.Config{}
 	svc := newMockService(nil)
@@ -755,7 +1264,9 @@ func TestHandleGetArticlesLogsDBError(t *testing.T) {
 	cfg := &config.Config{}
 	svc := newMockService(nil)
 	testDatabaseError := "database connection failed"
-	svc.getArticlesMetadata = func(_ context.Context, _ string, _ int, _ int) (*service.GetArticlesResult, error) {
+	svc.getArticlesMetadata = func(
+		_ context.Context, _ string, _ *model.ArticleFilter, _ int, _ int,
+	) (*service.GetArticlesResult, error) {
 		return nil, &serviceError{msg: testDatabaseError}
 	}
 	h := newHandlers(cfg, svc)
@@ -906,3 +1417,614 @@ func TestHandleDeleteAllArticlesLogsDBError(t *testing.T) {
 		t.Error("expected to find db_error attribute in log record")
 	}
 }
+
+func TestHandleGetSettings(t *testing.T) {
+	h := newHandlers(&config.Config{}, &MockService{})
+
+	req := httptest.NewRequest("GET", "/v1/settings", http.NoBody)
+	w := httptest.NewRecorder()
+
+	h.handleGetSettings(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("expected status %d, got %d", http.StatusOK, w.Code)
+	}
+
+	var resp model.Settings
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+
+	if resp.LinkMode != consts.LinkModeKeep {
+		t.Errorf("expected link mode %q, got %q", consts.LinkModeKeep, resp.LinkMode)
+	}
+}
+
+func TestHandleUpdateSettings(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           string
+		expectedStatus int
+		expectedMode   consts.LinkMode
+	}{
+		{
+			name:           "endnotes",
+			body:           `{"linkMode":"endnotes"}`,
+			expectedStatus: http.StatusOK,
+			expectedMode:   consts.LinkModeEndnotes,
+		},
+		{
+			name:           "empty defaults to keep",
+			body:           `{}`,
+			expectedStatus: http.StatusOK,
+			expectedMode:   consts.LinkModeKeep,
+		},
+		{
+			name:           "invalid mode",
+			body:           `{"linkMode":"footnotes"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "invalid json",
+			body:           `{`,
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stored *model.Settings
+			svc := &MockService{
+				updateSettings: func(_ context.Context, settings *model.Settings) (*model.Settings, error) {
+					stored = settings
+					return settings, nil
+				},
+			}
+			h := newHandlers(&config.Config{}, svc)
+
+			req := httptest.NewRequest("PUT", "/v1/settings", bytes.NewReader([]byte(tt.body)))
+			w := httptest.NewRecorder()
+
+			h.handleUpdateSettings(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+
+			if tt.expectedStatus != http.StatusOK {
+				if stored != nil {
+					t.Error("expected settings not to be stored")
+				}
+				return
+			}
+
+			if stored == nil || stored.LinkMode != tt.expectedMode {
+				t.Errorf("expected stored link mode %q, got %+v", tt.expectedMode, stored)
+			}
+		})
+	}
+}
+
+func TestHandleCreateFeed(t *testing.T) {
+	tests := []struct {
+		name             string
+		body             string
+		createErr        error
+		expectedStatus   int
+		expectedDelivery consts.FeedDelivery
+	}{
+		{
+			name:             "default delivery",
+			body:             `{"url":"https://example.com/feed.xml"}`,
+			expectedStatus:   http.StatusCreated,
+			expectedDelivery: consts.FeedDeliveryImmediate,
+		},
+		{
+			name:             "digest",
+			body:             `{"url":"https://example.com/feed.xml","delivery":"digest"}`,
+			expectedStatus:   http.StatusCreated,
+			expectedDelivery: consts.FeedDeliveryDigest,
+		},
+		{
+			name:           "invalid delivery",
+			body:           `{"url":"https://example.com/feed.xml","delivery":"weekly"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "missing url",
+			body:           `{}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "not a feed",
+			body:           `{"url":"https://example.com/"}`,
+			createErr:      fmt.Errorf("%w: unexpected EOF", feed.ErrInvalidFeed),
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "fetch error",
+			body:           `{"url":"https://example.com/feed.xml"}`,
+			createErr:      &serviceError{msg: "failed to fetch feed"},
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var delivery consts.FeedDelivery
+			svc := newMockService(nil)
+			svc.createFeed = func(_ context.Context, _, rawURL string, d consts.FeedDelivery) (*model.Feed, error) {
+				delivery = d
+				if tt.createErr != nil {
+					return nil, tt.createErr
+				}
+				return &model.Feed{ID: "feed-id", URL: rawURL, Delivery: d}, nil
+			}
+			h := newHandlers(&config.Config{}, svc)
+
+			req := httptest.NewRequest("POST", "/v1/feeds", strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+
+			h.handleCreateFeed(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+
+			if tt.expectedStatus != http.StatusCreated {
+				return
+			}
+
+			var resp model.Feed
+			if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if delivery != tt.expectedDelivery || resp.Delivery != tt.expectedDelivery {
+				t.Errorf("expected delivery %q, got %q", tt.expectedDelivery, delivery)
+			}
+		})
+	}
+}
+
+func TestHandleGetFeeds(t *testing.T) {
+	svc := newMockService(nil)
+	svc.getFeeds = func(_ context.Context, accountID string) ([]*model.Feed, error) {
+		return []*model.Feed{{Account: accountID, ID: "feed-id", URL: "https://example.com/feed.xml"}}, nil
+	}
+	h := newHandlers(&config.Config{}, svc)
+
+	req := httptest.NewRequest("GET", "/v1/feeds", http.NoBody)
+	w := httptest.NewRecorder()
+
+	h.handleGetFeeds(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("expected status %d, got %d", http.StatusOK, w.Code)
+	}
+
+	var resp listFeedsResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(resp.Feeds) != 1 || resp.Feeds[0].ID != "feed-id" {
+		t.Errorf("expected the account feed, got %+v", resp.Feeds)
+	}
+}
+
+func TestHandleDeleteFeed(t *testing.T) {
+	tests := []struct {
+		name           string
+		deleteErr      error
+		expectedStatus int
+	}{
+		{name: "success", expectedStatus: http.StatusNoContent},
+		{name: "not found", deleteErr: service.ErrFeedNotFound, expectedStatus: http.StatusNotFound},
+		{name: "service error", deleteErr: &serviceError{msg: testDatabaseError},
+			expectedStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := newMockService(nil)
+			svc.deleteFeed = func(_ context.Context, _, _ string) error {
+				return tt.deleteErr
+			}
+			h := newHandlers(&config.Config{}, svc)
+
+			req := httptest.NewRequest("DELETE", "/v1/feeds/feed-id", http.NoBody)
+			w := httptest.NewRecorder()
+
+			h.handleDeleteFeed(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+		})
+	}
+}
+
+func TestHandlePollFeeds(t *testing.T) {
+	svc := newMockService(nil)
+	svc.pollFeeds = func(_ context.Context, _ string) (*service.PollFeedsResult, error) {
+		return &service.PollFeedsResult{Feeds: 2, Articles: 3}, nil
+	}
+	h := newHandlers(&config.Config{}, svc)
+
+	req := httptest.NewRequest("POST", "/v1/feeds/poll", http.NoBody)
+	w := httptest.NewRecorder()
+
+	h.handlePollFeeds(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("expected status %d, got %d", http.StatusOK, w.Code)
+	}
+
+	var resp service.PollFeedsResult
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if resp.Feeds != 2 || resp.Articles != 3 {
+		t.Errorf("expected 2 feeds and 3 articles, got %+v", resp)
+	}
+}
+
+func TestHandleImport(t *testing.T) {
+	tests := []struct {
+		name           string
+		query          string
+		body           string
+		importErr      error
+		expectedStatus int
+		expectedFormat consts.ImportFormat
+	}{
+		{name: "detected format", body: "url,time_added\n", expectedStatus: http.StatusAccepted},
+		{name: "explicit format", query: "?format=Instapaper-CSV", body: "URL,Timestamp\n",
+			expectedStatus: http.StatusAccepted, expectedFormat: consts.ImportFormatInstapaperCSV},
+		{name: "unknown format parameter", query: "?format=evernote", expectedStatus: http.StatusBadRequest},
+		{name: "invalid file", body: "not an export", importErr: importer.ErrUnknownFormat,
+			expectedStatus: http.StatusBadRequest},
+		{name: "too many items", importErr: service.ErrTooManyItems, expectedStatus: http.StatusRequestEntityTooLarge},
+		{name: "service error", importErr: &serviceError{msg: testDatabaseError},
+			expectedStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotData []byte
+			var gotOpts service.ImportOptions
+			svc := newMockService(nil)
+			svc.importFunc = func(_ context.Context, accountID string, data []byte, opts service.ImportOptions) (
+				*model.ImportJob, error,
+			) {
+				gotData, gotOpts = data, opts
+				if tt.importErr != nil {
+					return nil, tt.importErr
+				}
+				return &model.ImportJob{Account: accountID, ID: "job-1", Status: consts.ImportStatusRunning}, nil
+			}
+			h := newHandlers(&config.Config{}, svc)
+
+			req := httptest.NewRequest("POST", "/v1/import"+tt.query, strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+
+			h.handleImport(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("expected status %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+			if w.Code != http.StatusAccepted {
+				return
+			}
+
+			if string(gotData) != tt.body {
+				t.Errorf("expected body %q to be imported, got %q", tt.body, gotData)
+			}
+			if gotOpts.Format != tt.expectedFormat || gotOpts.Wait {
+				t.Errorf("expected background import in format %q, got %+v", tt.expectedFormat, gotOpts)
+			}
+
+			var job model.ImportJob
+			if err := json.NewDecoder(w.Body).Decode(&job); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if job.ID != "job-1" {
+				t.Errorf("expected job job-1, got %q", job.ID)
+			}
+		})
+	}
+}
+
+func TestHandleGetImportJob(t *testing.T) {
+	svc := newMockService(nil)
+	svc.getImportJob = func(_ context.Context, accountID, jobID string) (*model.ImportJob, error) {
+		if jobID != "job-1" {
+			return nil, service.ErrImportNotFound
+		}
+		return &model.ImportJob{Account: accountID, ID: jobID, Status: consts.ImportStatusCompleted, Total: 2}, nil
+	}
+
+	router := chi.NewRouter()
+	router.Get("/v1/import/{id}", newHandlers(&config.Config{}, svc).handleGetImportJob)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/v1/import/job-1", http.NoBody))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, w.Code)
+	}
+
+	var job model.ImportJob
+	if err := json.NewDecoder(w.Body).Decode(&job); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if job.Status != consts.ImportStatusCompleted || job.Total != 2 {
+		t.Errorf("expected completed job with 2 items, got %+v", job)
+	}
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/v1/import/missing", http.NoBody))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected status %d, got %d", http.StatusNotFound, w.Code)
+	}
+}
+
+func TestHandleExport(t *testing.T) {
+	tests := []struct {
+		name             string
+		query            string
+		writes           bool
+		exportErr        error
+		expectedStatus   int
+		expectedOpts     service.ExportOptions
+		expectedZipBytes string
+	}{
+		{name: "articles only", writes: true, expectedStatus: http.StatusOK, expectedZipBytes: "PK"},
+		{name: "with files", query: "?epub=true&html=1", writes: true, expectedStatus: http.StatusOK,
+			expectedOpts: service.ExportOptions{EPUB: true, HTML: true}, expectedZipBytes: "PK"},
+		{name: "error before writing", exportErr: &serviceError{msg: testDatabaseError},
+			expectedStatus: http.StatusInternalServerError},
+		{name: "error while writing", writes: true, exportErr: &serviceError{msg: testDatabaseError},
+			expectedStatus: http.StatusOK, expectedZipBytes: "PK"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotOpts service.ExportOptions
+			svc := newMockService(nil)
+			svc.export = func(_ context.Context, _ string, w io.Writer, opts service.ExportOptions) (int, error) {
+				gotOpts = opts
+				if tt.writes {
+					if _, err := w.Write([]byte("PK")); err != nil {
+						return 0, err
+					}
+				}
+				return 1, tt.exportErr
+			}
+			h := newHandlers(&config.Config{}, svc)
+
+			req := httptest.NewRequest("GET", "/v1/export"+tt.query, http.NoBody)
+			w := httptest.NewRecorder()
+
+			h.handleExport(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("expected status %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+			if gotOpts != tt.expectedOpts {
+				t.Errorf("expected options %+v, got %+v", tt.expectedOpts, gotOpts)
+			}
+			if w.Code != http.StatusOK {
+				var errResp model.ErrorResponse
+				if err := json.NewDecoder(w.Body).Decode(&errResp); err != nil {
+					t.Fatalf("failed to decode response: %v", err)
+				}
+				if errResp.Error == "" {
+					t.Error("expected error message in response")
+				}
+				return
+			}
+
+			if ct := w.Header().Get("Content-Type"); ct != "application/zip" {
+				t.Errorf("expected Content-Type application/zip, got %q", ct)
+			}
+			if cd := w.Header().Get("Content-Disposition"); !strings.HasPrefix(cd, `attachment; filename="savetoink-export-`) {
+				t.Errorf("expected attachment Content-Disposition, got %q", cd)
+			}
+			if w.Body.String() != tt.expectedZipBytes {
+				t.Errorf("expected body %q, got %q", tt.expectedZipBytes, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestHandleExportLambda(t *testing.T) {
+	tests := []struct {
+		name           string
+		size           int
+		exportErr      error
+		expectedStatus int
+	}{
+		{name: "small archive", size: 2, expectedStatus: http.StatusOK},
+		{name: "archive too large", size: consts.LambdaMaxExportSize + 1, expectedStatus: http.StatusRequestEntityTooLarge},
+		{name: "error while writing", size: 2, exportErr: &serviceError{msg: testDatabaseError},
+			expectedStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := newMockService(nil)
+			svc.export = func(_ context.Context, _ string, w io.Writer, _ service.ExportOptions) (int, error) {
+				if _, err := w.Write(make([]byte, tt.size)); err != nil {
+					return 0, err
+				}
+				return 1, tt.exportErr
+			}
+			h := newHandlers(&config.Config{Lambda: true}, svc)
+
+			req := httptest.NewRequest("GET", "/v1/export", http.NoBody)
+			w := httptest.NewRecorder()
+
+			h.handleExport(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+			if w.Code == http.StatusOK && w.Body.Len() != tt.size {
+				t.Errorf("expected a %d bytes archive, got %d bytes", tt.size, w.Body.Len())
+			}
+			if w.Code != http.StatusOK && w.Header().Get("Content-Type") == "application/zip" {
+				t.Error("expected a JSON error, got an archive")
+			}
+		})
+	}
+}
+func TestHandleSetTags(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           string
+		setErr         error
+		expectedStatus int
+	}{
+		{name: "success", body: `{"tags":["go","errors"],"collection":"Reading list"}`,
+			expectedStatus: http.StatusOK},
+		{name: "invalid body", body: `{"tags":"go"}`, expectedStatus: http.StatusBadRequest},
+		{name: "invalid tags", body: `{"tags":["go"]}`, setErr: service.ErrInvalidTags,
+			expectedStatus: http.StatusBadRequest},
+		{name: "not found", body: `{"tags":["go"]}`, setErr: service.ErrArticleNotFound,
+			expectedStatus: http.StatusNotFound},
+		{name: "service error", body: `{"tags":["go"]}`, setErr: &serviceError{msg: testDatabaseError},
+			expectedStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var tags []string
+			var collection string
+			svc := newMockService(nil)
+			svc.setTags = func(_ context.Context, _, id string, t []string, c string) (*model.Article, error) {
+				tags, collection = t, c
+				if tt.setErr != nil {
+					return nil, tt.setErr
+				}
+				return &model.Article{ID: id, Tags: t, Collection: c}, nil
+			}
+			h := newHandlers(&config.Config{}, svc)
+
+			req := httptest.NewRequest("PUT", "/v1/articles/article-id/tags", strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+
+			h.handleSetTags(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+
+			if strings.Join(tags, ",") != "go,errors" || collection != "Reading list" {
+				t.Errorf("expected tags [go errors] in Reading list, got %v in %q", tags, collection)
+			}
+
+			var article model.Article
+			if err := json.NewDecoder(w.Body).Decode(&article); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if len(article.Tags) != 2 {
+				t.Errorf("expected 2 tags, got %v", article.Tags)
+			}
+		})
+	}
+}
+
+func TestHandleGetTags(t *testing.T) {
+	svc := newMockService(nil)
+	svc.getTags = func(_ context.Context, _ string) (*model.Tags, error) {
+		return &model.Tags{
+			Tags:        []*model.TagCount{{Name: "go", Count: 3}, {Name: "errors", Count: 1}},
+			Collections: []*model.TagCount{{Name: "Reading list", Count: 2}},
+		}, nil
+	}
+	h := newHandlers(&config.Config{}, svc)
+
+	req := httptest.NewRequest("GET", "/v1/tags", http.NoBody)
+	w := httptest.NewRecorder()
+
+	h.handleGetTags(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, w.Code)
+	}
+
+	var resp model.Tags
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(resp.Tags) != 2 || resp.Tags[0].Name != "go" || resp.Tags[0].Count != 3 {
+		t.Errorf("expected tags go (3) and errors (1), got %+v", resp.Tags)
+	}
+	if len(resp.Collections) != 1 || resp.Collections[0].Count != 2 {
+		t.Errorf("expected the Reading list collection, got %+v", resp.Collections)
+	}
+}
+
+func TestHandleUpdateArticle(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           string
+		updateErr      error
+		expectedStatus int
+	}{
+		{name: "read state and favorite", body: `{"readState":"archived","favorite":true}`,
+			expectedStatus: http.StatusOK},
+		{name: "invalid body", body: `{"favorite":"yes"}`, expectedStatus: http.StatusBadRequest},
+		{name: "nothing to update", body: `{}`, expectedStatus: http.StatusBadRequest},
+		{name: "invalid state", body: `{"readState":"later"}`, updateErr: service.ErrInvalidReadState,
+			expectedStatus: http.StatusBadRequest},
+		{name: "not found", body: `{"favorite":true}`, updateErr: service.ErrArticleNotFound,
+			expectedStatus: http.StatusNotFound},
+		{name: "service error", body: `{"favorite":true}`, updateErr: &serviceError{msg: testDatabaseError},
+			expectedStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var articleID string
+			var update service.ArticleUpdate
+			svc := newMockService(nil)
+			svc.updateArticle = func(_ context.Context, _, id string, u service.ArticleUpdate) (*model.Article, error) {
+				articleID, update = id, u
+				if tt.updateErr != nil {
+					return nil, tt.updateErr
+				}
+				return &model.Article{ID: id, ReadState: *u.ReadState, Favorite: *u.Favorite}, nil
+			}
+
+			router := chi.NewRouter()
+			router.Patch("/v1/articles/{id}", newHandlers(&config.Config{}, svc).handleUpdateArticle)
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest("PATCH", "/v1/articles/article-id", strings.NewReader(tt.body)))
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+
+			if articleID != "article-id" || *update.ReadState != consts.ReadStateArchived || !*update.Favorite {
+				t.Errorf("expected article-id archived and favorite, got %s %+v", articleID, update)
+			}
+
+			var article model.Article
+			if err := json.NewDecoder(w.Body).Decode(&article); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if article.ReadState != consts.ReadStateArchived || !article.Favorite {
+				t.Errorf("expected archived favorite article, got %+v", article)
+			}
+		})
+	}
+}
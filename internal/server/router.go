@@ -58,8 +58,41 @@ func NewRouter(cfg *config.Config) *chi.Mux {
 			r.Post("/", handlers.handleCreateArticle)
 			r.Get("/", handlers.handleGetArticles)
 			r.Delete("/", handlers.handleDeleteAllArticles)
+			r.Get("/search", handlers.handleSearchArticles)
 			r.Get("/{id}", handlers.handleGetArticle)
+			r.Patch("/{id}", handlers.handleUpdateArticle)
 			r.Delete("/{id}", handlers.handleDeleteArticle)
+			r.Put("/{id}/tags", handlers.handleSetTags)
+		})
+
+		r.Route("/tags", func(r chi.Router) {
+			r.Use(auth.EnsureAutheticatedMiddleware)
+			r.Get("/", handlers.handleGetTags)
+		})
+
+		r.Route("/feeds", func(r chi.Router) {
+			r.Use(auth.EnsureAutheticatedMiddleware)
+			r.Post("/", handlers.handleCreateFeed)
+			r.Get("/", handlers.handleGetFeeds)
+			r.Post("/poll", handlers.handlePollFeeds)
+			r.Delete("/{id}", handlers.handleDeleteFeed)
+		})
+
+		r.Route("/import", func(r chi.Router) {
+			r.Use(auth.EnsureAutheticatedMiddleware)
+			r.Post("/", handlers.handleImport)
+			r.Get("/{id}", handlers.handleGetImportJob)
+		})
+
+		r.Route("/export", func(r chi.Router) {
+			r.Use(auth.EnsureAutheticatedMiddleware)
+			r.Get("/", handlers.handleExport)
+		})
+
+		r.Route("/settings", func(r chi.Router) {
+			r.Use(auth.EnsureAutheticatedMiddleware)
+			r.Get("/", handlers.handleGetSettings)
+			r.Put("/", handlers.handleUpdateSettings)
 		})
 	})
 
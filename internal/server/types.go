@@ -9,7 +9,34 @@ import (
 )
 
 type articleRequest struct {
-	URL string `json:"url"`
+	URL             string `json:"url"`
+	HTML            string `json:"html,omitempty"`
+	Direction       string `json:"direction,omitempty"`
+	VerticalWriting bool   `json:"verticalWriting,omitempty"`
+	NoCache         bool   `json:"noCache,omitempty"`
+}
+
+type feedRequest struct {
+	URL      string `json:"url"`
+	Delivery string `json:"delivery,omitempty"`
+}
+
+type listFeedsResponse struct {
+	Feeds []*model.Feed `json:"feeds"`
+}
+
+type articleUpdateRequest struct {
+	ReadState *string `json:"readState,omitempty"`
+	Favorite  *bool   `json:"favorite,omitempty"`
+}
+
+type tagsRequest struct {
+	Tags       []string `json:"tags"`
+	Collection string   `json:"collection,omitempty"`
+}
+
+type settingsRequest struct {
+	LinkMode string `json:"linkMode"`
 }
 
 type articleResponse struct {
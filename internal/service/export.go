@@ -0,0 +1,117 @@
+package service
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+
+	"github.com/shaftoe/savetoink/internal/consts"
+	"github.com/shaftoe/savetoink/internal/exporter"
+	"github.com/shaftoe/savetoink/internal/model"
+)
+
+// ExportOptions holds the optional files of an export.
+type ExportOptions struct {
+	// EPUB adds the EPUB of each article with content.
+	EPUB bool
+	// HTML adds a standalone HTML document of each article with content.
+	HTML bool
+}
+
+// Export writes the library of an account to w as a zip archive, streaming it while articles are read from the
+// repository: consts.ExportArticlesFile lists every article with its content as JSON Lines, followed by
+// consts.ExportBookmarksFile, a Netscape bookmark file, and the EPUB and HTML files of the articles requested by
+// opts in consts.ExportArticlesDir. Only the bookmarks are held in memory, the files of the articles are written
+// reading the library again. Returns the number of articles exported.
+func (s *Service) Export(ctx context.Context, accountID string, w io.Writer, opts ExportOptions) (int, error) {
+	if s.repo == nil {
+		return 0, errors.New("repository not configured")
+	}
+
+	archive := zip.NewWriter(w)
+
+	articlesFile, err := archive.Create(consts.ExportArticlesFile)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create %s: %w", consts.ExportArticlesFile, err)
+	}
+
+	encoder := json.NewEncoder(articlesFile)
+	var bookmarks []exporter.Bookmark
+	err = s.repo.EachByAccount(ctx, accountID, func(article *model.Article) error {
+		bookmarks = append(bookmarks, exporter.BookmarkOf(article))
+		return encoder.Encode(article)
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to export articles: %w", err)
+	}
+
+	bookmarksFile, err := archive.Create(consts.ExportBookmarksFile)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create %s: %w", consts.ExportBookmarksFile, err)
+	}
+	if err = exporter.WriteBookmarks(bookmarksFile, bookmarks); err != nil {
+		return 0, fmt.Errorf("failed to export bookmarks: %w", err)
+	}
+
+	if opts.EPUB || opts.HTML {
+		err = s.repo.EachByAccount(ctx, accountID, func(article *model.Article) error {
+			return s.exportArticleFiles(ctx, archive, article, opts)
+		})
+		if err != nil {
+			return 0, fmt.Errorf("failed to export article files: %w", err)
+		}
+	}
+
+	if err = archive.Close(); err != nil {
+		return 0, fmt.Errorf("failed to write export archive: %w", err)
+	}
+
+	return len(bookmarks), nil
+}
+
+// exportArticleFiles writes the EPUB and HTML files of an article requested by opts, articles without content are
+// skipped. An article failing to convert to EPUB is logged and doesn't stop the export.
+func (s *Service) exportArticleFiles(
+	ctx context.Context, archive *zip.Writer, article *model.Article, opts ExportOptions,
+) error {
+	if article.Content == "" {
+		return nil
+	}
+
+	if opts.HTML {
+		file, err := archive.Create(consts.ExportArticlesDir + exporter.Filename(article, "html"))
+		if err != nil {
+			return err
+		}
+		if _, err = io.WriteString(file, exporter.HTMLDocument(article)); err != nil {
+			return err
+		}
+	}
+
+	if opts.EPUB {
+		epubData, err := s.generator.Generate(ctx, article)
+		if err != nil {
+			slog.Warn("failed to export article as EPUB", "article_id", article.ID, "error", err)
+			return nil
+		}
+
+		// EPUB files are already compressed
+		file, err := archive.CreateHeader(&zip.FileHeader{
+			Name:     consts.ExportArticlesDir + exporter.Filename(article, "epub"),
+			Method:   zip.Store,
+			Modified: article.CreatedAt,
+		})
+		if err != nil {
+			return err
+		}
+		if _, err = file.Write(epubData); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,128 @@
+package service
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/shaftoe/savetoink/internal/consts"
+	"github.com/shaftoe/savetoink/internal/importer"
+	"github.com/shaftoe/savetoink/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func readZipFile(t *testing.T, file *zip.File) []byte {
+	t.Helper()
+
+	rc, err := file.Open()
+	require.NoError(t, err)
+	defer func() { _ = rc.Close() }()
+
+	data, err := io.ReadAll(rc)
+	require.NoError(t, err)
+	return data
+}
+
+func TestExport(t *testing.T) {
+	mockRepo := &MockRepository{}
+	svc := newFeedService(mockRepo, nil)
+	ctx := context.Background()
+
+	savedAt := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	articles := []*model.Article{
+		{Account: "user1", ID: "3f2a1b7c9d", URL: "https://example.com/epub", Title: "Writing an EPUB generator",
+			Content: "<p>EPUB content</p>", CreatedAt: savedAt, Tags: []string{"go", "epub"}},
+		{Account: "user1", ID: "8e7d6c5b4a", URL: "https://example.com/failed", CreatedAt: savedAt,
+			Error: "failed to fetch"},
+		{Account: "user2", ID: "0a1b2c3d4e", URL: "https://example.com/other", Title: "Other account",
+			Content: "<p>Other</p>", CreatedAt: savedAt},
+	}
+	for _, article := range articles {
+		require.NoError(t, mockRepo.Store(ctx, article))
+	}
+
+	tests := []struct {
+		name          string
+		opts          ExportOptions
+		expectedFiles []string
+	}{
+		{
+			name:          "articles and bookmarks",
+			expectedFiles: []string{consts.ExportArticlesFile, consts.ExportBookmarksFile},
+		},
+		{
+			name: "with EPUB and HTML files",
+			opts: ExportOptions{EPUB: true, HTML: true},
+			expectedFiles: []string{
+				consts.ExportArticlesFile,
+				consts.ExportBookmarksFile,
+				"articles/2024-05-01-writing-an-epub-generator-3f2a1b7c.html",
+				"articles/2024-05-01-writing-an-epub-generator-3f2a1b7c.epub",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			count, err := svc.Export(ctx, "user1", &buf, tt.opts)
+			require.NoError(t, err)
+			assert.Equal(t, 2, count)
+
+			archive, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+			require.NoError(t, err)
+
+			files := make(map[string]*zip.File, len(archive.File))
+			names := make([]string, 0, len(archive.File))
+			for _, file := range archive.File {
+				files[file.Name] = file
+				names = append(names, file.Name)
+			}
+			assert.Equal(t, tt.expectedFiles, names)
+
+			var exported []model.Article
+			scanner := bufio.NewScanner(bytes.NewReader(readZipFile(t, files[consts.ExportArticlesFile])))
+			for scanner.Scan() {
+				var article model.Article
+				require.NoError(t, json.Unmarshal(scanner.Bytes(), &article))
+				exported = append(exported, article)
+			}
+			require.Len(t, exported, 2)
+			assert.Equal(t, "<p>EPUB content</p>", exported[0].Content, "the content is exported")
+			assert.Equal(t, []string{"go", "epub"}, exported[0].Tags)
+			assert.Equal(t, "failed to fetch", exported[1].Error)
+
+			items, format, err := importer.Parse(readZipFile(t, files[consts.ExportBookmarksFile]), "")
+			require.NoError(t, err)
+			assert.Equal(t, consts.ImportFormatBookmarks, format, "the bookmarks can be imported back")
+			require.Len(t, items, 2)
+			assert.Equal(t, "https://example.com/epub", items[0].URL)
+			assert.Equal(t, savedAt, items[0].SavedAt)
+			assert.Equal(t, []string{"go", "epub"}, items[0].Tags)
+
+			if file, ok := files["articles/2024-05-01-writing-an-epub-generator-3f2a1b7c.html"]; ok {
+				assert.Contains(t, string(readZipFile(t, file)), "<p>EPUB content</p>")
+			}
+			if file, ok := files["articles/2024-05-01-writing-an-epub-generator-3f2a1b7c.epub"]; ok {
+				assert.Equal(t, zip.Store, file.Method, "EPUB files are stored uncompressed")
+				assert.True(t, bytes.HasPrefix(readZipFile(t, file), []byte("PK")), "EPUB files are zip archives")
+			}
+		})
+	}
+}
+
+func TestExportNoRepository(t *testing.T) {
+	svc := newFeedService(nil, nil)
+	svc.repo = nil
+
+	var buf bytes.Buffer
+	_, err := svc.Export(context.Background(), "user1", &buf, ExportOptions{})
+	require.Error(t, err)
+	assert.Zero(t, buf.Len(), "nothing is written without a repository")
+}
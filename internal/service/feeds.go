@@ -0,0 +1,439 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/shaftoe/savetoink/internal/consts"
+	"github.com/shaftoe/savetoink/internal/content"
+	"github.com/shaftoe/savetoink/internal/epub"
+	"github.com/shaftoe/savetoink/internal/feed"
+	"github.com/shaftoe/savetoink/internal/model"
+	"github.com/shaftoe/savetoink/internal/repository"
+)
+
+var (
+	// ErrFeedNotFound is returned when an account is not subscribed to a feed.
+	ErrFeedNotFound = errors.New("feed not found")
+	// ErrInvalidDelivery is returned for an unknown feed delivery mode.
+	ErrInvalidDelivery = errors.New("invalid feed delivery")
+)
+
+// PollFeedsResult holds the result of polling feeds.
+type PollFeedsResult struct {
+	// Feeds is the number of feeds polled.
+	Feeds int `json:"feeds"`
+	// Articles is the number of articles created from new entries.
+	Articles int `json:"articles"`
+	// Digests is the number of digests sent.
+	Digests int `json:"digests"`
+}
+
+// ParseFeedDelivery parses a feed delivery mode, defaulting to consts.FeedDeliveryImmediate when empty.
+func ParseFeedDelivery(value string) (consts.FeedDelivery, error) {
+	switch delivery := consts.FeedDelivery(strings.ToLower(strings.TrimSpace(value))); delivery {
+	case "":
+		return consts.FeedDeliveryImmediate, nil
+	case consts.FeedDeliveryImmediate, consts.FeedDeliveryDigest, consts.FeedDeliveryNone:
+		return delivery, nil
+	default:
+		return "", fmt.Errorf("%w: %q, must be one of %s, %s or %s", ErrInvalidDelivery, value,
+			consts.FeedDeliveryImmediate, consts.FeedDeliveryDigest, consts.FeedDeliveryNone)
+	}
+}
+
+// CreateFeed subscribes the account to the feed at rawURL, or updates the delivery of an existing subscription.
+// The feed is fetched and parsed to validate it, only entries published after subscribing are saved.
+func (s *Service) CreateFeed(
+	ctx context.Context,
+	accountID, rawURL string,
+	delivery consts.FeedDelivery,
+) (*model.Feed, error) {
+	if s.repo == nil {
+		return nil, errors.New("repository not configured")
+	}
+
+	delivery, err := ParseFeedDelivery(string(delivery))
+	if err != nil {
+		return nil, err
+	}
+
+	feedURL, err := content.CleanURL(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to clean url: %w", err)
+	}
+
+	feedID, err := content.ArticleIDFromURL(feedURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate feed id: %w", err)
+	}
+
+	existing, err := s.repo.GetFeed(ctx, accountID, feedID)
+	switch {
+	case err == nil:
+		existing.Delivery = delivery
+		if err = s.repo.StoreFeed(ctx, existing); err != nil {
+			return nil, fmt.Errorf("failed to store feed: %w", err)
+		}
+		return existing, nil
+	case !errors.Is(err, repository.ErrNotFound):
+		return nil, fmt.Errorf("failed to get feed: %w", err)
+	}
+
+	resp, err := s.extractor.FetchFeed(ctx, feedURL, "", "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch feed: %w", err)
+	}
+
+	parsed, err := feed.Parse(resp.Body, resp.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	subscription := &model.Feed{
+		Account:      accountID,
+		ID:           feedID,
+		URL:          feedURL,
+		Title:        parsed.Title,
+		Delivery:     delivery,
+		SubscribedAt: time.Now().UTC(),
+		ETag:         resp.ETag,
+		LastModified: resp.LastModified,
+	}
+	subscription.LastPolledAt = &subscription.SubscribedAt
+	for _, entry := range parsed.Entries {
+		seeEntry(subscription, entry.ID)
+	}
+	if newest, ok := parsed.Newest(); ok {
+		subscription.LastItemID = newest.ID
+		if !newest.Published.IsZero() {
+			subscription.LastItemAt = &newest.Published
+		}
+	}
+
+	if err = s.repo.StoreFeed(ctx, subscription); err != nil {
+		return nil, fmt.Errorf("failed to store feed: %w", err)
+	}
+
+	return subscription, nil
+}
+
+// GetFeeds returns the feeds the account is subscribed to.
+func (s *Service) GetFeeds(ctx context.Context, accountID string) ([]*model.Feed, error) {
+	if s.repo == nil {
+		return []*model.Feed{}, nil
+	}
+
+	feeds, err := s.repo.GetFeedsByAccount(ctx, accountID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get feeds: %w", err)
+	}
+
+	return feeds, nil
+}
+
+// DeleteFeed unsubscribes the account from a feed, articles already saved from it are kept.
+func (s *Service) DeleteFeed(ctx context.Context, accountID, feedID string) error {
+	if s.repo == nil {
+		return errors.New("repository not configured")
+	}
+
+	if _, err := s.repo.GetFeed(ctx, accountID, feedID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrFeedNotFound
+		}
+		return fmt.Errorf("failed to get feed: %w", err)
+	}
+
+	if err := s.repo.DeleteFeed(ctx, accountID, feedID); err != nil {
+		return fmt.Errorf("failed to delete feed: %w", err)
+	}
+
+	return nil
+}
+
+// PollFeeds polls the feeds of an account, or of all accounts when accountID is empty, creating articles for new
+// entries and sending the digests that are due. Feeds failing to poll record the error and don't stop the others.
+func (s *Service) PollFeeds(ctx context.Context, accountID string) (*PollFeedsResult, error) {
+	result := &PollFeedsResult{}
+	if s.repo == nil {
+		return result, nil
+	}
+
+	var feeds []*model.Feed
+	var err error
+	if accountID == "" {
+		feeds, err = s.repo.GetAllFeeds(ctx)
+	} else {
+		feeds, err = s.repo.GetFeedsByAccount(ctx, accountID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get feeds: %w", err)
+	}
+
+	var errs error
+	for _, subscription := range feeds {
+		if ctx.Err() != nil {
+			break
+		}
+
+		result.Feeds++
+		result.Articles += s.pollFeed(ctx, subscription)
+
+		sent, digestErr := s.sendDigest(ctx, subscription)
+		if digestErr != nil {
+			subscription.Error = digestErr.Error()
+		}
+		if sent {
+			result.Digests++
+		}
+
+		if storeErr := s.repo.StoreFeed(ctx, subscription); storeErr != nil {
+			errs = errors.Join(errs, fmt.Errorf("failed to store feed: %w", storeErr))
+		}
+	}
+
+	return result, errs
+}
+
+// RunFeedPoller polls the feeds of all accounts every interval until ctx is done.
+func (s *Service) RunFeedPoller(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			result, err := s.PollFeeds(ctx, "")
+			if err != nil {
+				slog.Error("failed to poll feeds", "error", err)
+				continue
+			}
+			slog.Info("polled feeds", "feeds", result.Feeds, "articles", result.Articles, "digests", result.Digests)
+		}
+	}
+}
+
+// pollFeed fetches a feed with a conditional request and creates the articles of its new entries, oldest first,
+// advancing the feed state past each one and remembering it as seen, like the entries that are not new. Entries
+// already saved by the account are skipped, unless they failed.
+// Polling stops early when the site defers fetching, the remaining entries are retried on the next poll.
+// Returns the number of articles created.
+func (s *Service) pollFeed(ctx context.Context, subscription *model.Feed) int {
+	now := time.Now().UTC()
+	subscription.LastPolledAt = &now
+	subscription.Error = ""
+
+	resp, err := s.extractor.FetchFeed(ctx, subscription.URL, subscription.ETag, subscription.LastModified)
+	if err != nil {
+		subscription.Error = err.Error()
+		return 0
+	}
+	if resp.NotModified {
+		return 0
+	}
+
+	parsed, err := feed.Parse(resp.Body, resp.URL)
+	if err != nil {
+		subscription.Error = err.Error()
+		return 0
+	}
+	if parsed.Title != "" {
+		subscription.Title = parsed.Title
+	}
+	subscription.ETag = resp.ETag
+	subscription.LastModified = resp.LastModified
+
+	entries := parsed.NewEntries(subscription.LastItemID, subscription.LastItemAt, subscription.SeenItemIDs,
+		consts.FeedMaxNewEntries)
+	for _, entry := range parsed.Entries {
+		if !slices.ContainsFunc(entries, func(newEntry feed.Entry) bool { return newEntry.ID == entry.ID }) {
+			seeEntry(subscription, entry.ID)
+		}
+	}
+
+	opts := ProcessOptions{NoSend: subscription.Delivery != consts.FeedDeliveryImmediate}
+	created := 0
+	for _, entry := range entries {
+		if !s.articleSaved(ctx, subscription.Account, entry.URL) {
+			result, createErr := s.CreateArticle(ctx, entry.URL, subscription.Account, opts)
+			if errors.Is(createErr, content.ErrDeferred) {
+				subscription.Error = createErr.Error()
+				break
+			}
+			if createErr == nil {
+				created++
+				if subscription.Delivery == consts.FeedDeliveryDigest && s.cfg.SendEnabled {
+					subscription.DigestArticleIDs = append(subscription.DigestArticleIDs, result.Article.ID)
+				}
+			}
+		}
+
+		subscription.LastItemID = entry.ID
+		if !entry.Published.IsZero() {
+			subscription.LastItemAt = &entry.Published
+		}
+		seeEntry(subscription, entry.ID)
+	}
+
+	if extra := len(subscription.DigestArticleIDs) - consts.FeedDigestMaxArticles; extra > 0 {
+		subscription.DigestArticleIDs = subscription.DigestArticleIDs[extra:]
+	}
+
+	return created
+}
+
+// articleSaved reports whether the article at rawURL is already saved by the account without errors, see
+// Service.savedArticle.
+func (s *Service) articleSaved(ctx context.Context, accountID, rawURL string) bool {
+	cleanURL, err := content.CleanURL(rawURL)
+	if err != nil {
+		return false
+	}
+
+	articleID, err := content.ArticleIDFromURL(cleanURL)
+	if err != nil {
+		return false
+	}
+
+	article, err := s.savedArticle(ctx, accountID, articleID)
+	return err == nil && article != nil && article.Error == ""
+}
+
+// savedArticle returns the article of the URL with ID articleID saved by the account, stored with this ID or,
+// after a redirect or with a canonical URL, with the ID aliased to it. Returns nil when it is not saved.
+func (s *Service) savedArticle(ctx context.Context, accountID, articleID string) (*model.Article, error) {
+	if s.repo == nil {
+		return nil, nil
+	}
+
+	article, err := s.repo.GetByAccountAndID(ctx, accountID, articleID)
+	if errors.Is(err, repository.ErrNotFound) {
+		storedID, aliasErr := s.repo.GetAlias(ctx, accountID, articleID)
+		if errors.Is(aliasErr, repository.ErrNotFound) {
+			return nil, nil
+		}
+		if aliasErr != nil {
+			return nil, fmt.Errorf("failed to get article alias: %w", aliasErr)
+		}
+		article, err = s.repo.GetByAccountAndID(ctx, accountID, storedID)
+	}
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get article: %w", err)
+	}
+
+	return article, nil
+}
+
+// seeEntry remembers the entry with ID id as seen by the subscription, forgetting the oldest entries beyond
+// consts.FeedMaxSeenEntries.
+func seeEntry(subscription *model.Feed, id string) {
+	if slices.Contains(subscription.SeenItemIDs, id) {
+		return
+	}
+
+	subscription.SeenItemIDs = append(subscription.SeenItemIDs, id)
+	if extra := len(subscription.SeenItemIDs) - consts.FeedMaxSeenEntries; extra > 0 {
+		subscription.SeenItemIDs = subscription.SeenItemIDs[extra:]
+	}
+}
+
+// sendDigest sends the articles collected for a feed with digest delivery as a single EPUB, once per
+// consts.FeedDigestInterval, and marks them delivered. Reports whether a digest was sent.
+func (s *Service) sendDigest(ctx context.Context, subscription *model.Feed) (bool, error) {
+	if len(subscription.DigestArticleIDs) == 0 {
+		return false, nil
+	}
+
+	last := subscription.SubscribedAt
+	if subscription.LastDigestAt != nil {
+		last = *subscription.LastDigestAt
+	}
+	now := time.Now().UTC()
+	if now.Sub(last) < consts.FeedDigestInterval {
+		return false, nil
+	}
+
+	var articles []*model.Article
+	for _, id := range subscription.DigestArticleIDs {
+		article, err := s.repo.GetByAccountAndID(ctx, subscription.Account, id)
+		if err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				continue
+			}
+			return false, fmt.Errorf("failed to get digest article: %w", err)
+		}
+		articles = append(articles, article)
+	}
+
+	if len(articles) > 0 {
+		digest := digestArticle(subscription, articles, now)
+
+		epubData, err := s.generator.Generate(ctx, digest)
+		if err != nil {
+			return false, fmt.Errorf("failed to generate digest EPUB: %w", err)
+		}
+		if err = epub.Validate(epubData).Err(); err != nil {
+			return false, fmt.Errorf("failed to validate digest EPUB: %w", err)
+		}
+
+		emailResp, err := s.Send(ctx, NewProcessResult(digest, epubData, subscription.URL), "")
+		if err != nil {
+			return false, err
+		}
+
+		// only the delivery is written, the account may have changed the articles since they were read
+		for _, article := range articles {
+			s.enrichArticle(article, &article.ID, emailResp, subscription.Account)
+			if _, err = s.repo.UpdateDelivery(ctx, article); err != nil && !errors.Is(err, repository.ErrNotFound) {
+				s.dbErrors = errors.Join(s.dbErrors, err)
+			}
+		}
+	}
+
+	subscription.DigestArticleIDs = nil
+	subscription.LastDigestAt = &now
+
+	return len(articles) > 0, nil
+}
+
+// digestArticle combines articles into a single article, each one a section titled with its title.
+func digestArticle(subscription *model.Feed, articles []*model.Article, now time.Time) *model.Article {
+	title := subscription.Title
+	if title == "" {
+		title = subscription.URL
+	}
+
+	var body strings.Builder
+	words, minutes := 0, 0
+	for _, article := range articles {
+		body.WriteString("<h1>" + html.EscapeString(article.Title) + "</h1>\n")
+		body.WriteString(article.Content)
+		body.WriteString("\n")
+		words += article.WordCount
+		minutes += article.ReadingTimeMinutes
+	}
+
+	return &model.Article{
+		Account:            subscription.Account,
+		URL:                subscription.URL,
+		Title:              fmt.Sprintf("%s digest, %s", title, now.Format(time.DateOnly)),
+		SiteName:           subscription.Title,
+		Language:           articles[0].Language,
+		Content:            body.String(),
+		WordCount:          words,
+		ReadingTimeMinutes: minutes,
+		CreatedAt:          now,
+	}
+}
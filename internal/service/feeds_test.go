@@ -0,0 +1,251 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/shaftoe/savetoink/internal/config"
+	"github.com/shaftoe/savetoink/internal/consts"
+	"github.com/shaftoe/savetoink/internal/content"
+	"github.com/shaftoe/savetoink/internal/email"
+	"github.com/shaftoe/savetoink/internal/epub"
+	"github.com/shaftoe/savetoink/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockSender struct {
+	requests []*email.Request
+}
+
+func (m *mockSender) SendEmail(_ context.Context, req *email.Request) (*email.SendEmailResponse, error) {
+	m.requests = append(m.requests, req)
+	return &email.SendEmailResponse{Status: "success", EmailUUID: fmt.Sprintf("uuid-%d", len(m.requests))}, nil
+}
+
+// feedServer serves an RSS feed listing the first n posts, most recent first, and the posts themselves, which
+// declare a canonical URL under /articles.
+type feedServer struct {
+	*httptest.Server
+	mu    sync.Mutex
+	posts int
+}
+
+func newFeedServer(t *testing.T, posts int) *feedServer {
+	t.Helper()
+	fs := &feedServer{posts: posts}
+	fs.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fs.mu.Lock()
+		posts := fs.posts
+		fs.mu.Unlock()
+
+		if r.URL.Path != "/feed" {
+			w.Header().Set("Content-Type", "text/html")
+			_, _ = fmt.Fprintf(w, `<html><head><title>Post %[1]s</title><link rel="canonical" href="/articles/%[1]s">`+
+				`</head><body><article><h1>Post %[1]s</h1>`+
+				`<p>This is the content of post %[1]s, long enough to be extracted as the main content of `+
+				`the page by the extractor.</p></article></body></html>`, strings.TrimPrefix(r.URL.Path, "/posts/"))
+			return
+		}
+
+		etag := fmt.Sprintf(`"%d"`, posts)
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", etag)
+		var items strings.Builder
+		for i := posts; i >= 1; i-- {
+			published := time.Date(2024, time.January, i, 0, 0, 0, 0, time.UTC).Format(time.RFC1123)
+			fmt.Fprintf(&items, `<item><title>Post %d</title><link>/posts/%d</link><pubDate>%s</pubDate></item>`,
+				i, i, published)
+		}
+		_, _ = fmt.Fprintf(w, `<rss version="2.0"><channel><title>Blog</title>%s</channel></rss>`, items.String())
+	}))
+	t.Cleanup(fs.Close)
+	return fs
+}
+
+func (fs *feedServer) publish() {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	fs.posts++
+}
+
+func newFeedService(repo *MockRepository, sender email.Sender) *Service {
+	return &Service{
+		extractor: content.NewExtractor(content.WithFetchConfig(content.FetchConfig{HostInterval: -1})),
+		generator: epub.NewGenerator(),
+		sender:    sender,
+		repo:      repo,
+		cfg: &config.Config{
+			SendEnabled: sender != nil,
+			SenderEmail: "sender@example.com",
+			DestEmail:   "kindle@example.com",
+		},
+	}
+}
+
+func TestParseFeedDelivery(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    consts.FeedDelivery
+		wantErr bool
+	}{
+		{value: "", want: consts.FeedDeliveryImmediate},
+		{value: "immediate", want: consts.FeedDeliveryImmediate},
+		{value: " Digest ", want: consts.FeedDeliveryDigest},
+		{value: "none", want: consts.FeedDeliveryNone},
+		{value: "weekly", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := ParseFeedDelivery(tt.value)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidDelivery) {
+					t.Errorf("expected ErrInvalidDelivery, got %v", err)
+				}
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestCreateFeed(t *testing.T) {
+	server := newFeedServer(t, 2)
+	mockRepo := &MockRepository{}
+	svc := newFeedService(mockRepo, nil)
+
+	subscription, err := svc.CreateFeed(context.Background(), "user1", server.URL+"/feed", "")
+	require.NoError(t, err)
+	assert.Equal(t, "Blog", subscription.Title)
+	assert.Equal(t, consts.FeedDeliveryImmediate, subscription.Delivery)
+	assert.Equal(t, server.URL+"/posts/2", subscription.LastItemID)
+	assert.Equal(t, `"2"`, subscription.ETag)
+	assert.Equal(t, []string{server.URL + "/posts/2", server.URL + "/posts/1"}, subscription.SeenItemIDs)
+	assert.Empty(t, mockRepo.articles, "existing entries must not be saved")
+
+	// subscribing again updates the delivery
+	updated, err := svc.CreateFeed(context.Background(), "user1", server.URL+"/feed", consts.FeedDeliveryNone)
+	require.NoError(t, err)
+	assert.Equal(t, subscription.ID, updated.ID)
+	assert.Equal(t, consts.FeedDeliveryNone, updated.Delivery)
+	assert.Len(t, mockRepo.feeds, 1)
+
+	_, err = svc.CreateFeed(context.Background(), "user1", server.URL+"/posts/1", "")
+	assert.Error(t, err, "an HTML page is not a feed")
+
+	require.NoError(t, svc.DeleteFeed(context.Background(), "user1", subscription.ID))
+	assert.ErrorIs(t, svc.DeleteFeed(context.Background(), "user1", subscription.ID), ErrFeedNotFound)
+}
+
+func TestPollFeeds(t *testing.T) {
+	server := newFeedServer(t, 1)
+	mockRepo := &MockRepository{}
+	sender := &mockSender{}
+	svc := newFeedService(mockRepo, sender)
+	ctx := context.Background()
+
+	subscription, err := svc.CreateFeed(ctx, "user1", server.URL+"/feed", consts.FeedDeliveryImmediate)
+	require.NoError(t, err)
+
+	result, err := svc.PollFeeds(ctx, "user1")
+	require.NoError(t, err)
+	assert.Equal(t, &PollFeedsResult{Feeds: 1}, result, "an unchanged feed creates no article")
+
+	server.publish()
+	server.publish()
+
+	result, err = svc.PollFeeds(ctx, "")
+	require.NoError(t, err)
+	assert.Equal(t, &PollFeedsResult{Feeds: 1, Articles: 2}, result)
+	assert.Len(t, sender.requests, 2, "immediate delivery sends each article")
+	assert.Equal(t, server.URL+"/posts/3", subscription.LastItemID)
+	assert.Empty(t, subscription.Error)
+	assert.NotNil(t, subscription.LastPolledAt)
+
+	// seen entries are skipped once the last entry is forgotten, e.g. when it is no longer in the feed
+	subscription.LastItemID, subscription.LastItemAt, subscription.ETag = "", nil, ""
+	result, err = svc.PollFeeds(ctx, "user1")
+	require.NoError(t, err)
+	assert.Equal(t, 0, result.Articles)
+
+	// entries already saved, stored with the ID of their canonical URL, are skipped even if the feed state is lost
+	subscription.LastItemID, subscription.LastItemAt, subscription.ETag = "", nil, ""
+	subscription.SeenItemIDs = nil
+	stored := len(mockRepo.articles)
+	result, err = svc.PollFeeds(ctx, "user1")
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Articles, "only the first post was never saved")
+	assert.Len(t, mockRepo.articles, stored+1)
+	assert.Len(t, sender.requests, 3)
+}
+
+func TestPollFeedsDigest(t *testing.T) {
+	server := newFeedServer(t, 0)
+	mockRepo := &MockRepository{}
+	sender := &mockSender{}
+	svc := newFeedService(mockRepo, sender)
+	ctx := context.Background()
+
+	subscription, err := svc.CreateFeed(ctx, "user1", server.URL+"/feed", consts.FeedDeliveryDigest)
+	require.NoError(t, err)
+
+	server.publish()
+	server.publish()
+
+	result, err := svc.PollFeeds(ctx, "user1")
+	require.NoError(t, err)
+	assert.Equal(t, &PollFeedsResult{Feeds: 1, Articles: 2}, result, "the digest is not due yet")
+	assert.Empty(t, sender.requests)
+	assert.Len(t, subscription.DigestArticleIDs, 2)
+	for _, id := range subscription.DigestArticleIDs {
+		article, getErr := svc.repo.GetByAccountAndID(ctx, "user1", id)
+		require.NoError(t, getErr)
+		assert.Equal(t, consts.StatusPending, article.DeliveryStatus)
+	}
+
+	lastDigestAt := time.Now().Add(-consts.FeedDigestInterval)
+	subscription.LastDigestAt = &lastDigestAt
+
+	result, err = svc.PollFeeds(ctx, "user1")
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Digests)
+	require.Len(t, sender.requests, 1)
+	assert.Contains(t, sender.requests[0].Article.Title, "Blog digest")
+	assert.Contains(t, sender.requests[0].Article.Content, "<h1>Post 1</h1>")
+	assert.Contains(t, sender.requests[0].Article.Content, "<h1>Post 2</h1>")
+	assert.Empty(t, subscription.DigestArticleIDs)
+
+	delivered := 0
+	for _, article := range mockRepo.articles {
+		if article.DeliveryStatus == consts.StatusDelivered {
+			delivered++
+		}
+	}
+	assert.Equal(t, 2, delivered)
+}
+
+func TestDigestArticle(t *testing.T) {
+	now := time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC)
+	digest := digestArticle(&model.Feed{Account: "user1", URL: "https://example.com/feed"}, []*model.Article{
+		{Title: "A & B", Content: "<p>a</p>", WordCount: 10, ReadingTimeMinutes: 1, Language: "en"},
+		{Title: "C", Content: "<p>c</p>", WordCount: 20, ReadingTimeMinutes: 2, Language: "fr"},
+	}, now)
+
+	assert.Equal(t, "https://example.com/feed digest, 2024-03-01", digest.Title)
+	assert.Equal(t, "<h1>A &amp; B</h1>\n<p>a</p>\n<h1>C</h1>\n<p>c</p>\n", digest.Content)
+	assert.Equal(t, 30, digest.WordCount)
+	assert.Equal(t, 3, digest.ReadingTimeMinutes)
+	assert.Equal(t, "en", digest.Language)
+}
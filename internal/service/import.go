@@ -0,0 +1,228 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shaftoe/savetoink/internal/consts"
+	"github.com/shaftoe/savetoink/internal/content"
+	"github.com/shaftoe/savetoink/internal/importer"
+	"github.com/shaftoe/savetoink/internal/model"
+	"github.com/shaftoe/savetoink/internal/repository"
+)
+
+var (
+	// ErrImportNotFound is returned when an import job doesn't exist.
+	ErrImportNotFound = errors.New("import not found")
+	// ErrTooManyItems is returned when a file has more than consts.ImportMaxItems items to import.
+	ErrTooManyItems = errors.New("too many items to import")
+	// ErrImportInterrupted is recorded on import jobs stopped before processing all their items without storing
+	// their failure, e.g. when the server was stopped.
+	ErrImportInterrupted = errors.New("import interrupted")
+)
+
+// ImportOptions holds the options of an import.
+type ImportOptions struct {
+	// Format is the format of the imported file, detected from its content when empty.
+	Format consts.ImportFormat
+	// Wait saves the items before returning instead of in the background, e.g. from the CLI.
+	Wait bool
+	// Progress is called with the job after each item is processed, if set.
+	Progress func(job *model.ImportJob)
+}
+
+// Import parses a file exported by another service or a browser and saves its items as articles, without sending
+// them to Kindle, with their original save time and tags. Items already saved by the account, or listed twice, are
+// skipped. Items are saved in the background unless opts.Wait, the returned job tracks their progress. Lambda freezes
+// once the response is sent, so there the items are always saved before returning and files with more than
+// consts.LambdaMaxImportItems items are refused with ErrTooManyItems.
+func (s *Service) Import(
+	ctx context.Context,
+	accountID string,
+	data []byte,
+	opts ImportOptions,
+) (*model.ImportJob, error) {
+	if s.repo == nil {
+		return nil, errors.New("repository not configured")
+	}
+
+	items, format, err := importer.Parse(data, opts.Format)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(items) > consts.ImportMaxItems {
+		return nil, fmt.Errorf("%w: %d, at most %d", ErrTooManyItems, len(items), consts.ImportMaxItems)
+	}
+
+	if s.cfg.Lambda && !opts.Wait {
+		if len(items) > consts.LambdaMaxImportItems {
+			return nil, fmt.Errorf("%w: %d, at most %d on Lambda, import larger files with the CLI import command",
+				ErrTooManyItems, len(items), consts.LambdaMaxImportItems)
+		}
+		opts.Wait = true
+	}
+
+	settings, err := s.GetSettings(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	job := &model.ImportJob{
+		Account:   accountID,
+		ID:        uuid.NewString(),
+		Format:    format,
+		Status:    consts.ImportStatusRunning,
+		StartedAt: now,
+		UpdatedAt: now,
+		Total:     len(items),
+	}
+	if err = s.repo.StoreImportJob(ctx, job); err != nil {
+		return nil, fmt.Errorf("failed to store import job: %w", err)
+	}
+
+	processOpts := ProcessOptions{LinkMode: settings.LinkMode, NoSend: true}
+	if opts.Wait {
+		return job, s.runImport(ctx, job, items, processOpts, opts.Progress)
+	}
+
+	started := *job
+	go func() {
+		if runErr := s.runImport(context.WithoutCancel(ctx), job, items, processOpts, opts.Progress); runErr != nil {
+			slog.Error("failed to store import job", "import_id", job.ID, "error", runErr)
+		}
+	}()
+
+	return &started, nil
+}
+
+// runImport saves the items of an import job in order, storing the job progress every
+// consts.ImportProgressInterval items and once done.
+func (s *Service) runImport(
+	ctx context.Context,
+	job *model.ImportJob,
+	items []importer.Item,
+	opts ProcessOptions,
+	progress func(job *model.ImportJob),
+) error {
+	seen := make(map[string]bool, len(items))
+	for i, item := range items {
+		if err := ctx.Err(); err != nil {
+			job.Status = consts.ImportStatusFailed
+			job.Errors = append(job.Errors, err.Error())
+			break
+		}
+
+		s.importItem(ctx, job, item, opts, seen)
+		if progress != nil {
+			progress(job)
+		}
+
+		if (i+1)%consts.ImportProgressInterval == 0 && i+1 < len(items) {
+			job.UpdatedAt = time.Now().UTC()
+			if err := s.repo.StoreImportJob(ctx, job); err != nil {
+				slog.Error("failed to store import progress", "import_id", job.ID, "error", err)
+			}
+		}
+	}
+
+	if job.Status == consts.ImportStatusRunning {
+		job.Status = consts.ImportStatusCompleted
+	}
+	now := time.Now().UTC()
+	job.UpdatedAt = now
+	job.CompletedAt = &now
+
+	// the job is stored even when the import was canceled
+	if err := s.repo.StoreImportJob(context.WithoutCancel(ctx), job); err != nil {
+		return fmt.Errorf("failed to store import job: %w", err)
+	}
+
+	return nil
+}
+
+// importItem saves an item of an import job, unless it is already saved, even under the ID of its final or
+// canonical URL, or was already seen in the import, and counts the outcome on the job.
+func (s *Service) importItem(
+	ctx context.Context,
+	job *model.ImportJob,
+	item importer.Item,
+	opts ProcessOptions,
+	seen map[string]bool,
+) {
+	cleanURL, err := content.CleanURL(item.URL)
+	if err != nil {
+		job.Failed++
+		recordImportError(job, item.URL, err)
+		return
+	}
+
+	articleID, err := content.ArticleIDFromURL(cleanURL)
+	if err != nil {
+		job.Failed++
+		recordImportError(job, item.URL, err)
+		return
+	}
+
+	if seen[articleID] || s.articleSaved(ctx, job.Account, cleanURL) {
+		job.Skipped++
+		return
+	}
+	seen[articleID] = true
+
+	tags, err := ParseTags(item.Tags)
+	if err != nil {
+		job.Failed++
+		recordImportError(job, item.URL, err)
+		return
+	}
+
+	opts.SavedAt, opts.Title, opts.Tags = item.SavedAt, item.Title, tags
+	if _, err = s.CreateArticle(ctx, cleanURL, job.Account, opts); err != nil {
+		job.Failed++
+		recordImportError(job, item.URL, err)
+		return
+	}
+
+	job.Imported++
+}
+
+func recordImportError(job *model.ImportJob, itemURL string, err error) {
+	if len(job.Errors) < consts.ImportMaxErrors {
+		job.Errors = append(job.Errors, itemURL+": "+err.Error())
+	}
+}
+
+// GetImportJob returns an import job of the account. A running job whose progress was not updated for
+// consts.ImportStaleAfter is stored and returned as failed with ErrImportInterrupted.
+func (s *Service) GetImportJob(ctx context.Context, accountID, jobID string) (*model.ImportJob, error) {
+	if s.repo == nil {
+		return nil, errors.New("repository not configured")
+	}
+
+	job, err := s.repo.GetImportJob(ctx, accountID, jobID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrImportNotFound
+		}
+		return nil, fmt.Errorf("failed to get import job: %w", err)
+	}
+
+	if job.Status == consts.ImportStatusRunning && time.Since(job.UpdatedAt) > consts.ImportStaleAfter {
+		now := time.Now().UTC()
+		job.Status = consts.ImportStatusFailed
+		job.Errors = append(job.Errors, ErrImportInterrupted.Error())
+		job.UpdatedAt = now
+		job.CompletedAt = &now
+		if err = s.repo.StoreImportJob(ctx, job); err != nil {
+			return nil, fmt.Errorf("failed to store import job: %w", err)
+		}
+	}
+
+	return job, nil
+}
@@ -0,0 +1,174 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shaftoe/savetoink/internal/consts"
+	"github.com/shaftoe/savetoink/internal/content"
+	"github.com/shaftoe/savetoink/internal/importer"
+	"github.com/shaftoe/savetoink/internal/model"
+	"github.com/shaftoe/savetoink/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/gone" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = fmt.Fprintf(w, `<html><head><title>Page %[1]s</title></head><body><article><h1>Page %[1]s</h1>`+
+			`<p>This is the content of page %[1]s, long enough to be extracted as the main content of `+
+			`the page by the extractor.</p></article></body></html>`, r.URL.Path)
+	}))
+	defer server.Close()
+
+	mockRepo := &MockRepository{}
+	sender := &mockSender{}
+	svc := newFeedService(mockRepo, sender)
+	ctx := context.Background()
+
+	savedID, err := content.ArticleIDFromURL(server.URL + "/saved")
+	require.NoError(t, err)
+	require.NoError(t, mockRepo.Store(ctx, &model.Article{Account: "user1", ID: savedID, URL: server.URL + "/saved"}))
+
+	export := "title,url,time_added,tags,status\n" +
+		"First,URL/first,1700000000,Go|reading|go,unread\n" +
+		"First again,URL/first?utm_source=feed,1700000100,,unread\n" +
+		"Saved,URL/saved,1700000200,,unread\n" +
+		"Gone,URL/gone,1700000300,dead,archive\n" +
+		"Long tag,URL/long,1700000400," + strings.Repeat("t", consts.TagMaxLength+1) + ",unread\n"
+
+	var progress []int
+	job, err := svc.Import(ctx, "user1", []byte(strings.ReplaceAll(export, "URL", server.URL)), ImportOptions{
+		Wait:     true,
+		Progress: func(job *model.ImportJob) { progress = append(progress, job.Imported+job.Skipped+job.Failed) },
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, consts.ImportFormatPocketCSV, job.Format)
+	assert.Equal(t, consts.ImportStatusCompleted, job.Status)
+	assert.Equal(t, 5, job.Total)
+	assert.Equal(t, 1, job.Imported)
+	assert.Equal(t, 2, job.Skipped, "duplicates and saved articles are skipped")
+	assert.Equal(t, 2, job.Failed)
+	require.Len(t, job.Errors, 2)
+	assert.Contains(t, job.Errors[0], server.URL+"/gone")
+	assert.Contains(t, job.Errors[1], server.URL+"/long: "+ErrInvalidTags.Error())
+	assert.NotNil(t, job.CompletedAt)
+	assert.Equal(t, []int{1, 2, 3, 4, 5}, progress)
+	assert.Empty(t, sender.requests, "imported articles are not sent")
+
+	stored, err := svc.GetImportJob(ctx, "user1", job.ID)
+	require.NoError(t, err)
+	assert.Equal(t, consts.ImportStatusCompleted, stored.Status)
+
+	firstID, err := content.ArticleIDFromURL(server.URL + "/first")
+	require.NoError(t, err)
+	first, err := mockRepo.GetByAccountAndID(ctx, "user1", firstID)
+	require.NoError(t, err)
+	assert.Equal(t, "Page /first", first.Title)
+	assert.Equal(t, time.Unix(1700000000, 0).UTC(), first.CreatedAt, "the original save time is kept")
+	assert.Equal(t, []string{"go", "reading"}, first.Tags, "imported tags are normalized")
+	assert.Equal(t, consts.StatusPending, first.DeliveryStatus)
+
+	goneID, err := content.ArticleIDFromURL(server.URL + "/gone")
+	require.NoError(t, err)
+	gone, err := mockRepo.GetByAccountAndID(ctx, "user1", goneID)
+	require.NoError(t, err)
+	assert.Equal(t, "Gone", gone.Title, "failed items keep their imported title")
+	assert.Equal(t, []string{"dead"}, gone.Tags)
+	assert.NotEmpty(t, gone.Error)
+
+	longID, err := content.ArticleIDFromURL(server.URL + "/long")
+	require.NoError(t, err)
+	_, err = mockRepo.GetByAccountAndID(ctx, "user1", longID)
+	assert.ErrorIs(t, err, repository.ErrNotFound, "items with invalid tags are not saved")
+}
+
+func TestImportCanonical(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write([]byte(`<html><head><title>Canonical</title><link rel="canonical" href="/article"></head>` +
+			`<body><article><h1>Canonical</h1><p>This page is saved with the ID of its canonical URL, declared ` +
+			`in its head, long enough to be extracted as the main content.</p></article></body></html>`))
+	}))
+	defer server.Close()
+
+	mockRepo := &MockRepository{}
+	svc := newFeedService(mockRepo, nil)
+	export := []byte("url,time_added\n" + server.URL + "/amp/article,1700000000\n")
+
+	job, err := svc.Import(context.Background(), "user1", export, ImportOptions{Wait: true})
+	require.NoError(t, err)
+	assert.Equal(t, 1, job.Imported)
+
+	job, err = svc.Import(context.Background(), "user1", export, ImportOptions{Wait: true})
+	require.NoError(t, err)
+	assert.Equal(t, 0, job.Imported)
+	assert.Equal(t, 1, job.Skipped, "links saved under the ID of their canonical URL are skipped")
+	assert.Len(t, mockRepo.articles, 1)
+}
+
+func TestImportInvalid(t *testing.T) {
+	svc := newFeedService(&MockRepository{}, nil)
+
+	_, err := svc.Import(context.Background(), "user1", []byte("name,email\n"), ImportOptions{Wait: true})
+	assert.ErrorIs(t, err, importer.ErrUnknownFormat)
+
+	var export strings.Builder
+	export.WriteString("url,time_added\n")
+	for i := range consts.ImportMaxItems + 1 {
+		fmt.Fprintf(&export, "https://example.com/%d,\n", i)
+	}
+	_, err = svc.Import(context.Background(), "user1", []byte(export.String()), ImportOptions{Wait: true})
+	assert.ErrorIs(t, err, ErrTooManyItems)
+
+	_, err = svc.GetImportJob(context.Background(), "user1", "missing")
+	assert.ErrorIs(t, err, ErrImportNotFound)
+}
+
+func TestImportLambda(t *testing.T) {
+	svc := newFeedService(&MockRepository{}, nil)
+	svc.cfg.Lambda = true
+
+	job, err := svc.Import(context.Background(), "user1", []byte("url,time_added\n"), ImportOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, consts.ImportStatusCompleted, job.Status, "the items are saved before returning")
+
+	var export strings.Builder
+	export.WriteString("url,time_added\n")
+	for i := range consts.LambdaMaxImportItems + 1 {
+		fmt.Fprintf(&export, "https://example.com/%d,\n", i)
+	}
+	_, err = svc.Import(context.Background(), "user1", []byte(export.String()), ImportOptions{})
+	assert.ErrorIs(t, err, ErrTooManyItems)
+}
+
+func TestGetImportJobInterrupted(t *testing.T) {
+	now := time.Now().UTC()
+	mockRepo := &MockRepository{imports: []*model.ImportJob{
+		{Account: "user1", ID: "stale", Status: consts.ImportStatusRunning, UpdatedAt: now.Add(-2 * time.Hour)},
+		{Account: "user1", ID: "running", Status: consts.ImportStatusRunning, UpdatedAt: now.Add(-time.Minute)},
+	}}
+	svc := newFeedService(mockRepo, nil)
+
+	stale, err := svc.GetImportJob(context.Background(), "user1", "stale")
+	require.NoError(t, err)
+	assert.Equal(t, consts.ImportStatusFailed, stale.Status)
+	assert.Equal(t, []string{ErrImportInterrupted.Error()}, stale.Errors)
+	assert.NotNil(t, stale.CompletedAt)
+	assert.Equal(t, consts.ImportStatusFailed, mockRepo.imports[0].Status, "the failure is stored")
+
+	running, err := svc.GetImportJob(context.Background(), "user1", "running")
+	require.NoError(t, err)
+	assert.Equal(t, consts.ImportStatusRunning, running.Status)
+}
@@ -0,0 +1,117 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/shaftoe/savetoink/internal/consts"
+	"github.com/shaftoe/savetoink/internal/model"
+	"github.com/shaftoe/savetoink/internal/repository"
+)
+
+// ErrInvalidReadState is returned for an unknown reading workflow state.
+var ErrInvalidReadState = errors.New("invalid read state")
+
+// ArticleUpdate holds the reading workflow fields of an article to update, nil fields are left unchanged.
+type ArticleUpdate struct {
+	ReadState *consts.ReadState
+	Favorite  *bool
+}
+
+// ParseReadState parses a reading workflow state.
+func ParseReadState(value string) (consts.ReadState, error) {
+	switch state := consts.ReadState(strings.ToLower(strings.TrimSpace(value))); state {
+	case consts.ReadStateUnread, consts.ReadStateRead, consts.ReadStateArchived:
+		return state, nil
+	default:
+		return "", fmt.Errorf("%w %q: must be one of %s, %s or %s", ErrInvalidReadState, value,
+			consts.ReadStateUnread, consts.ReadStateRead, consts.ReadStateArchived)
+	}
+}
+
+// UpdateArticle updates the read state and favorite flag of an article, recording when they changed. Marking an
+// article unread clears when it was read and archived. Returns the article without content, ErrArticleNotFound
+// when the account hasn't saved it and ErrInvalidReadState for an unknown state.
+func (s *Service) UpdateArticle(
+	ctx context.Context,
+	accountID, articleID string,
+	update ArticleUpdate,
+) (*model.Article, error) {
+	if update.ReadState != nil {
+		if _, err := ParseReadState(string(*update.ReadState)); err != nil {
+			return nil, err
+		}
+	}
+
+	if s.repo == nil {
+		return nil, errors.New("repository not configured")
+	}
+
+	article, err := s.repo.GetByAccountAndID(ctx, accountID, articleID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrArticleNotFound
+		}
+		return nil, fmt.Errorf("failed to get article: %w", err)
+	}
+
+	now := time.Now().UTC()
+	if update.ReadState != nil {
+		setReadState(article, *update.ReadState, now)
+	}
+	if update.Favorite != nil {
+		setFavorite(article, *update.Favorite, now)
+	}
+
+	// only the reading workflow attributes are written, an article saved again meanwhile keeps its new content
+	article, err = s.repo.UpdateReadingState(ctx, article)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrArticleNotFound
+		}
+		return nil, fmt.Errorf("failed to update article: %w", err)
+	}
+
+	metadata := *article
+	metadata.Content = ""
+	return &metadata, nil
+}
+
+// setReadState moves article to state at now, keeping the times of the states it was already in.
+func setReadState(article *model.Article, state consts.ReadState, now time.Time) {
+	switch state {
+	case consts.ReadStateUnread:
+		article.ReadAt, article.ArchivedAt = nil, nil
+	case consts.ReadStateRead:
+		if article.ReadAt == nil {
+			article.ReadAt = &now
+		}
+		article.ArchivedAt = nil
+	case consts.ReadStateArchived:
+		if article.ArchivedAt == nil {
+			article.ArchivedAt = &now
+		}
+	}
+	article.ReadState = state
+}
+
+func setFavorite(article *model.Article, favorite bool, now time.Time) {
+	switch {
+	case !favorite:
+		article.FavoritedAt = nil
+	case !article.Favorite:
+		article.FavoritedAt = &now
+	}
+	article.Favorite = favorite
+}
+
+// markUnread adds a delivered article, or one saved without sending, to the unread ones unless it already has
+// a read state, e.g. an article of a digest read before the digest was sent.
+func markUnread(article *model.Article) {
+	if article.ReadState == "" {
+		article.ReadState = consts.ReadStateUnread
+	}
+}
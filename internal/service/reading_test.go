@@ -0,0 +1,153 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/shaftoe/savetoink/internal/config"
+	"github.com/shaftoe/savetoink/internal/consts"
+	"github.com/shaftoe/savetoink/internal/email"
+	"github.com/shaftoe/savetoink/internal/epub"
+	"github.com/shaftoe/savetoink/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseReadState(t *testing.T) {
+	tests := []struct {
+		value       string
+		expected    consts.ReadState
+		expectedErr error
+	}{
+		{value: "unread", expected: consts.ReadStateUnread},
+		{value: " Archived ", expected: consts.ReadStateArchived},
+		{value: "read", expected: consts.ReadStateRead},
+		{value: "", expectedErr: ErrInvalidReadState},
+		{value: "deleted", expectedErr: ErrInvalidReadState},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			state, err := ParseReadState(tt.value)
+			require.ErrorIs(t, err, tt.expectedErr)
+			assert.Equal(t, tt.expected, state)
+		})
+	}
+}
+
+func TestUpdateArticle(t *testing.T) {
+	mockRepo := &MockRepository{articles: []*model.Article{
+		{Account: "user1", ID: "1", Content: "<p>content</p>", CreatedAt: time.Now(),
+			ReadState: consts.ReadStateUnread},
+	}}
+	svc := newFeedService(mockRepo, nil)
+	ctx := context.Background()
+
+	state := func(s consts.ReadState) *consts.ReadState { return &s }
+	favorite := func(f bool) *bool { return &f }
+
+	article, err := svc.UpdateArticle(ctx, "user1", "1", ArticleUpdate{ReadState: state(consts.ReadStateRead)})
+	require.NoError(t, err)
+	assert.Equal(t, consts.ReadStateRead, article.ReadState)
+	require.NotNil(t, article.ReadAt)
+	assert.Nil(t, article.ArchivedAt)
+	assert.Empty(t, article.Content, "the article is returned without content")
+	readAt := *article.ReadAt
+
+	article, err = svc.UpdateArticle(ctx, "user1", "1",
+		ArticleUpdate{ReadState: state(consts.ReadStateArchived), Favorite: favorite(true)})
+	require.NoError(t, err)
+	assert.Equal(t, consts.ReadStateArchived, article.ReadState)
+	assert.Equal(t, readAt, *article.ReadAt, "archiving keeps when the article was read")
+	assert.NotNil(t, article.ArchivedAt)
+	assert.True(t, article.Favorite)
+	assert.NotNil(t, article.FavoritedAt)
+
+	result, err := svc.GetArticlesMetadata(ctx, "user1", &model.ArticleFilter{Favorite: favorite(true),
+		ReadStates: []consts.ReadState{consts.ReadStateArchived}}, 1, 20)
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Total)
+
+	article, err = svc.UpdateArticle(ctx, "user1", "1",
+		ArticleUpdate{ReadState: state(consts.ReadStateUnread), Favorite: favorite(false)})
+	require.NoError(t, err)
+	assert.Equal(t, consts.ReadStateUnread, article.ReadState)
+	assert.Nil(t, article.ReadAt)
+	assert.Nil(t, article.ArchivedAt)
+	assert.False(t, article.Favorite)
+	assert.Nil(t, article.FavoritedAt)
+
+	stored, err := mockRepo.GetByAccountAndID(ctx, "user1", "1")
+	require.NoError(t, err)
+	assert.NotEmpty(t, stored.Content)
+
+	_, err = svc.UpdateArticle(ctx, "user1", "missing", ArticleUpdate{Favorite: favorite(true)})
+	require.ErrorIs(t, err, ErrArticleNotFound)
+
+	_, err = svc.UpdateArticle(ctx, "user1", "1", ArticleUpdate{ReadState: state("later")})
+	require.ErrorIs(t, err, ErrInvalidReadState)
+}
+
+func TestEnrichArticleMarksUnread(t *testing.T) {
+	tests := []struct {
+		name          string
+		sendEnabled   bool
+		emailResp     *email.SendEmailResponse
+		readState     consts.ReadState
+		expectedState consts.ReadState
+	}{
+		{name: "delivered", sendEnabled: true, emailResp: &email.SendEmailResponse{EmailUUID: "uuid"},
+			expectedState: consts.ReadStateUnread},
+		{name: "delivery failed", sendEnabled: true, expectedState: consts.ReadStateUnread},
+		{name: "sending disabled", expectedState: consts.ReadStateUnread},
+		{name: "already read", sendEnabled: true, emailResp: &email.SendEmailResponse{EmailUUID: "uuid"},
+			readState: consts.ReadStateRead, expectedState: consts.ReadStateRead},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &Service{cfg: &config.Config{SendEnabled: tt.sendEnabled}}
+			article := &model.Article{ReadState: tt.readState}
+			id := "1"
+
+			svc.enrichArticle(article, &id, tt.emailResp, "user1")
+
+			assert.Equal(t, tt.expectedState, article.ReadState)
+		})
+	}
+}
+
+func TestCreateArticleMarksUnread(t *testing.T) {
+	tests := []struct {
+		name           string
+		extractor      *stubExtractor
+		opts           ProcessOptions
+		expectedStatus consts.Status
+	}{
+		{name: "not sent", extractor: &stubExtractor{article: &model.Article{Title: "Saved", Content: "<p>Saved.</p>"}},
+			opts: ProcessOptions{LinkMode: consts.LinkModeKeep, NoSend: true}, expectedStatus: consts.StatusPending},
+		{name: "extraction failed", extractor: &stubExtractor{err: errors.New("extraction failed")},
+			opts: ProcessOptions{LinkMode: consts.LinkModeKeep}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := &MockRepository{}
+			svc := &Service{
+				extractor: tt.extractor,
+				generator: epub.NewGenerator(),
+				repo:      mockRepo,
+				cfg:       &config.Config{SendEnabled: true},
+			}
+
+			_, _ = svc.CreateArticle(context.Background(), "https://example.com/unread", "user1", tt.opts)
+
+			require.NotEmpty(t, mockRepo.articles)
+			stored := mockRepo.articles[len(mockRepo.articles)-1]
+			assert.Equal(t, consts.ReadStateUnread, stored.ReadState)
+			assert.Equal(t, tt.expectedStatus, stored.DeliveryStatus)
+		})
+	}
+}
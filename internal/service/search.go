@@ -0,0 +1,57 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/shaftoe/savetoink/internal/model"
+	"github.com/shaftoe/savetoink/internal/search"
+)
+
+// SearchOptions holds a full-text search query and its filters.
+type SearchOptions struct {
+	// Query are the words to search, words in double quotes are searched as a phrase.
+	Query string
+	// Domain filters articles from the domain or its subdomains.
+	Domain string
+	// Language filters articles in the language, a BCP 47 tag.
+	Language string
+	Page     int
+	PageSize int
+}
+
+// SearchArticles searches the title, author, site name, excerpt and content of the articles of the account,
+// returning a page of the matching articles without content, the most relevant first. Returns
+// search.ErrEmptyQuery or search.ErrQueryTooLong for invalid queries.
+func (s *Service) SearchArticles(
+	ctx context.Context,
+	accountID string,
+	opts SearchOptions,
+) (*GetArticlesResult, error) {
+	query, err := search.ParseQuery(opts.Query)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.repo == nil {
+		return &GetArticlesResult{
+			Articles: []*model.Article{},
+			Page:     opts.Page,
+			PageSize: opts.PageSize,
+		}, nil
+	}
+
+	articles, total, err := s.repo.Search(ctx, accountID, query.WithFilters(opts.Domain, opts.Language),
+		opts.Page, opts.PageSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search articles: %w", err)
+	}
+
+	return &GetArticlesResult{
+		Articles: articles,
+		Page:     opts.Page,
+		PageSize: opts.PageSize,
+		Total:    total,
+		HasMore:  opts.Page*opts.PageSize < total,
+	}, nil
+}
@@ -0,0 +1,72 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/shaftoe/savetoink/internal/model"
+	"github.com/shaftoe/savetoink/internal/search"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSearchArticles(t *testing.T) {
+	mockRepo := &MockRepository{}
+	svc := newFeedService(mockRepo, nil)
+	ctx := context.Background()
+
+	savedAt := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	articles := []*model.Article{
+		{Account: "user1", ID: "1", URL: "https://go.dev/blog/errors", Title: "Error handling in Go",
+			Content: "<p>Errors are values.</p>", Language: "en", CreatedAt: savedAt},
+		{Account: "user1", ID: "2", URL: "https://example.com/tour", Title: "A tour of Go",
+			Content: "<p>Handling errors in Go is explicit.</p>", Language: "en-US", CreatedAt: savedAt},
+		{Account: "user1", ID: "3", URL: "https://example.fr/go", Title: "La gestion des erreurs en Go",
+			Language: "fr", CreatedAt: savedAt},
+		{Account: "user2", ID: "4", URL: "https://go.dev/blog/errors", Title: "Error handling in Go",
+			CreatedAt: savedAt},
+	}
+	for _, article := range articles {
+		require.NoError(t, mockRepo.Store(ctx, article))
+	}
+
+	tests := []struct {
+		name            string
+		opts            SearchOptions
+		expectedIDs     []string
+		expectedTotal   int
+		expectedHasMore bool
+	}{
+		{name: "terms", opts: SearchOptions{Query: "go", Page: 1, PageSize: 20},
+			expectedIDs: []string{"1", "2", "3"}, expectedTotal: 3},
+		{name: "phrase", opts: SearchOptions{Query: `"handling errors"`, Page: 1, PageSize: 20},
+			expectedIDs: []string{"2"}, expectedTotal: 1},
+		{name: "domain", opts: SearchOptions{Query: "go", Domain: "www.go.dev", Page: 1, PageSize: 20},
+			expectedIDs: []string{"1"}, expectedTotal: 1},
+		{name: "language", opts: SearchOptions{Query: "go", Language: "fr", Page: 1, PageSize: 20},
+			expectedIDs: []string{"3"}, expectedTotal: 1},
+		// the shortest articles rank first
+		{name: "paged", opts: SearchOptions{Query: "go", Page: 1, PageSize: 2},
+			expectedIDs: []string{"3", "2"}, expectedTotal: 3, expectedHasMore: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := svc.SearchArticles(ctx, "user1", tt.opts)
+			require.NoError(t, err)
+
+			ids := make([]string, 0, len(result.Articles))
+			for _, article := range result.Articles {
+				ids = append(ids, article.ID)
+				assert.Empty(t, article.Content, "articles are returned without content")
+			}
+			assert.ElementsMatch(t, tt.expectedIDs, ids)
+			assert.Equal(t, tt.expectedTotal, result.Total)
+			assert.Equal(t, tt.expectedHasMore, result.HasMore)
+		})
+	}
+
+	_, err := svc.SearchArticles(ctx, "user1", SearchOptions{Query: " ! ", Page: 1, PageSize: 20})
+	require.ErrorIs(t, err, search.ErrEmptyQuery)
+}
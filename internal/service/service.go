@@ -5,35 +5,66 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
 	"time"
 
 	"github.com/shaftoe/savetoink/internal/config"
 	"github.com/shaftoe/savetoink/internal/consts"
 	"github.com/shaftoe/savetoink/internal/content"
+	"github.com/shaftoe/savetoink/internal/content/cache"
 	"github.com/shaftoe/savetoink/internal/email"
 	"github.com/shaftoe/savetoink/internal/email/mailjet"
 	"github.com/shaftoe/savetoink/internal/epub"
 	"github.com/shaftoe/savetoink/internal/model"
 	"github.com/shaftoe/savetoink/internal/repository"
+	"github.com/shaftoe/savetoink/internal/transform"
 	"golang.org/x/sync/errgroup"
 )
 
 // Interface defines the contract for service operations.
 type Interface interface {
-	Process(ctx context.Context, url string) (*ProcessResult, error)
+	Process(ctx context.Context, url string, opts ProcessOptions) (*ProcessResult, error)
 	Send(ctx context.Context, result *ProcessResult, subject string) (*email.SendEmailResponse, error)
 	WriteToFile(result *ProcessResult, outputPath string) error
-	CreateArticle(ctx context.Context, rawURL, accountID string) (*CreateArticleResult, error)
+	CreateArticle(ctx context.Context, rawURL, accountID string, opts ProcessOptions) (*CreateArticleResult, error)
 	GetArticle(ctx context.Context, accountID, articleID string) (*model.Article, error)
-	GetArticlesMetadata(ctx context.Context, accountID string, page, pageSize int) (*GetArticlesResult, error)
+	GetArticlesMetadata(
+		ctx context.Context,
+		accountID string,
+		filter *model.ArticleFilter,
+		page, pageSize int,
+	) (*GetArticlesResult, error)
+	SearchArticles(ctx context.Context, accountID string, opts SearchOptions) (*GetArticlesResult, error)
+	UpdateArticle(ctx context.Context, accountID, articleID string, update ArticleUpdate) (*model.Article, error)
+	SetTags(ctx context.Context, accountID, articleID string, tags []string, collection string) (*model.Article, error)
+	GetTags(ctx context.Context, accountID string) (*model.Tags, error)
 	DeleteArticle(ctx context.Context, accountID, articleID string) (*DeleteArticleResult, error)
 	DeleteAllArticles(ctx context.Context, accountID string) (*DeleteArticleResult, error)
+	GetSettings(ctx context.Context, accountID string) (*model.Settings, error)
+	UpdateSettings(ctx context.Context, settings *model.Settings) (*model.Settings, error)
+	CreateFeed(ctx context.Context, accountID, rawURL string, delivery consts.FeedDelivery) (*model.Feed, error)
+	GetFeeds(ctx context.Context, accountID string) ([]*model.Feed, error)
+	DeleteFeed(ctx context.Context, accountID, feedID string) error
+	PollFeeds(ctx context.Context, accountID string) (*PollFeedsResult, error)
+	Import(ctx context.Context, accountID string, data []byte, opts ImportOptions) (*model.ImportJob, error)
+	GetImportJob(ctx context.Context, accountID, jobID string) (*model.ImportJob, error)
+	Export(ctx context.Context, accountID string, w io.Writer, opts ExportOptions) (int, error)
 	GetDBError() error
 }
 
+// Extractor fetches URLs and extracts their articles and feed entries, see content.Extractor.
+type Extractor interface {
+	ExtractFromURL(ctx context.Context, urlStr string) (*model.Article, error)
+	ExtractFromHTML(doc []byte, urlStr string) (*model.Article, error)
+	FetchFeed(ctx context.Context, urlStr, etag, lastModified string) (*content.FeedResponse, error)
+}
+
 // Service holds the stateless dependencies and provides methods to process articles.
 type Service struct {
-	extractor *content.Extractor
+	extractor Extractor
 	generator *epub.Generator
 	sender    email.Sender
 	repo      repository.Repository
@@ -44,6 +75,7 @@ type Service struct {
 // New creates a new Service instance with the given config.
 // All internal dependencies (extractor, generator, sender, repository) are created based on configuration.
 // DynamoDB repository is wired only if both DynamoDBTable and AWSConfig are available.
+// In server mode URLs are fetched with SSRF protection, see content.WithSSRFProtection.
 func New(cfg *config.Config) *Service {
 	var sender email.Sender
 	if cfg.SendEnabled {
@@ -57,18 +89,58 @@ func New(cfg *config.Config) *Service {
 
 	var repo repository.Repository
 	if cfg.DynamoDBTable != "" && cfg.AWSConfig != nil {
-		repo = repository.NewDynamoDB(cfg.AWSConfig, cfg.DynamoDBTable)
+		repo = repository.NewDynamoDB(cfg.AWSConfig, cfg.DynamoDBTable,
+			repository.WithArticleIndexes(cfg.ArticleIndexes))
 	}
 
+	extractorOpts := []content.Option{content.WithRules(cfg.Rules), content.WithFetchConfig(cfg.Fetch)}
+	if fetchCache := newFetchCache(cfg); fetchCache != nil {
+		extractorOpts = append(extractorOpts, content.WithCache(fetchCache))
+	}
+	if cfg.Mode == consts.ModeServer {
+		extractorOpts = append(extractorOpts, content.WithSSRFProtection(content.HostPolicy{
+			Allow: cfg.FetchAllowHosts,
+			Deny:  cfg.FetchDenyHosts,
+		}))
+	}
+
+	extractor := content.NewExtractor(extractorOpts...)
+
 	return &Service{
-		extractor: content.NewExtractor(),
-		generator: epub.NewGenerator(),
+		extractor: extractor,
+		generator: epub.NewGenerator(epub.WithImageFetcher(extractor)),
 		sender:    sender,
 		repo:      repo,
 		cfg:       cfg,
 	}
 }
 
+// newFetchCache creates the cache of fetched documents configured in cfg, nil when caching is disabled.
+// The on-disk cache defaults to a directory in the user cache directory.
+func newFetchCache(cfg *config.Config) cache.Cache {
+	switch cfg.FetchCache {
+	case consts.CacheNone:
+		return nil
+	case consts.CacheDisk:
+		dir := cfg.FetchCacheDir
+		if dir == "" {
+			userDir, err := os.UserCacheDir()
+			if err != nil {
+				log.Printf("warning: fetch cache disabled, no cache directory: %v", err)
+				return nil
+			}
+			dir = filepath.Join(userDir, consts.FetchCacheDirName)
+		}
+		return cache.NewDisk(dir)
+	default:
+		size := cfg.FetchCacheSize
+		if size <= 0 {
+			size = consts.FetchCacheMaxSize
+		}
+		return cache.NewMemory(size)
+	}
+}
+
 // CreateArticleResult holds the result of creating an article.
 type CreateArticleResult struct {
 	Article   *model.Article
@@ -90,6 +162,30 @@ type DeleteArticleResult struct {
 	Deleted int
 }
 
+// ProcessOptions holds the preferences applied when processing an article.
+type ProcessOptions struct {
+	// LinkMode controls how hyperlinks are rendered, defaults to consts.LinkModeKeep.
+	LinkMode consts.LinkMode
+	// Direction overrides the text direction derived from the article language.
+	Direction consts.TextDirection
+	// VerticalWriting enables vertical writing mode for languages supporting it, e.g. Japanese.
+	VerticalWriting bool
+	// HTML is the document rendered by the client, e.g. for paywalled pages. The URL is fetched when empty.
+	HTML []byte
+	// NoCache fetches the URL from the origin server even when a cached copy is fresh.
+	NoCache bool
+	// NoSend saves the article without sending it to Kindle even when sending is enabled, its delivery stays
+	// pending, e.g. for articles delivered later in a feed digest.
+	NoSend bool
+	// SavedAt overrides the creation time of the saved article, e.g. the time it was saved in another service.
+	SavedAt time.Time
+	// Title is the title of the saved article when its content can't be extracted, e.g. an imported dead link.
+	Title string
+	// Tags and Collection are recorded on the article, listed in the EPUB metadata.
+	Tags       []string
+	Collection string
+}
+
 // ProcessResult holds the result of processing an article.
 type ProcessResult struct {
 	article  *model.Article
@@ -122,10 +218,21 @@ func NewProcessResult(article *model.Article, epubData []byte, url string) *Proc
 	}
 }
 
-// Process extracts content from a URL and generates EPUB data.
-// Can be called multiple times to re-fetch fresh content.
-func (s *Service) Process(ctx context.Context, url string) (*ProcessResult, error) {
-	article, err := s.extractor.ExtractFromURL(ctx, url)
+// Process extracts content from a URL, or from the HTML document in opts, moves footnotes to endnotes,
+// rewrites links according to opts, generates EPUB data and validates it.
+// Non-fatal validation issues are recorded as warnings on the article, fatal ones fail processing.
+// Can be called multiple times, set opts.NoCache to re-fetch fresh content bypassing the fetch cache.
+func (s *Service) Process(ctx context.Context, url string, opts ProcessOptions) (*ProcessResult, error) {
+	var article *model.Article
+	var err error
+	if len(opts.HTML) > 0 {
+		article, err = s.extractor.ExtractFromHTML(opts.HTML, url)
+	} else {
+		if opts.NoCache {
+			ctx = content.WithoutCache(ctx)
+		}
+		article, err = s.extractor.ExtractFromURL(ctx, url)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to extract article: %w", err)
 	}
@@ -134,11 +241,32 @@ func (s *Service) Process(ctx context.Context, url string) (*ProcessResult, erro
 		article.Title = "Untitled"
 	}
 
-	epubData, err := s.generator.Generate(article)
+	article.Direction = opts.Direction
+	article.VerticalWriting = opts.VerticalWriting
+	article.Tags = opts.Tags
+	article.Collection = opts.Collection
+
+	article.Content, err = transform.Footnotes(article.Content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to transform footnotes: %w", err)
+	}
+
+	article.Content, err = transform.Links(article.Content, article.URL, opts.LinkMode)
+	if err != nil {
+		return nil, fmt.Errorf("failed to transform links: %w", err)
+	}
+
+	epubData, err := s.generator.Generate(ctx, article)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate EPUB: %w", err)
 	}
 
+	report := epub.Validate(epubData)
+	if validationErr := report.Err(); validationErr != nil {
+		return nil, fmt.Errorf("failed to validate EPUB: %w", validationErr)
+	}
+	article.Warnings = report.Warnings()
+
 	return &ProcessResult{
 		article:  article,
 		epubData: epubData,
@@ -196,7 +324,7 @@ func (s *Service) WriteToFile(result *ProcessResult, outputPath string) error {
 		return errors.New("output path is empty")
 	}
 
-	err := s.generator.GenerateAndWrite(result.article, outputPath)
+	err := s.generator.GenerateAndWrite(context.Background(), result.article, outputPath)
 	if err != nil {
 		return fmt.Errorf("failed to write EPUB document: %w", err)
 	}
@@ -205,13 +333,23 @@ func (s *Service) WriteToFile(result *ProcessResult, outputPath string) error {
 }
 
 // CreateArticle orchestrates the entire article creation flow:
-// - cleans the URL and generates an article ID
-// - processes the article (extracts content and generates EPUB)
-// - optionally sends the article to Kindle via email
+// - canonicalizes the URL and generates an article ID, validating the tags and collection of opts (ErrInvalidTags)
+// - processes the article with opts, falling back to the account settings (extracts content and generates EPUB)
+// - keeps the tags, collection and reading workflow state of the article when it was already saved, unless opts
+// sets the tags or collection
+// - optionally sends the article to Kindle via email, unless opts.NoSend
 // - enriches the article with delivery metadata
 // - stores the article to the database in the background (if repository is configured)
+// - re-keys the stored article by its final or canonical URL, deleting the pending and legacy ID copies and
+// recording the ID it is stored with as an alias of the ID of the requested URL
+// When fetching is deferred by the site the stored article records when to retry, the returned error wraps
+// content.ErrDeferred.
 // Returns CreateArticleResult with the article and status information.
-func (s *Service) CreateArticle(ctx context.Context, rawURL, accountID string) (*CreateArticleResult, error) {
+func (s *Service) CreateArticle(
+	ctx context.Context,
+	rawURL, accountID string,
+	opts ProcessOptions,
+) (*CreateArticleResult, error) {
 	cleanURL, err := content.CleanURL(rawURL)
 	if err != nil {
 		return nil, fmt.Errorf("failed to clean url: %w", err)
@@ -222,23 +360,72 @@ func (s *Service) CreateArticle(ctx context.Context, rawURL, accountID string) (
 		return nil, fmt.Errorf("failed to generate article id: %w", err)
 	}
 
+	if opts.Tags != nil {
+		tags, tagsErr := ParseTags(opts.Tags)
+		if tagsErr != nil {
+			return nil, tagsErr
+		}
+		// set tags replace the ones of the article saved before, even when none is left
+		opts.Tags = append([]string{}, tags...)
+	}
+	if opts.Collection, err = ParseCollection(opts.Collection); err != nil {
+		return nil, err
+	}
+
+	if opts.LinkMode == "" {
+		settings, settingsErr := s.GetSettings(ctx, accountID)
+		if settingsErr != nil {
+			return nil, settingsErr
+		}
+		opts.LinkMode = settings.LinkMode
+	}
+
+	previous, err := s.savedArticle(ctx, accountID, articleID)
+	if err != nil {
+		return nil, err
+	}
+
+	var storedID string
 	eg, articlesChan := s.startBackgroundDBStore(ctx)
 	defer func() {
 		close(articlesChan)
 		_ = eg.Wait()
+		if storedID == "" {
+			return
+		}
+		if aliasErr := s.storeAlias(ctx, accountID, articleID, storedID); aliasErr != nil {
+			s.dbErrors = errors.Join(s.dbErrors, aliasErr)
+		}
+		if staleErr := s.deleteStaleArticles(ctx, accountID, rawURL, storedID, articleID); staleErr != nil {
+			s.dbErrors = errors.Join(s.dbErrors, staleErr)
+		}
 	}()
 
-	article := &model.Article{
-		Account:   accountID,
-		ID:        articleID,
-		URL:       cleanURL,
-		CreatedAt: time.Now().UTC(),
+	createdAt := time.Now().UTC()
+	if !opts.SavedAt.IsZero() {
+		createdAt = opts.SavedAt.UTC()
 	}
+
+	article := &model.Article{
+		Account:    accountID,
+		ID:         articleID,
+		URL:        cleanURL,
+		Title:      opts.Title,
+		Tags:       opts.Tags,
+		Collection: opts.Collection,
+		CreatedAt:  createdAt,
+	}
+	keepUserFields(article, previous, opts)
+	markUnread(article)
 	articlesChan <- article
 
-	result, err := s.Process(ctx, cleanURL)
+	result, err := s.Process(ctx, cleanURL, opts)
 	if err != nil {
 		article.Error = err.Error()
+		var deferred *content.DeferredError
+		if errors.As(err, &deferred) {
+			article.RetryAt = &deferred.RetryAt
+		}
 		articlesChan <- article
 		return nil, fmt.Errorf("failed to process article: %w", err)
 	}
@@ -251,7 +438,7 @@ func (s *Service) CreateArticle(ctx context.Context, rawURL, accountID string) (
 	}
 
 	var emailResp *email.SendEmailResponse
-	if s.cfg.SendEnabled {
+	if s.cfg.SendEnabled && !opts.NoSend {
 		emailResp, err = s.Send(ctx, result, "")
 		if err != nil {
 			article.Error = err.Error()
@@ -260,7 +447,19 @@ func (s *Service) CreateArticle(ctx context.Context, rawURL, accountID string) (
 		}
 	}
 
-	s.enrichArticle(result.Article(), &articleID, emailResp, accountID)
+	storedID = canonicalArticleID(result.Article(), articleID)
+	// the pending article is stored in the background with articleID, only another ID may have been saved before
+	if storedID != articleID && (previous == nil || previous.ID != storedID) {
+		if stored, storedErr := s.savedArticle(ctx, accountID, storedID); storedErr == nil && stored != nil {
+			previous = stored
+		}
+	}
+	keepUserFields(result.Article(), previous, opts)
+	s.enrichArticle(result.Article(), &storedID, emailResp, accountID)
+	result.Article().CreatedAt = createdAt
+	if s.cfg.SendEnabled && opts.NoSend {
+		result.Article().DeliveryStatus = consts.StatusPending
+	}
 	articlesChan <- result.Article()
 
 	return &CreateArticleResult{
@@ -270,6 +469,88 @@ func (s *Service) CreateArticle(ctx context.Context, rawURL, accountID string) (
 	}, nil
 }
 
+// keepUserFields copies to article the fields set by the account on previous, the same article saved before, if
+// any: its tags and collection, unless set by opts, and its reading workflow state.
+func keepUserFields(article, previous *model.Article, opts ProcessOptions) {
+	if previous == nil {
+		return
+	}
+
+	if opts.Tags == nil {
+		article.Tags = previous.Tags
+	}
+	if opts.Collection == "" {
+		article.Collection = previous.Collection
+	}
+	article.ReadState, article.ReadAt, article.ArchivedAt = previous.ReadState, previous.ReadAt, previous.ArchivedAt
+	article.Favorite, article.FavoritedAt = previous.Favorite, previous.FavoritedAt
+}
+
+// canonicalArticleID returns the ID of article derived from its canonical URL, or its final URL after redirects,
+// falling back to id.
+func canonicalArticleID(article *model.Article, id string) string {
+	articleURL := article.CanonicalURL
+	if articleURL == "" {
+		articleURL = article.URL
+	}
+
+	canonicalID, err := content.ArticleIDFromURL(articleURL)
+	if err != nil {
+		return id
+	}
+
+	return canonicalID
+}
+
+// storeAlias records that the article saved from the URL with ID requestedID is stored with ID storedID, so that
+// it is found from the URL it was saved from, see Service.savedArticle.
+func (s *Service) storeAlias(ctx context.Context, accountID, requestedID, storedID string) error {
+	if s.repo == nil || requestedID == storedID {
+		return nil
+	}
+
+	return s.repo.StoreAlias(ctx, accountID, requestedID, storedID)
+}
+
+// deleteStaleArticles deletes the copies of the article stored with ID storedID that are stored with other IDs:
+// the pending record stored with pendingID, derived from the requested URL, and the record stored with the legacy
+// ID of rawURL, see content.LegacyArticleIDFromURL.
+func (s *Service) deleteStaleArticles(ctx context.Context, accountID, rawURL, storedID, pendingID string) error {
+	if s.repo == nil {
+		return nil
+	}
+
+	var errs error
+	if pendingID != storedID {
+		if err := s.repo.DeleteByAccountAndID(ctx, accountID, pendingID); err != nil {
+			errs = errors.Join(errs, err)
+		}
+	}
+
+	legacyID, err := content.LegacyArticleIDFromURL(rawURL)
+	if err != nil || legacyID == storedID || legacyID == pendingID {
+		return errs
+	}
+
+	legacy, err := s.repo.GetByAccountAndID(ctx, accountID, legacyID)
+	if err != nil {
+		if !errors.Is(err, repository.ErrNotFound) {
+			errs = errors.Join(errs, err)
+		}
+		return errs
+	}
+
+	// legacy records were stored with the query stripped from their URL, e.g. for ?id= or ?p= URLs, they are
+	// recognized by their ID
+	if legacyRecordID, _ := content.LegacyArticleIDFromURL(legacy.URL); legacyRecordID == legacyID {
+		if err = s.repo.DeleteByAccountAndID(ctx, accountID, legacyID); err != nil {
+			errs = errors.Join(errs, err)
+		}
+	}
+
+	return errs
+}
+
 // GetDBError returns any accumulated database errors from background operations.
 func (s *Service) GetDBError() error {
 	return s.dbErrors
@@ -347,6 +628,7 @@ func (s *Service) enrichArticle(
 ) {
 	article.Account = accountID
 	article.ID = *id
+	markUnread(article)
 
 	if !s.cfg.SendEnabled {
 		return
@@ -364,10 +646,13 @@ func (s *Service) enrichArticle(
 	article.DeliveredBy = s.cfg.EmailProvider
 }
 
-func (s *Service) getMessage(_ *model.Article, _ *email.SendEmailResponse) string {
+func (s *Service) getMessage(article *model.Article, _ *email.SendEmailResponse) string {
 	if !s.cfg.SendEnabled {
 		return "article processed successfully (email sending disabled)"
 	}
+	if article.DeliveryStatus == consts.StatusPending {
+		return "article processed successfully (delivery pending)"
+	}
 	return "article sent to Kindle successfully"
 }
 
@@ -393,12 +678,18 @@ func (s *Service) GetArticle(ctx context.Context, accountID, articleID string) (
 	return article, nil
 }
 
+// ErrSortUnavailable is returned when articles are listed in an order whose index isn't deployed yet, see
+// config.Config.ArticleIndexes.
+var ErrSortUnavailable = errors.New("sort not available yet")
+
 // GetArticlesMetadata retrieves article metadata for a given account with pagination.
+// filter selects and sorts the articles, all of them newest first when nil.
 // page starts at 1, pageSize limits the number of articles returned.
 // Content field is excluded from returned articles.
 func (s *Service) GetArticlesMetadata(
 	ctx context.Context,
 	accountID string,
+	filter *model.ArticleFilter,
 	page, pageSize int,
 ) (*GetArticlesResult, error) {
 	if s.repo == nil {
@@ -411,7 +702,10 @@ func (s *Service) GetArticlesMetadata(
 		}, nil
 	}
 
-	articles, lastEvaluatedKey, total, err := s.repo.GetMetadataByAccount(ctx, accountID, page, pageSize)
+	articles, lastEvaluatedKey, total, err := s.repo.GetMetadataByAccount(ctx, accountID, filter, page, pageSize)
+	if errors.Is(err, repository.ErrIndexUnavailable) {
+		return nil, fmt.Errorf("%w: %w", ErrSortUnavailable, err)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to get articles: %w", err)
 	}
@@ -428,3 +722,46 @@ func (s *Service) GetArticlesMetadata(
 		HasMore:  lastEvaluatedKey != nil,
 	}, nil
 }
+
+// GetSettings retrieves the settings for a given account.
+// Defaults are returned if the account has no stored settings or no repository is configured.
+func (s *Service) GetSettings(ctx context.Context, accountID string) (*model.Settings, error) {
+	defaults := &model.Settings{Account: accountID, LinkMode: consts.LinkModeKeep}
+
+	if s.repo == nil {
+		return defaults, nil
+	}
+
+	settings, err := s.repo.GetSettings(ctx, accountID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return defaults, nil
+		}
+		return nil, fmt.Errorf("failed to get settings: %w", err)
+	}
+
+	if settings.LinkMode == "" {
+		settings.LinkMode = defaults.LinkMode
+	}
+
+	return settings, nil
+}
+
+// UpdateSettings validates and stores the settings for an account.
+func (s *Service) UpdateSettings(ctx context.Context, settings *model.Settings) (*model.Settings, error) {
+	if s.repo == nil {
+		return nil, errors.New("repository not configured")
+	}
+
+	linkMode, err := transform.ParseLinkMode(string(settings.LinkMode))
+	if err != nil {
+		return nil, err
+	}
+	settings.LinkMode = linkMode
+
+	if err = s.repo.StoreSettings(ctx, settings); err != nil {
+		return nil, fmt.Errorf("failed to update settings: %w", err)
+	}
+
+	return settings, nil
+}
@@ -2,27 +2,56 @@ package service
 
 import (
 	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"slices"
 	"sort"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
 	"github.com/shaftoe/savetoink/internal/config"
 	"github.com/shaftoe/savetoink/internal/consts"
+	"github.com/shaftoe/savetoink/internal/content"
+	"github.com/shaftoe/savetoink/internal/content/cache"
+	"github.com/shaftoe/savetoink/internal/epub"
 	"github.com/shaftoe/savetoink/internal/model"
 	"github.com/shaftoe/savetoink/internal/repository"
+	"github.com/shaftoe/savetoink/internal/search"
 )
 
+// MockRepository is a Repository in memory, safe for concurrent use by the background store of CreateArticle.
 type MockRepository struct {
+	mu       sync.Mutex
 	articles []*model.Article
+	settings map[string]*model.Settings
+	feeds    []*model.Feed
+	imports  []*model.ImportJob
+	aliases  map[[2]string]string
 }
 
 func (m *MockRepository) Store(_ context.Context, article *model.Article) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i, stored := range m.articles {
+		if stored.Account == article.Account && stored.ID == article.ID {
+			m.articles[i] = article
+			return nil
+		}
+	}
 	m.articles = append(m.articles, article)
 	return nil
 }
 
 func (m *MockRepository) GetByAccountAndID(_ context.Context, account, id string) (*model.Article, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
 	for _, article := range m.articles {
 		if article.Account == account && article.ID == id {
 			return article, nil
@@ -31,23 +60,58 @@ func (m *MockRepository) GetByAccountAndID(_ context.Context, account, id string
 	return nil, repository.ErrNotFound
 }
 
+func (m *MockRepository) UpdateReadingState(_ context.Context, article *model.Article) (*model.Article, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, stored := range m.articles {
+		if stored.Account == article.Account && stored.ID == article.ID {
+			stored.ReadState, stored.ReadAt, stored.ArchivedAt = article.ReadState, article.ReadAt, article.ArchivedAt
+			stored.Favorite, stored.FavoritedAt = article.Favorite, article.FavoritedAt
+			return stored, nil
+		}
+	}
+	return nil, repository.ErrNotFound
+}
+
+func (m *MockRepository) UpdateDelivery(_ context.Context, article *model.Article) (*model.Article, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, stored := range m.articles {
+		if stored.Account == article.Account && stored.ID == article.ID {
+			stored.DeliveryStatus, stored.DeliveredFrom, stored.DeliveredTo = article.DeliveryStatus,
+				article.DeliveredFrom, article.DeliveredTo
+			stored.DeliveredEmailUUID, stored.DeliveredBy = article.DeliveredEmailUUID, article.DeliveredBy
+			return stored, nil
+		}
+	}
+	return nil, repository.ErrNotFound
+}
+
 func (m *MockRepository) GetMetadataByAccount(
 	_ context.Context,
 	account string,
+	filter *model.ArticleFilter,
 	page, pageSize int,
 ) (articles []*model.Article, lastEvaluatedKey map[string]types.AttributeValue, total int, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if filter == nil {
+		filter = &model.ArticleFilter{}
+	}
+
 	var result []*model.Article
 	for _, article := range m.articles {
-		if article.Account == account {
+		if article.Account == account && matchesFilter(article, filter) {
 			articleCopy := *article
 			articleCopy.Content = ""
 			result = append(result, &articleCopy)
 		}
 	}
 
-	sort.Slice(result, func(i, j int) bool {
-		return result[i].CreatedAt.After(result[j].CreatedAt)
-	})
+	sortArticles(result, filter)
 
 	total = len(result)
 	skip := max((page-1)*pageSize, 0)
@@ -66,7 +130,166 @@ func (m *MockRepository) GetMetadataByAccount(
 	return result[skip:end], lastEvaluatedKey, total, nil
 }
 
+// matchesFilter selects articles like the DynamoDB repository: articles without the attribute of the sort are
+// not in its index.
+func matchesFilter(article *model.Article, filter *model.ArticleFilter) bool {
+	inRange := func(t, from, to time.Time) bool {
+		return (from.IsZero() || !t.Before(from)) && (to.IsZero() || !t.After(to))
+	}
+
+	switch {
+	case filter.Sort == consts.SortPublished && article.PublishedAt == nil,
+		filter.Sort == consts.SortReadingTime && article.ReadingTimeMinutes == 0,
+		filter.DeliveryStatus != "" && article.DeliveryStatus != filter.DeliveryStatus,
+		filter.SourceDomain != "" && strings.TrimPrefix(article.SourceDomain, "www.") != filter.SourceDomain,
+		filter.Language != "" && article.Language != filter.Language &&
+			!strings.HasPrefix(article.Language, filter.Language+"-"),
+		filter.ContentType != "" && article.ContentType != filter.ContentType,
+		len(filter.ReadStates) > 0 && !slices.Contains(filter.ReadStates, article.ReadState),
+		filter.Favorite != nil && *filter.Favorite != article.Favorite,
+		filter.Tag != "" && !slices.Contains(article.Tags, filter.Tag),
+		filter.Collection != "" && article.Collection != filter.Collection,
+		!inRange(article.CreatedAt, filter.CreatedFrom, filter.CreatedTo),
+		filter.MinReadingTime != 0 && article.ReadingTimeMinutes < filter.MinReadingTime,
+		filter.MaxReadingTime != 0 && article.ReadingTimeMinutes > filter.MaxReadingTime:
+		return false
+	}
+
+	if !filter.PublishedFrom.IsZero() || !filter.PublishedTo.IsZero() {
+		return article.PublishedAt != nil && inRange(*article.PublishedAt, filter.PublishedFrom, filter.PublishedTo)
+	}
+	return true
+}
+
+func sortArticles(articles []*model.Article, filter *model.ArticleFilter) {
+	less := func(i, j int) bool {
+		switch filter.Sort {
+		case consts.SortPublished:
+			return articles[i].PublishedAt.Before(*articles[j].PublishedAt)
+		case consts.SortReadingTime:
+			return articles[i].ReadingTimeMinutes < articles[j].ReadingTimeMinutes
+		default:
+			return articles[i].CreatedAt.Before(articles[j].CreatedAt)
+		}
+	}
+
+	if filter.Order == consts.SortOrderAsc {
+		sort.SliceStable(articles, less)
+	} else {
+		sort.SliceStable(articles, func(i, j int) bool { return less(j, i) })
+	}
+}
+
+func (m *MockRepository) EachByAccount(_ context.Context, account string, fn func(*model.Article) error) error {
+	m.mu.Lock()
+	articles := slices.Clone(m.articles)
+	m.mu.Unlock()
+
+	for _, article := range articles {
+		if article.Account != account {
+			continue
+		}
+		if err := fn(article); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *MockRepository) Search(
+	ctx context.Context,
+	account string,
+	query *search.Query,
+	page, pageSize int,
+) ([]*model.Article, int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	index := search.NewMemoryIndex()
+	byID := make(map[string]*model.Article)
+	for _, article := range m.articles {
+		if article.Account != account {
+			continue
+		}
+		if err := index.Add(ctx, search.DocumentOf(article)); err != nil {
+			return nil, 0, err
+		}
+		metadata := *article
+		metadata.Content = ""
+		byID[article.ID] = &metadata
+	}
+
+	results, err := index.Search(ctx, account, query)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	articles := []*model.Article{}
+	for i := (page - 1) * pageSize; i < min(page*pageSize, len(results)); i++ {
+		articles = append(articles, byID[results[i].ID])
+	}
+	return articles, len(results), nil
+}
+
+func (m *MockRepository) GetTags(_ context.Context, account string) (*model.Tags, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	tags, collections := map[string]int{}, map[string]int{}
+	for _, article := range m.articles {
+		if article.Account != account {
+			continue
+		}
+		for _, tag := range article.Tags {
+			tags[tag]++
+		}
+		if article.Collection != "" {
+			collections[article.Collection]++
+		}
+	}
+
+	counts := func(names map[string]int) []*model.TagCount {
+		result := []*model.TagCount{}
+		for name, count := range names {
+			result = append(result, &model.TagCount{Name: name, Count: count})
+		}
+		sort.Slice(result, func(i, j int) bool {
+			if result[i].Count != result[j].Count {
+				return result[i].Count > result[j].Count
+			}
+			return result[i].Name < result[j].Name
+		})
+		return result
+	}
+
+	return &model.Tags{Tags: counts(tags), Collections: counts(collections)}, nil
+}
+
+func (m *MockRepository) StoreAlias(_ context.Context, account, id, articleID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.aliases == nil {
+		m.aliases = make(map[[2]string]string)
+	}
+	m.aliases[[2]string{account, id}] = articleID
+	return nil
+}
+
+func (m *MockRepository) GetAlias(_ context.Context, account, id string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if articleID, ok := m.aliases[[2]string{account, id}]; ok {
+		return articleID, nil
+	}
+	return "", repository.ErrNotFound
+}
+
 func (m *MockRepository) DeleteByAccountAndID(_ context.Context, account, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
 	for i, article := range m.articles {
 		if article.Account == account && article.ID == id {
 			m.articles = append(m.articles[:i], m.articles[i+1:]...)
@@ -77,6 +300,9 @@ func (m *MockRepository) DeleteByAccountAndID(_ context.Context, account, id str
 }
 
 func (m *MockRepository) DeleteByAccount(_ context.Context, account string) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
 	initialLen := len(m.articles)
 	var filtered []*model.Article
 	for _, article := range m.articles {
@@ -88,6 +314,112 @@ func (m *MockRepository) DeleteByAccount(_ context.Context, account string) (int
 	return initialLen - len(m.articles), nil
 }
 
+func (m *MockRepository) GetSettings(_ context.Context, account string) (*model.Settings, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if settings, ok := m.settings[account]; ok {
+		return settings, nil
+	}
+	return nil, repository.ErrNotFound
+}
+
+func (m *MockRepository) StoreSettings(_ context.Context, settings *model.Settings) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.settings == nil {
+		m.settings = make(map[string]*model.Settings)
+	}
+	m.settings[settings.Account] = settings
+	return nil
+}
+
+func (m *MockRepository) StoreFeed(_ context.Context, feed *model.Feed) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i, stored := range m.feeds {
+		if stored.Account == feed.Account && stored.ID == feed.ID {
+			m.feeds[i] = feed
+			return nil
+		}
+	}
+	m.feeds = append(m.feeds, feed)
+	return nil
+}
+
+func (m *MockRepository) GetFeed(_ context.Context, account, id string) (*model.Feed, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, feed := range m.feeds {
+		if feed.Account == account && feed.ID == id {
+			return feed, nil
+		}
+	}
+	return nil, repository.ErrNotFound
+}
+
+func (m *MockRepository) GetFeedsByAccount(_ context.Context, account string) ([]*model.Feed, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	feeds := []*model.Feed{}
+	for _, feed := range m.feeds {
+		if feed.Account == account {
+			feeds = append(feeds, feed)
+		}
+	}
+	return feeds, nil
+}
+
+func (m *MockRepository) GetAllFeeds(_ context.Context) ([]*model.Feed, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.feeds, nil
+}
+
+func (m *MockRepository) DeleteFeed(_ context.Context, account, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i, feed := range m.feeds {
+		if feed.Account == account && feed.ID == id {
+			m.feeds = append(m.feeds[:i], m.feeds[i+1:]...)
+			return nil
+		}
+	}
+	return nil
+}
+
+func (m *MockRepository) StoreImportJob(_ context.Context, job *model.ImportJob) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i, stored := range m.imports {
+		if stored.Account == job.Account && stored.ID == job.ID {
+			m.imports[i] = job
+			return nil
+		}
+	}
+	m.imports = append(m.imports, job)
+	return nil
+}
+
+func (m *MockRepository) GetImportJob(_ context.Context, account, id string) (*model.ImportJob, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, job := range m.imports {
+		if job.Account == account && job.ID == id {
+			return job, nil
+		}
+	}
+	return nil, repository.ErrNotFound
+}
+
 func TestGetArticlesMetadata(t *testing.T) {
 	now := time.Now()
 	articles := []*model.Article{
@@ -175,7 +507,7 @@ func TestGetArticlesMetadata(t *testing.T) {
 			mockRepo := &MockRepository{articles: articles}
 			svc := &Service{repo: mockRepo}
 
-			result, err := svc.GetArticlesMetadata(context.Background(), tt.accountID, tt.page, tt.pageSize)
+			result, err := svc.GetArticlesMetadata(context.Background(), tt.accountID, nil, tt.page, tt.pageSize)
 
 			if err != nil {
 				t.Fatalf("unexpected error: %v", err)
@@ -207,7 +539,7 @@ func TestGetArticlesMetadata(t *testing.T) {
 func TestGetArticlesMetadataWithNilRepo(t *testing.T) {
 	svc := &Service{repo: nil}
 
-	result, err := svc.GetArticlesMetadata(context.Background(), "user1", 1, 10)
+	result, err := svc.GetArticlesMetadata(context.Background(), "user1", nil, 1, 10)
 
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
@@ -300,7 +632,7 @@ func TestGetArticlesMetadataWithDeliveryStatus(t *testing.T) {
 	mockRepo := &MockRepository{articles: articles}
 	svc := &Service{repo: mockRepo}
 
-	result, err := svc.GetArticlesMetadata(context.Background(), "user1", 1, 10)
+	result, err := svc.GetArticlesMetadata(context.Background(), "user1", nil, 1, 10)
 
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
@@ -323,6 +655,45 @@ func TestGetArticlesMetadataWithDeliveryStatus(t *testing.T) {
 	}
 }
 
+func TestGetArticlesMetadataWithFilter(t *testing.T) {
+	now := time.Now()
+	articles := []*model.Article{
+		{Account: "user1", ID: "1", CreatedAt: now.Add(-2 * time.Hour), ReadingTimeMinutes: 12,
+			DeliveryStatus: consts.StatusDelivered},
+		{Account: "user1", ID: "2", CreatedAt: now.Add(-1 * time.Hour), ReadingTimeMinutes: 3,
+			DeliveryStatus: consts.StatusDelivered},
+		{Account: "user1", ID: "3", CreatedAt: now, ReadingTimeMinutes: 7, DeliveryStatus: consts.StatusDelivered},
+		{Account: "user1", ID: "4", CreatedAt: now, ReadingTimeMinutes: 5, DeliveryStatus: consts.StatusFailed},
+	}
+
+	mockRepo := &MockRepository{articles: articles}
+	svc := &Service{repo: mockRepo}
+
+	filter := &model.ArticleFilter{
+		DeliveryStatus: consts.StatusDelivered,
+		MinReadingTime: 5,
+		Sort:           consts.SortReadingTime,
+		Order:          consts.SortOrderAsc,
+	}
+	result, err := svc.GetArticlesMetadata(context.Background(), "user1", filter, 1, 1)
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if result.Total != 2 {
+		t.Errorf("expected total 2, got %d", result.Total)
+	}
+
+	if !result.HasMore {
+		t.Errorf("expected has_more true, got false")
+	}
+
+	if len(result.Articles) != 1 || result.Articles[0].ID != "3" {
+		t.Errorf("expected the shortest delivered article 3, got %v", result.Articles)
+	}
+}
+
 func TestDeleteArticle_Success(t *testing.T) {
 	mockRepo := &MockRepository{
 		articles: []*model.Article{
@@ -503,3 +874,369 @@ func TestWriteToFile_EmptyPath(t *testing.T) {
 		t.Error("expected error for empty path, got nil")
 	}
 }
+
+func TestGetSettings_Defaults(t *testing.T) {
+	svc := &Service{repo: &MockRepository{}}
+
+	settings, err := svc.GetSettings(context.Background(), "user1")
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if settings.Account != "user1" || settings.LinkMode != consts.LinkModeKeep {
+		t.Errorf("expected default settings for user1, got %+v", settings)
+	}
+}
+
+func TestUpdateSettings(t *testing.T) {
+	mockRepo := &MockRepository{}
+	svc := &Service{repo: mockRepo}
+
+	_, err := svc.UpdateSettings(context.Background(), &model.Settings{Account: "user1", LinkMode: consts.LinkModeStrip})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	settings, err := svc.GetSettings(context.Background(), "user1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if settings.LinkMode != consts.LinkModeStrip {
+		t.Errorf("expected link mode %q, got %q", consts.LinkModeStrip, settings.LinkMode)
+	}
+}
+
+func TestUpdateSettings_InvalidLinkMode(t *testing.T) {
+	svc := &Service{repo: &MockRepository{}}
+
+	_, err := svc.UpdateSettings(context.Background(), &model.Settings{Account: "user1", LinkMode: "bogus"})
+
+	if err == nil {
+		t.Error("expected error for invalid link mode, got nil")
+	}
+}
+
+func TestUpdateSettings_NoRepo(t *testing.T) {
+	svc := &Service{repo: nil}
+
+	_, err := svc.UpdateSettings(context.Background(), &model.Settings{Account: "user1"})
+
+	if err == nil {
+		t.Error("expected error with no repo, got nil")
+	}
+}
+
+func TestCanonicalArticleID(t *testing.T) {
+	canonicalID, _ := content.ArticleIDFromURL("https://example.com/article")
+	finalID, _ := content.ArticleIDFromURL("https://example.com/amp/article")
+
+	tests := []struct {
+		name    string
+		article *model.Article
+		want    string
+	}{
+		{"canonical URL", &model.Article{URL: "https://example.com/amp/article", CanonicalURL: "https://example.com/article"},
+			canonicalID},
+		{"final URL", &model.Article{URL: "https://example.com/amp/article?utm_source=x"}, finalID},
+		{"invalid URL", &model.Article{URL: "not-a-url"}, "fallback"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := canonicalArticleID(tt.article, "fallback"); got != tt.want {
+				t.Errorf("canonicalArticleID() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDeleteStaleArticles(t *testing.T) {
+	legacyID, _ := content.LegacyArticleIDFromURL("https://Example.com/article")
+	storedID, _ := content.ArticleIDFromURL("https://example.com/article")
+	itemLegacyID, _ := content.LegacyArticleIDFromURL("https://news.ycombinator.com/item?id=1")
+	itemID, _ := content.ArticleIDFromURL("https://news.ycombinator.com/item?id=1")
+	otherID, _ := content.ArticleIDFromURL("https://example.com/other")
+
+	mockRepo := &MockRepository{
+		articles: []*model.Article{
+			{Account: "user1", ID: "pending", URL: "https://example.com/short"},
+			{Account: "user1", ID: legacyID, URL: "https://Example.com/article"},
+			{Account: "user1", ID: storedID, URL: "https://example.com/article"},
+			// legacy records were stored with the query stripped from their URL
+			{Account: "user1", ID: itemLegacyID, URL: "https://news.ycombinator.com/item"},
+			{Account: "user1", ID: itemID, URL: "https://news.ycombinator.com/item?id=1"},
+			{Account: "user1", ID: otherID, URL: "https://example.com/other"},
+		},
+	}
+	svc := &Service{repo: mockRepo}
+
+	err := svc.deleteStaleArticles(context.Background(), "user1", "https://Example.com/article", storedID, "pending")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	err = svc.deleteStaleArticles(context.Background(), "user1", "https://news.ycombinator.com/item?id=1&utm_source=x",
+		itemID, itemID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var ids []string
+	for _, article := range mockRepo.articles {
+		ids = append(ids, article.ID)
+	}
+	if len(ids) != 3 || ids[0] != storedID || ids[1] != itemID || ids[2] != otherID {
+		t.Errorf("expected the pending and legacy articles to be deleted, got %v", ids)
+	}
+}
+
+func TestCreateArticleCanonicalURL(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write([]byte(`<html><head><title>Canonical</title><link rel="canonical" href="/article"></head>` +
+			`<body><article><h1>Canonical</h1><p>This article is served on several URLs but has a single ` +
+			`canonical one, declared in its head.</p></article></body></html>`))
+	}))
+	defer server.Close()
+
+	mockRepo := &MockRepository{}
+	svc := &Service{
+		extractor: content.NewExtractor(),
+		generator: epub.NewGenerator(),
+		repo:      mockRepo,
+		cfg:       &config.Config{},
+	}
+
+	result, err := svc.CreateArticle(context.Background(), server.URL+"/amp/article?utm_source=feed", "user1",
+		ProcessOptions{LinkMode: consts.LinkModeKeep})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	canonicalID, _ := content.ArticleIDFromURL(server.URL + "/article")
+	if result.Article.ID != canonicalID {
+		t.Errorf("expected ID of canonical URL %s, got %s", canonicalID, result.Article.ID)
+	}
+
+	if len(mockRepo.articles) != 1 || mockRepo.articles[0].ID != canonicalID {
+		t.Errorf("expected only the article stored with the canonical ID, got %d articles", len(mockRepo.articles))
+	}
+}
+
+func TestCreateArticleDeferred(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Retry-After", "60")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	mockRepo := &MockRepository{}
+	svc := &Service{
+		extractor: content.NewExtractor(),
+		generator: epub.NewGenerator(),
+		repo:      mockRepo,
+		cfg:       &config.Config{},
+	}
+
+	_, err := svc.CreateArticle(context.Background(), server.URL+"/article", "user1",
+		ProcessOptions{LinkMode: consts.LinkModeKeep})
+	if !errors.Is(err, content.ErrDeferred) {
+		t.Fatalf("expected deferred error, got %v", err)
+	}
+
+	if len(mockRepo.articles) == 0 {
+		t.Fatal("expected the article to be stored")
+	}
+	stored := mockRepo.articles[len(mockRepo.articles)-1]
+	if stored.RetryAt == nil {
+		t.Fatal("expected the article stored with a retry time")
+	}
+	if until := time.Until(*stored.RetryAt); until <= 0 || until > time.Minute {
+		t.Errorf("expected retry within a minute, got %v", until)
+	}
+}
+
+// stubExtractor extracts a copy of article from any URL, without fetching it.
+type stubExtractor struct {
+	article *model.Article
+	err     error
+}
+
+func (e *stubExtractor) ExtractFromURL(_ context.Context, urlStr string) (*model.Article, error) {
+	if e.err != nil {
+		return nil, e.err
+	}
+	article := *e.article
+	article.URL = urlStr
+	return &article, nil
+}
+
+func (e *stubExtractor) ExtractFromHTML(_ []byte, urlStr string) (*model.Article, error) {
+	return e.ExtractFromURL(context.Background(), urlStr)
+}
+
+func (e *stubExtractor) FetchFeed(_ context.Context, _, _, _ string) (*content.FeedResponse, error) {
+	return nil, errors.New("not a feed")
+}
+
+func TestCreateArticleWithExtractor(t *testing.T) {
+	tests := []struct {
+		name          string
+		extractor     *stubExtractor
+		expectedTitle string
+		expectedError string
+	}{
+		{name: "extracted", extractor: &stubExtractor{article: &model.Article{
+			Title: "Stubbed", Content: "<p>Stubbed content.</p>", WordCount: 2,
+		}}, expectedTitle: "Stubbed"},
+		{name: "extraction failed", extractor: &stubExtractor{err: errors.New("extraction failed")},
+			expectedError: "failed to extract article: extraction failed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := &MockRepository{}
+			svc := &Service{
+				extractor: tt.extractor,
+				generator: epub.NewGenerator(),
+				repo:      mockRepo,
+				cfg:       &config.Config{},
+			}
+
+			_, err := svc.CreateArticle(context.Background(), "https://example.com/stubbed", "user1",
+				ProcessOptions{LinkMode: consts.LinkModeKeep})
+			if (err != nil) != (tt.expectedError != "") {
+				t.Fatalf("expected error %q, got %v", tt.expectedError, err)
+			}
+
+			if len(mockRepo.articles) == 0 {
+				t.Fatal("expected the article to be stored")
+			}
+			stored := mockRepo.articles[len(mockRepo.articles)-1]
+			if stored.Title != tt.expectedTitle || stored.Error != tt.expectedError {
+				t.Errorf("expected stored title %q and error %q, got %q and %q",
+					tt.expectedTitle, tt.expectedError, stored.Title, stored.Error)
+			}
+		})
+	}
+}
+
+func TestCreateArticleInvalidTags(t *testing.T) {
+	tests := []struct {
+		name string
+		opts ProcessOptions
+	}{
+		{name: "long tag", opts: ProcessOptions{Tags: []string{strings.Repeat("t", consts.TagMaxLength+1)}}},
+		{name: "too many tags", opts: ProcessOptions{
+			Tags: strings.Split("abcdefghijklmnopqrstuvwxyz0123456789"[:consts.TagMaxCount+1], "")}},
+		{name: "long collection", opts: ProcessOptions{Collection: strings.Repeat("c", consts.TagMaxLength+1)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := &MockRepository{}
+			svc := &Service{
+				extractor: &stubExtractor{article: &model.Article{Title: "Tagged", Content: "<p>Tagged.</p>"}},
+				generator: epub.NewGenerator(),
+				repo:      mockRepo,
+				cfg:       &config.Config{},
+			}
+
+			tt.opts.LinkMode = consts.LinkModeKeep
+			_, err := svc.CreateArticle(context.Background(), "https://example.com/tagged", "user1", tt.opts)
+			if !errors.Is(err, ErrInvalidTags) {
+				t.Fatalf("expected ErrInvalidTags, got %v", err)
+			}
+			if len(mockRepo.articles) != 0 {
+				t.Errorf("expected no stored article, got %d", len(mockRepo.articles))
+			}
+		})
+	}
+}
+
+func TestCreateArticleKeepsUserFields(t *testing.T) {
+	const rawURL = "https://example.com/saved"
+
+	tests := []struct {
+		name               string
+		opts               ProcessOptions
+		expectedTags       []string
+		expectedCollection string
+	}{
+		{name: "saved again", opts: ProcessOptions{LinkMode: consts.LinkModeKeep},
+			expectedTags: []string{"go"}, expectedCollection: "reading"},
+		{name: "saved again with tags and collection",
+			opts:         ProcessOptions{LinkMode: consts.LinkModeKeep, Tags: []string{"rust"}, Collection: "later"},
+			expectedTags: []string{"rust"}, expectedCollection: "later"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			articleID, err := content.ArticleIDFromURL(rawURL)
+			if err != nil {
+				t.Fatalf("failed to get article ID: %v", err)
+			}
+			mockRepo := &MockRepository{articles: []*model.Article{{
+				ID: articleID, Account: "user1", URL: rawURL, Title: "Saved",
+				Tags: []string{"go"}, Collection: "reading", ReadState: consts.ReadStateRead, Favorite: true,
+			}}}
+			svc := &Service{
+				extractor: &stubExtractor{article: &model.Article{
+					Title: "Saved", Content: "<p>Saved content.</p>", WordCount: 2,
+				}},
+				generator: epub.NewGenerator(),
+				repo:      mockRepo,
+				cfg:       &config.Config{},
+			}
+
+			if _, err := svc.CreateArticle(context.Background(), rawURL, "user1", tt.opts); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			stored, err := mockRepo.GetByAccountAndID(context.Background(), "user1", articleID)
+			if err != nil {
+				t.Fatalf("expected the article to be stored: %v", err)
+			}
+			if !reflect.DeepEqual(stored.Tags, tt.expectedTags) || stored.Collection != tt.expectedCollection {
+				t.Errorf("expected tags %v and collection %q, got %v and %q",
+					tt.expectedTags, tt.expectedCollection, stored.Tags, stored.Collection)
+			}
+			if stored.ReadState != consts.ReadStateRead || !stored.Favorite {
+				t.Errorf("expected the read favorite article, got read state %q and favorite %v",
+					stored.ReadState, stored.Favorite)
+			}
+		})
+	}
+}
+
+func TestNewFetchCache(t *testing.T) {
+	dir := t.TempDir()
+
+	tests := []struct {
+		name string
+		cfg  *config.Config
+		want any
+	}{
+		{name: "default", cfg: &config.Config{}, want: &cache.Memory{}},
+		{name: "memory", cfg: &config.Config{FetchCache: consts.CacheMemory, FetchCacheSize: 1024}, want: &cache.Memory{}},
+		{name: "disk", cfg: &config.Config{FetchCache: consts.CacheDisk, FetchCacheDir: dir}, want: &cache.Disk{}},
+		{name: "none", cfg: &config.Config{FetchCache: consts.CacheNone}, want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := newFetchCache(tt.cfg)
+			if tt.want == nil {
+				if got != nil {
+					t.Errorf("expected no cache, got %T", got)
+				}
+				return
+			}
+			if reflect.TypeOf(got) != reflect.TypeOf(tt.want) {
+				t.Errorf("expected %T, got %T", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,110 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/shaftoe/savetoink/internal/consts"
+	"github.com/shaftoe/savetoink/internal/model"
+	"github.com/shaftoe/savetoink/internal/repository"
+)
+
+var (
+	// ErrArticleNotFound is returned when an account hasn't saved an article.
+	ErrArticleNotFound = errors.New("article not found")
+	// ErrInvalidTags is returned for too many or too long tags or collection names.
+	ErrInvalidTags = errors.New("invalid tags")
+)
+
+// ParseTags trims and lowercases tags, dropping empty ones and duplicates. Returns ErrInvalidTags for more than
+// consts.TagMaxCount tags or tags longer than consts.TagMaxLength characters.
+func ParseTags(tags []string) ([]string, error) {
+	var parsed []string
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || slices.Contains(parsed, tag) {
+			continue
+		}
+		if utf8.RuneCountInString(tag) > consts.TagMaxLength {
+			return nil, fmt.Errorf("%w: tag %q is longer than %d characters", ErrInvalidTags, tag, consts.TagMaxLength)
+		}
+		parsed = append(parsed, tag)
+	}
+
+	if len(parsed) > consts.TagMaxCount {
+		return nil, fmt.Errorf("%w: more than %d tags", ErrInvalidTags, consts.TagMaxCount)
+	}
+
+	return parsed, nil
+}
+
+// ParseCollection trims the name of a collection, empty for no collection. Returns ErrInvalidTags for names longer
+// than consts.TagMaxLength characters.
+func ParseCollection(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if utf8.RuneCountInString(name) > consts.TagMaxLength {
+		return "", fmt.Errorf("%w: collection %q is longer than %d characters", ErrInvalidTags, name,
+			consts.TagMaxLength)
+	}
+	return name, nil
+}
+
+// SetTags replaces the tags and collection of an article, removing it from its collection when collection is
+// empty. Returns the article without content, ErrArticleNotFound when the account hasn't saved it and
+// ErrInvalidTags for invalid tags.
+func (s *Service) SetTags(
+	ctx context.Context,
+	accountID, articleID string,
+	tags []string,
+	collection string,
+) (*model.Article, error) {
+	tags, err := ParseTags(tags)
+	if err != nil {
+		return nil, err
+	}
+
+	collection, err = ParseCollection(collection)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.repo == nil {
+		return nil, errors.New("repository not configured")
+	}
+
+	article, err := s.repo.GetByAccountAndID(ctx, accountID, articleID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrArticleNotFound
+		}
+		return nil, fmt.Errorf("failed to get article: %w", err)
+	}
+
+	article.Tags = tags
+	article.Collection = collection
+	if err = s.repo.Store(ctx, article); err != nil {
+		return nil, fmt.Errorf("failed to store article: %w", err)
+	}
+
+	metadata := *article
+	metadata.Content = ""
+	return &metadata, nil
+}
+
+// GetTags returns the tags and collections of an account with their number of articles, the largest first.
+func (s *Service) GetTags(ctx context.Context, accountID string) (*model.Tags, error) {
+	if s.repo == nil {
+		return &model.Tags{Tags: []*model.TagCount{}, Collections: []*model.TagCount{}}, nil
+	}
+
+	tags, err := s.repo.GetTags(ctx, accountID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tags: %w", err)
+	}
+
+	return tags, nil
+}
@@ -0,0 +1,92 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shaftoe/savetoink/internal/consts"
+	"github.com/shaftoe/savetoink/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTags(t *testing.T) {
+	tests := []struct {
+		name        string
+		tags        []string
+		expected    []string
+		expectedErr error
+	}{
+		{name: "normalized", tags: []string{" Go ", "go", "", "Reading List"}, expected: []string{"go", "reading list"}},
+		{name: "none", tags: nil, expected: nil},
+		{name: "too long", tags: []string{strings.Repeat("a", consts.TagMaxLength+1)}, expectedErr: ErrInvalidTags},
+		{name: "duplicates counted once", tags: strings.Split(strings.Repeat("a,b,c,d,e,f,g,h,", 5), ","),
+			expected: []string{"a", "b", "c", "d", "e", "f", "g", "h"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tags, err := ParseTags(tt.tags)
+			require.ErrorIs(t, err, tt.expectedErr)
+			assert.Equal(t, tt.expected, tags)
+		})
+	}
+
+	many := make([]string, consts.TagMaxCount+1)
+	for i := range many {
+		many[i] = strings.Repeat("x", i+1)
+	}
+	_, err := ParseTags(many)
+	require.ErrorIs(t, err, ErrInvalidTags)
+}
+
+func TestSetTags(t *testing.T) {
+	mockRepo := &MockRepository{articles: []*model.Article{
+		{Account: "user1", ID: "1", Content: "<p>content</p>", CreatedAt: time.Now(), Tags: []string{"old"}},
+		{Account: "user1", ID: "2", CreatedAt: time.Now().Add(-time.Hour), Tags: []string{"go"}},
+	}}
+	svc := newFeedService(mockRepo, nil)
+	ctx := context.Background()
+
+	article, err := svc.SetTags(ctx, "user1", "1", []string{"Go", "Errors"}, " Reading list ")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"go", "errors"}, article.Tags)
+	assert.Equal(t, "Reading list", article.Collection)
+	assert.Empty(t, article.Content, "the article is returned without content")
+
+	stored, err := mockRepo.GetByAccountAndID(ctx, "user1", "1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"go", "errors"}, stored.Tags)
+	assert.NotEmpty(t, stored.Content)
+
+	result, err := svc.GetArticlesMetadata(ctx, "user1", &model.ArticleFilter{Tag: "go"}, 1, 20)
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.Total)
+
+	result, err = svc.GetArticlesMetadata(ctx, "user1", &model.ArticleFilter{Collection: "Reading list"}, 1, 20)
+	require.NoError(t, err)
+	require.Len(t, result.Articles, 1)
+	assert.Equal(t, "1", result.Articles[0].ID)
+
+	tags, err := svc.GetTags(ctx, "user1")
+	require.NoError(t, err)
+	assert.Equal(t, []*model.TagCount{{Name: "go", Count: 2}, {Name: "errors", Count: 1}}, tags.Tags)
+	assert.Equal(t, []*model.TagCount{{Name: "Reading list", Count: 1}}, tags.Collections)
+
+	_, err = svc.SetTags(ctx, "user1", "missing", []string{"go"}, "")
+	require.ErrorIs(t, err, ErrArticleNotFound)
+
+	_, err = svc.SetTags(ctx, "user1", "1", nil, strings.Repeat("c", consts.TagMaxLength+1))
+	require.ErrorIs(t, err, ErrInvalidTags)
+}
+
+func TestGetTagsNoRepository(t *testing.T) {
+	svc := &Service{}
+
+	tags, err := svc.GetTags(context.Background(), "user1")
+	require.NoError(t, err)
+	assert.Empty(t, tags.Tags)
+	assert.Empty(t, tags.Collections)
+}
@@ -0,0 +1,293 @@
+package transform
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/shaftoe/savetoink/internal/consts"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+var (
+	// noteIDPattern matches fragment identifiers commonly used for footnote definitions,
+	// e.g. "fn1", "fn:1", "footnote-1", "cite_note-3", "endnote_2".
+	noteIDPattern = regexp.MustCompile(`(?i)(fn|foot|note|cite)`)
+
+	// markerPattern matches the visible text of a footnote reference, e.g. "1", "[2]", "*".
+	markerPattern = regexp.MustCompile(`^\[?\s*(\d{1,3}|[a-z]|[*†‡§])\s*\]?$`)
+
+	definitionElements = map[atom.Atom]bool{
+		atom.Li:    true,
+		atom.P:     true,
+		atom.Div:   true,
+		atom.Aside: true,
+		atom.Dd:    true,
+	}
+
+	containerElements = map[atom.Atom]bool{
+		atom.Div:     true,
+		atom.Section: true,
+		atom.Article: true,
+		atom.Main:    true,
+	}
+)
+
+type footnote struct {
+	number     int
+	definition *html.Node
+}
+
+// Footnotes detects footnote references and their definitions in content, moves the
+// definitions to an endnotes section at the end and marks them with epub:type
+// "noteref" and "footnote" so e-readers can show them as popups.
+// Content without footnotes is returned unchanged.
+func Footnotes(content string) (string, error) {
+	root, err := parseFragment(content)
+	if err != nil {
+		return "", err
+	}
+
+	ids := make(map[string]*html.Node)
+	order := make(map[*html.Node]int)
+	walk(root, func(node *html.Node) {
+		order[node] = len(order)
+		if id := getAttr(node, "id"); id != "" {
+			if _, exists := ids[id]; !exists {
+				ids[id] = node
+			}
+		}
+	})
+
+	var notes []*footnote
+	byDefinition := make(map[*html.Node]*footnote)
+	references := make(map[*html.Node]bool)
+
+	walk(root, func(node *html.Node) {
+		targetID, target := fragmentTarget(node, ids)
+		if target == nil || order[target] <= order[node] || !isNoteRef(node, targetID) {
+			return
+		}
+
+		definition := definitionOf(target, root)
+		if definition == nil || isAncestor(definition, node) {
+			return
+		}
+
+		note, exists := byDefinition[definition]
+		if !exists {
+			note = &footnote{number: len(notes) + 1, definition: definition}
+			byDefinition[definition] = note
+			notes = append(notes, note)
+		}
+
+		references[node] = true
+		if node.Parent != nil && node.Parent.DataAtom == atom.Sup {
+			references[node.Parent] = true
+		}
+
+		setAttr(node, "href", "#"+fmt.Sprintf(consts.FootnoteIDFormat, note.number))
+		setAttr(node, "epub:type", "noteref")
+		removeAttr(node, "target")
+	})
+
+	if len(notes) == 0 {
+		return content, nil
+	}
+
+	section := newElement(atom.Section,
+		html.Attribute{Key: "epub:type", Val: "endnotes"},
+		html.Attribute{Key: "role", Val: "doc-endnotes"},
+	)
+	heading := newElement(atom.H2)
+	heading.AppendChild(newText(consts.EndnotesTitle))
+	section.AppendChild(heading)
+
+	for _, note := range notes {
+		removeBacklinks(note.definition, ids, references)
+		section.AppendChild(buildFootnote(note))
+	}
+
+	contentContainer(root).AppendChild(section)
+
+	return renderFragment(root)
+}
+
+func fragmentTarget(node *html.Node, ids map[string]*html.Node) (string, *html.Node) {
+	if node.DataAtom != atom.A {
+		return "", nil
+	}
+
+	href := strings.TrimSpace(getAttr(node, "href"))
+	if len(href) < 2 || href[0] != '#' {
+		return "", nil
+	}
+
+	id := href[1:]
+	return id, ids[id]
+}
+
+func isNoteRef(link *html.Node, targetID string) bool {
+	if hasClass(link, "footnote") || hasClass(link, "noteref") || hasClass(link, "fnref") ||
+		getAttr(link, "epub:type") == "noteref" || getAttr(link, "role") == "doc-noteref" {
+		return true
+	}
+
+	marker := markerPattern.MatchString(strings.TrimSpace(textContent(link)))
+	if marker && link.Parent != nil && link.Parent.DataAtom == atom.Sup {
+		return true
+	}
+
+	return marker && noteIDPattern.MatchString(targetID)
+}
+
+// definitionOf returns the block element holding the footnote definition targeted by a reference.
+// Targets that are inline anchors resolve to their closest block ancestor.
+func definitionOf(target, root *html.Node) *html.Node {
+	for node := target; node != nil && node != root; node = node.Parent {
+		if definitionElements[node.DataAtom] {
+			return node
+		}
+	}
+	return nil
+}
+
+// removeBacklinks removes links from the definition pointing back to its references,
+// along with the wrappers left empty by the removal.
+func removeBacklinks(definition *html.Node, ids map[string]*html.Node, references map[*html.Node]bool) {
+	walk(definition, func(node *html.Node) {
+		if _, target := fragmentTarget(node, ids); target == nil || !references[target] {
+			return
+		}
+		// nested in a backlink already removed
+		if node == definition || !isAncestor(definition, node) {
+			return
+		}
+
+		for node.Parent != definition && onlyChild(node) {
+			node = node.Parent
+		}
+		node.Parent.RemoveChild(node)
+	})
+}
+
+func buildFootnote(note *footnote) *html.Node {
+	definition := note.definition
+	parent := definition.Parent
+
+	aside := newElement(atom.Aside,
+		html.Attribute{Key: "id", Val: fmt.Sprintf(consts.FootnoteIDFormat, note.number)},
+		html.Attribute{Key: "epub:type", Val: "footnote"},
+		html.Attribute{Key: "role", Val: "doc-footnote"},
+	)
+
+	parent.RemoveChild(definition)
+	if definition.DataAtom == atom.Li || definition.DataAtom == atom.Dd {
+		for child := definition.FirstChild; child != nil; child = definition.FirstChild {
+			definition.RemoveChild(child)
+			aside.AppendChild(child)
+		}
+	} else {
+		removeAttr(definition, "id")
+		aside.AppendChild(definition)
+	}
+
+	number := newText(fmt.Sprintf("%d. ", note.number))
+	if first := firstContentChild(aside); first != nil && first.DataAtom == atom.P {
+		first.InsertBefore(number, first.FirstChild)
+	} else {
+		aside.InsertBefore(number, aside.FirstChild)
+	}
+
+	for parent != nil && parent.Parent != nil && parent.DataAtom != atom.Body && isEmpty(parent) {
+		next := parent.Parent
+		removeOrphanHeading(parent)
+		next.RemoveChild(parent)
+		parent = next
+	}
+
+	return aside
+}
+
+// removeOrphanHeading removes the heading introducing a footnotes container about to be removed,
+// e.g. "References", when nothing but other headings would follow it.
+func removeOrphanHeading(container *html.Node) {
+	previous := siblingElement(container, func(n *html.Node) *html.Node { return n.PrevSibling })
+	if previous == nil || !isHeading(previous) {
+		return
+	}
+
+	next := siblingElement(container, func(n *html.Node) *html.Node { return n.NextSibling })
+	if next == nil || isHeading(next) {
+		previous.Parent.RemoveChild(previous)
+	}
+}
+
+func siblingElement(node *html.Node, step func(*html.Node) *html.Node) *html.Node {
+	for sibling := step(node); sibling != nil; sibling = step(sibling) {
+		if sibling.Type != html.TextNode || strings.TrimSpace(sibling.Data) != "" {
+			return sibling
+		}
+	}
+	return nil
+}
+
+func isHeading(node *html.Node) bool {
+	switch node.DataAtom {
+	case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6:
+		return true
+	default:
+		return false
+	}
+}
+
+// contentContainer returns the innermost wrapper element holding the whole content,
+// so appended sections stay next to the rest of the article.
+func contentContainer(root *html.Node) *html.Node {
+	container := root
+	for {
+		var element *html.Node
+		count := 0
+		for child := container.FirstChild; child != nil; child = child.NextSibling {
+			if child.Type == html.TextNode && strings.TrimSpace(child.Data) == "" {
+				continue
+			}
+			element = child
+			count++
+		}
+
+		if count != 1 || element.Type != html.ElementNode || !containerElements[element.DataAtom] {
+			return container
+		}
+		container = element
+	}
+}
+
+// onlyChild reports whether node is the only non-whitespace child of its parent.
+func onlyChild(node *html.Node) bool {
+	for sibling := node.Parent.FirstChild; sibling != nil; sibling = sibling.NextSibling {
+		if sibling != node && !(sibling.Type == html.TextNode && strings.TrimSpace(sibling.Data) == "") {
+			return false
+		}
+	}
+	return true
+}
+
+func firstContentChild(node *html.Node) *html.Node {
+	for child := node.FirstChild; child != nil; child = child.NextSibling {
+		if child.Type != html.TextNode || strings.TrimSpace(child.Data) != "" {
+			return child
+		}
+	}
+	return nil
+}
+
+func isAncestor(ancestor, node *html.Node) bool {
+	for n := node; n != nil; n = n.Parent {
+		if n == ancestor {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,115 @@
+package transform
+
+import (
+	"strings"
+	"testing"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+func TestFootnotes(t *testing.T) {
+	tests := []struct {
+		name        string
+		content     string
+		contains    []string
+		notContains []string
+	}{
+		{
+			name: "markdown style footnotes",
+			content: `<p>Claim<sup id="fnref:1"><a href="#fn:1" class="footnote-ref">1</a></sup>.</p>` +
+				`<div class="footnotes"><hr/><ol><li id="fn:1"><p>Source of the claim.` +
+				` <a href="#fnref:1" class="footnote-backref">↩</a></p></li></ol></div>`,
+			contains: []string{
+				`<a href="#note-1" class="footnote-ref" epub:type="noteref">1</a>`,
+				`<aside id="note-1" epub:type="footnote" role="doc-footnote"><p>1. Source of the claim. </p></aside>`,
+				`<section epub:type="endnotes" role="doc-endnotes"><h2>Notes</h2>`,
+			},
+			notContains: []string{`class="footnotes"`, "↩", `id="fn:1"`},
+		},
+		{
+			name: "wikipedia style references",
+			content: `<p>Fact<sup id="cite_ref-1" class="reference"><a href="#cite_note-1">[1]</a></sup>.</p>` +
+				`<h2>References</h2><ol class="references"><li id="cite_note-1"><span class="mw-cite-backlink"><b>` +
+				`<a href="#cite_ref-1">^</a></b></span> <span class="reference-text">A book.</span></li></ol>`,
+			contains: []string{
+				`<a href="#note-1" epub:type="noteref">[1]</a>`,
+				`<span class="reference-text">A book.</span></aside>`,
+			},
+			notContains: []string{"mw-cite-backlink", `class="references"`, "References"},
+		},
+		{
+			name: "substack style footnotes",
+			content: `<div><p>Text<a class="footnote-anchor" id="footnote-anchor-1" href="#footnote-1">1</a></p>` +
+				`<div class="footnote"><a id="footnote-1" href="#footnote-anchor-1" class="footnote-number">1</a>` +
+				`<div class="footnote-content"><p>Aside text.</p></div></div></div>`,
+			contains: []string{
+				`href="#note-1" epub:type="noteref">1</a>`,
+				`<aside id="note-1" epub:type="footnote" role="doc-footnote">1. <div class="footnote">`,
+				`Aside text.`,
+				`</section></div>`,
+			},
+			notContains: []string{"footnote-number"},
+		},
+		{
+			name:        "repeated references share a note",
+			content:     `<p>A<sup><a href="#n1">1</a></sup> B<sup><a href="#n1">1</a></sup></p><p id="n1">Shared.</p>`,
+			contains:    []string{`<aside id="note-1"`},
+			notContains: []string{`id="note-2"`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Footnotes(tt.content)
+			if err != nil {
+				t.Fatalf("Footnotes() unexpected error = %v", err)
+			}
+
+			for _, expected := range tt.contains {
+				if !strings.Contains(result, expected) {
+					t.Errorf("expected result to contain %q, got %q", expected, result)
+				}
+			}
+
+			for _, unexpected := range tt.notContains {
+				if strings.Contains(result, unexpected) {
+					t.Errorf("expected result not to contain %q, got %q", unexpected, result)
+				}
+			}
+		})
+	}
+}
+
+func TestFootnotes_NoFootnotes(t *testing.T) {
+	content := `<p>Jump to <a href="#section">section</a> or <a href="https://example.com">elsewhere</a>.</p>` +
+		`<h2 id="section">Section</h2>`
+
+	result, err := Footnotes(content)
+	if err != nil {
+		t.Fatalf("Footnotes() unexpected error = %v", err)
+	}
+
+	if result != content {
+		t.Errorf("expected content unchanged, got %q", result)
+	}
+}
+
+func TestRemoveBacklinks_Nested(t *testing.T) {
+	reference := newElement(atom.Sup, html.Attribute{Key: "id", Val: "ref-1"})
+	definition := newElement(atom.Li, html.Attribute{Key: "id", Val: "note-1"})
+
+	outer := newElement(atom.A, html.Attribute{Key: "href", Val: "#ref-1"})
+	inner := newElement(atom.A, html.Attribute{Key: "href", Val: "#ref-1"})
+	inner.AppendChild(newText("^"))
+	outer.AppendChild(inner)
+	definition.AppendChild(outer)
+	definition.AppendChild(newText(" A book."))
+
+	ids := map[string]*html.Node{"ref-1": reference, "note-1": definition}
+	removeBacklinks(definition, ids, map[*html.Node]bool{reference: true})
+
+	if got := textContent(definition); got != " A book." {
+		t.Errorf("expected the backlinks to be removed, got %q", got)
+	}
+}
@@ -0,0 +1,134 @@
+// Package transform provides content transformations applied to extracted articles before EPUB generation.
+package transform
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// parseFragment parses an HTML fragment into a detached <body> element holding its nodes.
+func parseFragment(content string) (*html.Node, error) {
+	body := &html.Node{
+		Type:     html.ElementNode,
+		Data:     "body",
+		DataAtom: atom.Body,
+	}
+
+	nodes, err := html.ParseFragment(strings.NewReader(content), body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse content: %w", err)
+	}
+
+	for _, node := range nodes {
+		body.AppendChild(node)
+	}
+
+	return body, nil
+}
+
+// renderFragment renders the children of root back to an HTML string.
+func renderFragment(root *html.Node) (string, error) {
+	var buf bytes.Buffer
+	for child := root.FirstChild; child != nil; child = child.NextSibling {
+		if err := html.Render(&buf, child); err != nil {
+			return "", fmt.Errorf("failed to render content: %w", err)
+		}
+	}
+	return buf.String(), nil
+}
+
+func getAttr(node *html.Node, key string) string {
+	for _, attr := range node.Attr {
+		if attr.Key == key {
+			return attr.Val
+		}
+	}
+	return ""
+}
+
+func setAttr(node *html.Node, key, val string) {
+	for i, attr := range node.Attr {
+		if attr.Key == key {
+			node.Attr[i].Val = val
+			return
+		}
+	}
+	node.Attr = append(node.Attr, html.Attribute{Key: key, Val: val})
+}
+
+func removeAttr(node *html.Node, key string) {
+	attrs := node.Attr[:0]
+	for _, attr := range node.Attr {
+		if attr.Key != key {
+			attrs = append(attrs, attr)
+		}
+	}
+	node.Attr = attrs
+}
+
+func hasClass(node *html.Node, substr string) bool {
+	return strings.Contains(strings.ToLower(getAttr(node, "class")), substr)
+}
+
+func textContent(node *html.Node) string {
+	if node.Type == html.TextNode {
+		return node.Data
+	}
+
+	var sb strings.Builder
+	for child := node.FirstChild; child != nil; child = child.NextSibling {
+		sb.WriteString(textContent(child))
+	}
+	return sb.String()
+}
+
+// walk calls fn for every element node under root in document order.
+// Nodes are collected first so fn can safely modify the tree.
+func walk(root *html.Node, fn func(*html.Node)) {
+	var elements []*html.Node
+	var collect func(*html.Node)
+	collect = func(node *html.Node) {
+		for child := node.FirstChild; child != nil; child = child.NextSibling {
+			if child.Type == html.ElementNode {
+				elements = append(elements, child)
+			}
+			collect(child)
+		}
+	}
+	collect(root)
+
+	for _, element := range elements {
+		fn(element)
+	}
+}
+
+func isEmpty(node *html.Node) bool {
+	if strings.TrimSpace(textContent(node)) != "" {
+		return false
+	}
+
+	hasMedia := false
+	walk(node, func(n *html.Node) {
+		if n.DataAtom == atom.Img || n.DataAtom == atom.Svg {
+			hasMedia = true
+		}
+	})
+	return !hasMedia
+}
+
+func newElement(a atom.Atom, attrs ...html.Attribute) *html.Node {
+	return &html.Node{
+		Type:     html.ElementNode,
+		Data:     a.String(),
+		DataAtom: a,
+		Attr:     attrs,
+	}
+}
+
+func newText(text string) *html.Node {
+	return &html.Node{Type: html.TextNode, Data: text}
+}
@@ -0,0 +1,123 @@
+package transform
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/shaftoe/savetoink/internal/consts"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// ParseLinkMode parses a link handling mode, defaulting to consts.LinkModeKeep when empty.
+func ParseLinkMode(mode string) (consts.LinkMode, error) {
+	switch consts.LinkMode(mode) {
+	case "":
+		return consts.LinkModeKeep, nil
+	case consts.LinkModeKeep, consts.LinkModeStrip, consts.LinkModeEndnotes:
+		return consts.LinkMode(mode), nil
+	default:
+		return "", fmt.Errorf("unsupported link mode %q, must be one of: %s, %s, %s",
+			mode, consts.LinkModeKeep, consts.LinkModeStrip, consts.LinkModeEndnotes)
+	}
+}
+
+// Links rewrites hyperlinks in content according to mode. Relative URLs are resolved against
+// baseURL first. Links within the document (e.g. footnote references) are always kept:
+//   - consts.LinkModeKeep keeps external links
+//   - consts.LinkModeStrip replaces external links with their text
+//   - consts.LinkModeEndnotes replaces external links with numbered references to a "Links" appendix
+func Links(content, baseURL string, mode consts.LinkMode) (string, error) {
+	root, err := parseFragment(content)
+	if err != nil {
+		return "", err
+	}
+
+	base, err := url.Parse(baseURL)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse base url: %w", err)
+	}
+
+	numbers := make(map[string]int)
+	var links []string
+
+	walk(root, func(node *html.Node) {
+		if node.DataAtom != atom.A {
+			return
+		}
+
+		href := strings.TrimSpace(getAttr(node, "href"))
+		if href == "" || strings.HasPrefix(href, "#") {
+			return
+		}
+
+		resolved, parseErr := base.Parse(href)
+		if parseErr != nil {
+			return
+		}
+		setAttr(node, "href", resolved.String())
+
+		if resolved.Scheme != "http" && resolved.Scheme != "https" {
+			return
+		}
+
+		switch mode {
+		case consts.LinkModeStrip:
+			unwrap(node)
+		case consts.LinkModeEndnotes:
+			number, exists := numbers[resolved.String()]
+			if !exists {
+				links = append(links, resolved.String())
+				number = len(links)
+				numbers[resolved.String()] = number
+			}
+			node.Parent.InsertBefore(linkReference(number), node.NextSibling)
+			unwrap(node)
+		case consts.LinkModeKeep:
+		}
+	})
+
+	if len(links) > 0 {
+		contentContainer(root).AppendChild(linksAppendix(links))
+	}
+
+	return renderFragment(root)
+}
+
+// unwrap replaces node with its children.
+func unwrap(node *html.Node) {
+	parent := node.Parent
+	for child := node.FirstChild; child != nil; child = node.FirstChild {
+		node.RemoveChild(child)
+		parent.InsertBefore(child, node)
+	}
+	parent.RemoveChild(node)
+}
+
+func linkReference(number int) *html.Node {
+	sup := newElement(atom.Sup)
+	link := newElement(atom.A, html.Attribute{Key: "href", Val: "#" + fmt.Sprintf(consts.LinkIDFormat, number)})
+	link.AppendChild(newText(fmt.Sprintf("[%d]", number)))
+	sup.AppendChild(link)
+	return sup
+}
+
+func linksAppendix(links []string) *html.Node {
+	section := newElement(atom.Section, html.Attribute{Key: "class", Val: "links"})
+	heading := newElement(atom.H2)
+	heading.AppendChild(newText(consts.LinksTitle))
+	section.AppendChild(heading)
+
+	list := newElement(atom.Ol)
+	for i, link := range links {
+		item := newElement(atom.Li, html.Attribute{Key: "id", Val: fmt.Sprintf(consts.LinkIDFormat, i+1)})
+		anchor := newElement(atom.A, html.Attribute{Key: "href", Val: link})
+		anchor.AppendChild(newText(link))
+		item.AppendChild(anchor)
+		list.AppendChild(item)
+	}
+	section.AppendChild(list)
+
+	return section
+}
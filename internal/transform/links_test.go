@@ -0,0 +1,103 @@
+package transform
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/shaftoe/savetoink/internal/consts"
+)
+
+const linksContent = `<div><p>See <a href="/docs">the docs</a>, <a href="https://example.org/a">A</a>` +
+	` and <a href="https://example.org/a">A again</a>.` +
+	`<sup><a href="#note-1" epub:type="noteref">1</a></sup> <a href="mailto:me@example.com">Mail</a></p></div>`
+
+func TestLinks(t *testing.T) {
+	tests := []struct {
+		name        string
+		mode        consts.LinkMode
+		contains    []string
+		notContains []string
+	}{
+		{
+			name: "keep resolves relative urls",
+			mode: consts.LinkModeKeep,
+			contains: []string{
+				`<a href="https://example.com/docs">the docs</a>`,
+				`<a href="https://example.org/a">A</a>`,
+				`<a href="#note-1" epub:type="noteref">1</a>`,
+			},
+			notContains: []string{consts.LinksTitle},
+		},
+		{
+			name: "strip keeps text and internal links",
+			mode: consts.LinkModeStrip,
+			contains: []string{
+				`See the docs, A and A again.`,
+				`<a href="#note-1" epub:type="noteref">1</a>`,
+				`<a href="mailto:me@example.com">Mail</a>`,
+			},
+			notContains: []string{`href="https://`, consts.LinksTitle},
+		},
+		{
+			name: "endnotes numbers links and lists urls",
+			mode: consts.LinkModeEndnotes,
+			contains: []string{
+				`the docs<sup><a href="#link-1">[1]</a></sup>`,
+				`A<sup><a href="#link-2">[2]</a></sup>`,
+				`A again<sup><a href="#link-2">[2]</a></sup>`,
+				`<a href="#note-1" epub:type="noteref">1</a>`,
+				`<section class="links"><h2>Links</h2><ol>` +
+					`<li id="link-1"><a href="https://example.com/docs">https://example.com/docs</a></li>` +
+					`<li id="link-2"><a href="https://example.org/a">https://example.org/a</a></li></ol></section></div>`,
+			},
+			notContains: []string{`id="link-3"`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Links(linksContent, "https://example.com/post", tt.mode)
+			if err != nil {
+				t.Fatalf("Links() unexpected error = %v", err)
+			}
+
+			for _, expected := range tt.contains {
+				if !strings.Contains(result, expected) {
+					t.Errorf("expected result to contain %q, got %q", expected, result)
+				}
+			}
+
+			for _, unexpected := range tt.notContains {
+				if strings.Contains(result, unexpected) {
+					t.Errorf("expected result not to contain %q, got %q", unexpected, result)
+				}
+			}
+		})
+	}
+}
+
+func TestParseLinkMode(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected consts.LinkMode
+		wantErr  bool
+	}{
+		{input: "", expected: consts.LinkModeKeep},
+		{input: "keep", expected: consts.LinkModeKeep},
+		{input: "strip", expected: consts.LinkModeStrip},
+		{input: "endnotes", expected: consts.LinkModeEndnotes},
+		{input: "footnotes", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			mode, err := ParseLinkMode(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseLinkMode() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if mode != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, mode)
+			}
+		})
+	}
+}